@@ -0,0 +1,402 @@
+package contests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/metrics"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxContestQuestions = 30
+	defaultPoints       = 10
+	maxPoints           = 100
+	// games of a full regular season, the most wins a team can get
+	gamesInSeason = 82
+)
+
+var awards = map[string]string{
+	"mvp":  "Most Valuable Player",
+	"roy":  "Rookie of the Year",
+	"dpoy": "Defensive Player of the Year",
+	"smoy": "Sixth Man of the Year",
+	"mip":  "Most Improved Player",
+}
+
+type ContestsHandler struct {
+	DB database.Database
+}
+
+type ContestPayload struct {
+	Name        string                               `json:"name"`
+	Description string                               `json:"description"`
+	Season      string                               `json:"season"`
+	Deadline    time.Time                            `json:"deadline"`
+	UserID      int64                                `json:"userid"`
+	Questions   []databasestructs.PredictionQuestion `json:"questions"`
+}
+
+type PredictionsPayload struct {
+	ContestID   int64                        `json:"contestid"`
+	UserID      int64                        `json:"userid"`
+	Predictions []databasestructs.Prediction `json:"predictions"`
+}
+
+type ContestResponse struct {
+	databasestructs.PredictionContest
+	IsLocked  bool                                 `json:"is_locked"`
+	Questions []databasestructs.PredictionQuestion `json:"questions"`
+}
+
+type LeaderboardResponse struct {
+	ContestID int64                             `json:"contestid"`
+	Resolved  bool                              `json:"resolved"`
+	Standings []databasestructs.ContestStanding `json:"standings"`
+}
+
+func parseID(r *http.Request, key string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[key], 10, 64)
+}
+
+func (c ContestsHandler) CreateContest(w http.ResponseWriter, r *http.Request) {
+	var payload ContestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload.Name = strings.TrimSpace(payload.Name)
+	if payload.Name == "" || len(payload.Name) > 128 {
+		http.Error(w, "name has to be between 1 and 128 characters", http.StatusBadRequest)
+		return
+	}
+
+	if len(payload.Description) > 256 {
+		http.Error(w, "description can't be longer than 256 characters", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := strconv.Atoi(payload.Season); err != nil {
+		http.Error(w, "season has to be the year the season ends in", http.StatusBadRequest)
+		return
+	}
+
+	if !payload.Deadline.After(time.Now()) {
+		http.Error(w, "deadline has to be in the future", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.validateQuestions(payload.Questions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contest := databasestructs.PredictionContest{Name: payload.Name, Description: payload.Description, Season: payload.Season, Deadline: payload.Deadline.Unix(), UserID: payload.UserID}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// a contest missing some of its questions would be open for predictions, so it's only kept whole
+	var contestID int64
+	err := c.DB.WithTx(ctx, func(tx database.Database) error {
+		result, err := tx.InsertContest(contest)
+		if err != nil {
+			return err
+		}
+
+		contestID, err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for i, question := range payload.Questions {
+			question.ContestID = contestID
+			question.Position = int64(i)
+			if _, err := tx.InsertContestQuestion(question); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Created contest with ID: %d", contestID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (c ContestsHandler) GetContests(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	contests, err := c.DB.GetContests(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(contests)
+}
+
+func (c ContestsHandler) GetContestByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "contestid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contest, err := c.DB.GetContestByID(id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "contest not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	questions, err := c.DB.GetContestQuestions(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := ContestResponse{PredictionContest: contest, IsLocked: isLocked(contest, time.Now()), Questions: questions}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SubmitPredictions stores the user's answers, they can be changed until the contest locks
+func (c ContestsHandler) SubmitPredictions(w http.ResponseWriter, r *http.Request) {
+	var payload PredictionsPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contest, err := c.DB.GetContestByID(payload.ContestID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "contest not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if isLocked(contest, time.Now()) {
+		http.Error(w, "predictions for this contest are locked", http.StatusConflict)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	questions, err := c.DB.GetContestQuestions(ctx, payload.ContestID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	byID := make(map[int64]databasestructs.PredictionQuestion, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+
+	for _, prediction := range payload.Predictions {
+		question, ok := byID[prediction.QuestionID]
+		if !ok {
+			http.Error(w, fmt.Sprintf("question %d is not part of the contest", prediction.QuestionID), http.StatusBadRequest)
+			return
+		}
+
+		if err := c.validatePrediction(question, prediction); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, prediction := range payload.Predictions {
+		prediction.UserID = payload.UserID
+		if byID[prediction.QuestionID].Type == "win_total" {
+			prediction.Value = ""
+		} else {
+			prediction.Number = nil
+		}
+
+		_, err = c.DB.InsertPrediction(prediction)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := fmt.Sprintf("Saved %d predictions for contest %d", len(payload.Predictions), payload.ContestID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (c ContestsHandler) GetUserPredictions(w http.ResponseWriter, r *http.Request) {
+	contestID, err := parseID(r, "contestid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := parseID(r, "userid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	predictions, err := c.DB.GetUserPredictions(ctx, contestID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(predictions)
+}
+
+func (c ContestsHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "contestid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contest, err := c.DB.GetContestByID(id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "contest not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	standings, err := c.DB.GetContestLeaderboard(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range standings {
+		standing := &standings[i]
+		standing.Score = round(standing.Score)
+
+		// users with the same score share the rank
+		standing.Rank = int64(i + 1)
+		if i > 0 && standings[i-1].Score == standing.Score {
+			standing.Rank = standings[i-1].Rank
+		}
+	}
+
+	response := LeaderboardResponse{ContestID: id, Resolved: contest.Resolved, Standings: standings}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c ContestsHandler) validateQuestions(questions []databasestructs.PredictionQuestion) error {
+	if len(questions) == 0 || len(questions) > maxContestQuestions {
+		return fmt.Errorf("a contest needs between 1 and %d questions", maxContestQuestions)
+	}
+
+	for i := range questions {
+		question := &questions[i]
+		if question.Points == 0 {
+			question.Points = defaultPoints
+		}
+		if question.Points < 1 || question.Points > maxPoints {
+			return fmt.Errorf("question %d: points have to be between 1 and %d", i+1, maxPoints)
+		}
+
+		switch question.Type {
+		case "win_total":
+			question.Target = strings.ToUpper(question.Target)
+			if _, err := c.DB.SelectTeamByAbbrevation(question.Target); err != nil {
+				return fmt.Errorf("question %d: unknown team %q", i+1, question.Target)
+			}
+			if question.Prompt == "" {
+				question.Prompt = fmt.Sprintf("How many games will %s win?", question.Target)
+			}
+		case "award":
+			question.Target = strings.ToLower(question.Target)
+			name, ok := awards[question.Target]
+			if !ok {
+				return fmt.Errorf("question %d: unknown award %q", i+1, question.Target)
+			}
+			if question.Prompt == "" {
+				question.Prompt = fmt.Sprintf("Who will win %s?", name)
+			}
+		case "stat_leader":
+			question.Target = strings.ToLower(question.Target)
+			if _, ok := metrics.Find(question.Target); !ok {
+				return fmt.Errorf("question %d: unknown stat %q", i+1, question.Target)
+			}
+			if question.Prompt == "" {
+				question.Prompt = fmt.Sprintf("Who will lead the league in %s?", question.Target)
+			}
+		default:
+			return fmt.Errorf("question %d: unknown type %q", i+1, question.Type)
+		}
+
+		question.Prompt = strings.TrimSpace(question.Prompt)
+		if len(question.Prompt) > 256 {
+			return fmt.Errorf("question %d: prompt can't be longer than 256 characters", i+1)
+		}
+	}
+
+	return nil
+}
+
+func (c ContestsHandler) validatePrediction(question databasestructs.PredictionQuestion, prediction databasestructs.Prediction) error {
+	if question.Type == "win_total" {
+		if prediction.Number == nil || math.IsNaN(*prediction.Number) || *prediction.Number < 0 || *prediction.Number > gamesInSeason {
+			return fmt.Errorf("question %d: predict between 0 and %d wins", question.ID, gamesInSeason)
+		}
+		return nil
+	}
+
+	if exists, err := c.DB.CheckPlayerExists(prediction.Value); err != nil || !exists {
+		return fmt.Errorf("question %d: unknown player %q", question.ID, prediction.Value)
+	}
+
+	return nil
+}
+
+func isLocked(contest databasestructs.PredictionContest, now time.Time) bool {
+	return contest.Resolved || now.Unix() >= contest.Deadline
+}
+
+func round(value float64) float64 {
+	return math.Round(value*10) / 10
+}
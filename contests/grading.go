@@ -0,0 +1,213 @@
+package contests
+
+import (
+	"context"
+	"log"
+	"math"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/metrics"
+	"sportsvoting/seasonresults"
+	"time"
+)
+
+const (
+	gradingTimeout = 2 * time.Minute
+	teamsInLeague  = 30
+	// games a player needs to qualify for the per game leaders, same as the league rule
+	leaderMinGames = 65
+)
+
+type seasonResults struct {
+	standings map[string]databasestructs.TeamStanding
+	awards    map[string]string
+	// the regular season is over, every team played all of its games
+	complete bool
+}
+
+// GradeContests refreshes the results of every season with locked contests and grades the questions whose outcome is final.
+// A contest is resolved once all of its questions are graded.
+func GradeContests(db database.Database, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), gradingTimeout)
+	defer cancel()
+
+	pending, err := db.GetUnresolvedContests(ctx, now.Unix())
+	if err != nil {
+		return err
+	}
+
+	results := make(map[string]*seasonResults)
+	for _, contest := range pending {
+		season, ok := results[contest.Season]
+		if !ok {
+			if err := seasonresults.InsertSeasonResults(db, contest.Season); err != nil {
+				log.Println(err)
+			}
+
+			season, err = getSeasonResults(ctx, db, contest.Season)
+			if err != nil {
+				return err
+			}
+			results[contest.Season] = season
+		}
+
+		if err := gradeContest(ctx, db, contest, season); err != nil {
+			log.Printf("Couldn't grade contest %d: %v\n", contest.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func gradeContest(ctx context.Context, db database.Database, contest databasestructs.PredictionContest, results *seasonResults) error {
+	questions, err := db.GetContestQuestions(ctx, contest.ID)
+	if err != nil {
+		return err
+	}
+
+	graded := 0
+	for _, question := range questions {
+		if question.Answer != nil {
+			graded++
+			continue
+		}
+
+		answer, answerNumber, final, err := resolveQuestion(ctx, db, contest.Season, question, results)
+		if err != nil {
+			return err
+		}
+
+		if !final {
+			continue
+		}
+
+		if err := scoreQuestion(ctx, db, question, answer, answerNumber); err != nil {
+			return err
+		}
+
+		_, err = db.ResolveContestQuestion(question.ID, answer, answerNumber)
+		if err != nil {
+			return err
+		}
+		graded++
+	}
+
+	if graded == len(questions) {
+		_, err = db.SetContestResolved(contest.ID)
+		return err
+	}
+
+	return nil
+}
+
+// resolveQuestion returns the correct answer of the question once the data it depends on is final
+func resolveQuestion(ctx context.Context, db database.Database, season string, question databasestructs.PredictionQuestion, results *seasonResults) (string, *float64, bool, error) {
+	switch question.Type {
+	case "win_total":
+		standing, ok := results.standings[question.Target]
+		if !ok || !results.complete && standing.Wins+standing.Losses < gamesInSeason {
+			return "", nil, false, nil
+		}
+
+		wins := float64(standing.Wins)
+		return standing.TeamAbbr, &wins, true, nil
+	case "award":
+		playerID, ok := results.awards[question.Target]
+		return playerID, nil, ok, nil
+	case "stat_leader":
+		if !results.complete {
+			return "", nil, false, nil
+		}
+
+		leader, err := statLeader(ctx, db, season, question.Target)
+		if err != nil || leader == "" {
+			return "", nil, false, err
+		}
+		return leader, nil, true, nil
+	}
+
+	return "", nil, false, nil
+}
+
+// scoreQuestion gives full points for the right player, win totals lose a point for every win they're off by
+func scoreQuestion(ctx context.Context, db database.Database, question databasestructs.PredictionQuestion, answer string, answerNumber *float64) error {
+	predictions, err := db.GetQuestionPredictions(ctx, question.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, prediction := range predictions {
+		var score float64
+		if question.Type == "win_total" {
+			if prediction.Number != nil && answerNumber != nil {
+				score = math.Max(0, float64(question.Points)-math.Abs(*prediction.Number-*answerNumber))
+			}
+		} else if prediction.Value == answer {
+			score = float64(question.Points)
+		}
+
+		_, err := db.SetPredictionScore(question.ID, prediction.UserID, round(score))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func statLeader(ctx context.Context, db database.Database, season, stat string) (string, error) {
+	metric, ok := metrics.Find(stat)
+	if !ok {
+		return "", nil
+	}
+
+	filter := databasestructs.LeadersFilter{Season: season, Stat: stat, Ascending: metric.LowerIsBetter, Limit: 1}
+	if !metric.Cumulative {
+		filter.MinGames = leaderMinGames
+	}
+
+	leaders, _, err := db.GetStatLeaders(ctx, filter)
+	if err != nil || len(leaders) == 0 {
+		return "", err
+	}
+
+	return leaders[0].ID, nil
+}
+
+func getSeasonResults(ctx context.Context, db database.Database, season string) (*seasonResults, error) {
+	results := &seasonResults{standings: map[string]databasestructs.TeamStanding{}, awards: map[string]string{}}
+
+	standings, err := db.GetTeamStandings(ctx, season)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, standing := range standings {
+		results.standings[standing.TeamAbbr] = standing
+	}
+
+	awards, err := db.GetSeasonAwards(ctx, season)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, award := range awards {
+		results.awards[award.Award] = award.PlayerID
+	}
+
+	results.complete = len(results.standings) >= teamsInLeague && (len(results.awards) > 0 || playedFullSeason(results.standings))
+	return results, nil
+}
+
+// playedFullSeason reports whether every team played a full schedule. Shortened seasons like 1999, 2012 and 2020,
+// where teams didn't even play the same number of games, only count as over once their awards are out,
+// those are published after the regular season.
+func playedFullSeason(standings map[string]databasestructs.TeamStanding) bool {
+	for _, standing := range standings {
+		if standing.Wins+standing.Losses < gamesInSeason {
+			return false
+		}
+	}
+
+	return true
+}
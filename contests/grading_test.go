@@ -0,0 +1,80 @@
+package contests
+
+import (
+	"context"
+	"fmt"
+	"sportsvoting/databasestructs"
+	"sportsvoting/internal/testutil"
+	"testing"
+)
+
+func TestShortenedSeasonIsFinalOnceAwardsAreOut(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	exec := testutil.Must(t)
+
+	// the 2020 teams finished the season between 63 and 75 games
+	for i := 0; i < teamsInLeague; i++ {
+		abbr := fmt.Sprintf("T%02d", i)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: abbr, Name: abbr}))
+		exec(db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2020", TeamAbbr: abbr, Wins: 40, Losses: int64(23 + i%13)}))
+	}
+	exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "antetgi01", Name: "Giannis Antetokounmpo", TeamAbbr: "T00"}))
+
+	winTotal := databasestructs.PredictionQuestion{Type: "win_total", Target: "T00"}
+	resolve := func() (bool, string) {
+		t.Helper()
+		results, err := getSeasonResults(ctx, db, "2020")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, wins, final, err := resolveQuestion(ctx, db, "2020", winTotal, results)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wins == nil {
+			return final, "<nil>"
+		}
+		return final, fmt.Sprint(*wins)
+	}
+
+	if final, _ := resolve(); final {
+		t.Error("win total is final before the awards are out")
+	}
+
+	exec(db.InsertSeasonAward(databasestructs.SeasonAward{Season: "2020", Award: "mvp", PlayerID: "antetgi01"}))
+	if final, wins := resolve(); !final || wins != "40" {
+		t.Errorf("win total after the awards: final = %v, wins = %s, want true, 40", final, wins)
+	}
+}
+
+func TestFullSeasonIsFinalBeforeAwards(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewDB(t)
+	exec := testutil.Must(t)
+
+	for i := 0; i < teamsInLeague; i++ {
+		abbr := fmt.Sprintf("T%02d", i)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: abbr, Name: abbr}))
+		exec(db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2024", TeamAbbr: abbr, Wins: 41, Losses: 41}))
+	}
+
+	results, err := getSeasonResults(ctx, db, "2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results.complete {
+		t.Error("season where every team played 82 games isn't complete")
+	}
+
+	// one team short of a full schedule keeps the season open
+	exec(db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2024", TeamAbbr: "T05", Wins: 41, Losses: 40}))
+	results, err = getSeasonResults(ctx, db, "2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.complete {
+		t.Error("season with a game left is complete")
+	}
+}
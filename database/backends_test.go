@@ -0,0 +1,893 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/database/memory_db"
+	"sportsvoting/database/sqlite_db"
+	"sportsvoting/databasestructs"
+	"sportsvoting/internal/testutil"
+	"sportsvoting/migrate"
+	"testing"
+
+	migrateDatabase "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+)
+
+// testDBName is the database the MySQL and PostgreSQL tests run in, everything in it is dropped first
+const testDBName = "nba_test"
+
+func TestMain(m *testing.M) {
+	// the migrations are read relative to the project root
+	if err := os.Chdir(".."); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+type backend struct {
+	name string
+	open func(t *testing.T) database.Database
+}
+
+// backends lists the databases the repository tests run on, MySQL and PostgreSQL only run when
+// TEST_MYSQL_ADDRESS or TEST_POSTGRES_ADDRESS point at a server with an nba_test database DBUSER can drop tables in
+func backends() []backend {
+	list := []backend{
+		{"sqlite", func(t *testing.T) database.Database {
+			return openMigrated(t, database.Config{DbType: database.SQLITE, Addr: sqlite_db.MEMORY}, nil)
+		}},
+		{"memory", func(t *testing.T) database.Database {
+			return memory_db.NewDB()
+		}},
+	}
+
+	if addr := os.Getenv("TEST_MYSQL_ADDRESS"); addr != "" {
+		list = append(list, backend{"mysql", func(t *testing.T) database.Database {
+			return openMigrated(t, database.Config{DbType: database.MYSQL, DbName: testDBName, Addr: addr}, func(db database.Database) (migrateDatabase.Driver, error) {
+				return mysql.WithInstance(db.GetDB(), &mysql.Config{})
+			})
+		}})
+	}
+	if addr := os.Getenv("TEST_POSTGRES_ADDRESS"); addr != "" {
+		list = append(list, backend{"postgres", func(t *testing.T) database.Database {
+			return openMigrated(t, database.Config{DbType: database.POSTGRES, DbName: testDBName, Addr: addr}, func(db database.Database) (migrateDatabase.Driver, error) {
+				return postgres.WithInstance(db.GetDB(), &postgres.Config{})
+			})
+		}})
+	}
+
+	return list
+}
+
+// openMigrated connects to the database, drops what an earlier test left behind with the driver of the server and runs the migrations
+func openMigrated(t *testing.T, conf database.Config, driver func(db database.Database) (migrateDatabase.Driver, error)) database.Database {
+	t.Helper()
+	db, err := database.NewDB(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.CloseConnection)
+
+	if driver != nil {
+		d, err := driver(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Drop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := migrate.RunMigrations(db.GetDB(), conf.DbType); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// forEachBackend runs the test on a fresh database of every backend
+func forEachBackend(t *testing.T, test func(t *testing.T, db database.Database)) {
+	for _, b := range backends() {
+		b := b
+		t.Run(b.name, func(t *testing.T) {
+			test(t, b.open(t))
+		})
+	}
+}
+
+// seed inserts two users, one of them a panelist, and a custom poll with the candidates Alpha and Bravo
+func seed(t *testing.T, db database.Database) {
+	t.Helper()
+	exec := testutil.Must(t)
+
+	exec(db.InsertNewUser(databasestructs.User{Username: "panelist", CreatedAt: 1}))
+	exec(db.InsertNewUser(databasestructs.User{Username: "fan", CreatedAt: 1}))
+	exec(db.InsertUserRoles(databasestructs.Role{UserID: 1, Role: "user,panelist"}))
+	exec(db.InsertUserRoles(databasestructs.Role{UserID: 2, Role: "user"}))
+	exec(db.InsertPolls(databasestructs.Poll{Name: "custom", SelectedStats: "Custom", UserID: 1}))
+	exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Alpha", Status: "approved"}, "alpha"))
+	exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Bravo", Status: "approved"}, "bravo"))
+}
+
+func TestMigrationsRunTwice(t *testing.T) {
+	db := openMigrated(t, database.Config{DbType: database.SQLITE, Addr: sqlite_db.MEMORY}, nil)
+
+	if err := migrate.RunMigrations(db.GetDB(), database.SQLITE); err != nil {
+		t.Errorf("second run: %v", err)
+	}
+}
+
+func TestInsertReturnsID(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+
+		for _, tc := range []struct {
+			name   string
+			insert func() (sql.Result, error)
+			want   int64
+		}{
+			{"user", func() (sql.Result, error) {
+				return db.InsertNewUser(databasestructs.User{Username: "third"})
+			}, 3},
+			{"poll", func() (sql.Result, error) {
+				return db.InsertPolls(databasestructs.Poll{Name: "second", SelectedStats: "Custom", UserID: 1})
+			}, 2},
+			{"candidate", func() (sql.Result, error) {
+				return db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Charlie", Status: "approved"}, "charlie")
+			}, 3},
+			{"group", func() (sql.Result, error) {
+				return db.InsertGroup(databasestructs.Group{Name: "league", InviteCode: "invite", OwnerID: 1})
+			}, 1},
+			{"ballot", func() (sql.Result, error) {
+				return db.InsertSurveyBallot(1, 2)
+			}, 1},
+		} {
+			res, err := tc.insert()
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if id, err := res.LastInsertId(); err != nil || id != tc.want {
+				t.Errorf("%s: id = %d, %v, want %d", tc.name, id, err, tc.want)
+			}
+		}
+	})
+}
+
+func TestUniqueViolationIsConflict(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+
+		_, err := db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "alpha", Status: "approved"}, "alpha")
+		if !errors.Is(err, database.ErrConflict) {
+			t.Errorf("duplicate candidate: err = %v, want ErrConflict", err)
+		}
+
+		testutil.Must(t)(db.InsertGroup(databasestructs.Group{Name: "one", InviteCode: "invite", OwnerID: 1}))
+		_, err = db.InsertGroup(databasestructs.Group{Name: "two", InviteCode: "invite", OwnerID: 2})
+		if !errors.Is(err, database.ErrConflict) {
+			t.Errorf("duplicate invite code: err = %v, want ErrConflict", err)
+		}
+	})
+}
+
+func TestForeignKeyViolationKeepsNothing(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+
+		if _, err := db.InsertGroupMember(databasestructs.GroupMember{GroupID: 9, UserID: 1, Role: "member"}); err == nil {
+			t.Fatal("member of a missing group was inserted")
+		}
+
+		members, err := db.GetGroupMembers(context.Background(), 9)
+		if err != nil || len(members) != 0 {
+			t.Errorf("members = %v, %v, want none", members, err)
+		}
+	})
+}
+
+func TestGetPollCandidatesByStatus(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		testutil.Must(t)(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Aaron", Status: "pending", IsWriteIn: true, SubmittedBy: 2}, "aaron"))
+
+		for status, want := range map[string]string{"approved": "[Alpha Bravo]", "pending": "[Aaron]"} {
+			candidates, err := db.GetPollCandidates(context.Background(), 1, status)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, c := range candidates {
+				names = append(names, c.Name)
+			}
+			if fmt.Sprint(names) != want {
+				t.Errorf("status %q: candidates = %v, want %s", status, names, want)
+			}
+		}
+	})
+}
+
+func TestPanelVotes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		for _, v := range []struct{ userID, candidateID int64 }{{1, 1}, {2, 1}} {
+			testutil.Must(t)(db.InsertCandidateVote(1, v.userID, v.candidateID, ""))
+		}
+
+		panel, err := db.GetPanelPollVotes(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tallies []string
+		for _, p := range panel {
+			tallies = append(tallies, fmt.Sprintf("%s panelist=%t %d", p.Name, p.IsPanelist, p.Votes))
+		}
+		sort.Strings(tallies)
+		if want := "[Alpha panelist=false 1 Alpha panelist=true 1]"; fmt.Sprint(tallies) != want {
+			t.Errorf("panel tallies = %v, want %s", tallies, want)
+		}
+
+		segments, err := db.GetPollVotesBySegment(ctx, 1, "panel")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tallies = nil
+		for _, s := range segments {
+			tallies = append(tallies, fmt.Sprintf("%s %s %d", s.Segment, s.Name, s.Votes))
+		}
+		sort.Strings(tallies)
+		if want := "[fans Alpha 1 panel Alpha 1]"; fmt.Sprint(tallies) != want {
+			t.Errorf("panel segments = %v, want %s", tallies, want)
+		}
+	})
+}
+
+func TestPollVoteTimeline(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		exec := testutil.Must(t)
+		exec(db.InsertCandidateVote(1, 1, 1, ""))
+		exec(db.InsertCandidateVote(1, 2, 2, ""))
+		exec(db.InsertCandidateVote(1, 1, 2, ""))
+		exec(db.RetractPlayerVote(1, 2))
+
+		// the bucket size comes before the poll, a mixed up placeholder would find no votes
+		const bucketSeconds = 3600
+		buckets, err := db.GetPollVoteTimeline(context.Background(), 1, bucketSeconds)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		votes := map[string]int64{}
+		for _, b := range buckets {
+			if b.Start%bucketSeconds != 0 {
+				t.Errorf("bucket %d doesn't start on the hour", b.Start)
+			}
+			votes[b.Name] += b.Votes
+		}
+		// the changed and the retracted vote are taken back again
+		if want := "map[Alpha:0 Bravo:1]"; fmt.Sprint(votes) != want {
+			t.Errorf("timeline votes = %v, want %s", votes, want)
+		}
+	})
+}
+
+func TestUserVoteEventsOnlyInVisiblePolls(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		exec := testutil.Must(t)
+		exec(db.InsertGroup(databasestructs.Group{Name: "league", InviteCode: "invite", OwnerID: 1}))
+		exec(db.InsertGroupMember(databasestructs.GroupMember{GroupID: 1, UserID: 1, Role: "owner"}))
+		exec(db.InsertPolls(databasestructs.Poll{Name: "private", SelectedStats: "Custom", UserID: 1, GroupID: 1}))
+		exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 2, Name: "Charlie", Status: "approved"}, "charlie"))
+		exec(db.InsertCandidateVote(1, 1, 1, ""))
+		exec(db.InsertCandidateVote(2, 1, 3, ""))
+
+		for _, tc := range []struct {
+			viewerID, pollID int64
+			want             string
+		}{
+			{0, 0, "[1]"},
+			{2, 0, "[1]"},
+			{1, 0, "[2 1]"},
+			{1, 2, "[2]"},
+		} {
+			events, err := db.GetUserVoteEvents(context.Background(), 1, tc.viewerID, tc.pollID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var polls []int64
+			for _, e := range events {
+				polls = append(polls, e.PollID)
+			}
+			if fmt.Sprint(polls) != tc.want {
+				t.Errorf("viewer %d, poll %d: events in polls %v, want %s", tc.viewerID, tc.pollID, polls, tc.want)
+			}
+		}
+	})
+}
+
+func TestVoteWritesRecordEvents(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertCandidateVote(1, 1, 1, ""))
+		exec(db.InsertCandidateVote(1, 1, 2, ""))
+		exec(db.InsertCandidateVote(1, 2, 1, ""))
+		exec(db.RetractPlayerVote(1, 2))
+		exec(db.InsertCandidateVote(1, 2, 2, ""))
+		exec(db.ResetPollVotes(1))
+
+		tallies, err := db.GetPlayerPollVotes(ctx, 1)
+		if err != nil || len(tallies) != 0 {
+			t.Errorf("tallies after the reset = %v, %v, want none", tallies, err)
+		}
+
+		for userID, want := range map[int64]string{1: "[reset changed cast]", 2: "[reset cast retracted cast]"} {
+			events, err := db.GetUserVoteEvents(ctx, userID, userID, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var history []string
+			for _, e := range events {
+				history = append(history, e.Event)
+			}
+			if fmt.Sprint(history) != want {
+				t.Errorf("user %d: history = %v, want %s", userID, history, want)
+			}
+		}
+	})
+}
+
+func TestUserPollsAndVotesOnlyInVisiblePolls(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		exec := testutil.Must(t)
+		exec(db.InsertGroup(databasestructs.Group{Name: "league", InviteCode: "invite", OwnerID: 1}))
+		exec(db.InsertGroupMember(databasestructs.GroupMember{GroupID: 1, UserID: 1, Role: "owner"}))
+		exec(db.InsertPolls(databasestructs.Poll{Name: "private", SelectedStats: "Custom", UserID: 1, GroupID: 1}))
+		exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 2, Name: "Charlie", Status: "approved"}, "charlie"))
+		exec(db.InsertCandidateVote(1, 1, 1, ""))
+		exec(db.InsertCandidateVote(2, 1, 3, ""))
+
+		for viewerID, want := range map[int64]string{0: "[1]", 2: "[1]", 1: "[1 2]"} {
+			polls, err := db.GetPollByUserID(1, viewerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, p := range polls {
+				ids = append(ids, p.ID)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			if fmt.Sprint(ids) != want {
+				t.Errorf("viewer %d: polls = %v, want %s", viewerID, ids, want)
+			}
+
+			votes, err := db.GetVotesOfUser(context.Background(), 1, viewerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = nil
+			for _, v := range votes {
+				ids = append(ids, v.PollID)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			if fmt.Sprint(ids) != want {
+				t.Errorf("viewer %d: votes in polls %v, want %s", viewerID, ids, want)
+			}
+		}
+	})
+}
+
+func TestMatchupVoteOncePerPair(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		testutil.Must(t)(db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 1, UserID: 1, WinnerID: "1", LoserID: "2"}))
+
+		_, err := db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 1, UserID: 1, WinnerID: "2", LoserID: "1"})
+		if !errors.Is(err, database.ErrConflict) {
+			t.Errorf("repeated pair: err = %v, want ErrConflict", err)
+		}
+
+		testutil.Must(t)(db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 1, UserID: 2, WinnerID: "2", LoserID: "1"}))
+		testutil.Must(t)(db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 1, UserID: 1, WinnerID: "1", LoserID: "3"}))
+	})
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	errAbort := errors.New("abort")
+
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// the vote is a write of several statements with a transaction of its own, which joins the outer one
+		writes := func(tx database.Database) error {
+			exec := testutil.Must(t)
+			exec(tx.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Charlie", Status: "approved"}, "charlie"))
+			exec(tx.InsertCandidateVote(1, 1, 1, ""))
+			exec(tx.InsertNewUser(databasestructs.User{Username: "third", CreatedAt: 1}))
+			return nil
+		}
+
+		for name, fn := range map[string]func(tx database.Database) error{
+			"error": func(tx database.Database) error {
+				writes(tx)
+				return errAbort
+			},
+			"failed write": func(tx database.Database) error {
+				writes(tx)
+				_, err := tx.InsertGroupMember(databasestructs.GroupMember{GroupID: 9, UserID: 1, Role: "member"})
+				return err
+			},
+		} {
+			if err := db.WithTx(ctx, fn); err == nil {
+				t.Fatalf("%s: WithTx returned nil", name)
+			}
+
+			candidates, err := db.GetPollCandidates(ctx, 1, "approved")
+			if err != nil || len(candidates) != 2 {
+				t.Errorf("%s: candidates = %v, %v, want only the seeded ones", name, candidates, err)
+			}
+			tallies, err := db.GetPlayerPollVotes(ctx, 1)
+			if err != nil || len(tallies) != 0 {
+				t.Errorf("%s: tallies = %v, %v, want none", name, tallies, err)
+			}
+			events, err := db.GetUserVoteEvents(ctx, 1, 1, 1)
+			if err != nil || len(events) != 0 {
+				t.Errorf("%s: vote events = %v, %v, want none", name, events, err)
+			}
+			if _, err := db.GetUserByUsername("third"); !errors.Is(err, database.ErrNotFound) {
+				t.Errorf("%s: user inserted in the transaction: err = %v, want %v", name, err, database.ErrNotFound)
+			}
+		}
+
+		if err := db.WithTx(ctx, writes); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.GetUserByUsername("third"); err != nil {
+			t.Errorf("user of the committed transaction: %v", err)
+		}
+		tallies, err := db.GetPlayerPollVotes(ctx, 1)
+		if err != nil || len(tallies) != 1 {
+			t.Errorf("tallies = %v, %v, want the committed vote", tallies, err)
+		}
+	})
+}
+
+func TestStatLeadersTotalPastTheLastPage(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		exec := testutil.Must(t)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+		for i, points := range []float64{30, 25, 20} {
+			id := fmt.Sprintf("p%d", i+1)
+			exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: id, Name: "Player " + id, TeamAbbr: "BOS"}))
+			exec(db.InsertStats(databasestructs.PlayerStats{PlayerID: id, Season: "2024", TeamAbbr: "BOS", Games: 60, Minutes: 30, Points: points}))
+			exec(db.InsertAdvancedStats(databasestructs.AdvancedStats{PlayerID: id, Season: "2024", TeamAbbr: "BOS"}))
+		}
+
+		for _, tc := range []struct {
+			offset  int64
+			leaders int
+		}{
+			{0, 2},
+			{2, 1},
+			{5, 0},
+		} {
+			filter := databasestructs.LeadersFilter{Season: "2024", Stat: "ppg", Limit: 2, Offset: tc.offset}
+			leaders, total, err := db.GetStatLeaders(context.Background(), filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(leaders) != tc.leaders || total != 3 {
+				t.Errorf("offset %d: %d leaders of %d, want %d of 3", tc.offset, len(leaders), total, tc.leaders)
+			}
+		}
+	})
+}
+
+func TestChangedVoteToUnknownPlayerKeepsTheVote(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+		exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "p1", Name: "Player One", TeamAbbr: "BOS"}))
+		exec(db.InsertPolls(databasestructs.Poll{Name: "mvp", SelectedStats: "All stats", Season: "2024", UserID: 1}))
+		exec(db.InsertPlayerVotes(2, 2, "p1", ""))
+
+		if _, err := db.InsertPlayerVotes(2, 2, "nobody", ""); err == nil {
+			t.Fatal("vote changed to a player that doesn't exist")
+		}
+
+		tallies, err := db.GetPlayerPollVotes(ctx, 2)
+		if err != nil || len(tallies) != 1 || tallies[0].Name != "Player One" || tallies[0].Votes != 1 {
+			t.Errorf("tallies = %+v, %v, want the earlier vote for Player One", tallies, err)
+		}
+
+		events, err := db.GetUserVoteEvents(ctx, 2, 2, 2)
+		if err != nil || len(events) != 1 || events[0].Event != "cast" {
+			t.Errorf("vote events = %+v, %v, want only the cast", events, err)
+		}
+	})
+}
+
+func TestPollUpdateAndDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		weight := 0.5
+		testutil.Must(t)(db.UpdatePollByID(databasestructs.Poll{ID: 1, Name: "renamed", Description: "about", SelectedStats: "Custom", Season: "2024", ScoreFormula: "ppg", AllowWriteIns: true, PanelWeight: &weight, AllowGuests: true}))
+
+		poll, err := db.GetPollByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if poll.Name != "renamed" || poll.Description != "about" || poll.Season != "2024" || poll.ScoreFormula != "ppg" || !poll.AllowWriteIns || !poll.AllowGuests || poll.PanelWeight == nil || *poll.PanelWeight != weight || poll.UserID != 1 {
+			t.Errorf("updated poll = %+v", poll)
+		}
+
+		testutil.Must(t)(db.DeletePollByID(1))
+		if _, err := db.GetPollByID(1); !errors.Is(err, database.ErrNotFound) {
+			t.Errorf("deleted poll: err = %v, want %v", err, database.ErrNotFound)
+		}
+		candidates, err := db.GetPollCandidates(context.Background(), 1, "approved")
+		if err != nil || len(candidates) != 0 {
+			t.Errorf("candidates of the deleted poll = %v, %v, want none", candidates, err)
+		}
+	})
+}
+
+func TestPlayerVotesTallyTheLatestVote(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+		exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "p1", Name: "Player One", TeamAbbr: "BOS"}))
+		exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "p2", Name: "Player Two", TeamAbbr: "BOS"}))
+		exec(db.InsertPolls(databasestructs.Poll{Name: "mvp", SelectedStats: "All stats", Season: "2024", UserID: 1}))
+		exec(db.InsertPlayerVotes(2, 1, "p1", ""))
+		exec(db.InsertPlayerVotes(2, 2, "p1", ""))
+		exec(db.InsertPlayerVotes(2, 2, "p2", ""))
+
+		tallies, err := db.GetPlayerPollVotes(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var votes []string
+		for _, v := range tallies {
+			votes = append(votes, fmt.Sprintf("%s %d", v.Name, v.Votes))
+		}
+		sort.Strings(votes)
+		if want := "[Player One 1 Player Two 1]"; fmt.Sprint(votes) != want {
+			t.Errorf("tallies = %v, want %s", votes, want)
+		}
+
+		exec(db.ResetPollVotes(2))
+		tallies, err = db.GetPlayerPollVotes(ctx, 2)
+		if err != nil || len(tallies) != 0 {
+			t.Errorf("tallies after the reset = %v, %v, want none", tallies, err)
+		}
+	})
+}
+
+func TestMatchupRatingsAndVotes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertPolls(databasestructs.Poll{Name: "other", SelectedStats: "Custom", UserID: 1}))
+		exec(db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 1, UserID: 1, WinnerID: "1", LoserID: "2", RatingChange: 16}))
+		exec(db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 1, UserID: 2, WinnerID: "2", LoserID: "1"}))
+		exec(db.InsertMatchupVote(databasestructs.MatchupVote{PollID: 2, UserID: 1, WinnerID: "1", LoserID: "2"}))
+		exec(db.UpdateMatchupRating(1, "1", 16, true))
+		exec(db.UpdateMatchupRating(1, "2", -16, false))
+		exec(db.UpdateMatchupRating(1, "1", -10, false))
+
+		ratings, err := db.GetMatchupRatings(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []string
+		for _, r := range ratings {
+			rows = append(rows, fmt.Sprintf("%s %.0f %d/%d", r.PlayerID, r.Rating, r.Wins, r.Matches))
+		}
+		sort.Strings(rows)
+		if want := "[1 1506 1/2 2 1484 0/1]"; fmt.Sprint(rows) != want {
+			t.Errorf("ratings = %v, want %s", rows, want)
+		}
+
+		votes, err := db.GetMatchupVotes(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(votes) != 2 || votes[0].WinnerID != "1" || votes[0].RatingChange != 16 || votes[1].WinnerID != "2" {
+			t.Fatalf("votes = %+v, want both votes of the poll in order", votes)
+		}
+		exec(db.SetMatchupVoteChange(votes[1].ID, 12))
+		if votes, err = db.GetMatchupVotes(ctx, 1); err != nil || votes[1].RatingChange != 12 {
+			t.Errorf("votes after setting the change = %+v, %v, want 12 on the second", votes, err)
+		}
+
+		userVotes, err := db.GetUserMatchupVotes(ctx, 1, 2)
+		if err != nil || len(userVotes) != 1 || userVotes[0].WinnerID != "2" || userVotes[0].LoserID != "1" {
+			t.Errorf("votes of user 2 = %+v, %v, want only theirs", userVotes, err)
+		}
+
+		// poll 2 has votes but no ratings yet
+		polls, err := db.GetPollsWithoutMatchupRatings(ctx)
+		if err != nil || fmt.Sprint(polls) != "[2]" {
+			t.Errorf("polls without ratings = %v, %v, want [2]", polls, err)
+		}
+
+		if err := db.ResetMatchups(1); err != nil {
+			t.Fatal(err)
+		}
+		ratings, err = db.GetMatchupRatings(ctx, 1)
+		if err != nil || len(ratings) != 0 {
+			t.Errorf("ratings after the reset = %v, %v, want none", ratings, err)
+		}
+		votes, err = db.GetMatchupVotes(ctx, 1)
+		if err != nil || len(votes) != 0 {
+			t.Errorf("votes after the reset = %v, %v, want none", votes, err)
+		}
+		if votes, err = db.GetMatchupVotes(ctx, 2); err != nil || len(votes) != 1 {
+			t.Errorf("votes of the other poll = %v, %v, want the one vote", votes, err)
+		}
+	})
+}
+
+func TestBracketMatchupsTally(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+		for _, id := range []string{"p1", "p2", "p3", "p4"} {
+			exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: id, Name: "Player " + id, TeamAbbr: "BOS"}))
+		}
+		exec(db.InsertPolls(databasestructs.Poll{Name: "bracket", SelectedStats: "All stats", Season: "2024", UserID: 1}))
+		exec(db.InsertBracket(databasestructs.Bracket{PollID: 2, Size: 4, RoundHours: 24, CurrentRound: 1, RoundEndsAt: 100}))
+		exec(db.InsertBracketMatchup(databasestructs.BracketMatchup{PollID: 2, Round: 1, Position: 1, Seed1: 1, Player1ID: "p1", Seed2: 4, Player2ID: "p4"}))
+		exec(db.InsertBracketMatchup(databasestructs.BracketMatchup{PollID: 2, Round: 1, Position: 2, Seed1: 2, Player1ID: "p2", Seed2: 3, Player2ID: "p3"}))
+		// the same position again is skipped, so a round can't be seeded twice
+		exec(db.InsertBracketMatchup(databasestructs.BracketMatchup{PollID: 2, Round: 1, Position: 1, Seed1: 1, Player1ID: "p1", Seed2: 3, Player2ID: "p3"}))
+
+		exec(db.InsertBracketVote(databasestructs.BracketVote{MatchupID: 1, UserID: 1, PlayerID: "p1"}))
+		exec(db.InsertBracketVote(databasestructs.BracketVote{MatchupID: 1, UserID: 2, PlayerID: "p1"}))
+		// a user switching their pick replaces it
+		exec(db.InsertBracketVote(databasestructs.BracketVote{MatchupID: 1, UserID: 2, PlayerID: "p4"}))
+		exec(db.SetBracketMatchupWinner(2, "p3"))
+
+		matchups, err := db.GetBracketMatchups(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []string
+		for _, m := range matchups {
+			rows = append(rows, fmt.Sprintf("%d/%d %s %d-%d %s winner=%s", m.Round, m.Position, m.Player1Name, m.Votes1, m.Votes2, m.Player2Name, m.WinnerID))
+		}
+		if want := "[1/1 Player p1 1-1 Player p4 winner= 1/2 Player p2 0-0 Player p3 winner=p3]"; fmt.Sprint(rows) != want {
+			t.Errorf("matchups = %v, want %s", rows, want)
+		}
+
+		matchup, err := db.GetBracketMatchup(2)
+		if err != nil || matchup.PollID != 2 || matchup.Player1ID != "p2" || matchup.WinnerID != "p3" {
+			t.Errorf("matchup 2 = %+v, %v", matchup, err)
+		}
+
+		due, err := db.GetDueBrackets(ctx, 100)
+		if err != nil || len(due) != 1 {
+			t.Fatalf("due brackets = %+v, %v, want the bracket", due, err)
+		}
+		exec(db.AdvanceBracketRound(2, 2, 200))
+		// only the round after the current one can be opened
+		exec(db.AdvanceBracketRound(2, 2, 300))
+		exec(db.SetBracketChampion(2, "p1"))
+
+		bracket, err := db.GetBracket(2)
+		if err != nil || bracket.CurrentRound != 2 || bracket.RoundEndsAt != 200 || bracket.ChampionID != "p1" {
+			t.Errorf("bracket = %+v, %v, want round 2 until 200 won by p1", bracket, err)
+		}
+		if due, err = db.GetDueBrackets(ctx, 1000); err != nil || len(due) != 0 {
+			t.Errorf("due brackets after the final = %+v, %v, want none", due, err)
+		}
+	})
+}
+
+func TestStatLeadersShareTiedRanks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		exec := testutil.Must(t)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "LAL", Name: "Los Angeles"}))
+		for i, p := range []struct {
+			team, position string
+			points         float64
+		}{
+			{"BOS", "PG", 30},
+			{"LAL", "SG-PG", 25},
+			{"BOS", "C", 25},
+			{"LAL", "PG", 20},
+		} {
+			id := fmt.Sprintf("p%d", i+1)
+			exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: id, Name: "Player " + id, TeamAbbr: p.team}))
+			exec(db.InsertStats(databasestructs.PlayerStats{PlayerID: id, Season: "2024", TeamAbbr: p.team, Games: 60, Minutes: 30, Points: p.points, Position: p.position}))
+			exec(db.InsertAdvancedStats(databasestructs.AdvancedStats{PlayerID: id, Season: "2024", TeamAbbr: p.team}))
+		}
+
+		for _, tc := range []struct {
+			filter databasestructs.LeadersFilter
+			want   string
+		}{
+			{databasestructs.LeadersFilter{}, "[1 p1 30 2 p2 25 2 p3 25 4 p4 20]"},
+			{databasestructs.LeadersFilter{Ascending: true}, "[1 p4 20 2 p2 25 2 p3 25 4 p1 30]"},
+			{databasestructs.LeadersFilter{Position: "PG"}, "[1 p1 30 2 p2 25 3 p4 20]"},
+			// the position is matched as it is, not as a pattern
+			{databasestructs.LeadersFilter{Position: "_G"}, "[]"},
+			{databasestructs.LeadersFilter{Position: "%"}, "[]"},
+			{databasestructs.LeadersFilter{Position: "G"}, "[]"},
+			// the minutes qualifier keeps a player right at the minimum, like metrics.IsQualified
+			{databasestructs.LeadersFilter{MinMinutes: 30}, "[1 p1 30 2 p2 25 2 p3 25 4 p4 20]"},
+			{databasestructs.LeadersFilter{Team: "LAL"}, "[1 p2 25 2 p4 20]"},
+			{databasestructs.LeadersFilter{Offset: 2}, "[2 p3 25 4 p4 20]"},
+		} {
+			filter := tc.filter
+			filter.Season, filter.Stat, filter.Limit = "2024", "ppg", 10
+			leaders, _, err := db.GetStatLeaders(context.Background(), filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var rows []string
+			for _, l := range leaders {
+				rows = append(rows, fmt.Sprintf("%d %s %.0f", l.Rank, l.ID, l.Value))
+			}
+			if fmt.Sprint(rows) != tc.want {
+				t.Errorf("%+v: leaders = %v, want %s", tc.filter, rows, tc.want)
+			}
+		}
+	})
+}
+
+func TestContestPredictionsAndLeaderboard(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertContest(databasestructs.PredictionContest{Name: "early", Season: "2024", Deadline: 100, UserID: 1}))
+		exec(db.InsertContest(databasestructs.PredictionContest{Name: "late", Season: "2024", Deadline: 200, UserID: 1}))
+		exec(db.InsertContestQuestion(databasestructs.PredictionQuestion{ContestID: 1, Position: 2, Prompt: "MVP?", Type: "award", Target: "mvp", Points: 10}))
+		exec(db.InsertContestQuestion(databasestructs.PredictionQuestion{ContestID: 1, Position: 1, Prompt: "Boston wins?", Type: "win_total", Target: "BOS", Points: 10}))
+
+		if _, err := db.InsertContestQuestion(databasestructs.PredictionQuestion{ContestID: 1, Position: 1, Prompt: "again", Type: "award", Target: "roy"}); !errors.Is(err, database.ErrConflict) {
+			t.Errorf("question in a taken position: err = %v, want ErrConflict", err)
+		}
+
+		contests, err := db.GetContests(ctx)
+		if err != nil || len(contests) != 2 || contests[0].Name != "late" {
+			t.Errorf("contests = %+v, %v, want the latest deadline first", contests, err)
+		}
+		due, err := db.GetUnresolvedContests(ctx, 150)
+		if err != nil || len(due) != 1 || due[0].ID != 1 {
+			t.Errorf("unresolved contests = %+v, %v, want the early one", due, err)
+		}
+
+		wins := 50.0
+		exec(db.InsertPrediction(databasestructs.Prediction{QuestionID: 2, UserID: 1, Number: &wins}))
+		exec(db.InsertPrediction(databasestructs.Prediction{QuestionID: 1, UserID: 1, Value: "p2"}))
+		// a user changing their pick replaces it
+		exec(db.InsertPrediction(databasestructs.Prediction{QuestionID: 1, UserID: 1, Value: "p1"}))
+		exec(db.InsertPrediction(databasestructs.Prediction{QuestionID: 1, UserID: 2, Value: "p2"}))
+
+		predictions, err := db.GetUserPredictions(ctx, 1, 1)
+		if err != nil || len(predictions) != 2 || predictions[0].QuestionID != 2 || predictions[1].Value != "p1" {
+			t.Errorf("predictions of user 1 = %+v, %v, want both in the order of the questions", predictions, err)
+		}
+		if predictions, err = db.GetQuestionPredictions(ctx, 1); err != nil || len(predictions) != 2 {
+			t.Errorf("predictions of question 1 = %+v, %v, want both users", predictions, err)
+		}
+
+		exec(db.ResolveContestQuestion(1, "p1", nil))
+		exec(db.SetPredictionScore(1, 1, 10))
+		exec(db.SetPredictionScore(1, 2, 0))
+		exec(db.SetContestResolved(1))
+
+		questions, err := db.GetContestQuestions(ctx, 1)
+		if err != nil || len(questions) != 2 || questions[0].Answer != nil || questions[1].Answer == nil || *questions[1].Answer != "p1" {
+			t.Errorf("questions = %+v, %v, want the second by position answered", questions, err)
+		}
+		if contest, err := db.GetContestByID(1); err != nil || !contest.Resolved {
+			t.Errorf("contest = %+v, %v, want it resolved", contest, err)
+		}
+
+		standings, err := db.GetContestLeaderboard(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []string
+		for _, s := range standings {
+			rows = append(rows, fmt.Sprintf("%s %.0f/%d", s.Username, s.Score, s.Graded))
+		}
+		if want := "[panelist 10/1 fan 0/1]"; fmt.Sprint(rows) != want {
+			t.Errorf("leaderboard = %v, want %s", rows, want)
+		}
+	})
+}
+
+func TestGroupLeaderboard(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		seed(t, db)
+		exec := testutil.Must(t)
+		exec(db.InsertNewUser(databasestructs.User{Username: "another", CreatedAt: 1}))
+		exec(db.InsertGroup(databasestructs.Group{Name: "league", InviteCode: "invite", OwnerID: 1}))
+		for _, userID := range []int64{1, 2, 3} {
+			exec(db.InsertGroupMember(databasestructs.GroupMember{GroupID: 1, UserID: userID, Role: "member"}))
+		}
+		exec(db.InsertPolls(databasestructs.Poll{Name: "private", SelectedStats: "Custom", UserID: 1, GroupID: 1}))
+		exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 2, Name: "Charlie", Status: "approved"}, "charlie"))
+		// only the votes in the group's polls count
+		exec(db.InsertCandidateVote(2, 3, 3, ""))
+		exec(db.InsertCandidateVote(1, 1, 1, ""))
+
+		exec(db.InsertContest(databasestructs.PredictionContest{Name: "contest", Season: "2024", Deadline: 100, UserID: 1}))
+		exec(db.InsertContestQuestion(databasestructs.PredictionQuestion{ContestID: 1, Position: 1, Prompt: "MVP?", Type: "award", Target: "mvp", Points: 10}))
+		exec(db.InsertPrediction(databasestructs.Prediction{QuestionID: 1, UserID: 2, Value: "p1"}))
+		exec(db.SetPredictionScore(1, 2, 10))
+
+		standings, err := db.GetGroupLeaderboard(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows []string
+		for _, s := range standings {
+			rows = append(rows, fmt.Sprintf("%s %.0f %d/%d", s.Username, s.Score, s.Predictions, s.PollsVoted))
+		}
+		if want := "[fan 10 1/0 another 0 0/1 panelist 0 0/0]"; fmt.Sprint(rows) != want {
+			t.Errorf("leaderboard = %v, want %s", rows, want)
+		}
+	})
+}
+
+func TestSeasonResultsReplaceEarlierOnes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db database.Database) {
+		ctx := context.Background()
+		exec := testutil.Must(t)
+		exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+		exec(db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2024", TeamAbbr: "BOS", Wins: 40, Losses: 20}))
+		exec(db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2024", TeamAbbr: "BOS", Wins: 64, Losses: 18}))
+		exec(db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2023", TeamAbbr: "BOS", Wins: 57, Losses: 25}))
+		exec(db.InsertSeasonAward(databasestructs.SeasonAward{Season: "2024", Award: "mvp", PlayerID: "p1"}))
+		exec(db.InsertSeasonAward(databasestructs.SeasonAward{Season: "2024", Award: "mvp", PlayerID: "p2"}))
+
+		if _, err := db.InsertSeasonAward(databasestructs.SeasonAward{Season: "2024", Award: "best", PlayerID: "p1"}); err == nil {
+			t.Error("award outside the list was inserted")
+		}
+		if _, err := db.InsertTeamStanding(databasestructs.TeamStanding{Season: "2024", TeamAbbr: "XXX"}); err == nil {
+			t.Error("standing of a team that doesn't exist was inserted")
+		}
+
+		standings, err := db.GetTeamStandings(ctx, "2024")
+		if err != nil || len(standings) != 1 || standings[0].Wins != 64 || standings[0].Losses != 18 {
+			t.Errorf("standings = %+v, %v, want the later record", standings, err)
+		}
+		awards, err := db.GetSeasonAwards(ctx, "2024")
+		if err != nil || len(awards) != 1 || awards[0].PlayerID != "p2" {
+			t.Errorf("awards = %+v, %v, want the later winner", awards, err)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertBracket returns ErrConflict when the poll already has a bracket
+func (r repository) InsertBracket(bracket databasestructs.Bracket) (sql.Result, error) {
+	res, err := r.Queries.InsertBracket(bracket)
+	return res, conflict(err)
+}
+
+func (r repository) GetBracket(pollid int64) (databasestructs.Bracket, error) {
+	return one(r.Queries.GetBracket(pollid), func(row scanner) (databasestructs.Bracket, error) {
+		var bracket databasestructs.Bracket
+		err := row.Scan(&bracket.PollID, &bracket.Size, &bracket.RoundHours, &bracket.CurrentRound, &bracket.RoundEndsAt, &bracket.ChampionID)
+		return bracket, err
+	})
+}
+
+func (r repository) GetDueBrackets(ctx context.Context, now int64) ([]databasestructs.Bracket, error) {
+	rows, err := r.Queries.GetDueBrackets(ctx, now)
+	return collect(rows, err, func(row scanner) (databasestructs.Bracket, error) {
+		var bracket databasestructs.Bracket
+		err := row.Scan(&bracket.PollID, &bracket.Size, &bracket.RoundHours, &bracket.CurrentRound, &bracket.RoundEndsAt)
+		return bracket, err
+	})
+}
+
+func (r repository) GetBracketMatchup(id int64) (databasestructs.BracketMatchup, error) {
+	return one(r.Queries.GetBracketMatchup(id), func(row scanner) (databasestructs.BracketMatchup, error) {
+		var matchup databasestructs.BracketMatchup
+		err := row.Scan(&matchup.ID, &matchup.PollID, &matchup.Round, &matchup.Position, &matchup.Seed1, &matchup.Player1ID, &matchup.Seed2, &matchup.Player2ID, &matchup.WinnerID)
+		return matchup, err
+	})
+}
+
+// GetBracketMatchups returns the matchups of every round in order, with the votes each player got
+func (r repository) GetBracketMatchups(ctx context.Context, pollid int64) ([]databasestructs.BracketMatchupTally, error) {
+	rows, err := r.Queries.GetBracketMatchups(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.BracketMatchupTally, error) {
+		t := databasestructs.BracketMatchupTally{BracketMatchup: databasestructs.BracketMatchup{PollID: pollid}}
+		err := row.Scan(&t.ID, &t.Round, &t.Position, &t.Seed1, &t.Player1ID, &t.Player1Name, &t.Seed2, &t.Player2ID, &t.Player2Name, &t.WinnerID, &t.Votes1, &t.Votes2)
+		return t, err
+	})
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertPollCandidate returns ErrConflict when a candidate with the same name is already in the poll
+func (r repository) InsertPollCandidate(candidate databasestructs.PollCandidate, nameKey string) (sql.Result, error) {
+	res, err := r.Queries.InsertPollCandidate(candidate, nameKey)
+	return res, conflict(err)
+}
+
+func (r repository) GetPollCandidates(ctx context.Context, pollid int64, status string) ([]databasestructs.PollCandidate, error) {
+	rows, err := r.Queries.GetPollCandidates(ctx, pollid, status)
+	return collect(rows, err, scanPollCandidate)
+}
+
+func (r repository) GetPollCandidateByID(id int64) (databasestructs.PollCandidate, error) {
+	return one(r.Queries.GetPollCandidateByID(id), scanPollCandidate)
+}
+
+func (r repository) GetPollCandidateByKey(pollid int64, nameKey string) (databasestructs.PollCandidate, error) {
+	return one(r.Queries.GetPollCandidateByKey(pollid, nameKey), scanPollCandidate)
+}
+
+func (r repository) CountUserWriteIns(pollid, userid int64) (int64, error) {
+	return one(r.Queries.CountUserWriteIns(pollid, userid), scanCount)
+}
+
+// InsertCandidateVote swaps the earlier vote for the new one together with writing the event
+func (r repository) InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error) {
+	return r.execInTransaction(func(tx Queries) (sql.Result, error) {
+		return tx.InsertCandidateVote(pollid, userid, candidateid, ip)
+	})
+}
+
+func scanPollCandidate(row scanner) (databasestructs.PollCandidate, error) {
+	var candidate databasestructs.PollCandidate
+	err := row.Scan(&candidate.ID, &candidate.PollID, &candidate.PlayerID, &candidate.Name, &candidate.Status, &candidate.IsWriteIn, &candidate.SubmittedBy)
+	return candidate, err
+}
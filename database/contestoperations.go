@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) GetContests(ctx context.Context) ([]databasestructs.PredictionContest, error) {
+	rows, err := r.Queries.GetContests(ctx)
+	return collect(rows, err, scanContest)
+}
+
+func (r repository) GetContestByID(id int64) (databasestructs.PredictionContest, error) {
+	return one(r.Queries.GetContestByID(id), scanContest)
+}
+
+func (r repository) GetUnresolvedContests(ctx context.Context, now int64) ([]databasestructs.PredictionContest, error) {
+	rows, err := r.Queries.GetUnresolvedContests(ctx, now)
+	return collect(rows, err, scanContest)
+}
+
+// InsertContestQuestion returns ErrConflict when the contest already has a question in that position
+func (r repository) InsertContestQuestion(question databasestructs.PredictionQuestion) (sql.Result, error) {
+	res, err := r.Queries.InsertContestQuestion(question)
+	return res, conflict(err)
+}
+
+func (r repository) GetContestQuestions(ctx context.Context, contestid int64) ([]databasestructs.PredictionQuestion, error) {
+	rows, err := r.Queries.GetContestQuestions(ctx, contestid)
+	return collect(rows, err, func(row scanner) (databasestructs.PredictionQuestion, error) {
+		var question databasestructs.PredictionQuestion
+		err := row.Scan(&question.ID, &question.ContestID, &question.Position, &question.Prompt, &question.Type, &question.Target, &question.Points, &question.Answer, &question.AnswerNumber)
+		return question, err
+	})
+}
+
+func (r repository) GetQuestionPredictions(ctx context.Context, questionid int64) ([]databasestructs.Prediction, error) {
+	rows, err := r.Queries.GetQuestionPredictions(ctx, questionid)
+	return collect(rows, err, scanPrediction)
+}
+
+func (r repository) GetUserPredictions(ctx context.Context, contestid, userid int64) ([]databasestructs.Prediction, error) {
+	rows, err := r.Queries.GetUserPredictions(ctx, contestid, userid)
+	return collect(rows, err, scanPrediction)
+}
+
+// GetContestLeaderboard returns the users with the most points first, the handler ranks them
+func (r repository) GetContestLeaderboard(ctx context.Context, contestid int64) ([]databasestructs.ContestStanding, error) {
+	rows, err := r.Queries.GetContestLeaderboard(ctx, contestid)
+	return collect(rows, err, func(row scanner) (databasestructs.ContestStanding, error) {
+		var standing databasestructs.ContestStanding
+		err := row.Scan(&standing.UserID, &standing.Username, &standing.Score, &standing.Graded)
+		return standing, err
+	})
+}
+
+func scanContest(row scanner) (databasestructs.PredictionContest, error) {
+	var contest databasestructs.PredictionContest
+	err := row.Scan(&contest.ID, &contest.Name, &contest.Description, &contest.Season, &contest.Deadline, &contest.Resolved, &contest.UserID)
+	return contest, err
+}
+
+func scanPrediction(row scanner) (databasestructs.Prediction, error) {
+	var prediction databasestructs.Prediction
+	err := row.Scan(&prediction.QuestionID, &prediction.UserID, &prediction.Value, &prediction.Number, &prediction.Score)
+	return prediction, err
+}
@@ -5,12 +5,16 @@ import (
 	"database/sql"
 	"errors"
 	"sportsvoting/database/mysql_db"
+	"sportsvoting/database/postgres_db"
+	"sportsvoting/database/sqlite_db"
 	"sportsvoting/databasestructs"
 	"time"
 )
 
 const (
-	MYSQL string = "mysql"
+	MYSQL    string = "mysql"
+	POSTGRES string = "postgres"
+	SQLITE   string = "sqlite"
 )
 
 type Database interface {
@@ -18,9 +22,24 @@ type Database interface {
 	TeamOperations
 	StatsOperations
 	PollOperations
+	MatchupOperations
+	BracketOperations
+	SurveyOperations
+	ContestOperations
+	CandidateOperations
+	GroupOperations
+	GuestVoteOperations
+	FraudOperations
+	VoteEventOperations
+	SeasonResultsOperations
 	UserOperations
 	GoatOperations
+	LeagueOperations
 	SyncOperations
+	// WithTx runs fn in a transaction, which is committed when fn returns nil and rolled back otherwise.
+	// fn has to make every call on tx: the in-memory database holds its lock until fn returns, so a call
+	// on the outer handle from fn deadlocks, and on the other backends it isn't part of the transaction
+	WithTx(ctx context.Context, fn func(tx Database) error) error
 	CloseConnection()
 	GetDB() *sql.DB
 }
@@ -28,14 +47,15 @@ type Database interface {
 type PlayerOperations interface {
 	InsertPlayer(info databasestructs.PlayerInfo) (sql.Result, error)
 	UpdatePlayerAge(playerid string, age int64) (sql.Result, error)
-	SelectPlayerGamesPlayed(season string) (*sql.Rows, error)
-	CheckPlayerExists(playerid string) *sql.Row
+	SelectPlayerGamesPlayed(season string) ([]databasestructs.PlayerStats, error)
+	CheckPlayerExists(playerid string) (bool, error)
+	GetPlayersForSearch(ctx context.Context, season string) ([]databasestructs.PlayerSearchResult, error)
 }
 
 type TeamOperations interface {
 	InsertTeam(info databasestructs.TeamInfo) (sql.Result, error)
 	UpdateTeamForPlayer(teamabbr, playerid string) (sql.Result, error)
-	SelectTeamByAbbrevation(teamabbr string) *sql.Row
+	SelectTeamByAbbrevation(teamabbr string) (string, error)
 }
 
 type StatsOperations interface {
@@ -46,36 +66,147 @@ type StatsOperations interface {
 	UpdateTradedPlayerAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error)
 	InsertAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error)
 	UpdateOffAndDefRtg(offrtg, defrtg float64, playerid, season string) (sql.Result, error)
-	GetSixManStats(ctx context.Context, season string) (*sql.Rows, error)
-	GetDPOYStats(ctx context.Context, season string) (*sql.Rows, error)
-	GetROYStats(ctx context.Context, season string) (*sql.Rows, error)
+	GetSixManStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error)
+	GetDPOYStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error)
+	GetROYStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error)
 	SetRookieStatus(id string) (sql.Result, error)
+	GetSeasonStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error)
+	GetStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) ([]databasestructs.StatLeader, int64, error)
+	GetAllSeasonStats(ctx context.Context) ([]databasestructs.PlayerInfo, error)
 }
 
 type PollOperations interface {
-	GetPolls(ctx context.Context) (*sql.Rows, error)
-	GetPollByID(id int64) *sql.Row
-	GetPollByUserID(userid int64) (*sql.Rows, error)
+	GetPolls(ctx context.Context, userid int64) ([]databasestructs.Poll, error)
+	GetPollByID(id int64) (databasestructs.Poll, error)
+	GetPollByUserID(userid, viewerid int64) ([]databasestructs.Poll, error)
 	InsertPolls(poll databasestructs.Poll) (sql.Result, error)
 	InsertPollsWithId(poll databasestructs.Poll) (sql.Result, error)
 	DeletePollByID(pollid int64) (sql.Result, error)
 	ResetPollVotes(pollid int64) (sql.Result, error)
 	UpdatePollByID(poll databasestructs.Poll) (sql.Result, error)
 	InsertSeasonEntered(season string) (sql.Result, error)
-	SelectSeasonsAvailable() (*sql.Rows, error)
-	SelectSeasonsForNonGOATStats() (*sql.Rows, error)
-	GetPlayerStatsForPoll(ctx context.Context, season string) (*sql.Rows, error)
-	GetPlayerPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
-	InsertPlayerVotes(pollid, userid int64, playerid string) (sql.Result, error)
-	GetTeamPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
+	SelectSeasonsAvailable() ([]string, error)
+	SelectSeasonsForNonGOATStats() ([]string, error)
+	GetPlayerStatsForPoll(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error)
+	GetPlayerPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error)
+	GetPanelPollVotes(ctx context.Context, pollid int64) ([]databasestructs.PanelVoteTally, error)
+	GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) ([]databasestructs.TimelineBucket, error)
+	GetPollVotesBySegment(ctx context.Context, pollid int64, segment string) ([]databasestructs.SegmentVoteTally, error)
+	InsertPlayerVotes(pollid, userid int64, playerid, ip string) (sql.Result, error)
+	GetTeamPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error)
 	UpdatePollImage(image databasestructs.Image) (sql.Result, error)
 }
 
+type MatchupOperations interface {
+	InsertMatchupVote(vote databasestructs.MatchupVote) (sql.Result, error)
+	UpdateMatchupRating(pollid int64, playerid string, change float64, won bool) (sql.Result, error)
+	GetMatchupRatings(ctx context.Context, pollid int64) ([]databasestructs.MatchupRating, error)
+	GetMatchupVotes(ctx context.Context, pollid int64) ([]databasestructs.MatchupVote, error)
+	GetUserMatchupVotes(ctx context.Context, pollid, userid int64) ([]databasestructs.MatchupVote, error)
+	ResetMatchups(pollid int64) error
+	GetPollsWithoutMatchupRatings(ctx context.Context) ([]int64, error)
+	SetMatchupVoteChange(id int64, change float64) (sql.Result, error)
+}
+
+type BracketOperations interface {
+	InsertBracket(bracket databasestructs.Bracket) (sql.Result, error)
+	GetBracket(pollid int64) (databasestructs.Bracket, error)
+	GetDueBrackets(ctx context.Context, now int64) ([]databasestructs.Bracket, error)
+	AdvanceBracketRound(pollid, round, roundEndsAt int64) (sql.Result, error)
+	SetBracketChampion(pollid int64, championid string) (sql.Result, error)
+	InsertBracketMatchup(matchup databasestructs.BracketMatchup) (sql.Result, error)
+	GetBracketMatchup(id int64) (databasestructs.BracketMatchup, error)
+	GetBracketMatchups(ctx context.Context, pollid int64) ([]databasestructs.BracketMatchupTally, error)
+	SetBracketMatchupWinner(id int64, winnerid string) (sql.Result, error)
+	InsertBracketVote(vote databasestructs.BracketVote) (sql.Result, error)
+}
+
+type SurveyOperations interface {
+	InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error)
+	GetPollQuestions(ctx context.Context, pollid int64) ([]databasestructs.PollQuestion, error)
+	DeletePollQuestions(pollid int64) (sql.Result, error)
+	CountSurveyBallots(pollid int64) (int64, error)
+	InsertSurveyBallot(pollid, userid int64) (sql.Result, error)
+	DeleteSurveyBallot(pollid, userid int64) (sql.Result, error)
+	InsertSurveyAnswer(answer databasestructs.SurveyAnswer) (sql.Result, error)
+	GetSurveyAnswers(ctx context.Context, pollid int64) ([]databasestructs.SurveyAnswer, error)
+}
+
+type ContestOperations interface {
+	InsertContest(contest databasestructs.PredictionContest) (sql.Result, error)
+	GetContests(ctx context.Context) ([]databasestructs.PredictionContest, error)
+	GetContestByID(id int64) (databasestructs.PredictionContest, error)
+	GetUnresolvedContests(ctx context.Context, now int64) ([]databasestructs.PredictionContest, error)
+	SetContestResolved(id int64) (sql.Result, error)
+	InsertContestQuestion(question databasestructs.PredictionQuestion) (sql.Result, error)
+	GetContestQuestions(ctx context.Context, contestid int64) ([]databasestructs.PredictionQuestion, error)
+	ResolveContestQuestion(id int64, answer string, answerNumber *float64) (sql.Result, error)
+	InsertPrediction(prediction databasestructs.Prediction) (sql.Result, error)
+	GetQuestionPredictions(ctx context.Context, questionid int64) ([]databasestructs.Prediction, error)
+	GetUserPredictions(ctx context.Context, contestid, userid int64) ([]databasestructs.Prediction, error)
+	SetPredictionScore(questionid, userid int64, score float64) (sql.Result, error)
+	GetContestLeaderboard(ctx context.Context, contestid int64) ([]databasestructs.ContestStanding, error)
+}
+
+type CandidateOperations interface {
+	InsertPollCandidate(candidate databasestructs.PollCandidate, nameKey string) (sql.Result, error)
+	GetPollCandidates(ctx context.Context, pollid int64, status string) ([]databasestructs.PollCandidate, error)
+	GetPollCandidateByID(id int64) (databasestructs.PollCandidate, error)
+	GetPollCandidateByKey(pollid int64, nameKey string) (databasestructs.PollCandidate, error)
+	UpdatePollCandidateStatus(id int64, status string) (sql.Result, error)
+	CountUserWriteIns(pollid, userid int64) (int64, error)
+	InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error)
+}
+
+type GroupOperations interface {
+	InsertGroup(group databasestructs.Group) (sql.Result, error)
+	GetGroupByID(id int64) (databasestructs.Group, error)
+	GetGroupByInviteCode(code string) (databasestructs.Group, error)
+	GetUserGroups(ctx context.Context, userid int64) ([]databasestructs.Group, error)
+	UpdateGroupInviteCode(id int64, code string) (sql.Result, error)
+	DeleteGroup(id int64) (sql.Result, error)
+	InsertGroupMember(member databasestructs.GroupMember) (sql.Result, error)
+	DeleteGroupMember(groupid, userid int64) (sql.Result, error)
+	GetGroupMembers(ctx context.Context, groupid int64) ([]databasestructs.GroupMember, error)
+	GetGroupMemberRole(groupid, userid int64) (string, error)
+	GetGroupPolls(ctx context.Context, groupid int64) ([]databasestructs.Poll, error)
+	GetGroupLeaderboard(ctx context.Context, groupid int64) ([]databasestructs.GroupStanding, error)
+}
+
+type GuestVoteOperations interface {
+	InsertGuestVote(vote databasestructs.GuestVote) (sql.Result, error)
+	CountGuestVotesByIP(pollid int64, ip string) (int64, error)
+	GetGuestDeviceVote(pollid int64, deviceid string) (int64, error)
+	GetGuestPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error)
+	ResetGuestVotes(pollid int64) (sql.Result, error)
+}
+
+type FraudOperations interface {
+	GetVotesForFraudCheck(ctx context.Context, since int64) ([]databasestructs.FraudCheckVote, error)
+	InsertVoteFlag(flag databasestructs.VoteFlag) (sql.Result, error)
+	GetVoteFlags(ctx context.Context, status string, pollid int64) ([]databasestructs.VoteFlag, error)
+	GetVoteFlagByID(id int64) (databasestructs.VoteFlag, error)
+	UpdateVoteFlagStatus(pollid, userid int64, status string, reviewedBy int64) (sql.Result, error)
+}
+
+type VoteEventOperations interface {
+	RetractPlayerVote(pollid, userid int64) (sql.Result, error)
+	GetUserVoteEvents(ctx context.Context, userid, viewerid, pollid int64) ([]databasestructs.VoteEvent, error)
+	GetPollVoteEvents(ctx context.Context, pollid, since int64) ([]databasestructs.VoteEvent, error)
+}
+
+type SeasonResultsOperations interface {
+	InsertTeamStanding(standing databasestructs.TeamStanding) (sql.Result, error)
+	GetTeamStandings(ctx context.Context, season string) ([]databasestructs.TeamStanding, error)
+	InsertSeasonAward(award databasestructs.SeasonAward) (sql.Result, error)
+	GetSeasonAwards(ctx context.Context, season string) ([]databasestructs.SeasonAward, error)
+}
+
 type UserOperations interface {
-	GetUserByUsername(username string) *sql.Row
-	GetUserByRefreshToken(refresh_token string) *sql.Row
-	GetUserByID(id int64) *sql.Row
-	GetUserRolesByID(id int64) *sql.Row
+	GetUserByUsername(username string) (databasestructs.User, error)
+	GetUserByRefreshToken(refresh_token string) (databasestructs.User, error)
+	GetUserByID(id int64) (databasestructs.User, error)
+	GetUserRolesByID(id int64) (string, error)
 	InsertUserRoles(role databasestructs.Role) (sql.Result, error)
 	UpdateUserRoles(roles string, user_id int64) (sql.Result, error)
 	InsertNewUser(user databasestructs.User) (sql.Result, error)
@@ -85,11 +216,12 @@ type UserOperations interface {
 	UpdateUserEmail(username, email string) (sql.Result, error)
 	UpdateUserUsername(oldusername, username string) (sql.Result, error)
 	UpdateUserProfilePic(username, profile_pic string) (sql.Result, error)
+	UpdateUserProfile(id int64, favoriteTeam, region string) (sql.Result, error)
 	DeleteUser(id int64) (sql.Result, error)
-	GetAllUsers() (*sql.Rows, error)
-	GetVotesOfUser(ctx context.Context, userid int64) (*sql.Rows, error)
+	GetAllUsers() ([]databasestructs.User, error)
+	GetVotesOfUser(ctx context.Context, userid, viewerid int64) ([]databasestructs.UserVote, error)
 	CreateAdminUser() error
-	GetCurrentProfilePic(id int64) *sql.Row
+	GetCurrentProfilePic(id int64) (string, error)
 }
 
 type GoatOperations interface {
@@ -97,8 +229,16 @@ type GoatOperations interface {
 	UpdateGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error)
 	UpdateGOATStats(stats databasestructs.GoatStats) (sql.Result, error)
 	InsertGOATStats(stats databasestructs.GoatStats) (sql.Result, error)
-	GetGOATStats() (*sql.Rows, error)
-	GetActivePlayers() (*sql.Rows, error)
+	GetGOATStats() ([]*databasestructs.PollResponse, error)
+	GetActivePlayers() ([]string, error)
+	GetGOATPlayerStats(ctx context.Context, playerid string) ([]databasestructs.GoatPlayerCareer, error)
+	InsertGOATPlayerSeason(season databasestructs.GoatPlayerSeason) (sql.Result, error)
+	GetGOATEraStats(ctx context.Context) ([]databasestructs.GoatEraSeason, error)
+	GetGOATCareerStats(ctx context.Context) ([]databasestructs.PlayerInfo, error)
+}
+
+type LeagueOperations interface {
+	InsertLeagueAverages(averages databasestructs.LeagueAverages) (sql.Result, error)
 }
 
 type SyncOperations interface {
@@ -116,8 +256,25 @@ type Config struct {
 func NewDB(conf Config) (Database, error) {
 	switch conf.DbType {
 	case MYSQL:
-		return mysql_db.NewDB(conf.DbName, conf.Addr)
+		db, err := mysql_db.NewDB(conf.DbName, conf.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return repository{Queries: db, inTx: func(tx *sql.Tx) Queries { return db.InTx(tx) }}, nil
+	case POSTGRES:
+		db, err := postgres_db.NewDB(conf.DbName, conf.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return repository{Queries: db, inTx: func(tx *sql.Tx) Queries { return db.InTx(tx) }}, nil
+	case SQLITE:
+		// the address is the path of the database file
+		db, err := sqlite_db.NewDB(conf.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return repository{Queries: db, inTx: func(tx *sql.Tx) Queries { return db.InTx(tx) }}, nil
+	default:
+		return nil, errors.New("incorrect db type entered")
 	}
-
-	return nil, errors.New("incorrect db type entered")
 }
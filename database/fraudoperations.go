@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+	"sportsvoting/databasestructs"
+)
+
+// GetVotesForFraudCheck returns the votes cast since the given unix time, grouped by poll and oldest first
+func (r repository) GetVotesForFraudCheck(ctx context.Context, since int64) ([]databasestructs.FraudCheckVote, error) {
+	rows, err := r.Queries.GetVotesForFraudCheck(ctx, since)
+	return collect(rows, err, func(row scanner) (databasestructs.FraudCheckVote, error) {
+		var vote databasestructs.FraudCheckVote
+		err := row.Scan(&vote.PollID, &vote.UserID, &vote.Choice, &vote.VotedAt, &vote.IP, &vote.Switches, &vote.AccountCreated, &vote.SignupIP)
+		return vote, err
+	})
+}
+
+func (r repository) GetVoteFlags(ctx context.Context, status string, pollid int64) ([]databasestructs.VoteFlag, error) {
+	rows, err := r.Queries.GetVoteFlags(ctx, status, pollid)
+	return collect(rows, err, scanVoteFlag)
+}
+
+func (r repository) GetVoteFlagByID(id int64) (databasestructs.VoteFlag, error) {
+	return one(r.Queries.GetVoteFlagByID(id), scanVoteFlag)
+}
+
+func scanVoteFlag(row scanner) (databasestructs.VoteFlag, error) {
+	var flag databasestructs.VoteFlag
+	err := row.Scan(&flag.ID, &flag.PollID, &flag.UserID, &flag.Username, &flag.Reason, &flag.Details, &flag.Status, &flag.CreatedAt, &flag.ReviewedBy)
+	return flag, err
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"sportsvoting/databasestructs"
+)
+
+// GetGOATStats returns the career averages and accolades the GOAT polls are voted on, best PER first
+func (r repository) GetGOATStats() ([]*databasestructs.PollResponse, error) {
+	rows, err := r.Queries.GetGOATStats()
+	return collect(rows, err, func(row scanner) (*databasestructs.PollResponse, error) {
+		player := &databasestructs.PollResponse{
+			Stats:        &databasestructs.PlayerStats{},
+			GoatPlayers:  &databasestructs.GoatPlayers{},
+			AdvStats:     &databasestructs.AdvancedStats{},
+			PlayoffStats: &databasestructs.PlayerStats{},
+		}
+		var position string
+		s, a, adv, playoffs := player.Stats, player.GoatPlayers, player.AdvStats, player.PlayoffStats
+		err := row.Scan(
+			&player.ID, &player.Name, &position, &s.Points, &s.Rebounds, &s.Assists, &s.Steals, &s.Blocks,
+			&a.AllStar, &a.AllNba, &a.AllDefense, &a.Championships, &a.Dpoy, &a.FMVP, &a.MVP, &adv.PER, &adv.OffWS, &adv.DefWS, &adv.WS, &adv.DefBPM, &adv.OffBPM, &adv.BPM,
+			&adv.DefRtg, &adv.OffRtg, &playoffs.Points, &playoffs.Rebounds, &playoffs.Assists)
+		return player, err
+	})
+}
+
+// GetActivePlayers returns the ids of the GOAT candidates who are still playing
+func (r repository) GetActivePlayers() ([]string, error) {
+	rows, err := r.Queries.GetActivePlayers()
+	return collect(rows, err, scanString)
+}
+
+// GetGOATPlayerStats returns the regular season and playoff careers of the player, both with the accolades
+func (r repository) GetGOATPlayerStats(ctx context.Context, playerid string) ([]databasestructs.GoatPlayerCareer, error) {
+	rows, err := r.Queries.GetGOATPlayerStats(ctx, playerid)
+	return collect(rows, err, func(row scanner) (databasestructs.GoatPlayerCareer, error) {
+		career := databasestructs.GoatPlayerCareer{
+			Accolades: databasestructs.GoatPlayers{ID: playerid},
+			Stats:     databasestructs.GoatStats{PlayerID: playerid},
+		}
+		a, s := &career.Accolades, &career.Stats
+		err := row.Scan(&a.Name, &a.AllStar, &a.AllNba, &a.AllDefense, &a.Championships, &a.Dpoy, &a.SixMan, &a.ROY, &a.FMVP, &a.MVP, &a.IsActive,
+			&s.Points, &s.Rebounds, &s.Assists, &s.Steals, &s.Blocks, &s.Turnovers, &s.FGPercentage, &s.ThreeFGPercentage, &s.FTPercentage, &s.PER, &s.OffWS, &s.DefWS, &s.WS, &s.OffBPM, &s.DefBPM, &s.BPM, &s.VORP, &s.OffRtg, &s.DefRtg,
+			&s.TotalPoints, &s.TotalRebounds, &s.TotalAssists, &s.TotalSteals, &s.TotalBlocks, &s.Position, &s.IsPlayoffs)
+		return career, err
+	})
+}
+
+// GetGOATEraStats returns a row for every season a GOAT candidate played, next to their career numbers
+func (r repository) GetGOATEraStats(ctx context.Context) ([]databasestructs.GoatEraSeason, error) {
+	rows, err := r.Queries.GetGOATEraStats(ctx)
+	return collect(rows, err, func(row scanner) (databasestructs.GoatEraSeason, error) {
+		var season databasestructs.GoatEraSeason
+		c, l := &season.Career, &season.League
+		err := row.Scan(&c.PlayerID, &season.Games, &c.Points, &c.Rebounds, &c.Assists, &c.Minutes, &c.TSPct, &l.Pace, &l.Points, &l.Rebounds, &l.Assists, &l.TSPct)
+		return season, err
+	})
+}
+
+// GetGOATCareerStats returns the regular season career averages of every GOAT candidate
+func (r repository) GetGOATCareerStats(ctx context.Context) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetGOATCareerStats(ctx)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerInfo, error) {
+		var p databasestructs.PlayerInfo
+		err := row.Scan(&p.ID, &p.Name, &p.Position, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.Turnovers, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.PER, &p.TSPct, &p.OffBPM, &p.DefBPM, &p.BPM, &p.OffRtg, &p.DefRtg)
+		p.PlayerStats.PlayerID = p.ID
+		p.AdvancedStats.PlayerID = p.ID
+		return p, err
+	})
+}
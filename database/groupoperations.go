@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertGroup returns ErrConflict when the invite code is taken
+func (r repository) InsertGroup(group databasestructs.Group) (sql.Result, error) {
+	res, err := r.Queries.InsertGroup(group)
+	return res, conflict(err)
+}
+
+func (r repository) GetGroupByID(id int64) (databasestructs.Group, error) {
+	return one(r.Queries.GetGroupByID(id), scanGroup)
+}
+
+func (r repository) GetGroupByInviteCode(code string) (databasestructs.Group, error) {
+	return one(r.Queries.GetGroupByInviteCode(code), scanGroup)
+}
+
+func (r repository) GetUserGroups(ctx context.Context, userid int64) ([]databasestructs.Group, error) {
+	rows, err := r.Queries.GetUserGroups(ctx, userid)
+	return collect(rows, err, scanGroup)
+}
+
+// UpdateGroupInviteCode returns ErrConflict when the invite code is taken
+func (r repository) UpdateGroupInviteCode(id int64, code string) (sql.Result, error) {
+	res, err := r.Queries.UpdateGroupInviteCode(id, code)
+	return res, conflict(err)
+}
+
+func (r repository) GetGroupMembers(ctx context.Context, groupid int64) ([]databasestructs.GroupMember, error) {
+	rows, err := r.Queries.GetGroupMembers(ctx, groupid)
+	return collect(rows, err, func(row scanner) (databasestructs.GroupMember, error) {
+		var member databasestructs.GroupMember
+		err := row.Scan(&member.GroupID, &member.UserID, &member.Username, &member.Role, &member.JoinedAt)
+		return member, err
+	})
+}
+
+// GetGroupMemberRole returns ErrNotFound when the user isn't a member of the group
+func (r repository) GetGroupMemberRole(groupid, userid int64) (string, error) {
+	return one(r.Queries.GetGroupMemberRole(groupid, userid), scanString)
+}
+
+func (r repository) GetGroupPolls(ctx context.Context, groupid int64) ([]databasestructs.Poll, error) {
+	rows, err := r.Queries.GetGroupPolls(ctx, groupid)
+	return collect(rows, err, scanPoll)
+}
+
+// GetGroupLeaderboard returns the members with the most points first, the handler ranks them
+func (r repository) GetGroupLeaderboard(ctx context.Context, groupid int64) ([]databasestructs.GroupStanding, error) {
+	rows, err := r.Queries.GetGroupLeaderboard(ctx, groupid)
+	return collect(rows, err, func(row scanner) (databasestructs.GroupStanding, error) {
+		var standing databasestructs.GroupStanding
+		err := row.Scan(&standing.UserID, &standing.Username, &standing.Score, &standing.PollsVoted, &standing.Predictions)
+		return standing, err
+	})
+}
+
+func scanGroup(row scanner) (databasestructs.Group, error) {
+	var group databasestructs.Group
+	err := row.Scan(&group.ID, &group.Name, &group.Description, &group.InviteCode, &group.OwnerID, &group.CreatedAt, &group.Members)
+	return group, err
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) CountGuestVotesByIP(pollid int64, ip string) (int64, error) {
+	return one(r.Queries.CountGuestVotesByIP(pollid, ip), scanCount)
+}
+
+// GetGuestDeviceVote returns how many votes the device cast in the poll, at most one
+func (r repository) GetGuestDeviceVote(pollid int64, deviceid string) (int64, error) {
+	return one(r.Queries.GetGuestDeviceVote(pollid, deviceid), scanCount)
+}
+
+// GetGuestPollVotes leaves out the votes for players that aren't in the database anymore
+func (r repository) GetGuestPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error) {
+	rows, err := r.Queries.GetGuestPollVotes(ctx, pollid)
+	tallies, err := collect(rows, err, func(row scanner) (databasestructs.VoteTally, error) {
+		var name sql.NullString
+		var tally databasestructs.VoteTally
+		err := row.Scan(&name, &tally.Votes)
+		tally.Name = name.String
+		return tally, err
+	})
+
+	known := tallies[:0]
+	for _, tally := range tallies {
+		if tally.Name != "" {
+			known = append(known, tally)
+		}
+	}
+
+	return known, err
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertMatchupVote returns ErrConflict when the user already voted on the pair, whichever of the two won
+func (r repository) InsertMatchupVote(vote databasestructs.MatchupVote) (sql.Result, error) {
+	res, err := r.Queries.InsertMatchupVote(vote)
+	return res, conflict(err)
+}
+
+func (r repository) GetMatchupRatings(ctx context.Context, pollid int64) ([]databasestructs.MatchupRating, error) {
+	rows, err := r.Queries.GetMatchupRatings(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.MatchupRating, error) {
+		var rating databasestructs.MatchupRating
+		err := row.Scan(&rating.PlayerID, &rating.Rating, &rating.Matches, &rating.Wins)
+		return rating, err
+	})
+}
+
+// GetMatchupVotes returns every vote of the poll in the order they were cast
+func (r repository) GetMatchupVotes(ctx context.Context, pollid int64) ([]databasestructs.MatchupVote, error) {
+	rows, err := r.Queries.GetMatchupVotes(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.MatchupVote, error) {
+		vote := databasestructs.MatchupVote{PollID: pollid}
+		err := row.Scan(&vote.ID, &vote.WinnerID, &vote.LoserID, &vote.RatingChange)
+		return vote, err
+	})
+}
+
+// GetPollsWithoutMatchupRatings returns the polls with matchup votes but no ratings, whose ratings have to be replayed from the votes
+func (r repository) GetPollsWithoutMatchupRatings(ctx context.Context) ([]int64, error) {
+	rows, err := r.Queries.GetPollsWithoutMatchupRatings(ctx)
+	return collect(rows, err, func(row scanner) (int64, error) {
+		var pollid int64
+		err := row.Scan(&pollid)
+		return pollid, err
+	})
+}
+
+func (r repository) GetUserMatchupVotes(ctx context.Context, pollid, userid int64) ([]databasestructs.MatchupVote, error) {
+	rows, err := r.Queries.GetUserMatchupVotes(ctx, pollid, userid)
+	return collect(rows, err, func(row scanner) (databasestructs.MatchupVote, error) {
+		vote := databasestructs.MatchupVote{PollID: pollid, UserID: userid}
+		err := row.Scan(&vote.WinnerID, &vote.LoserID)
+		return vote, err
+	})
+}
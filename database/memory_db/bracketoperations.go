@@ -0,0 +1,157 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+// InsertBracket returns ErrConflict when the poll already has a bracket
+func (m *MemoryDB) InsertBracket(bracket databasestructs.Bracket) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.brackets, func(b databasestructs.Bracket) bool { return b.PollID == bracket.PollID }); ok {
+			return nil, uniqueViolation("brackets.pollid")
+		}
+
+		bracket.ChampionID = ""
+		t.brackets = append(t.brackets, bracket)
+		return result{lastInsertID: bracket.PollID, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetBracket(pollid int64) (databasestructs.Bracket, error) {
+	var bracket databasestructs.Bracket
+	err := m.read(func(t *tables) error {
+		var ok bool
+		bracket, ok = find(t.brackets, func(b databasestructs.Bracket) bool { return b.PollID == pollid })
+		if !ok {
+			return database.ErrNotFound
+		}
+		return nil
+	})
+
+	return bracket, err
+}
+
+// GetDueBrackets returns the unfinished brackets whose current round closed before now
+func (m *MemoryDB) GetDueBrackets(ctx context.Context, now int64) ([]databasestructs.Bracket, error) {
+	var brackets []databasestructs.Bracket
+	err := m.read(func(t *tables) error {
+		brackets = where(t.brackets, func(b databasestructs.Bracket) bool { return b.ChampionID == "" && b.RoundEndsAt <= now })
+		return nil
+	})
+
+	return brackets, err
+}
+
+// AdvanceBracketRound only moves the bracket forward from the previous round, so a round can't be opened twice
+func (m *MemoryDB) AdvanceBracketRound(pollid, round, roundEndsAt int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.brackets, func(b databasestructs.Bracket) bool { return b.PollID == pollid && b.CurrentRound == round-1 }, func(b *databasestructs.Bracket) {
+			b.CurrentRound = round
+			b.RoundEndsAt = roundEndsAt
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) SetBracketChampion(pollid int64, championid string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.brackets, func(b databasestructs.Bracket) bool { return b.PollID == pollid }, func(b *databasestructs.Bracket) { b.ChampionID = championid })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// InsertBracketMatchup keeps the matchup already in that place of the bracket
+func (m *MemoryDB) InsertBracketMatchup(matchup databasestructs.BracketMatchup) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.matchups, func(bm databasestructs.BracketMatchup) bool {
+			return bm.PollID == matchup.PollID && bm.Round == matchup.Round && bm.Position == matchup.Position
+		}); ok {
+			return result{}, nil
+		}
+
+		matchup.ID = t.nextID("bracket_matchups")
+		matchup.WinnerID = ""
+		t.matchups = append(t.matchups, matchup)
+		return result{lastInsertID: matchup.ID, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetBracketMatchup(id int64) (databasestructs.BracketMatchup, error) {
+	var matchup databasestructs.BracketMatchup
+	err := m.read(func(t *tables) error {
+		var ok bool
+		matchup, ok = find(t.matchups, func(bm databasestructs.BracketMatchup) bool { return bm.ID == id })
+		if !ok {
+			return database.ErrNotFound
+		}
+		return nil
+	})
+
+	return matchup, err
+}
+
+// GetBracketMatchups returns the matchups of every round in order, with the votes each player got
+func (m *MemoryDB) GetBracketMatchups(ctx context.Context, pollid int64) ([]databasestructs.BracketMatchupTally, error) {
+	tallies := []databasestructs.BracketMatchupTally{}
+	err := m.read(func(t *tables) error {
+		for _, bm := range t.matchups {
+			if bm.PollID != pollid {
+				continue
+			}
+
+			tally := databasestructs.BracketMatchupTally{BracketMatchup: bm, Player1Name: t.bracketPlayerName(bm.Player1ID), Player2Name: t.bracketPlayerName(bm.Player2ID)}
+			for _, v := range t.bracketVotes {
+				if v.MatchupID == bm.ID && v.PlayerID == bm.Player1ID {
+					tally.Votes1++
+				}
+				if v.MatchupID == bm.ID && v.PlayerID == bm.Player2ID {
+					tally.Votes2++
+				}
+			}
+			tallies = append(tallies, tally)
+		}
+		return nil
+	})
+
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Round != tallies[j].Round {
+			return tallies[i].Round < tallies[j].Round
+		}
+		return tallies[i].Position < tallies[j].Position
+	})
+	return tallies, err
+}
+
+func (m *MemoryDB) SetBracketMatchupWinner(id int64, winnerid string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.matchups, func(bm databasestructs.BracketMatchup) bool { return bm.ID == id }, func(bm *databasestructs.BracketMatchup) { bm.WinnerID = winnerid })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// InsertBracketVote lets the user switch their pick while the matchup is open
+func (m *MemoryDB) InsertBracketVote(vote databasestructs.BracketVote) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.bracketVotes, func(v databasestructs.BracketVote) bool {
+			return v.MatchupID == vote.MatchupID && v.UserID == vote.UserID
+		}, func(v *databasestructs.BracketVote) { v.PlayerID = vote.PlayerID })
+		if updated == 0 {
+			t.bracketVotes = append(t.bracketVotes, vote)
+		}
+
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+// bracketPlayerName prefers the GOAT player, since brackets are mostly all-time polls
+func (t *tables) bracketPlayerName(playerid string) string {
+	if name := t.goatPlayerName(playerid); name != "" {
+		return name
+	}
+
+	return t.playerName(playerid)
+}
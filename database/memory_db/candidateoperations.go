@@ -0,0 +1,122 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strconv"
+)
+
+// InsertPollCandidate returns ErrConflict when a candidate with the same name is already in the poll
+func (m *MemoryDB) InsertPollCandidate(c databasestructs.PollCandidate, nameKey string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(c.Status, "approved", "pending", "rejected") {
+			return nil, checkViolation("poll_candidates.status", c.Status)
+		}
+		if _, ok := find(t.candidates, func(existing candidate) bool { return existing.PollID == c.PollID && existing.nameKey == nameKey }); ok {
+			return nil, uniqueViolation("poll_candidates.pollid, poll_candidates.name_key")
+		}
+
+		c.ID = t.nextID("poll_candidates")
+		t.candidates = append(t.candidates, candidate{PollCandidate: c, nameKey: nameKey})
+		return result{lastInsertID: c.ID, rowsAffected: 1}, nil
+	})
+}
+
+// GetPollCandidates returns the curated candidates before the write-ins, each by name
+func (m *MemoryDB) GetPollCandidates(ctx context.Context, pollid int64, status string) ([]databasestructs.PollCandidate, error) {
+	candidates := []databasestructs.PollCandidate{}
+	err := m.read(func(t *tables) error {
+		for _, c := range t.candidates {
+			if c.PollID == pollid && c.Status == status {
+				candidates = append(candidates, c.PollCandidate)
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].IsWriteIn != candidates[j].IsWriteIn {
+			return !candidates[i].IsWriteIn
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates, err
+}
+
+func (m *MemoryDB) GetPollCandidateByID(id int64) (databasestructs.PollCandidate, error) {
+	return m.findCandidate(func(c candidate) bool { return c.ID == id })
+}
+
+func (m *MemoryDB) GetPollCandidateByKey(pollid int64, nameKey string) (databasestructs.PollCandidate, error) {
+	return m.findCandidate(func(c candidate) bool { return c.PollID == pollid && c.nameKey == nameKey })
+}
+
+func (m *MemoryDB) UpdatePollCandidateStatus(id int64, status string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(status, "approved", "pending", "rejected") {
+			return nil, checkViolation("poll_candidates.status", status)
+		}
+
+		updated := update(t.candidates, func(c candidate) bool { return c.ID == id }, func(c *candidate) { c.Status = status })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) CountUserWriteIns(pollid, userid int64) (int64, error) {
+	var count int64
+	err := m.read(func(t *tables) error {
+		count = int64(len(where(t.candidates, func(c candidate) bool { return c.PollID == pollid && c.SubmittedBy == userid && c.IsWriteIn })))
+		return nil
+	})
+
+	return count, err
+}
+
+// InsertCandidateVote replaces whatever the user voted for in the poll before
+func (m *MemoryDB) InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		choice := strconv.FormatInt(candidateid, 10)
+		event := "cast"
+		var previous string
+		var switches int64
+
+		earlier, voted := find(t.votes, func(v playerVote) bool { return v.pollID == pollid && v.userID == userid })
+		if voted {
+			previous = earlier.choice()
+			if previous == choice {
+				return nil, nil
+			}
+			event = "changed"
+			switches = earlier.switches + 1
+		}
+
+		filter(&t.votes, func(v playerVote) bool { return v.pollID == pollid && v.userID == userid })
+		res := t.insertVote(playerVote{candidateID: candidateid, pollID: pollid, userID: userid, createdAt: now(), ip: ip, switches: switches})
+		t.insertVoteEvent(pollid, userid, event, choice, previous)
+		return res, nil
+	})
+}
+
+func (m *MemoryDB) findCandidate(match func(candidate) bool) (databasestructs.PollCandidate, error) {
+	var found databasestructs.PollCandidate
+	err := m.read(func(t *tables) error {
+		c, ok := find(t.candidates, match)
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		found = c.PollCandidate
+		return nil
+	})
+
+	return found, err
+}
+
+// candidateName is the name of the candidate of the poll whose id is the choice
+func (t *tables) candidateName(pollid int64, choice string) string {
+	c, _ := find(t.candidates, func(c candidate) bool { return c.PollID == pollid && strconv.FormatInt(c.ID, 10) == choice })
+	return c.Name
+}
@@ -0,0 +1,264 @@
+package memory_db
+
+import "sportsvoting/databasestructs"
+
+func cloneRows[T any](rows []T) []T {
+	if rows == nil {
+		return nil
+	}
+
+	return append(make([]T, 0, len(rows)), rows...)
+}
+
+func (t tables) clone() tables {
+	seq := make(map[string]int64, len(t.seq))
+	for table, id := range t.seq {
+		seq[table] = id
+	}
+
+	return tables{
+		seq:            seq,
+		teams:          cloneRows(t.teams),
+		players:        cloneRows(t.players),
+		stats:          cloneRows(t.stats),
+		advancedStats:  cloneRows(t.advancedStats),
+		goatPlayers:    cloneRows(t.goatPlayers),
+		goatStats:      cloneRows(t.goatStats),
+		goatSeasons:    cloneRows(t.goatSeasons),
+		leagueAverages: cloneRows(t.leagueAverages),
+		seasons:        cloneRows(t.seasons),
+		syncTimes:      cloneRows(t.syncTimes),
+		users:          cloneRows(t.users),
+		roles:          cloneRows(t.roles),
+		groups:         cloneRows(t.groups),
+		members:        cloneRows(t.members),
+		polls:          cloneRows(t.polls),
+		candidates:     cloneRows(t.candidates),
+		votes:          cloneRows(t.votes),
+		guestVotes:     cloneRows(t.guestVotes),
+		events:         cloneRows(t.events),
+		flags:          cloneRows(t.flags),
+		matchupVotes:   cloneRows(t.matchupVotes),
+		ratings:        cloneRows(t.ratings),
+		brackets:       cloneRows(t.brackets),
+		matchups:       cloneRows(t.matchups),
+		bracketVotes:   cloneRows(t.bracketVotes),
+		questions:      cloneRows(t.questions),
+		ballots:        cloneRows(t.ballots),
+		answers:        cloneRows(t.answers),
+
+		contests:         cloneRows(t.contests),
+		contestQuestions: cloneRows(t.contestQuestions),
+		predictions:      cloneRows(t.predictions),
+		standings:        cloneRows(t.standings),
+		awards:           cloneRows(t.awards),
+	}
+}
+
+// keys collects the primary keys of a table to look references up in
+func keys[T any, K comparable](rows []T, key func(T) K) map[K]bool {
+	set := make(map[K]bool, len(rows))
+	for _, row := range rows {
+		set[key(row)] = true
+	}
+
+	return set
+}
+
+// checkForeignKeys fails when any row points at a row that doesn't exist, the deletes cascade beforehand where the schema does
+func (t *tables) checkForeignKeys() error {
+	teams := keys(t.teams, func(team databasestructs.TeamInfo) string { return team.TeamAbbr })
+	players := keys(t.players, func(p databasestructs.PlayerInfo) string { return p.ID })
+	goatPlayers := keys(t.goatPlayers, func(p databasestructs.GoatPlayers) string { return p.ID })
+	users := keys(t.users, func(u user) int64 { return u.ID })
+	groups := keys(t.groups, func(g databasestructs.Group) int64 { return g.ID })
+	polls := keys(t.polls, func(p databasestructs.Poll) int64 { return p.ID })
+	candidates := keys(t.candidates, func(c candidate) int64 { return c.ID })
+	brackets := keys(t.brackets, func(b databasestructs.Bracket) int64 { return b.PollID })
+	matchups := keys(t.matchups, func(m databasestructs.BracketMatchup) int64 { return m.ID })
+	questions := keys(t.questions, func(q databasestructs.PollQuestion) int64 { return q.ID })
+	ballots := keys(t.ballots, func(b ballot) int64 { return b.id })
+	contests := keys(t.contests, func(c databasestructs.PredictionContest) int64 { return c.ID })
+	contestQuestions := keys(t.contestQuestions, func(q databasestructs.PredictionQuestion) int64 { return q.ID })
+
+	// optional references are left empty instead of NULL
+	optionalString := func(set map[string]bool, key string) bool { return key == "" || set[key] }
+	optionalID := func(set map[int64]bool, key int64) bool { return key == 0 || set[key] }
+
+	ok := true
+	for _, p := range t.players {
+		ok = ok && teams[p.TeamAbbr]
+	}
+	for _, s := range t.stats {
+		ok = ok && players[s.PlayerID] && teams[s.TeamAbbr]
+	}
+	for _, s := range t.advancedStats {
+		ok = ok && players[s.PlayerID] && teams[s.TeamAbbr]
+	}
+	for _, s := range t.goatStats {
+		ok = ok && goatPlayers[s.PlayerID]
+	}
+	for _, s := range t.goatSeasons {
+		ok = ok && goatPlayers[s.PlayerID]
+	}
+	for _, r := range t.roles {
+		ok = ok && users[r.userID]
+	}
+	for _, g := range t.groups {
+		ok = ok && users[g.OwnerID]
+	}
+	for _, m := range t.members {
+		ok = ok && groups[m.GroupID] && users[m.UserID]
+	}
+	for _, p := range t.polls {
+		ok = ok && users[p.UserID] && optionalID(groups, p.GroupID)
+	}
+	for _, c := range t.candidates {
+		ok = ok && polls[c.PollID] && optionalID(users, c.SubmittedBy)
+	}
+	for _, v := range t.votes {
+		ok = ok && polls[v.pollID] && users[v.userID] && optionalString(players, v.playerID) && optionalString(goatPlayers, v.goatPlayerID) && optionalID(candidates, v.candidateID)
+	}
+	for _, v := range t.guestVotes {
+		ok = ok && polls[v.PollID] && optionalID(candidates, v.CandidateID)
+	}
+	for _, e := range t.events {
+		ok = ok && polls[e.PollID] && users[e.userID]
+	}
+	for _, f := range t.flags {
+		ok = ok && polls[f.PollID] && users[f.UserID] && optionalID(users, f.ReviewedBy)
+	}
+	for _, v := range t.matchupVotes {
+		ok = ok && polls[v.PollID] && users[v.UserID]
+	}
+	for _, r := range t.ratings {
+		ok = ok && polls[r.pollID]
+	}
+	for _, b := range t.brackets {
+		ok = ok && polls[b.PollID]
+	}
+	for _, m := range t.matchups {
+		ok = ok && brackets[m.PollID]
+	}
+	for _, v := range t.bracketVotes {
+		ok = ok && matchups[v.MatchupID] && users[v.UserID]
+	}
+	for _, q := range t.questions {
+		ok = ok && polls[q.PollID]
+	}
+	for _, b := range t.ballots {
+		ok = ok && polls[b.pollID] && users[b.userID]
+	}
+	for _, a := range t.answers {
+		ok = ok && ballots[a.BallotID] && questions[a.QuestionID]
+	}
+	for _, c := range t.contests {
+		ok = ok && users[c.UserID]
+	}
+	for _, q := range t.contestQuestions {
+		ok = ok && contests[q.ContestID]
+	}
+	for _, p := range t.predictions {
+		ok = ok && contestQuestions[p.QuestionID] && users[p.UserID]
+	}
+	for _, s := range t.standings {
+		ok = ok && teams[s.TeamAbbr]
+	}
+
+	if !ok {
+		return ErrForeignKey
+	}
+
+	return nil
+}
+
+// deleteUser removes the user with everything that cascades from them, their polls and votes have to go first
+func (t *tables) deleteUser(id int64) int64 {
+	removed := filter(&t.users, func(u user) bool { return u.ID == id })
+
+	for _, g := range where(t.groups, func(g databasestructs.Group) bool { return g.OwnerID == id }) {
+		t.deleteGroup(g.ID)
+	}
+	filter(&t.members, func(m databasestructs.GroupMember) bool { return m.UserID == id })
+	filter(&t.roles, func(r role) bool { return r.userID == id })
+	filter(&t.matchupVotes, func(v matchupVote) bool { return v.UserID == id })
+	filter(&t.bracketVotes, func(v databasestructs.BracketVote) bool { return v.UserID == id })
+	for _, b := range where(t.ballots, func(b ballot) bool { return b.userID == id }) {
+		t.deleteBallot(b.id)
+	}
+	filter(&t.flags, func(f databasestructs.VoteFlag) bool { return f.UserID == id })
+	filter(&t.events, func(e voteEvent) bool { return e.userID == id })
+	filter(&t.predictions, func(p databasestructs.Prediction) bool { return p.UserID == id })
+
+	update(t.flags, func(f databasestructs.VoteFlag) bool { return f.ReviewedBy == id }, func(f *databasestructs.VoteFlag) { f.ReviewedBy = 0 })
+	update(t.candidates, func(c candidate) bool { return c.SubmittedBy == id }, func(c *candidate) { c.SubmittedBy = 0 })
+
+	return removed
+}
+
+// deleteGroup removes the group with its members and polls
+func (t *tables) deleteGroup(id int64) int64 {
+	removed := filter(&t.groups, func(g databasestructs.Group) bool { return g.ID == id })
+
+	filter(&t.members, func(m databasestructs.GroupMember) bool { return m.GroupID == id })
+	for _, p := range where(t.polls, func(p databasestructs.Poll) bool { return p.GroupID == id }) {
+		t.deletePoll(p.ID)
+	}
+
+	return removed
+}
+
+// deletePoll removes the poll with everything that cascades from it, the player votes have to be reset first
+func (t *tables) deletePoll(id int64) int64 {
+	removed := filter(&t.polls, func(p databasestructs.Poll) bool { return p.ID == id })
+
+	for _, c := range where(t.candidates, func(c candidate) bool { return c.PollID == id }) {
+		t.deleteCandidate(c.ID)
+	}
+	filter(&t.guestVotes, func(v databasestructs.GuestVote) bool { return v.PollID == id })
+	filter(&t.events, func(e voteEvent) bool { return e.PollID == id })
+	filter(&t.flags, func(f databasestructs.VoteFlag) bool { return f.PollID == id })
+	filter(&t.matchupVotes, func(v matchupVote) bool { return v.PollID == id })
+	filter(&t.ratings, func(r matchupRating) bool { return r.pollID == id })
+	t.deleteBracket(id)
+	for _, q := range where(t.questions, func(q databasestructs.PollQuestion) bool { return q.PollID == id }) {
+		t.deleteQuestion(q.ID)
+	}
+	for _, b := range where(t.ballots, func(b ballot) bool { return b.pollID == id }) {
+		t.deleteBallot(b.id)
+	}
+
+	return removed
+}
+
+// deleteCandidate removes the candidate with the votes for them
+func (t *tables) deleteCandidate(id int64) {
+	filter(&t.candidates, func(c candidate) bool { return c.ID == id })
+	filter(&t.votes, func(v playerVote) bool { return v.candidateID == id })
+	filter(&t.guestVotes, func(v databasestructs.GuestVote) bool { return v.CandidateID == id })
+}
+
+// deleteBracket removes the bracket of the poll with its matchups and their votes
+func (t *tables) deleteBracket(pollid int64) {
+	filter(&t.brackets, func(b databasestructs.Bracket) bool { return b.PollID == pollid })
+
+	matchups := keys(where(t.matchups, func(m databasestructs.BracketMatchup) bool { return m.PollID == pollid }), func(m databasestructs.BracketMatchup) int64 { return m.ID })
+	filter(&t.matchups, func(m databasestructs.BracketMatchup) bool { return matchups[m.ID] })
+	filter(&t.bracketVotes, func(v databasestructs.BracketVote) bool { return matchups[v.MatchupID] })
+}
+
+// deleteQuestion removes the survey question with the answers to it
+func (t *tables) deleteQuestion(id int64) int64 {
+	removed := filter(&t.questions, func(q databasestructs.PollQuestion) bool { return q.ID == id })
+	filter(&t.answers, func(a databasestructs.SurveyAnswer) bool { return a.QuestionID == id })
+
+	return removed
+}
+
+// deleteBallot removes the survey ballot with its answers
+func (t *tables) deleteBallot(id int64) int64 {
+	removed := filter(&t.ballots, func(b ballot) bool { return b.id == id })
+	filter(&t.answers, func(a databasestructs.SurveyAnswer) bool { return a.BallotID == id })
+
+	return removed
+}
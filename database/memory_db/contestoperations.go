@@ -0,0 +1,201 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+func (m *MemoryDB) InsertContest(contest databasestructs.PredictionContest) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		contest.ID = t.nextID("prediction_contests")
+		contest.Resolved = false
+		t.contests = append(t.contests, contest)
+		return result{lastInsertID: contest.ID, rowsAffected: 1}, nil
+	})
+}
+
+// GetContests returns the contests with the latest deadline first
+func (m *MemoryDB) GetContests(ctx context.Context) ([]databasestructs.PredictionContest, error) {
+	var contests []databasestructs.PredictionContest
+	err := m.read(func(t *tables) error {
+		contests = cloneRows(t.contests)
+		return nil
+	})
+
+	sort.SliceStable(contests, func(i, j int) bool { return contests[i].Deadline > contests[j].Deadline })
+	return contests, err
+}
+
+func (m *MemoryDB) GetContestByID(id int64) (databasestructs.PredictionContest, error) {
+	var contest databasestructs.PredictionContest
+	err := m.read(func(t *tables) error {
+		var ok bool
+		contest, ok = find(t.contests, func(c databasestructs.PredictionContest) bool { return c.ID == id })
+		if !ok {
+			return database.ErrNotFound
+		}
+		return nil
+	})
+
+	return contest, err
+}
+
+// GetUnresolvedContests returns the contests that are locked but not graded yet
+func (m *MemoryDB) GetUnresolvedContests(ctx context.Context, now int64) ([]databasestructs.PredictionContest, error) {
+	var contests []databasestructs.PredictionContest
+	err := m.read(func(t *tables) error {
+		contests = where(t.contests, func(c databasestructs.PredictionContest) bool { return !c.Resolved && c.Deadline <= now })
+		return nil
+	})
+
+	return contests, err
+}
+
+func (m *MemoryDB) SetContestResolved(id int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.contests, func(c databasestructs.PredictionContest) bool { return c.ID == id }, func(c *databasestructs.PredictionContest) { c.Resolved = true })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// InsertContestQuestion returns ErrConflict when the contest already has a question in that position
+func (m *MemoryDB) InsertContestQuestion(question databasestructs.PredictionQuestion) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(question.Type, "win_total", "award", "stat_leader") {
+			return nil, checkViolation("type", question.Type)
+		}
+		if _, ok := find(t.contestQuestions, func(q databasestructs.PredictionQuestion) bool {
+			return q.ContestID == question.ContestID && q.Position == question.Position
+		}); ok {
+			return nil, uniqueViolation("prediction_questions.contestid, prediction_questions.position")
+		}
+
+		question.ID = t.nextID("prediction_questions")
+		question.Answer = nil
+		question.AnswerNumber = nil
+		t.contestQuestions = append(t.contestQuestions, question)
+		return result{lastInsertID: question.ID, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetContestQuestions(ctx context.Context, contestid int64) ([]databasestructs.PredictionQuestion, error) {
+	var questions []databasestructs.PredictionQuestion
+	err := m.read(func(t *tables) error {
+		questions = t.questionsOf(contestid)
+		return nil
+	})
+
+	return questions, err
+}
+
+func (m *MemoryDB) ResolveContestQuestion(id int64, answer string, answerNumber *float64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.contestQuestions, func(q databasestructs.PredictionQuestion) bool { return q.ID == id }, func(q *databasestructs.PredictionQuestion) {
+			q.Answer = &answer
+			q.AnswerNumber = answerNumber
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// InsertPrediction overwrites an earlier prediction of the user for the same question
+func (m *MemoryDB) InsertPrediction(prediction databasestructs.Prediction) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.predictions, func(p databasestructs.Prediction) bool {
+			return p.QuestionID == prediction.QuestionID && p.UserID == prediction.UserID
+		}, func(p *databasestructs.Prediction) {
+			p.Value = prediction.Value
+			p.Number = prediction.Number
+		})
+		if updated == 0 {
+			prediction.Score = nil
+			t.predictions = append(t.predictions, prediction)
+		}
+
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetQuestionPredictions(ctx context.Context, questionid int64) ([]databasestructs.Prediction, error) {
+	var predictions []databasestructs.Prediction
+	err := m.read(func(t *tables) error {
+		predictions = where(t.predictions, func(p databasestructs.Prediction) bool { return p.QuestionID == questionid })
+		return nil
+	})
+
+	return predictions, err
+}
+
+// GetUserPredictions returns the predictions of the user in the order of the questions
+func (m *MemoryDB) GetUserPredictions(ctx context.Context, contestid, userid int64) ([]databasestructs.Prediction, error) {
+	predictions := []databasestructs.Prediction{}
+	err := m.read(func(t *tables) error {
+		for _, q := range t.questionsOf(contestid) {
+			if p, ok := find(t.predictions, func(p databasestructs.Prediction) bool { return p.QuestionID == q.ID && p.UserID == userid }); ok {
+				predictions = append(predictions, p)
+			}
+		}
+		return nil
+	})
+
+	return predictions, err
+}
+
+func (m *MemoryDB) SetPredictionScore(questionid, userid int64, score float64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.predictions, func(p databasestructs.Prediction) bool { return p.QuestionID == questionid && p.UserID == userid }, func(p *databasestructs.Prediction) { p.Score = &score })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// GetContestLeaderboard returns the users with the most points first, the handler ranks them
+func (m *MemoryDB) GetContestLeaderboard(ctx context.Context, contestid int64) ([]databasestructs.ContestStanding, error) {
+	standings := []databasestructs.ContestStanding{}
+	err := m.read(func(t *tables) error {
+		questions := keys(where(t.contestQuestions, func(q databasestructs.PredictionQuestion) bool { return q.ContestID == contestid }), func(q databasestructs.PredictionQuestion) int64 { return q.ID })
+
+		for _, u := range t.users {
+			predictions := where(t.predictions, func(p databasestructs.Prediction) bool { return questions[p.QuestionID] && p.UserID == u.ID })
+			if len(predictions) == 0 {
+				continue
+			}
+
+			standing := databasestructs.ContestStanding{UserID: u.ID, Username: u.Username}
+			standing.Score, standing.Graded = scored(predictions)
+			standings = append(standings, standing)
+		}
+		return nil
+	})
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].Username < standings[j].Username
+	})
+	return standings, err
+}
+
+// questionsOf returns the questions of the contest by position
+func (t *tables) questionsOf(contestid int64) []databasestructs.PredictionQuestion {
+	questions := where(t.contestQuestions, func(q databasestructs.PredictionQuestion) bool { return q.ContestID == contestid })
+	sort.SliceStable(questions, func(i, j int) bool { return questions[i].Position < questions[j].Position })
+	return questions
+}
+
+// scored adds up the points of the graded predictions and counts them
+func scored(predictions []databasestructs.Prediction) (float64, int64) {
+	var total float64
+	var graded int64
+	for _, p := range predictions {
+		if p.Score != nil {
+			total += *p.Score
+			graded++
+		}
+	}
+
+	return total, graded
+}
@@ -0,0 +1,105 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+// GetVotesForFraudCheck returns the votes cast since the given unix time with the age and signup address of the voter
+func (m *MemoryDB) GetVotesForFraudCheck(ctx context.Context, since int64) ([]databasestructs.FraudCheckVote, error) {
+	votes := []databasestructs.FraudCheckVote{}
+	err := m.read(func(t *tables) error {
+		for _, v := range t.votes {
+			if v.createdAt < since {
+				continue
+			}
+
+			voter, _ := t.user(v.userID)
+			votes = append(votes, databasestructs.FraudCheckVote{PollID: v.pollID, UserID: v.userID, Choice: v.choice(), VotedAt: v.createdAt, IP: v.ip, Switches: v.switches, AccountCreated: voter.CreatedAt, SignupIP: voter.SignupIP})
+		}
+		return nil
+	})
+
+	sort.SliceStable(votes, func(i, j int) bool {
+		if votes[i].PollID != votes[j].PollID {
+			return votes[i].PollID < votes[j].PollID
+		}
+		return votes[i].VotedAt < votes[j].VotedAt
+	})
+	return votes, err
+}
+
+// InsertVoteFlag keeps the first flag for the same reason, so cleared flags aren't opened again by the next run
+func (m *MemoryDB) InsertVoteFlag(flag databasestructs.VoteFlag) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(flag.Reason, "new_account_burst", "ip_cluster", "vote_switching") {
+			return nil, checkViolation("vote_flags.reason", flag.Reason)
+		}
+		if _, ok := find(t.flags, func(f databasestructs.VoteFlag) bool {
+			return f.PollID == flag.PollID && f.UserID == flag.UserID && f.Reason == flag.Reason
+		}); ok {
+			return result{}, nil
+		}
+
+		flag.ID = t.nextID("vote_flags")
+		flag.Username = ""
+		flag.Status = "open"
+		flag.ReviewedBy = 0
+		t.flags = append(t.flags, flag)
+		return result{lastInsertID: flag.ID, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetVoteFlags(ctx context.Context, status string, pollid int64) ([]databasestructs.VoteFlag, error) {
+	flags := []databasestructs.VoteFlag{}
+	err := m.read(func(t *tables) error {
+		for _, f := range t.flags {
+			if f.Status == status && (pollid == 0 || f.PollID == pollid) {
+				flags = append(flags, t.withUsername(f))
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(flags, func(i, j int) bool { return flags[i].CreatedAt > flags[j].CreatedAt })
+	return flags, err
+}
+
+func (m *MemoryDB) GetVoteFlagByID(id int64) (databasestructs.VoteFlag, error) {
+	var flag databasestructs.VoteFlag
+	err := m.read(func(t *tables) error {
+		f, ok := find(t.flags, func(f databasestructs.VoteFlag) bool { return f.ID == id })
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		flag = t.withUsername(f)
+		return nil
+	})
+
+	return flag, err
+}
+
+// UpdateVoteFlagStatus reviews every flag of the user's ballot in the poll at once, since the ballot is either counted or not
+func (m *MemoryDB) UpdateVoteFlagStatus(pollid, userid int64, status string, reviewedBy int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(status, "open", "excluded", "cleared") {
+			return nil, checkViolation("vote_flags.status", status)
+		}
+
+		updated := update(t.flags, func(f databasestructs.VoteFlag) bool { return f.PollID == pollid && f.UserID == userid }, func(f *databasestructs.VoteFlag) {
+			f.Status = status
+			f.ReviewedBy = reviewedBy
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (t *tables) withUsername(flag databasestructs.VoteFlag) databasestructs.VoteFlag {
+	voter, _ := t.user(flag.UserID)
+	flag.Username = voter.Username
+	return flag
+}
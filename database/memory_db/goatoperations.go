@@ -0,0 +1,295 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"sportsvoting/databasestructs"
+)
+
+// InsertGOATPlayer keeps the player already stored under the id
+func (m *MemoryDB) InsertGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := t.goatPlayer(info.ID); ok {
+			return result{}, nil
+		}
+
+		t.goatPlayers = append(t.goatPlayers, info)
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+// UpdateGOATPlayer updates the accolades, the name stays as it was inserted
+func (m *MemoryDB) UpdateGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.goatPlayers, func(p databasestructs.GoatPlayers) bool { return p.ID == info.ID }, func(p *databasestructs.GoatPlayers) {
+			name := p.Name
+			*p = info
+			p.Name = name
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// UpdateGOATStats updates the regular season or playoff career of the player, whichever the stats are for
+func (m *MemoryDB) UpdateGOATStats(stats databasestructs.GoatStats) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.goatStats, func(s databasestructs.GoatStats) bool {
+			return s.PlayerID == stats.PlayerID && s.IsPlayoffs == stats.IsPlayoffs
+		}, func(s *databasestructs.GoatStats) {
+			isActive := s.IsActive
+			*s = stats
+			s.IsActive = isActive
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) InsertGOATStats(stats databasestructs.GoatStats) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		t.goatStats = append(t.goatStats, stats)
+		return result{lastInsertID: t.nextID("goat_stats"), rowsAffected: 1}, nil
+	})
+}
+
+// GetGOATStats returns the career averages and accolades the GOAT polls are voted on, best PER first
+func (m *MemoryDB) GetGOATStats() ([]*databasestructs.PollResponse, error) {
+	// the averages run over every career row next to every playoff row of the player, like the join of the sql backends
+	type group struct {
+		playerID string
+		position string
+	}
+	type sums struct {
+		rows                                           float64
+		points, rebounds, assists, steals, blocks      float64
+		per, ows, dws, ws, dbpm, obpm, bpm, drtg, ortg float64
+		playoffPoints, playoffRebounds, playoffAssists float64
+	}
+
+	players := []*databasestructs.PollResponse{}
+	err := m.read(func(t *tables) error {
+		totals := map[group]*sums{}
+		var groups []group
+		for _, p := range t.goatPlayers {
+			for _, sr := range t.goatStats {
+				if sr.PlayerID != p.ID {
+					continue
+				}
+
+				for _, sp := range t.goatStats {
+					if sp.PlayerID != p.ID || !sp.IsPlayoffs {
+						continue
+					}
+
+					g := group{p.ID, sr.Position}
+					if totals[g] == nil {
+						totals[g] = &sums{}
+						groups = append(groups, g)
+					}
+
+					s := totals[g]
+					s.rows++
+					s.points, s.rebounds, s.assists, s.steals, s.blocks = s.points+sr.Points, s.rebounds+sr.Rebounds, s.assists+sr.Assists, s.steals+sr.Steals, s.blocks+sr.Blocks
+					s.per, s.ows, s.dws, s.ws = s.per+sr.PER, s.ows+sr.OffWS, s.dws+sr.DefWS, s.ws+sr.WS
+					s.dbpm, s.obpm, s.bpm, s.drtg, s.ortg = s.dbpm+sr.DefBPM, s.obpm+sr.OffBPM, s.bpm+sr.BPM, s.drtg+sr.DefRtg, s.ortg+sr.OffRtg
+					s.playoffPoints, s.playoffRebounds, s.playoffAssists = s.playoffPoints+sp.Points, s.playoffRebounds+sp.Rebounds, s.playoffAssists+sp.Assists
+				}
+			}
+		}
+
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].playerID != groups[j].playerID {
+				return groups[i].playerID < groups[j].playerID
+			}
+			return groups[i].position < groups[j].position
+		})
+
+		for _, g := range groups {
+			p, _ := t.goatPlayer(g.playerID)
+			s := totals[g]
+			avg := func(sum float64) float64 { return math.Round(sum/s.rows*10) / 10 }
+			players = append(players, &databasestructs.PollResponse{
+				ID:          p.ID,
+				Name:        p.Name,
+				Stats:       &databasestructs.PlayerStats{Points: avg(s.points), Rebounds: avg(s.rebounds), Assists: avg(s.assists), Steals: avg(s.steals), Blocks: avg(s.blocks)},
+				GoatPlayers: &databasestructs.GoatPlayers{AllStar: p.AllStar, AllNba: p.AllNba, AllDefense: p.AllDefense, Championships: p.Championships, Dpoy: p.Dpoy, FMVP: p.FMVP, MVP: p.MVP},
+				AdvStats: &databasestructs.AdvancedStats{
+					PER: avg(s.per), OffWS: avg(s.ows), DefWS: avg(s.dws), WS: avg(s.ws), DefBPM: avg(s.dbpm), OffBPM: avg(s.obpm), BPM: avg(s.bpm), DefRtg: avg(s.drtg), OffRtg: avg(s.ortg),
+				},
+				PlayoffStats: &databasestructs.PlayerStats{Points: avg(s.playoffPoints), Rebounds: avg(s.playoffRebounds), Assists: avg(s.playoffAssists)},
+			})
+		}
+		return nil
+	})
+
+	sort.SliceStable(players, func(i, j int) bool { return players[i].AdvStats.PER > players[j].AdvStats.PER })
+	return players, err
+}
+
+// GetActivePlayers returns the ids of the GOAT candidates who are still playing
+func (m *MemoryDB) GetActivePlayers() ([]string, error) {
+	ids := []string{}
+	err := m.read(func(t *tables) error {
+		for _, p := range t.goatPlayers {
+			if p.IsActive {
+				ids = append(ids, p.ID)
+			}
+		}
+		return nil
+	})
+
+	return ids, err
+}
+
+// GetGOATPlayerStats returns the regular season and playoff careers of the player, both with the accolades
+func (m *MemoryDB) GetGOATPlayerStats(ctx context.Context, playerid string) ([]databasestructs.GoatPlayerCareer, error) {
+	careers := []databasestructs.GoatPlayerCareer{}
+	err := m.read(func(t *tables) error {
+		p, ok := t.goatPlayer(playerid)
+		if !ok {
+			return nil
+		}
+
+		for _, s := range t.goatStats {
+			if s.PlayerID == playerid {
+				s.IsActive, s.Minutes, s.TSPct = false, 0, 0
+				careers = append(careers, databasestructs.GoatPlayerCareer{Accolades: p, Stats: s})
+			}
+		}
+		return nil
+	})
+
+	return careers, err
+}
+
+// InsertGOATPlayerSeason updates the games played when the season is already stored
+func (m *MemoryDB) InsertGOATPlayerSeason(season databasestructs.GoatPlayerSeason) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.goatSeasons, func(s databasestructs.GoatPlayerSeason) bool {
+			return s.PlayerID == season.PlayerID && s.Season == season.Season
+		}, func(s *databasestructs.GoatPlayerSeason) { s.Games = season.Games })
+		if updated > 0 {
+			return result{rowsAffected: updated}, nil
+		}
+
+		t.goatSeasons = append(t.goatSeasons, season)
+		return result{lastInsertID: t.nextID("goat_player_seasons"), rowsAffected: 1}, nil
+	})
+}
+
+// GetGOATEraStats returns a row for every season a GOAT candidate played, next to their career numbers
+func (m *MemoryDB) GetGOATEraStats(ctx context.Context) ([]databasestructs.GoatEraSeason, error) {
+	seasons := []databasestructs.GoatEraSeason{}
+	err := m.read(func(t *tables) error {
+		for _, career := range t.regularSeasonCareers() {
+			for _, s := range t.goatSeasons {
+				if s.PlayerID != career.PlayerID {
+					continue
+				}
+
+				league, ok := find(t.leagueAverages, func(l databasestructs.LeagueAverages) bool { return l.Season == s.Season })
+				if !ok {
+					continue
+				}
+
+				league.Season = ""
+				seasons = append(seasons, databasestructs.GoatEraSeason{Career: career, Games: s.Games, League: league})
+			}
+		}
+		return nil
+	})
+
+	return seasons, err
+}
+
+// GetGOATCareerStats returns the regular season career averages of every GOAT candidate
+func (m *MemoryDB) GetGOATCareerStats(ctx context.Context) ([]databasestructs.PlayerInfo, error) {
+	players := []databasestructs.PlayerInfo{}
+	err := m.read(func(t *tables) error {
+		for _, p := range t.sortedGoatPlayers() {
+			var rows float64
+			var position string
+			var sum databasestructs.GoatStats
+			for _, s := range t.goatStats {
+				if s.PlayerID != p.ID || s.IsPlayoffs {
+					continue
+				}
+
+				rows++
+				if s.Position > position {
+					position = s.Position
+				}
+				sum.Minutes, sum.Points, sum.Rebounds, sum.Assists, sum.Steals, sum.Blocks, sum.Turnovers = sum.Minutes+s.Minutes, sum.Points+s.Points, sum.Rebounds+s.Rebounds, sum.Assists+s.Assists, sum.Steals+s.Steals, sum.Blocks+s.Blocks, sum.Turnovers+s.Turnovers
+				sum.FGPercentage, sum.ThreeFGPercentage, sum.FTPercentage = sum.FGPercentage+s.FGPercentage, sum.ThreeFGPercentage+s.ThreeFGPercentage, sum.FTPercentage+s.FTPercentage
+				sum.PER, sum.TSPct, sum.OffBPM, sum.DefBPM, sum.BPM, sum.OffRtg, sum.DefRtg = sum.PER+s.PER, sum.TSPct+s.TSPct, sum.OffBPM+s.OffBPM, sum.DefBPM+s.DefBPM, sum.BPM+s.BPM, sum.OffRtg+s.OffRtg, sum.DefRtg+s.DefRtg
+			}
+			if rows == 0 {
+				continue
+			}
+
+			players = append(players, databasestructs.PlayerInfo{
+				ID:   p.ID,
+				Name: p.Name,
+				PlayerStats: databasestructs.PlayerStats{
+					PlayerID: p.ID, Position: position, Minutes: sum.Minutes / rows, Points: sum.Points / rows, Rebounds: sum.Rebounds / rows, Assists: sum.Assists / rows,
+					Steals: sum.Steals / rows, Blocks: sum.Blocks / rows, Turnovers: sum.Turnovers / rows,
+					FGPercentage: sum.FGPercentage / rows, ThreeFGPercentage: sum.ThreeFGPercentage / rows, FTPercentage: sum.FTPercentage / rows,
+				},
+				AdvancedStats: databasestructs.AdvancedStats{
+					PlayerID: p.ID, PER: sum.PER / rows, TSPct: sum.TSPct / rows, OffBPM: sum.OffBPM / rows, DefBPM: sum.DefBPM / rows, BPM: sum.BPM / rows, OffRtg: sum.OffRtg / rows, DefRtg: sum.DefRtg / rows,
+				},
+			})
+		}
+		return nil
+	})
+
+	return players, err
+}
+
+// regularSeasonCareers averages the regular season rows of every player with goat stats, by player id
+func (t *tables) regularSeasonCareers() []databasestructs.GoatStats {
+	careers := []databasestructs.GoatStats{}
+	for _, p := range t.sortedGoatPlayers() {
+		career := databasestructs.GoatStats{PlayerID: p.ID}
+		var rows float64
+		for _, s := range t.goatStats {
+			if s.PlayerID != p.ID || s.IsPlayoffs {
+				continue
+			}
+
+			rows++
+			career.Points += s.Points
+			career.Rebounds += s.Rebounds
+			career.Assists += s.Assists
+			career.Minutes = math.Max(career.Minutes, s.Minutes)
+			career.TSPct = math.Max(career.TSPct, s.TSPct)
+		}
+		if rows == 0 {
+			continue
+		}
+
+		career.Points /= rows
+		career.Rebounds /= rows
+		career.Assists /= rows
+		careers = append(careers, career)
+	}
+
+	return careers
+}
+
+// sortedGoatPlayers returns the GOAT players by id, the order GROUP BY puts them in
+func (t *tables) sortedGoatPlayers() []databasestructs.GoatPlayers {
+	players := cloneRows(t.goatPlayers)
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
+func (t *tables) goatPlayer(playerid string) (databasestructs.GoatPlayers, bool) {
+	return find(t.goatPlayers, func(p databasestructs.GoatPlayers) bool { return p.ID == playerid })
+}
+
+func (t *tables) goatPlayerName(playerid string) string {
+	p, _ := t.goatPlayer(playerid)
+	return p.Name
+}
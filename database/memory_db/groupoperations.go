@@ -0,0 +1,196 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+// InsertGroup returns ErrConflict when the invite code is taken
+func (m *MemoryDB) InsertGroup(group databasestructs.Group) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.groups, func(g databasestructs.Group) bool { return g.InviteCode == group.InviteCode }); ok {
+			return nil, uniqueViolation("poll_groups.invite_code")
+		}
+
+		group.ID = t.nextID("poll_groups")
+		group.Members = 0
+		t.groups = append(t.groups, group)
+		return result{lastInsertID: group.ID, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetGroupByID(id int64) (databasestructs.Group, error) {
+	return m.findGroup(func(g databasestructs.Group) bool { return g.ID == id })
+}
+
+func (m *MemoryDB) GetGroupByInviteCode(code string) (databasestructs.Group, error) {
+	return m.findGroup(func(g databasestructs.Group) bool { return g.InviteCode == code })
+}
+
+func (m *MemoryDB) GetUserGroups(ctx context.Context, userid int64) ([]databasestructs.Group, error) {
+	groups := []databasestructs.Group{}
+	err := m.read(func(t *tables) error {
+		for _, g := range t.groups {
+			if _, member := t.member(g.ID, userid); member {
+				groups = append(groups, t.withMembers(g))
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, err
+}
+
+// UpdateGroupInviteCode returns ErrConflict when the invite code is taken
+func (m *MemoryDB) UpdateGroupInviteCode(id int64, code string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.groups, func(g databasestructs.Group) bool { return g.InviteCode == code && g.ID != id }); ok {
+			return nil, uniqueViolation("poll_groups.invite_code")
+		}
+
+		updated := update(t.groups, func(g databasestructs.Group) bool { return g.ID == id }, func(g *databasestructs.Group) { g.InviteCode = code })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// DeleteGroup fails with ErrForeignKey while the polls of the group still have player votes
+func (m *MemoryDB) DeleteGroup(id int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		return result{rowsAffected: t.deleteGroup(id)}, nil
+	})
+}
+
+// InsertGroupMember keeps the membership the user already has
+func (m *MemoryDB) InsertGroupMember(member databasestructs.GroupMember) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(member.Role, "owner", "member") {
+			return nil, checkViolation("group_members.role", member.Role)
+		}
+		if _, ok := t.member(member.GroupID, member.UserID); ok {
+			return result{}, nil
+		}
+
+		member.Username = ""
+		t.members = append(t.members, member)
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) DeleteGroupMember(groupid, userid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		removed := filter(&t.members, func(gm databasestructs.GroupMember) bool { return gm.GroupID == groupid && gm.UserID == userid })
+		return result{rowsAffected: removed}, nil
+	})
+}
+
+// GetGroupMembers returns the members in the order they joined
+func (m *MemoryDB) GetGroupMembers(ctx context.Context, groupid int64) ([]databasestructs.GroupMember, error) {
+	members := []databasestructs.GroupMember{}
+	err := m.read(func(t *tables) error {
+		for _, gm := range t.members {
+			if gm.GroupID == groupid {
+				u, _ := t.user(gm.UserID)
+				gm.Username = u.Username
+				members = append(members, gm)
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].JoinedAt != members[j].JoinedAt {
+			return members[i].JoinedAt < members[j].JoinedAt
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members, err
+}
+
+// GetGroupMemberRole returns ErrNotFound when the user isn't a member of the group
+func (m *MemoryDB) GetGroupMemberRole(groupid, userid int64) (string, error) {
+	var role string
+	err := m.read(func(t *tables) error {
+		gm, ok := t.member(groupid, userid)
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		role = gm.Role
+		return nil
+	})
+
+	return role, err
+}
+
+func (m *MemoryDB) GetGroupPolls(ctx context.Context, groupid int64) ([]databasestructs.Poll, error) {
+	var polls []databasestructs.Poll
+	err := m.read(func(t *tables) error {
+		polls = where(t.polls, func(p databasestructs.Poll) bool { return p.GroupID == groupid })
+		return nil
+	})
+
+	return polls, err
+}
+
+// GetGroupLeaderboard ranks the members by their prediction contest points, then by how many of the group's polls they voted in
+func (m *MemoryDB) GetGroupLeaderboard(ctx context.Context, groupid int64) ([]databasestructs.GroupStanding, error) {
+	standings := []databasestructs.GroupStanding{}
+	err := m.read(func(t *tables) error {
+		polls := keys(where(t.polls, func(p databasestructs.Poll) bool { return p.GroupID == groupid }), func(p databasestructs.Poll) int64 { return p.ID })
+
+		for _, gm := range t.members {
+			u, ok := find(t.users, func(u user) bool { return u.ID == gm.UserID })
+			if gm.GroupID != groupid || !ok {
+				continue
+			}
+
+			standing := databasestructs.GroupStanding{UserID: u.ID, Username: u.Username}
+			standing.Score, standing.Predictions = scored(where(t.predictions, func(p databasestructs.Prediction) bool { return p.UserID == u.ID }))
+			voted := keys(where(t.votes, func(v playerVote) bool { return v.userID == u.ID && polls[v.pollID] }), func(v playerVote) int64 { return v.pollID })
+			standing.PollsVoted = int64(len(voted))
+			standings = append(standings, standing)
+		}
+		return nil
+	})
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.PollsVoted != b.PollsVoted {
+			return a.PollsVoted > b.PollsVoted
+		}
+		return a.Username < b.Username
+	})
+	return standings, err
+}
+
+func (m *MemoryDB) findGroup(match func(databasestructs.Group) bool) (databasestructs.Group, error) {
+	var found databasestructs.Group
+	err := m.read(func(t *tables) error {
+		g, ok := find(t.groups, match)
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		found = t.withMembers(g)
+		return nil
+	})
+
+	return found, err
+}
+
+// withMembers fills in how many members the group has
+func (t *tables) withMembers(group databasestructs.Group) databasestructs.Group {
+	group.Members = int64(len(where(t.members, func(gm databasestructs.GroupMember) bool { return gm.GroupID == group.ID })))
+	return group
+}
+
+func (t *tables) member(groupid, userid int64) (databasestructs.GroupMember, bool) {
+	return find(t.members, func(gm databasestructs.GroupMember) bool { return gm.GroupID == groupid && gm.UserID == userid })
+}
@@ -0,0 +1,84 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/databasestructs"
+	"strconv"
+)
+
+// InsertGuestVote replaces the earlier vote of the device in the poll
+func (m *MemoryDB) InsertGuestVote(vote databasestructs.GuestVote) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.guestVotes, func(v databasestructs.GuestVote) bool { return v.PollID == vote.PollID && v.DeviceID == vote.DeviceID }, func(v *databasestructs.GuestVote) { *v = vote })
+		if updated > 0 {
+			return result{rowsAffected: updated}, nil
+		}
+
+		t.guestVotes = append(t.guestVotes, vote)
+		return result{lastInsertID: t.nextID("guest_votes"), rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) CountGuestVotesByIP(pollid int64, ip string) (int64, error) {
+	return m.countGuestVotes(func(v databasestructs.GuestVote) bool { return v.PollID == pollid && v.IP == ip })
+}
+
+// GetGuestDeviceVote returns how many votes the device cast in the poll, at most one
+func (m *MemoryDB) GetGuestDeviceVote(pollid int64, deviceid string) (int64, error) {
+	return m.countGuestVotes(func(v databasestructs.GuestVote) bool { return v.PollID == pollid && v.DeviceID == deviceid })
+}
+
+// GetGuestPollVotes leaves out the votes for players that aren't in the database anymore
+func (m *MemoryDB) GetGuestPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error) {
+	counts := map[string]int64{}
+	err := m.read(func(t *tables) error {
+		for _, v := range t.guestVotes {
+			if v.PollID != pollid {
+				continue
+			}
+
+			name := t.playerName(v.PlayerID)
+			if name == "" {
+				name = t.goatPlayerName(v.PlayerID)
+			}
+			if name == "" && v.CandidateID != 0 {
+				name = t.candidateName(pollid, strconv.FormatInt(v.CandidateID, 10))
+			}
+			if name != "" {
+				counts[name]++
+			}
+		}
+		return nil
+	})
+
+	tallies := []databasestructs.VoteTally{}
+	for name, votes := range counts {
+		tallies = append(tallies, databasestructs.VoteTally{Name: name, Votes: votes})
+	}
+
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Votes != tallies[j].Votes {
+			return tallies[i].Votes > tallies[j].Votes
+		}
+		return tallies[i].Name < tallies[j].Name
+	})
+	return tallies, err
+}
+
+func (m *MemoryDB) ResetGuestVotes(pollid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		return result{rowsAffected: filter(&t.guestVotes, func(v databasestructs.GuestVote) bool { return v.PollID == pollid })}, nil
+	})
+}
+
+func (m *MemoryDB) countGuestVotes(match func(databasestructs.GuestVote) bool) (int64, error) {
+	var count int64
+	err := m.read(func(t *tables) error {
+		count = int64(len(where(t.guestVotes, match)))
+		return nil
+	})
+
+	return count, err
+}
@@ -0,0 +1,19 @@
+package memory_db
+
+import (
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertLeagueAverages replaces the averages already stored for the season
+func (m *MemoryDB) InsertLeagueAverages(averages databasestructs.LeagueAverages) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.leagueAverages, func(l databasestructs.LeagueAverages) bool { return l.Season == averages.Season }, func(l *databasestructs.LeagueAverages) { *l = averages })
+		if updated > 0 {
+			return result{rowsAffected: updated}, nil
+		}
+
+		t.leagueAverages = append(t.leagueAverages, averages)
+		return result{lastInsertID: t.nextID("league_averages"), rowsAffected: 1}, nil
+	})
+}
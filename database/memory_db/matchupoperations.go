@@ -0,0 +1,137 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/databasestructs"
+)
+
+const initialMatchupRating = 1500
+
+func (m *MemoryDB) InsertMatchupVote(vote databasestructs.MatchupVote) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		low, high := orderedPair(vote)
+		if _, taken := find(t.matchupVotes, func(v matchupVote) bool {
+			l, h := orderedPair(v.MatchupVote)
+			return v.PollID == vote.PollID && v.UserID == vote.UserID && l == low && h == high
+		}); taken {
+			return nil, uniqueViolation("index 'matchup_votes_pair'")
+		}
+
+		id := t.nextID("matchup_votes")
+		t.matchupVotes = append(t.matchupVotes, matchupVote{MatchupVote: vote, id: id})
+		return result{lastInsertID: id, rowsAffected: 1}, nil
+	})
+}
+
+// orderedPair returns the players of the vote in the order of the unique key, whoever won
+func orderedPair(vote databasestructs.MatchupVote) (string, string) {
+	if vote.WinnerID < vote.LoserID {
+		return vote.WinnerID, vote.LoserID
+	}
+	return vote.LoserID, vote.WinnerID
+}
+
+// UpdateMatchupRating applies the change on top of the stored rating, players start out at the initial rating
+func (m *MemoryDB) UpdateMatchupRating(pollid int64, playerid string, change float64, won bool) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		var wins int64
+		if won {
+			wins = 1
+		}
+
+		updated := update(t.ratings, func(r matchupRating) bool { return r.pollID == pollid && r.PlayerID == playerid }, func(r *matchupRating) {
+			r.Rating += change
+			r.Matches++
+			r.Wins += wins
+		})
+		if updated == 0 {
+			t.ratings = append(t.ratings, matchupRating{MatchupRating: databasestructs.MatchupRating{PlayerID: playerid, Rating: initialMatchupRating + change, Matches: 1, Wins: wins}, pollID: pollid})
+		}
+
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+// GetMatchupRatings returns the ratings of the poll by player id, the order of the primary key
+func (m *MemoryDB) GetMatchupRatings(ctx context.Context, pollid int64) ([]databasestructs.MatchupRating, error) {
+	ratings := []databasestructs.MatchupRating{}
+	err := m.read(func(t *tables) error {
+		for _, r := range t.ratings {
+			if r.pollID == pollid {
+				ratings = append(ratings, r.MatchupRating)
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i].PlayerID < ratings[j].PlayerID })
+	return ratings, err
+}
+
+// GetMatchupVotes returns every vote of the poll in the order they were cast
+func (m *MemoryDB) GetMatchupVotes(ctx context.Context, pollid int64) ([]databasestructs.MatchupVote, error) {
+	return m.matchupVotes(func(v matchupVote) bool { return v.PollID == pollid }, func(v matchupVote) databasestructs.MatchupVote {
+		return databasestructs.MatchupVote{ID: v.id, PollID: pollid, WinnerID: v.WinnerID, LoserID: v.LoserID, RatingChange: v.RatingChange}
+	})
+}
+
+// GetPollsWithoutMatchupRatings returns the polls with matchup votes but no ratings by id
+func (m *MemoryDB) GetPollsWithoutMatchupRatings(ctx context.Context) ([]int64, error) {
+	polls := []int64{}
+	err := m.read(func(t *tables) error {
+		rated := make(map[int64]bool)
+		for _, r := range t.ratings {
+			rated[r.pollID] = true
+		}
+
+		for _, v := range t.matchupVotes {
+			if !rated[v.PollID] {
+				polls = append(polls, v.PollID)
+				rated[v.PollID] = true
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(polls, func(i, j int) bool { return polls[i] < polls[j] })
+	return polls, err
+}
+
+func (m *MemoryDB) SetMatchupVoteChange(id int64, change float64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.matchupVotes, func(v matchupVote) bool { return v.id == id }, func(v *matchupVote) { v.RatingChange = change })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) GetUserMatchupVotes(ctx context.Context, pollid, userid int64) ([]databasestructs.MatchupVote, error) {
+	return m.matchupVotes(func(v matchupVote) bool { return v.PollID == pollid && v.UserID == userid }, func(v matchupVote) databasestructs.MatchupVote {
+		return databasestructs.MatchupVote{PollID: pollid, UserID: userid, WinnerID: v.WinnerID, LoserID: v.LoserID}
+	})
+}
+
+func (m *MemoryDB) ResetMatchups(pollid int64) error {
+	_, err := m.exec(func(t *tables) (sql.Result, error) {
+		filter(&t.matchupVotes, func(v matchupVote) bool { return v.PollID == pollid })
+		filter(&t.ratings, func(r matchupRating) bool { return r.pollID == pollid })
+		return result{}, nil
+	})
+
+	return err
+}
+
+func (m *MemoryDB) matchupVotes(match func(matchupVote) bool, row func(matchupVote) databasestructs.MatchupVote) ([]databasestructs.MatchupVote, error) {
+	votes := []databasestructs.MatchupVote{}
+	err := m.read(func(t *tables) error {
+		for _, v := range t.matchupVotes {
+			if match(v) {
+				votes = append(votes, row(v))
+			}
+		}
+		return nil
+	})
+
+	return votes, err
+}
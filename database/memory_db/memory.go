@@ -0,0 +1,278 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrForeignKey is returned when a write would leave a row pointing at one that doesn't exist
+	ErrForeignKey = errors.New("FOREIGN KEY constraint failed")
+)
+
+// MemoryDB keeps every table in memory, so the handlers can be exercised without a database server.
+// It enforces the unique keys, foreign keys and checks of the sqlite schema and returns rows in the same order.
+// Every method is applied as a whole or not at all.
+type MemoryDB struct {
+	mu     sync.Mutex
+	tables tables
+}
+
+// tables holds the rows of every table in insertion order, empty strings and zero ids stand in for NULL
+type tables struct {
+	seq map[string]int64
+
+	teams          []databasestructs.TeamInfo
+	players        []databasestructs.PlayerInfo
+	stats          []databasestructs.PlayerStats
+	advancedStats  []databasestructs.AdvancedStats
+	goatPlayers    []databasestructs.GoatPlayers
+	goatStats      []databasestructs.GoatStats
+	goatSeasons    []databasestructs.GoatPlayerSeason
+	leagueAverages []databasestructs.LeagueAverages
+	seasons        []string
+	syncTimes      []syncTime
+	users          []user
+	roles          []role
+	groups         []databasestructs.Group
+	members        []databasestructs.GroupMember
+	polls          []databasestructs.Poll
+	candidates     []candidate
+	votes          []playerVote
+	guestVotes     []databasestructs.GuestVote
+	events         []voteEvent
+	flags          []databasestructs.VoteFlag
+	matchupVotes   []matchupVote
+	ratings        []matchupRating
+	brackets       []databasestructs.Bracket
+	matchups       []databasestructs.BracketMatchup
+	bracketVotes   []databasestructs.BracketVote
+	questions      []databasestructs.PollQuestion
+	ballots        []ballot
+	answers        []databasestructs.SurveyAnswer
+
+	contests         []databasestructs.PredictionContest
+	contestQuestions []databasestructs.PredictionQuestion
+	predictions      []databasestructs.Prediction
+	standings        []databasestructs.TeamStanding
+	awards           []databasestructs.SeasonAward
+}
+
+type syncTime struct {
+	name     string
+	lastSync int64
+}
+
+type user struct {
+	databasestructs.User
+	// hasToken is false for the admin user, whose refresh token is NULL until they log in
+	hasToken bool
+}
+
+type role struct {
+	id     int64
+	userID int64
+	role   string
+}
+
+type candidate struct {
+	databasestructs.PollCandidate
+	nameKey string
+}
+
+type playerVote struct {
+	id           int64
+	playerID     string
+	goatPlayerID string
+	candidateID  int64
+	pollID       int64
+	userID       int64
+	createdAt    int64
+	ip           string
+	switches     int64
+}
+
+type voteEvent struct {
+	databasestructs.VoteEvent
+	userID int64
+}
+
+type matchupVote struct {
+	databasestructs.MatchupVote
+	id int64
+}
+
+type matchupRating struct {
+	databasestructs.MatchupRating
+	pollID int64
+}
+
+type ballot struct {
+	id     int64
+	pollID int64
+	userID int64
+}
+
+// result is what an insert or update reports back, like the sql drivers do
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+func NewDB() *MemoryDB {
+	return &MemoryDB{tables: tables{seq: map[string]int64{}}}
+}
+
+func (m *MemoryDB) CloseConnection() {}
+
+// GetDB returns nil, there is no connection behind the in-memory database
+func (m *MemoryDB) GetDB() *sql.DB {
+	return nil
+}
+
+// WithTx runs fn on a copy of the tables that replaces them when fn returns nil,
+// the other callers wait until then like they would for the write lock of sqlite.
+// m.mu is held for all of fn, so a call on m instead of tx from fn never returns.
+func (m *MemoryDB) WithTx(ctx context.Context, fn func(tx database.Database) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &MemoryDB{tables: m.tables.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	// a transaction whose context is done is rolled back by database/sql as well
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.tables = tx.tables
+	return nil
+}
+
+// read runs the query while no write is in progress
+func (m *MemoryDB) read(query func(t *tables) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return query(&m.tables)
+}
+
+// exec runs the write like a single statement, when it fails or breaks a foreign key none of it is kept
+func (m *MemoryDB) exec(write func(t *tables) (sql.Result, error)) (sql.Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := m.tables.clone()
+	res, err := write(&m.tables)
+	if err == nil {
+		err = m.tables.checkForeignKeys()
+	}
+
+	if err != nil {
+		m.tables = before
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// nextID hands out the autoincrement ids, which like in sqlite are never reused
+func (t *tables) nextID(table string) int64 {
+	t.seq[table]++
+	return t.seq[table]
+}
+
+// useID moves the autoincrement past an id that was inserted explicitly
+func (t *tables) useID(table string, id int64) {
+	if id > t.seq[table] {
+		t.seq[table] = id
+	}
+}
+
+func now() int64 {
+	return time.Now().Unix()
+}
+
+func uniqueViolation(columns string) error {
+	return fmt.Errorf("%w: UNIQUE constraint failed: %s", database.ErrConflict, columns)
+}
+
+func checkViolation(column, value string) error {
+	return fmt.Errorf("CHECK constraint failed: %s = %q", column, value)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter keeps the rows that match in place and returns how many were removed
+func filter[T any](rows *[]T, remove func(T) bool) int64 {
+	kept := (*rows)[:0]
+	for _, row := range *rows {
+		if !remove(row) {
+			kept = append(kept, row)
+		}
+	}
+
+	removed := int64(len(*rows) - len(kept))
+	*rows = kept
+	return removed
+}
+
+// update changes the rows that match in place and returns how many there were
+func update[T any](rows []T, match func(T) bool, change func(*T)) int64 {
+	var updated int64
+	for i := range rows {
+		if match(rows[i]) {
+			change(&rows[i])
+			updated++
+		}
+	}
+
+	return updated
+}
+
+// find returns the first row that matches
+func find[T any](rows []T, match func(T) bool) (T, bool) {
+	for _, row := range rows {
+		if match(row) {
+			return row, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// where returns every row that matches, never nil so the JSON is a list
+func where[T any](rows []T, match func(T) bool) []T {
+	matched := []T{}
+	for _, row := range rows {
+		if match(row) {
+			matched = append(matched, row)
+		}
+	}
+
+	return matched
+}
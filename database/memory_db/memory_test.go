@@ -0,0 +1,89 @@
+package memory_db_test
+
+import (
+	"context"
+	"errors"
+	"sportsvoting/database"
+	"sportsvoting/database/memory_db"
+	"sportsvoting/databasestructs"
+	"testing"
+)
+
+var _ database.Database = (*memory_db.MemoryDB)(nil)
+
+func TestUniqueViolationIsConflict(t *testing.T) {
+	db := memory_db.NewDB()
+	if _, err := db.InsertNewUser(databasestructs.User{Username: "fan"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertPolls(databasestructs.Poll{Name: "custom", SelectedStats: "Custom", UserID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	alpha := databasestructs.PollCandidate{PollID: 1, Name: "Alpha", Status: "approved"}
+	if _, err := db.InsertPollCandidate(alpha, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertPollCandidate(alpha, "alpha"); !errors.Is(err, database.ErrConflict) {
+		t.Fatalf("duplicate candidate: err = %v, want ErrConflict", err)
+	}
+
+	// the failed insert doesn't use up an id
+	res, err := db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Bravo", Status: "approved"}, "bravo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := res.LastInsertId(); id != 2 {
+		t.Errorf("id = %d, want 2", id)
+	}
+}
+
+func TestForeignKeyViolationKeepsNothing(t *testing.T) {
+	db := memory_db.NewDB()
+	if _, err := db.InsertNewUser(databasestructs.User{Username: "fan"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.InsertGroupMember(databasestructs.GroupMember{GroupID: 9, UserID: 1, Role: "member"}); !errors.Is(err, memory_db.ErrForeignKey) {
+		t.Fatalf("member of a missing group: err = %v, want ErrForeignKey", err)
+	}
+
+	members, err := db.GetGroupMembers(context.Background(), 9)
+	if err != nil || len(members) != 0 {
+		t.Errorf("members = %v, %v, want none", members, err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	db := memory_db.NewDB()
+
+	if _, err := db.GetPollByID(1); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("GetPollByID: err = %v, want ErrNotFound", err)
+	}
+	if _, err := db.GetGroupByInviteCode("missing"); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("GetGroupByInviteCode: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeletePollCascades(t *testing.T) {
+	db := memory_db.NewDB()
+	ctx := context.Background()
+	if _, err := db.InsertNewUser(databasestructs.User{Username: "fan"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertPolls(databasestructs.Poll{Name: "custom", SelectedStats: "Custom", UserID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertPollCandidate(databasestructs.PollCandidate{PollID: 1, Name: "Alpha", Status: "approved"}, "alpha"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.DeletePollByID(1); err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := db.GetPollCandidates(ctx, 1, "")
+	if err != nil || len(candidates) != 0 {
+		t.Errorf("candidates = %v, %v, want none", candidates, err)
+	}
+}
@@ -0,0 +1,83 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertPlayer keeps the player already stored under the id
+func (m *MemoryDB) InsertPlayer(info databasestructs.PlayerInfo) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := t.player(info.ID); ok {
+			return result{}, nil
+		}
+
+		t.players = append(t.players, databasestructs.PlayerInfo{ID: info.ID, Name: info.Name, College: info.College, TeamAbbr: info.TeamAbbr, Height: info.Height, Weight: info.Weight, Age: info.Age})
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) UpdatePlayerAge(playerid string, age int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.players, func(p databasestructs.PlayerInfo) bool { return p.ID == playerid }, func(p *databasestructs.PlayerInfo) { p.Age = age })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) SelectPlayerGamesPlayed(season string) ([]databasestructs.PlayerStats, error) {
+	games := []databasestructs.PlayerStats{}
+	err := m.read(func(t *tables) error {
+		for _, s := range t.stats {
+			if s.Season == season {
+				games = append(games, databasestructs.PlayerStats{PlayerID: s.PlayerID, Games: s.Games, Season: season})
+			}
+		}
+		return nil
+	})
+
+	return games, err
+}
+
+// CheckPlayerExists reports whether the player is in the database
+func (m *MemoryDB) CheckPlayerExists(playerid string) (bool, error) {
+	var exists bool
+	err := m.read(func(t *tables) error {
+		_, exists = t.player(playerid)
+		return nil
+	})
+
+	return exists, err
+}
+
+// GetPlayersForSearch returns the players of the season and the GOAT candidates, active players show up in both
+func (m *MemoryDB) GetPlayersForSearch(ctx context.Context, season string) ([]databasestructs.PlayerSearchResult, error) {
+	results := []databasestructs.PlayerSearchResult{}
+	err := m.read(func(t *tables) error {
+		for _, p := range t.players {
+			seasons := where(t.stats, func(s databasestructs.PlayerStats) bool { return s.PlayerID == p.ID && s.Season == season })
+			if len(seasons) == 0 {
+				results = append(results, databasestructs.PlayerSearchResult{ID: p.ID, Name: p.Name})
+			}
+			for _, s := range seasons {
+				results = append(results, databasestructs.PlayerSearchResult{ID: p.ID, Name: p.Name, Minutes: s.Minutes})
+			}
+		}
+
+		for _, g := range t.goatPlayers {
+			results = append(results, databasestructs.PlayerSearchResult{ID: g.ID, Name: g.Name, IsGoat: true})
+		}
+		return nil
+	})
+
+	return results, err
+}
+
+func (t *tables) player(playerid string) (databasestructs.PlayerInfo, bool) {
+	return find(t.players, func(p databasestructs.PlayerInfo) bool { return p.ID == playerid })
+}
+
+func (t *tables) playerName(playerid string) string {
+	p, _ := t.player(playerid)
+	return p.Name
+}
@@ -0,0 +1,452 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strconv"
+	"strings"
+)
+
+// GetPolls returns the public polls and the polls of the groups the user is in
+func (m *MemoryDB) GetPolls(ctx context.Context, userid int64) ([]databasestructs.Poll, error) {
+	var polls []databasestructs.Poll
+	err := m.read(func(t *tables) error {
+		polls = where(t.polls, func(p databasestructs.Poll) bool { return t.canView(p, userid) })
+		return nil
+	})
+
+	return polls, err
+}
+
+func (m *MemoryDB) GetPollByID(id int64) (databasestructs.Poll, error) {
+	var poll databasestructs.Poll
+	err := m.read(func(t *tables) error {
+		var err error
+		poll, err = t.poll(id)
+		return err
+	})
+
+	return poll, err
+}
+
+// GetPollByUserID returns the polls the user created that the viewer can see
+func (m *MemoryDB) GetPollByUserID(userid, viewerid int64) ([]databasestructs.Poll, error) {
+	var polls []databasestructs.Poll
+	err := m.read(func(t *tables) error {
+		polls = where(t.polls, func(p databasestructs.Poll) bool { return p.UserID == userid && t.canView(p, viewerid) })
+		return nil
+	})
+
+	return polls, err
+}
+
+func (m *MemoryDB) InsertPolls(poll databasestructs.Poll) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		poll.ID = t.nextID("polls")
+		t.polls = append(t.polls, poll)
+		return result{lastInsertID: poll.ID, rowsAffected: 1}, nil
+	})
+}
+
+// InsertPollsWithId keeps the poll that already has the id
+func (m *MemoryDB) InsertPollsWithId(poll databasestructs.Poll) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, err := t.poll(poll.ID); err == nil {
+			return result{}, nil
+		}
+
+		t.useID("polls", poll.ID)
+		t.polls = append(t.polls, poll)
+		return result{lastInsertID: poll.ID, rowsAffected: 1}, nil
+	})
+}
+
+// DeletePollByID fails with ErrForeignKey while the poll still has player votes
+func (m *MemoryDB) DeletePollByID(pollid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		return result{rowsAffected: t.deletePoll(pollid)}, nil
+	})
+}
+
+// ResetPollVotes removes every vote of the poll, the vote history keeps a reset event for each of them
+func (m *MemoryDB) ResetPollVotes(pollid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		for _, v := range where(t.votes, func(v playerVote) bool { return v.pollID == pollid }) {
+			t.insertVoteEvent(pollid, v.userID, "reset", "", v.choice())
+		}
+
+		return result{rowsAffected: filter(&t.votes, func(v playerVote) bool { return v.pollID == pollid })}, nil
+	})
+}
+
+func (m *MemoryDB) UpdatePollByID(poll databasestructs.Poll) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.polls, func(p databasestructs.Poll) bool { return p.ID == poll.ID }, func(p *databasestructs.Poll) {
+			p.Name = poll.Name
+			p.Description = poll.Description
+			p.SelectedStats = poll.SelectedStats
+			p.Season = poll.Season
+			p.ScoreFormula = poll.ScoreFormula
+			p.AllowWriteIns = poll.AllowWriteIns
+			p.PanelWeight = poll.PanelWeight
+			p.AllowGuests = poll.AllowGuests
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) UpdatePollImage(image databasestructs.Image) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.polls, func(p databasestructs.Poll) bool { return p.ID == image.ID }, func(p *databasestructs.Poll) { p.Image = image.ImageURL })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// InsertSeasonEntered adds the season every time, like the sql backends the table has no unique key
+func (m *MemoryDB) InsertSeasonEntered(season string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		t.seasons = append(t.seasons, season)
+		return result{lastInsertID: t.nextID("seasons_entered"), rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) SelectSeasonsAvailable() ([]string, error) {
+	var seasons []string
+	err := m.read(func(t *tables) error {
+		seasons = cloneRows(t.seasons)
+		return nil
+	})
+
+	if seasons == nil {
+		seasons = []string{}
+	}
+	return seasons, err
+}
+
+func (m *MemoryDB) SelectSeasonsForNonGOATStats() ([]string, error) {
+	var seasons []string
+	err := m.read(func(t *tables) error {
+		seasons = where(t.seasons, func(season string) bool { return !oneOf(season, "All", "Playoffs", "Career") })
+		return nil
+	})
+
+	return seasons, err
+}
+
+func (m *MemoryDB) GetPlayerStatsForPoll(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	return m.seasonStats(season, func(s databasestructs.PlayerStats) bool { return s.Minutes > 20 }, pollPlayer, func(p databasestructs.PlayerInfo) float64 { return p.PER })
+}
+
+// GetPlayerPollVotes returns ErrNotFound when the poll doesn't exist
+func (m *MemoryDB) GetPlayerPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error) {
+	var tallies []databasestructs.VoteTally
+	err := m.read(func(t *tables) error {
+		poll, err := t.poll(pollid)
+		if err != nil {
+			return err
+		}
+
+		goat := strings.Contains(poll.SelectedStats, "GOAT")
+		players := map[string]int64{}
+		candidates := map[string]int64{}
+		for _, v := range t.countedVotes(pollid) {
+			if goat && v.goatPlayerID != "" {
+				players[t.goatPlayerName(v.goatPlayerID)]++
+			} else if !goat && v.playerID != "" {
+				players[t.playerName(v.playerID)]++
+			}
+
+			if v.candidateID != 0 {
+				candidates[t.candidateName(pollid, strconv.FormatInt(v.candidateID, 10))]++
+			}
+		}
+
+		tallies = append(voteTallies(players, poll.Name), voteTallies(candidates, poll.Name)...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tallies, func(i, j int) bool { return tallies[i].Votes > tallies[j].Votes })
+	return tallies, nil
+}
+
+// GetPanelPollVotes tallies the votes of the poll per candidate, split between panelists and fans
+func (m *MemoryDB) GetPanelPollVotes(ctx context.Context, pollid int64) ([]databasestructs.PanelVoteTally, error) {
+	type group struct {
+		name       string
+		isPanelist bool
+	}
+
+	tallies := []databasestructs.PanelVoteTally{}
+	err := m.read(func(t *tables) error {
+		counts := map[group]int64{}
+		for _, v := range t.countedVotes(pollid) {
+			for _, roles := range t.userRoles(v.userID) {
+				counts[group{t.voteName(v), isPanelist(roles)}]++
+			}
+		}
+
+		for g, votes := range counts {
+			tallies = append(tallies, databasestructs.PanelVoteTally{Name: g.name, IsPanelist: g.isPanelist, Votes: votes})
+		}
+		return nil
+	})
+
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Votes != tallies[j].Votes {
+			return tallies[i].Votes > tallies[j].Votes
+		}
+		if tallies[i].Name != tallies[j].Name {
+			return tallies[i].Name < tallies[j].Name
+		}
+		return !tallies[i].IsPanelist && tallies[j].IsPanelist
+	})
+	return tallies, err
+}
+
+// GetPollVoteTimeline replays the vote history of the poll, per time bucket every candidate gains the votes cast or changed to them
+// and loses the ones changed away, retracted or reset, votes from before the history was kept aren't in it
+func (m *MemoryDB) GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) ([]databasestructs.TimelineBucket, error) {
+	if bucketSeconds <= 0 {
+		return nil, fmt.Errorf("bucket size has to be positive, got %d", bucketSeconds)
+	}
+
+	type group struct {
+		start int64
+		name  string
+	}
+
+	buckets := []databasestructs.TimelineBucket{}
+	err := m.read(func(t *tables) error {
+		counts := map[group]int64{}
+		for _, e := range t.events {
+			if e.PollID != pollid || t.excluded(pollid, e.userID) {
+				continue
+			}
+
+			start := e.CreatedAt - e.CreatedAt%bucketSeconds
+			if name := t.choiceName(pollid, e.Choice); name != "" {
+				counts[group{start, name}]++
+			}
+			if name := t.choiceName(pollid, e.Previous); name != "" {
+				counts[group{start, name}]--
+			}
+		}
+
+		for g, votes := range counts {
+			buckets = append(buckets, databasestructs.TimelineBucket{Start: g.start, Name: g.name, Votes: votes})
+		}
+		return nil
+	})
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Start != buckets[j].Start {
+			return buckets[i].Start < buckets[j].Start
+		}
+		return buckets[i].Name < buckets[j].Name
+	})
+	return buckets, err
+}
+
+// voter segments results can be broken down by, account age is taken at the time of the vote
+var segments = map[string]func(u user, roles string, votedAt int64) string{
+	"team": func(u user, roles string, votedAt int64) string {
+		return u.FavoriteTeam
+	},
+	"region": func(u user, roles string, votedAt int64) string {
+		return u.Region
+	},
+	"account_age": func(u user, roles string, votedAt int64) string {
+		age := votedAt - u.CreatedAt
+		switch {
+		case u.CreatedAt == 0 || votedAt == 0:
+			return ""
+		case age < 30*86400:
+			return "under 30 days"
+		case age < 365*86400:
+			return "30 days to 1 year"
+		default:
+			return "over 1 year"
+		}
+	},
+	"panel": func(u user, roles string, votedAt int64) string {
+		if isPanelist(roles) {
+			return "panel"
+		}
+		return "fans"
+	},
+}
+
+// GetPollVotesBySegment tallies the votes of the poll per candidate within each value of the voter segment, unknown values are empty
+func (m *MemoryDB) GetPollVotesBySegment(ctx context.Context, pollid int64, segment string) ([]databasestructs.SegmentVoteTally, error) {
+	segmentOf, ok := segments[segment]
+	if !ok {
+		return nil, fmt.Errorf("unknown segment %q", segment)
+	}
+
+	type group struct {
+		segment string
+		name    string
+	}
+
+	tallies := []databasestructs.SegmentVoteTally{}
+	err := m.read(func(t *tables) error {
+		counts := map[group]int64{}
+		for _, v := range t.countedVotes(pollid) {
+			voter, _ := t.user(v.userID)
+			name := t.voteName(v)
+			if name == "" {
+				continue
+			}
+
+			for _, roles := range t.userRoles(v.userID) {
+				counts[group{segmentOf(voter, roles, v.createdAt), name}]++
+			}
+		}
+
+		for g, votes := range counts {
+			tallies = append(tallies, databasestructs.SegmentVoteTally{Segment: g.segment, Name: g.name, Votes: votes})
+		}
+		return nil
+	})
+
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Segment != tallies[j].Segment {
+			return tallies[i].Segment < tallies[j].Segment
+		}
+		if tallies[i].Votes != tallies[j].Votes {
+			return tallies[i].Votes > tallies[j].Votes
+		}
+		return tallies[i].Name < tallies[j].Name
+	})
+	return tallies, err
+}
+
+// InsertPlayerVotes replaces the user's earlier vote in the poll, counting how often they switched for the fraud checks
+func (m *MemoryDB) InsertPlayerVotes(pollid, userid int64, playerid, ip string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		poll, err := t.poll(pollid)
+		if err != nil {
+			return nil, sql.ErrNoRows
+		}
+
+		goat := strings.Contains(poll.SelectedStats, "GOAT")
+		vote := playerVote{pollID: pollid, userID: userid, createdAt: now(), ip: ip}
+		if goat {
+			vote.goatPlayerID = playerid
+		} else {
+			vote.playerID = playerid
+		}
+
+		previous, voted := find(t.votes, func(v playerVote) bool { return v.pollID == pollid && v.userID == userid })
+		if !voted {
+			res := t.insertVote(vote)
+			t.insertVoteEvent(pollid, userid, "cast", playerid, "")
+			return res, nil
+		}
+
+		current := previous.playerID
+		if goat {
+			current = previous.goatPlayerID
+		}
+		if current == playerid {
+			return nil, nil
+		}
+
+		filter(&t.votes, func(v playerVote) bool { return v.id == previous.id })
+		vote.switches = previous.switches + 1
+		res := t.insertVote(vote)
+		t.insertVoteEvent(pollid, userid, "changed", playerid, previous.choice())
+		return res, nil
+	})
+}
+
+// GetTeamPollVotes always returns an empty list, none of the backends write team votes anymore
+func (m *MemoryDB) GetTeamPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error) {
+	return []databasestructs.VoteTally{}, nil
+}
+
+func (t *tables) poll(id int64) (databasestructs.Poll, error) {
+	poll, ok := find(t.polls, func(p databasestructs.Poll) bool { return p.ID == id })
+	if !ok {
+		return poll, database.ErrNotFound
+	}
+
+	return poll, nil
+}
+
+// canView tells if the poll is public or the user is a member of its group
+func (t *tables) canView(poll databasestructs.Poll, userid int64) bool {
+	_, member := t.member(poll.GroupID, userid)
+	return poll.GroupID == 0 || member
+}
+
+func (t *tables) insertVote(vote playerVote) sql.Result {
+	vote.id = t.nextID("player_votes")
+	t.votes = append(t.votes, vote)
+	return result{lastInsertID: vote.id, rowsAffected: 1}
+}
+
+// countedVotes leaves out the ballots admins excluded from the official result after a fraud review
+func (t *tables) countedVotes(pollid int64) []playerVote {
+	return where(t.votes, func(v playerVote) bool { return v.pollID == pollid && !t.excluded(pollid, v.userID) })
+}
+
+// excluded tells if the votes of the user in the poll were excluded after a fraud review
+func (t *tables) excluded(pollid, userid int64) bool {
+	_, excluded := find(t.flags, func(f databasestructs.VoteFlag) bool {
+		return f.PollID == pollid && f.UserID == userid && f.Status == "excluded"
+	})
+	return excluded
+}
+
+// choice is whatever the vote is for, as text like the choices in the vote history
+func (v playerVote) choice() string {
+	switch {
+	case v.playerID != "":
+		return v.playerID
+	case v.goatPlayerID != "":
+		return v.goatPlayerID
+	case v.candidateID != 0:
+		return strconv.FormatInt(v.candidateID, 10)
+	}
+
+	return ""
+}
+
+// voteName is the name of the player, GOAT player or candidate the vote is for
+func (t *tables) voteName(v playerVote) string {
+	if name := t.playerName(v.playerID); name != "" {
+		return name
+	}
+	if name := t.goatPlayerName(v.goatPlayerID); name != "" {
+		return name
+	}
+	if v.candidateID != 0 {
+		return t.candidateName(v.pollID, strconv.FormatInt(v.candidateID, 10))
+	}
+
+	return ""
+}
+
+// voteTallies orders the names like GROUP BY does, so votes that tie come out the same every time
+func voteTallies(counts map[string]int64, pollName string) []databasestructs.VoteTally {
+	tallies := []databasestructs.VoteTally{}
+	for name, votes := range counts {
+		tallies = append(tallies, databasestructs.VoteTally{Name: name, Votes: votes, PollName: pollName})
+	}
+
+	sort.Slice(tallies, func(i, j int) bool { return tallies[i].Name < tallies[j].Name })
+	return tallies
+}
+
+func isPanelist(roles string) bool {
+	return strings.Contains(","+roles+",", ",panelist,")
+}
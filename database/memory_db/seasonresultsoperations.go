@@ -0,0 +1,59 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertTeamStanding replaces the record of the team in the season
+func (m *MemoryDB) InsertTeamStanding(standing databasestructs.TeamStanding) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.standings, func(s databasestructs.TeamStanding) bool {
+			return s.Season == standing.Season && s.TeamAbbr == standing.TeamAbbr
+		}, func(s *databasestructs.TeamStanding) { *s = standing })
+		if updated == 0 {
+			t.standings = append(t.standings, standing)
+		}
+
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetTeamStandings(ctx context.Context, season string) ([]databasestructs.TeamStanding, error) {
+	var standings []databasestructs.TeamStanding
+	err := m.read(func(t *tables) error {
+		standings = where(t.standings, func(s databasestructs.TeamStanding) bool { return s.Season == season })
+		return nil
+	})
+
+	return standings, err
+}
+
+// InsertSeasonAward replaces the winner of the award in the season
+func (m *MemoryDB) InsertSeasonAward(award databasestructs.SeasonAward) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(award.Award, "mvp", "roy", "dpoy", "smoy", "mip") {
+			return nil, checkViolation("award", award.Award)
+		}
+
+		updated := update(t.awards, func(a databasestructs.SeasonAward) bool {
+			return a.Season == award.Season && a.Award == award.Award
+		}, func(a *databasestructs.SeasonAward) { *a = award })
+		if updated == 0 {
+			t.awards = append(t.awards, award)
+		}
+
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetSeasonAwards(ctx context.Context, season string) ([]databasestructs.SeasonAward, error) {
+	var awards []databasestructs.SeasonAward
+	err := m.read(func(t *tables) error {
+		awards = where(t.awards, func(a databasestructs.SeasonAward) bool { return a.Season == season })
+		return nil
+	})
+
+	return awards, err
+}
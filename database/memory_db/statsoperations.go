@@ -0,0 +1,297 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sportsvoting/databasestructs"
+	"strings"
+)
+
+func (m *MemoryDB) UpdateStats(stats databasestructs.PlayerStats) (sql.Result, error) {
+	return m.updateStats(stats, func(s *databasestructs.PlayerStats) {
+		s.TeamAbbr = stats.TeamAbbr
+	})
+}
+
+// UpdateTradedPlayerStats leaves the team of the season as it is
+func (m *MemoryDB) UpdateTradedPlayerStats(stats databasestructs.PlayerStats) (sql.Result, error) {
+	return m.updateStats(stats, func(s *databasestructs.PlayerStats) {})
+}
+
+func (m *MemoryDB) InsertStats(stats databasestructs.PlayerStats) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		stats.IsRookie = false
+		t.stats = append(t.stats, stats)
+		return result{lastInsertID: t.nextID("stats"), rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) UpdateAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error) {
+	return m.updateAdvancedStats(stats.PlayerID, stats.Season, func(s *databasestructs.AdvancedStats) {
+		setAdvancedStats(s, stats)
+		s.OffRtg = stats.OffRtg
+		s.DefRtg = stats.DefRtg
+		s.TeamAbbr = stats.TeamAbbr
+	})
+}
+
+func (m *MemoryDB) UpdateTradedPlayerAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error) {
+	return m.updateAdvancedStats(stats.PlayerID, stats.Season, func(s *databasestructs.AdvancedStats) {
+		setAdvancedStats(s, stats)
+	})
+}
+
+func (m *MemoryDB) InsertAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		t.advancedStats = append(t.advancedStats, stats)
+		return result{lastInsertID: t.nextID("advancedstats"), rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) UpdateOffAndDefRtg(offrtg, defrtg float64, playerid, season string) (sql.Result, error) {
+	return m.updateAdvancedStats(playerid, season, func(s *databasestructs.AdvancedStats) {
+		s.OffRtg = offrtg
+		s.DefRtg = defrtg
+	})
+}
+
+func (m *MemoryDB) GetSixManStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	return m.seasonStats(season, func(s databasestructs.PlayerStats) bool { return s.Games-s.GamesStarted > s.GamesStarted }, pollPlayer, func(p databasestructs.PlayerInfo) float64 { return p.PER })
+}
+
+func (m *MemoryDB) GetDPOYStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	return m.seasonStats(season, func(s databasestructs.PlayerStats) bool { return s.Minutes > 20 }, func(p databasestructs.PlayerInfo, s databasestructs.PlayerStats, adv databasestructs.AdvancedStats) databasestructs.PlayerInfo {
+		return databasestructs.PlayerInfo{
+			ID:            p.ID,
+			Name:          p.Name,
+			PlayerStats:   databasestructs.PlayerStats{Games: s.Games, Minutes: s.Minutes, Rebounds: s.Rebounds, Steals: s.Steals, Blocks: s.Blocks, Position: s.Position},
+			AdvancedStats: databasestructs.AdvancedStats{DefWS: adv.DefWS, DefBPM: adv.DefBPM, DefRtg: adv.DefRtg},
+		}
+	}, func(p databasestructs.PlayerInfo) float64 { return p.DefWS })
+}
+
+func (m *MemoryDB) GetROYStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	return m.seasonStats(season, func(s databasestructs.PlayerStats) bool { return s.IsRookie && s.Minutes > 10 }, func(p databasestructs.PlayerInfo, s databasestructs.PlayerStats, adv databasestructs.AdvancedStats) databasestructs.PlayerInfo {
+		return databasestructs.PlayerInfo{
+			ID:            p.ID,
+			Name:          p.Name,
+			PlayerStats:   pollStats(s),
+			AdvancedStats: databasestructs.AdvancedStats{PER: adv.PER, WS: adv.WS, BPM: adv.BPM, OffRtg: adv.OffRtg, DefRtg: adv.DefRtg},
+		}
+	}, func(p databasestructs.PlayerInfo) float64 { return p.PER })
+}
+
+func (m *MemoryDB) SetRookieStatus(id string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.stats, func(s databasestructs.PlayerStats) bool { return s.PlayerID == id }, func(s *databasestructs.PlayerStats) { s.IsRookie = true })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// GetSeasonStats returns the regular and advanced stats of every player who played in the season
+func (m *MemoryDB) GetSeasonStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	var players []databasestructs.PlayerInfo
+	err := m.read(func(t *tables) error {
+		players = t.seasonRows(season, func(s databasestructs.PlayerStats) bool { return true }, fullSeason)
+		return nil
+	})
+
+	return players, err
+}
+
+// leaderStats are the stats the leaderboards can rank by, the same names the backends accept
+var leaderStats = map[string]func(p databasestructs.PlayerInfo) float64{
+	"g":          func(p databasestructs.PlayerInfo) float64 { return float64(p.Games) },
+	"gs":         func(p databasestructs.PlayerInfo) float64 { return float64(p.GamesStarted) },
+	"mpg":        func(p databasestructs.PlayerInfo) float64 { return p.Minutes },
+	"ppg":        func(p databasestructs.PlayerInfo) float64 { return p.Points },
+	"rpg":        func(p databasestructs.PlayerInfo) float64 { return p.Rebounds },
+	"apg":        func(p databasestructs.PlayerInfo) float64 { return p.Assists },
+	"spg":        func(p databasestructs.PlayerInfo) float64 { return p.Steals },
+	"bpg":        func(p databasestructs.PlayerInfo) float64 { return p.Blocks },
+	"topg":       func(p databasestructs.PlayerInfo) float64 { return p.Turnovers },
+	"fgpct":      func(p databasestructs.PlayerInfo) float64 { return p.FGPercentage },
+	"threefgpct": func(p databasestructs.PlayerInfo) float64 { return p.ThreeFGPercentage },
+	"ftpct":      func(p databasestructs.PlayerInfo) float64 { return p.FTPercentage },
+	"per":        func(p databasestructs.PlayerInfo) float64 { return p.PER },
+	"ts":         func(p databasestructs.PlayerInfo) float64 { return p.TSPct },
+	"usg":        func(p databasestructs.PlayerInfo) float64 { return p.USGPCt },
+	"ows":        func(p databasestructs.PlayerInfo) float64 { return p.OffWS },
+	"dws":        func(p databasestructs.PlayerInfo) float64 { return p.DefWS },
+	"ws":         func(p databasestructs.PlayerInfo) float64 { return p.WS },
+	"obpm":       func(p databasestructs.PlayerInfo) float64 { return p.OffBPM },
+	"dbpm":       func(p databasestructs.PlayerInfo) float64 { return p.DefBPM },
+	"bpm":        func(p databasestructs.PlayerInfo) float64 { return p.BPM },
+	"vorp":       func(p databasestructs.PlayerInfo) float64 { return p.VORP },
+	"offrtg":     func(p databasestructs.PlayerInfo) float64 { return p.OffRtg },
+	"defrtg":     func(p databasestructs.PlayerInfo) float64 { return p.DefRtg },
+}
+
+// GetStatLeaders returns the page of leaders the filter asks for and how many players qualify in total,
+// players tied on the stat share a rank like with RANK() in the backends
+func (m *MemoryDB) GetStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) ([]databasestructs.StatLeader, int64, error) {
+	stat, ok := leaderStats[filter.Stat]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown stat %q", filter.Stat)
+	}
+
+	var players []databasestructs.PlayerInfo
+	err := m.read(func(t *tables) error {
+		players = t.seasonRows(filter.Season, func(s databasestructs.PlayerStats) bool { return leaderQualifies(s, filter) }, fullSeason)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	before := func(a, b float64) bool { return a > b }
+	if filter.Ascending {
+		before = func(a, b float64) bool { return a < b }
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		if a, b := stat(players[i]), stat(players[j]); a != b {
+			return before(a, b)
+		}
+		return players[i].Name < players[j].Name
+	})
+
+	leaders := []databasestructs.StatLeader{}
+	var rank int64
+	for i, p := range players {
+		if i == 0 || stat(p) != stat(players[i-1]) {
+			rank = int64(i) + 1
+		}
+		if int64(i) < filter.Offset || int64(i) >= filter.Offset+filter.Limit {
+			continue
+		}
+
+		leaders = append(leaders, databasestructs.StatLeader{Rank: rank, ID: p.ID, Name: p.Name, TeamAbbr: p.TeamAbbr, Position: p.Position, Games: p.Games, Minutes: p.Minutes, Value: stat(p)})
+	}
+
+	return leaders, int64(len(players)), nil
+}
+
+// leaderQualifies is the filter of the leaderboards, hybrid positions like "PF-C" count for both
+func leaderQualifies(s databasestructs.PlayerStats, filter databasestructs.LeadersFilter) bool {
+	if s.Games < filter.MinGames || s.Minutes < filter.MinMinutes {
+		return false
+	}
+	if filter.Position != "" && !strings.Contains("-"+s.Position+"-", "-"+filter.Position+"-") {
+		return false
+	}
+
+	return filter.Team == "" || s.TeamAbbr == filter.Team
+}
+
+// GetAllSeasonStats returns every player season that has both regular and advanced stats
+func (m *MemoryDB) GetAllSeasonStats(ctx context.Context) ([]databasestructs.PlayerInfo, error) {
+	players := []databasestructs.PlayerInfo{}
+	err := m.read(func(t *tables) error {
+		for _, p := range t.players {
+			for _, s := range t.stats {
+				for _, adv := range t.advancedStats {
+					if s.PlayerID == p.ID && adv.PlayerID == p.ID && s.Season == adv.Season {
+						players = append(players, fullSeason(p, s, adv))
+					}
+				}
+			}
+		}
+		return nil
+	})
+
+	return players, err
+}
+
+func (m *MemoryDB) updateStats(stats databasestructs.PlayerStats, change func(*databasestructs.PlayerStats)) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.stats, func(s databasestructs.PlayerStats) bool {
+			return s.PlayerID == stats.PlayerID && s.Season == stats.Season
+		}, func(s *databasestructs.PlayerStats) {
+			s.Games, s.GamesStarted, s.Minutes = stats.Games, stats.GamesStarted, stats.Minutes
+			s.Points, s.Rebounds, s.Assists, s.Steals, s.Blocks, s.Turnovers = stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers
+			s.FGPercentage, s.FTPercentage, s.ThreeFGPercentage = stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage
+			s.Position = stats.Position
+			change(s)
+		})
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) updateAdvancedStats(playerid, season string, change func(*databasestructs.AdvancedStats)) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.advancedStats, func(s databasestructs.AdvancedStats) bool { return s.PlayerID == playerid && s.Season == season }, change)
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+// setAdvancedStats copies the numbers every advanced stats update sets
+func setAdvancedStats(s *databasestructs.AdvancedStats, stats databasestructs.AdvancedStats) {
+	s.PER, s.TSPct, s.USGPCt = stats.PER, stats.TSPct, stats.USGPCt
+	s.OffWS, s.DefWS, s.WS = stats.OffWS, stats.DefWS, stats.WS
+	s.OffBPM, s.DefBPM, s.BPM, s.VORP = stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP
+}
+
+// seasonStats returns the award poll rows of the season, best first
+func (m *MemoryDB) seasonStats(season string, match func(databasestructs.PlayerStats) bool, row seasonRow, rank func(databasestructs.PlayerInfo) float64) ([]databasestructs.PlayerInfo, error) {
+	var players []databasestructs.PlayerInfo
+	err := m.read(func(t *tables) error {
+		players = t.seasonRows(season, match, row)
+		return nil
+	})
+
+	sort.SliceStable(players, func(i, j int) bool { return rank(players[i]) > rank(players[j]) })
+	return players, err
+}
+
+// seasonRow builds the result row of a player from the regular and advanced stats of one season
+type seasonRow func(p databasestructs.PlayerInfo, s databasestructs.PlayerStats, adv databasestructs.AdvancedStats) databasestructs.PlayerInfo
+
+// seasonRows joins the players with their regular and advanced stats of the season, traded players come up once per stint
+func (t *tables) seasonRows(season string, match func(databasestructs.PlayerStats) bool, row seasonRow) []databasestructs.PlayerInfo {
+	players := []databasestructs.PlayerInfo{}
+	for _, p := range t.players {
+		for _, s := range t.stats {
+			if s.PlayerID != p.ID || s.Season != season || !match(s) {
+				continue
+			}
+
+			for _, adv := range t.advancedStats {
+				if adv.PlayerID == p.ID && adv.Season == season {
+					players = append(players, row(p, s, adv))
+				}
+			}
+		}
+	}
+
+	return players
+}
+
+// pollPlayer fills in the season stats the award polls are voted on
+func pollPlayer(p databasestructs.PlayerInfo, s databasestructs.PlayerStats, adv databasestructs.AdvancedStats) databasestructs.PlayerInfo {
+	return databasestructs.PlayerInfo{
+		ID:          p.ID,
+		Name:        p.Name,
+		PlayerStats: pollStats(s),
+		AdvancedStats: databasestructs.AdvancedStats{
+			PER: adv.PER, OffWS: adv.OffWS, DefWS: adv.DefWS, WS: adv.WS, OffBPM: adv.OffBPM, DefBPM: adv.DefBPM, BPM: adv.BPM, VORP: adv.VORP, OffRtg: adv.OffRtg, DefRtg: adv.DefRtg,
+		},
+	}
+}
+
+// pollStats is the per game line of the season the award polls show
+func pollStats(s databasestructs.PlayerStats) databasestructs.PlayerStats {
+	return databasestructs.PlayerStats{
+		Games: s.Games, Minutes: s.Minutes, Points: s.Points, Rebounds: s.Rebounds, Assists: s.Assists, Steals: s.Steals, Blocks: s.Blocks,
+		FGPercentage: s.FGPercentage, ThreeFGPercentage: s.ThreeFGPercentage, FTPercentage: s.FTPercentage, Turnovers: s.Turnovers, Position: s.Position,
+	}
+}
+
+// fullSeason fills in every regular and advanced stat of the season
+func fullSeason(p databasestructs.PlayerInfo, s databasestructs.PlayerStats, adv databasestructs.AdvancedStats) databasestructs.PlayerInfo {
+	info := databasestructs.PlayerInfo{ID: p.ID, Name: p.Name, TeamAbbr: s.TeamAbbr, PlayerStats: s, AdvancedStats: adv}
+	info.PlayerStats.IsRookie = false
+	info.AdvancedStats.TeamAbbr = s.TeamAbbr
+	return info
+}
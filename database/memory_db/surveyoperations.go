@@ -0,0 +1,115 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/databasestructs"
+)
+
+// InsertPollQuestion returns ErrConflict when the poll already has a question in that position
+func (m *MemoryDB) InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(question.Type, "player", "team", "ranked", "yesno", "numeric") {
+			return nil, checkViolation("poll_questions.type", question.Type)
+		}
+		if _, ok := find(t.questions, func(q databasestructs.PollQuestion) bool {
+			return q.PollID == question.PollID && q.Position == question.Position
+		}); ok {
+			return nil, uniqueViolation("poll_questions.pollid, poll_questions.position")
+		}
+
+		question.ID = t.nextID("poll_questions")
+		t.questions = append(t.questions, question)
+		return result{lastInsertID: question.ID, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetPollQuestions(ctx context.Context, pollid int64) ([]databasestructs.PollQuestion, error) {
+	var questions []databasestructs.PollQuestion
+	err := m.read(func(t *tables) error {
+		questions = where(t.questions, func(q databasestructs.PollQuestion) bool { return q.PollID == pollid })
+		return nil
+	})
+
+	sort.Slice(questions, func(i, j int) bool { return questions[i].Position < questions[j].Position })
+	return questions, err
+}
+
+// DeletePollQuestions removes the questions of the poll together with the answers to them
+func (m *MemoryDB) DeletePollQuestions(pollid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		var removed int64
+		for _, q := range where(t.questions, func(q databasestructs.PollQuestion) bool { return q.PollID == pollid }) {
+			removed += t.deleteQuestion(q.ID)
+		}
+		return result{rowsAffected: removed}, nil
+	})
+}
+
+func (m *MemoryDB) CountSurveyBallots(pollid int64) (int64, error) {
+	var count int64
+	err := m.read(func(t *tables) error {
+		count = int64(len(where(t.ballots, func(b ballot) bool { return b.pollID == pollid })))
+		return nil
+	})
+
+	return count, err
+}
+
+// InsertSurveyBallot returns ErrConflict when the user already filled in the survey
+func (m *MemoryDB) InsertSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.ballots, func(b ballot) bool { return b.pollID == pollid && b.userID == userid }); ok {
+			return nil, uniqueViolation("survey_ballots.pollid, survey_ballots.userid")
+		}
+
+		id := t.nextID("survey_ballots")
+		t.ballots = append(t.ballots, ballot{id: id, pollID: pollid, userID: userid})
+		return result{lastInsertID: id, rowsAffected: 1}, nil
+	})
+}
+
+// DeleteSurveyBallot removes the user's previous ballot together with its answers
+func (m *MemoryDB) DeleteSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		var removed int64
+		for _, b := range where(t.ballots, func(b ballot) bool { return b.pollID == pollid && b.userID == userid }) {
+			removed += t.deleteBallot(b.id)
+		}
+		return result{rowsAffected: removed}, nil
+	})
+}
+
+func (m *MemoryDB) InsertSurveyAnswer(answer databasestructs.SurveyAnswer) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.answers, func(a databasestructs.SurveyAnswer) bool {
+			return a.BallotID == answer.BallotID && a.QuestionID == answer.QuestionID && a.Position == answer.Position
+		}); ok {
+			return nil, uniqueViolation("survey_answers.ballotid, survey_answers.questionid, survey_answers.position")
+		}
+
+		t.answers = append(t.answers, answer)
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) GetSurveyAnswers(ctx context.Context, pollid int64) ([]databasestructs.SurveyAnswer, error) {
+	var answers []databasestructs.SurveyAnswer
+	err := m.read(func(t *tables) error {
+		ballots := keys(where(t.ballots, func(b ballot) bool { return b.pollID == pollid }), func(b ballot) int64 { return b.id })
+		answers = where(t.answers, func(a databasestructs.SurveyAnswer) bool { return ballots[a.BallotID] })
+		return nil
+	})
+
+	sort.Slice(answers, func(i, j int) bool {
+		if answers[i].QuestionID != answers[j].QuestionID {
+			return answers[i].QuestionID < answers[j].QuestionID
+		}
+		if answers[i].BallotID != answers[j].BallotID {
+			return answers[i].BallotID < answers[j].BallotID
+		}
+		return answers[i].Position < answers[j].Position
+	})
+	return answers, err
+}
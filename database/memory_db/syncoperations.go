@@ -0,0 +1,45 @@
+package memory_db
+
+import (
+	"database/sql"
+	"sportsvoting/database"
+	"time"
+)
+
+// GetLastSyncTime returns ErrNotFound when the sync never ran
+func (m *MemoryDB) GetLastSyncTime(name string) (time.Time, error) {
+	var lastSync time.Time
+	err := m.read(func(t *tables) error {
+		s, ok := find(t.syncTimes, func(s syncTime) bool { return s.name == name })
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		lastSync = time.Unix(s.lastSync, 0)
+		return nil
+	})
+
+	return lastSync, err
+}
+
+func (m *MemoryDB) InsertLastSyncTime(newTime time.Time, name string) error {
+	_, err := m.exec(func(t *tables) (sql.Result, error) {
+		if !oneOf(name, "Regular", "GOAT") {
+			return nil, checkViolation("sync_time.name", name)
+		}
+
+		t.syncTimes = append(t.syncTimes, syncTime{name: name, lastSync: newTime.Unix()})
+		return result{rowsAffected: 1}, nil
+	})
+
+	return err
+}
+
+func (m *MemoryDB) UpdateLastSyncTime(newTime time.Time, name string) error {
+	_, err := m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.syncTimes, func(s syncTime) bool { return s.name == name }, func(s *syncTime) { s.lastSync = newTime.Unix() })
+		return result{rowsAffected: updated}, nil
+	})
+
+	return err
+}
@@ -0,0 +1,40 @@
+package memory_db
+
+import (
+	"database/sql"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+// InsertTeam keeps the team already stored under the abbreviation
+func (m *MemoryDB) InsertTeam(info databasestructs.TeamInfo) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		if _, ok := find(t.teams, func(team databasestructs.TeamInfo) bool { return team.TeamAbbr == info.TeamAbbr }); ok {
+			return result{}, nil
+		}
+
+		t.teams = append(t.teams, info)
+		return result{rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) UpdateTeamForPlayer(teamabbr, playerid string) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.players, func(p databasestructs.PlayerInfo) bool { return p.ID == playerid }, func(p *databasestructs.PlayerInfo) { p.TeamAbbr = teamabbr })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) SelectTeamByAbbrevation(teamabbr string) (string, error) {
+	err := m.read(func(t *tables) error {
+		if _, ok := find(t.teams, func(team databasestructs.TeamInfo) bool { return team.TeamAbbr == teamabbr }); !ok {
+			return database.ErrNotFound
+		}
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+	return teamabbr, nil
+}
@@ -0,0 +1,239 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CreateAdminUser hashes DBPASS with the lowest cost, logging in only compares the hash so the cost only slows things down
+func (m *MemoryDB) CreateAdminUser() error {
+	dbPass := strings.TrimSpace(os.Getenv("DBPASS"))
+	bytes, err := bcrypt.GenerateFromPassword([]byte(dbPass), bcrypt.MinCost)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.exec(func(t *tables) (sql.Result, error) {
+		if _, err := t.user(1); err == nil {
+			return result{}, nil
+		}
+
+		t.useID("users", 1)
+		t.users = append(t.users, user{User: databasestructs.User{ID: 1, Username: "admin", Password: string(bytes)}})
+		t.roles = append(t.roles, role{id: t.nextID("user_roles"), userID: 1, role: "user,admin"})
+		return result{lastInsertID: 1, rowsAffected: 1}, nil
+	})
+
+	return err
+}
+
+// DeleteUser fails with ErrForeignKey while the user still has polls or votes
+func (m *MemoryDB) DeleteUser(id int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		return result{rowsAffected: t.deleteUser(id)}, nil
+	})
+}
+
+func (m *MemoryDB) GetAllUsers() ([]databasestructs.User, error) {
+	var users []databasestructs.User
+	err := m.read(func(t *tables) error {
+		users = make([]databasestructs.User, 0, len(t.users))
+		for _, u := range t.users {
+			users = append(users, databasestructs.User{ID: u.ID, Username: u.Username, Email: u.Email, Password: u.Password, RefreshToken: u.RefreshToken, ProfilePic: u.ProfilePic})
+		}
+		return nil
+	})
+
+	return users, err
+}
+
+// GetUserByUsername returns the first user with the name, usernames aren't unique in the schema
+func (m *MemoryDB) GetUserByUsername(username string) (databasestructs.User, error) {
+	var found databasestructs.User
+	err := m.read(func(t *tables) error {
+		u, ok := find(t.users, func(u user) bool { return u.Username == username })
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		found = databasestructs.User{ID: u.ID, Username: u.Username, Email: u.Email, Password: u.Password, RefreshToken: u.RefreshToken, ProfilePic: u.ProfilePic}
+		return nil
+	})
+
+	return found, err
+}
+
+func (m *MemoryDB) GetUserByRefreshToken(refresh_token string) (databasestructs.User, error) {
+	var found databasestructs.User
+	err := m.read(func(t *tables) error {
+		u, ok := find(t.users, func(u user) bool { return u.hasToken && u.RefreshToken == refresh_token })
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		found = databasestructs.User{ID: u.ID, Username: u.Username, Email: u.Email, RefreshToken: refresh_token}
+		return nil
+	})
+
+	return found, err
+}
+
+// GetUserByID returns the public profile of the user, without the password or tokens
+func (m *MemoryDB) GetUserByID(id int64) (databasestructs.User, error) {
+	var found databasestructs.User
+	err := m.read(func(t *tables) error {
+		u, err := t.user(id)
+		found = databasestructs.User{ID: id, Username: u.Username, Email: u.Email, ProfilePic: u.ProfilePic, FavoriteTeam: u.FavoriteTeam, Region: u.Region}
+		return err
+	})
+
+	if err != nil {
+		return databasestructs.User{}, err
+	}
+	return found, nil
+}
+
+// GetUserRolesByID returns the comma separated roles of the user
+func (m *MemoryDB) GetUserRolesByID(id int64) (string, error) {
+	var roles string
+	err := m.read(func(t *tables) error {
+		r, ok := find(t.roles, func(r role) bool { return r.userID == id })
+		if !ok {
+			return database.ErrNotFound
+		}
+
+		roles = r.role
+		return nil
+	})
+
+	return roles, err
+}
+
+func (m *MemoryDB) InsertUserRoles(r databasestructs.Role) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		id := t.nextID("user_roles")
+		t.roles = append(t.roles, role{id: id, userID: r.UserID, role: r.Role})
+		return result{lastInsertID: id, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) UpdateUserRoles(roles string, user_id int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		updated := update(t.roles, func(r role) bool { return r.userID == user_id }, func(r *role) { r.role = roles })
+		return result{rowsAffected: updated}, nil
+	})
+}
+
+func (m *MemoryDB) GetCurrentProfilePic(id int64) (string, error) {
+	var profilePic string
+	err := m.read(func(t *tables) error {
+		u, err := t.user(id)
+		profilePic = u.ProfilePic
+		return err
+	})
+
+	return profilePic, err
+}
+
+func (m *MemoryDB) InsertNewUser(newUser databasestructs.User) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		id := t.nextID("users")
+		t.users = append(t.users, user{
+			User:     databasestructs.User{ID: id, Username: newUser.Username, Email: newUser.Email, Password: newUser.Password, RefreshToken: newUser.RefreshToken, CreatedAt: newUser.CreatedAt, SignupIP: newUser.SignupIP},
+			hasToken: true,
+		})
+		return result{lastInsertID: id, rowsAffected: 1}, nil
+	})
+}
+
+func (m *MemoryDB) UpdateUserRefreshToken(username, refresh_token string) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.Username == username }, func(u *user) {
+		u.RefreshToken = refresh_token
+		u.hasToken = true
+	})
+}
+
+func (m *MemoryDB) UpdateUserIsAdmin(username string, is_admin bool) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.Username == username }, func(u *user) { u.IsAdmin = is_admin })
+}
+
+func (m *MemoryDB) UpdateUserPassword(username, password string) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.Username == username }, func(u *user) { u.Password = password })
+}
+
+func (m *MemoryDB) UpdateUserEmail(username, email string) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.Username == username }, func(u *user) { u.Email = email })
+}
+
+// UpdateUserProfile sets the self-reported profile fields, empty ones are cleared
+func (m *MemoryDB) UpdateUserProfile(id int64, favoriteTeam, region string) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.ID == id }, func(u *user) {
+		u.FavoriteTeam = favoriteTeam
+		u.Region = region
+	})
+}
+
+func (m *MemoryDB) UpdateUserUsername(oldusername, username string) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.Username == oldusername }, func(u *user) { u.Username = username })
+}
+
+func (m *MemoryDB) UpdateUserProfilePic(username, profile_pic string) (sql.Result, error) {
+	return m.updateUsers(func(u user) bool { return u.Username == username }, func(u *user) { u.ProfilePic = profile_pic })
+}
+
+// GetVotesOfUser returns the votes of the user in the polls the viewer can see
+func (m *MemoryDB) GetVotesOfUser(ctx context.Context, userid, viewerid int64) ([]databasestructs.UserVote, error) {
+	votes := []databasestructs.UserVote{}
+	err := m.read(func(t *tables) error {
+		for _, v := range t.votes {
+			if v.userID != userid {
+				continue
+			}
+
+			poll, _ := t.poll(v.pollID)
+			if !t.canView(poll, viewerid) {
+				continue
+			}
+			votes = append(votes, databasestructs.UserVote{PollID: poll.ID, PlayerID: v.choice(), PlayerName: t.voteName(v), PollName: poll.Name, PollImage: poll.Image})
+		}
+		return nil
+	})
+
+	return votes, err
+}
+
+func (m *MemoryDB) updateUsers(match func(user) bool, change func(*user)) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		return result{rowsAffected: update(t.users, match, change)}, nil
+	})
+}
+
+func (t *tables) user(id int64) (user, error) {
+	u, ok := find(t.users, func(u user) bool { return u.ID == id })
+	if !ok {
+		return u, database.ErrNotFound
+	}
+
+	return u, nil
+}
+
+// userRoles returns every roles row of the user, or one without roles, like the LEFT JOIN the tallies use
+func (t *tables) userRoles(userid int64) []string {
+	var roles []string
+	for _, r := range t.roles {
+		if r.userID == userid {
+			roles = append(roles, r.role)
+		}
+	}
+
+	if roles == nil {
+		return []string{""}
+	}
+	return roles
+}
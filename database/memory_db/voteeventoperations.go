@@ -0,0 +1,94 @@
+package memory_db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+// vote events are only ever appended, so the history survives votes being changed, retracted or reset
+func (t *tables) insertVoteEvent(pollid, userid int64, event, choice, previous string) {
+	t.events = append(t.events, voteEvent{
+		VoteEvent: databasestructs.VoteEvent{ID: t.nextID("vote_events"), PollID: pollid, Event: event, Choice: choice, Previous: previous, CreatedAt: now()},
+		userID:    userid,
+	})
+}
+
+// RetractPlayerVote returns ErrNotFound when the user has no vote in the poll
+func (m *MemoryDB) RetractPlayerVote(pollid, userid int64) (sql.Result, error) {
+	return m.exec(func(t *tables) (sql.Result, error) {
+		previous, ok := find(t.votes, func(v playerVote) bool { return v.pollID == pollid && v.userID == userid })
+		if !ok {
+			return nil, database.ErrNotFound
+		}
+
+		removed := filter(&t.votes, func(v playerVote) bool { return v.pollID == pollid && v.userID == userid })
+		t.insertVoteEvent(pollid, userid, "retracted", "", previous.choice())
+		return result{rowsAffected: removed}, nil
+	})
+}
+
+// GetUserVoteEvents returns the vote history of the user in the polls the viewer can see, newest first, optionally only in one poll
+func (m *MemoryDB) GetUserVoteEvents(ctx context.Context, userid, viewerid, pollid int64) ([]databasestructs.VoteEvent, error) {
+	events := []databasestructs.VoteEvent{}
+	err := m.read(func(t *tables) error {
+		for _, e := range t.events {
+			if e.userID != userid || (pollid != 0 && e.PollID != pollid) {
+				continue
+			}
+
+			poll, _ := t.poll(e.PollID)
+			if !t.canView(poll, viewerid) {
+				continue
+			}
+			event := e.VoteEvent
+			event.PollName = poll.Name
+			event.ChoiceName = t.choiceName(e.PollID, e.Choice)
+			event.PreviousName = t.choiceName(e.PollID, e.Previous)
+			events = append(events, event)
+		}
+		return nil
+	})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].CreatedAt != events[j].CreatedAt {
+			return events[i].CreatedAt > events[j].CreatedAt
+		}
+		return events[i].ID > events[j].ID
+	})
+	return events, err
+}
+
+// GetPollVoteEvents only fills in the event, the names of the choices and when it happened
+func (m *MemoryDB) GetPollVoteEvents(ctx context.Context, pollid, since int64) ([]databasestructs.VoteEvent, error) {
+	events := []databasestructs.VoteEvent{}
+	err := m.read(func(t *tables) error {
+		for _, e := range t.events {
+			if e.PollID == pollid && e.CreatedAt >= since {
+				events = append(events, databasestructs.VoteEvent{PollID: pollid, Event: e.Event, ChoiceName: t.choiceName(pollid, e.Choice), PreviousName: t.choiceName(pollid, e.Previous), CreatedAt: e.CreatedAt})
+			}
+		}
+		return nil
+	})
+
+	// the events are appended in id order already
+	sort.SliceStable(events, func(i, j int) bool { return events[i].CreatedAt < events[j].CreatedAt })
+	return events, err
+}
+
+// choiceName looks the choice of a vote event up as a player, a GOAT player and a candidate of the poll, in that order
+func (t *tables) choiceName(pollid int64, choice string) string {
+	if choice == "" {
+		return ""
+	}
+	if name := t.playerName(choice); name != "" {
+		return name
+	}
+	if name := t.goatPlayerName(choice); name != "" {
+		return name
+	}
+
+	return t.candidateName(pollid, choice)
+}
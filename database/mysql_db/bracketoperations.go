@@ -0,0 +1,61 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (m *MySqlDB) InsertBracket(bracket databasestructs.Bracket) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO brackets(pollid, size, round_hours, current_round, round_ends_at) VALUES (?, ?, ?, ?, ?)", bracket.PollID, bracket.Size, bracket.RoundHours, bracket.CurrentRound, bracket.RoundEndsAt)
+}
+
+func (m *MySqlDB) GetBracket(pollid int64) *sql.Row {
+	return m.db.QueryRow("SELECT pollid, size, round_hours, current_round, round_ends_at, COALESCE(championid, '') FROM brackets WHERE pollid=?", pollid)
+}
+
+// GetDueBrackets returns the unfinished brackets whose current round closed before now
+func (m *MySqlDB) GetDueBrackets(ctx context.Context, now int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT pollid, size, round_hours, current_round, round_ends_at FROM brackets WHERE championid IS NULL AND round_ends_at <= ?", now)
+}
+
+// AdvanceBracketRound only moves the bracket forward from the previous round, so a round can't be opened twice
+func (m *MySqlDB) AdvanceBracketRound(pollid, round, roundEndsAt int64) (sql.Result, error) {
+	return m.db.Exec("UPDATE brackets SET current_round=?, round_ends_at=? WHERE pollid=? AND current_round=?", round, roundEndsAt, pollid, round-1)
+}
+
+func (m *MySqlDB) SetBracketChampion(pollid int64, championid string) (sql.Result, error) {
+	return m.db.Exec("UPDATE brackets SET championid=? WHERE pollid=?", championid, pollid)
+}
+
+func (m *MySqlDB) InsertBracketMatchup(matchup databasestructs.BracketMatchup) (sql.Result, error) {
+	return m.db.Exec("INSERT IGNORE INTO bracket_matchups(pollid, round, position, seed1, player1id, seed2, player2id) VALUES (?, ?, ?, ?, ?, ?, ?)", matchup.PollID, matchup.Round, matchup.Position, matchup.Seed1, matchup.Player1ID, matchup.Seed2, matchup.Player2ID)
+}
+
+func (m *MySqlDB) GetBracketMatchup(id int64) *sql.Row {
+	return m.db.QueryRow("SELECT id, pollid, round, position, seed1, player1id, seed2, player2id, COALESCE(winnerid, '') FROM bracket_matchups WHERE id=?", id)
+}
+
+// GetBracketMatchups returns every matchup of the bracket with the player names and the vote tally of both sides
+func (m *MySqlDB) GetBracketMatchups(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT bm.id, bm.round, bm.position, bm.seed1, bm.player1id, COALESCE(g1.name, p1.name, ''), bm.seed2, bm.player2id, COALESCE(g2.name, p2.name, ''), COALESCE(bm.winnerid, ''),
+		COALESCE(SUM(bv.playerid = bm.player1id), 0), COALESCE(SUM(bv.playerid = bm.player2id), 0)
+		FROM bracket_matchups bm
+		LEFT JOIN goat_players g1 ON g1.playerid = bm.player1id
+		LEFT JOIN players p1 ON p1.playerid = bm.player1id
+		LEFT JOIN goat_players g2 ON g2.playerid = bm.player2id
+		LEFT JOIN players p2 ON p2.playerid = bm.player2id
+		LEFT JOIN bracket_votes bv ON bv.matchupid = bm.id
+		WHERE bm.pollid=?
+		GROUP BY bm.id, bm.round, bm.position, bm.seed1, bm.player1id, g1.name, p1.name, bm.seed2, bm.player2id, g2.name, p2.name, bm.winnerid
+		ORDER BY bm.round, bm.position`, pollid)
+}
+
+func (m *MySqlDB) SetBracketMatchupWinner(id int64, winnerid string) (sql.Result, error) {
+	return m.db.Exec("UPDATE bracket_matchups SET winnerid=? WHERE id=?", winnerid, id)
+}
+
+// InsertBracketVote lets the user switch their pick while the matchup is open
+func (m *MySqlDB) InsertBracketVote(vote databasestructs.BracketVote) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO bracket_votes(matchupid, userid, playerid) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE playerid=?", vote.MatchupID, vote.UserID, vote.PlayerID, vote.PlayerID)
+}
@@ -0,0 +1,74 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+	"strconv"
+)
+
+func (m *MySqlDB) InsertPollCandidate(candidate databasestructs.PollCandidate, nameKey string) (sql.Result, error) {
+	var playerID interface{}
+	if candidate.PlayerID != "" {
+		playerID = candidate.PlayerID
+	}
+
+	var submittedBy interface{}
+	if candidate.SubmittedBy != 0 {
+		submittedBy = candidate.SubmittedBy
+	}
+
+	return m.db.Exec("INSERT INTO poll_candidates(pollid, playerid, name, name_key, status, is_write_in, submitted_by) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		candidate.PollID, playerID, candidate.Name, nameKey, candidate.Status, candidate.IsWriteIn, submittedBy)
+}
+
+func (m *MySqlDB) GetPollCandidates(ctx context.Context, pollid int64, status string) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE pollid=? AND status=? ORDER BY is_write_in, name", pollid, status)
+}
+
+func (m *MySqlDB) GetPollCandidateByID(id int64) *sql.Row {
+	return m.db.QueryRow("SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE id=?", id)
+}
+
+func (m *MySqlDB) GetPollCandidateByKey(pollid int64, nameKey string) *sql.Row {
+	return m.db.QueryRow("SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE pollid=? AND name_key=?", pollid, nameKey)
+}
+
+func (m *MySqlDB) UpdatePollCandidateStatus(id int64, status string) (sql.Result, error) {
+	return m.db.Exec("UPDATE poll_candidates SET status=? WHERE id=?", status, id)
+}
+
+func (m *MySqlDB) CountUserWriteIns(pollid, userid int64) *sql.Row {
+	return m.db.QueryRow("SELECT COUNT(*) FROM poll_candidates WHERE pollid=? AND submitted_by=? AND is_write_in=true", pollid, userid)
+}
+
+// InsertCandidateVote replaces whatever the user voted for in the poll before
+func (m *MySqlDB) InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error) {
+	choice := strconv.FormatInt(candidateid, 10)
+	event := "cast"
+
+	var previous string
+	var switches int64
+	err := m.db.QueryRow("SELECT COALESCE(playerid, goatplayerid, CAST(candidateid AS CHAR), ''), switches FROM player_votes WHERE pollid=? AND userid=?", pollid, userid).Scan(&previous, &switches)
+	if err == nil {
+		if previous == choice {
+			return nil, nil
+		}
+		event = "changed"
+		switches++
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	_, err = m.db.Exec("DELETE FROM player_votes WHERE pollid=? AND userid=?", pollid, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.db.Exec("INSERT INTO player_votes(candidateid, pollid, userid, votes_for, created_at, ip, switches) VALUES (?, ?, ?, 1, UNIX_TIMESTAMP(), ?, ?)", candidateid, pollid, userid, ip, switches)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, m.insertVoteEvent(pollid, userid, event, choice, previous)
+}
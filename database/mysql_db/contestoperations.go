@@ -0,0 +1,69 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (m *MySqlDB) InsertContest(contest databasestructs.PredictionContest) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO prediction_contests(name, description, season, deadline, userid) VALUES (?, ?, ?, ?, ?)", contest.Name, contest.Description, contest.Season, contest.Deadline, contest.UserID)
+}
+
+func (m *MySqlDB) GetContests(ctx context.Context) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, name, description, season, deadline, resolved, userid FROM prediction_contests ORDER BY deadline DESC")
+}
+
+func (m *MySqlDB) GetContestByID(id int64) *sql.Row {
+	return m.db.QueryRow("SELECT id, name, description, season, deadline, resolved, userid FROM prediction_contests WHERE id=?", id)
+}
+
+// GetUnresolvedContests returns the contests that are locked but not graded yet
+func (m *MySqlDB) GetUnresolvedContests(ctx context.Context, now int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, name, description, season, deadline, resolved, userid FROM prediction_contests WHERE resolved=false AND deadline <= ?", now)
+}
+
+func (m *MySqlDB) SetContestResolved(id int64) (sql.Result, error) {
+	return m.db.Exec("UPDATE prediction_contests SET resolved=true WHERE id=?", id)
+}
+
+func (m *MySqlDB) InsertContestQuestion(question databasestructs.PredictionQuestion) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO prediction_questions(contestid, position, prompt, type, target, points) VALUES (?, ?, ?, ?, ?, ?)", question.ContestID, question.Position, question.Prompt, question.Type, question.Target, question.Points)
+}
+
+func (m *MySqlDB) GetContestQuestions(ctx context.Context, contestid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, contestid, position, prompt, type, target, points, answer, answer_number FROM prediction_questions WHERE contestid=? ORDER BY position", contestid)
+}
+
+func (m *MySqlDB) ResolveContestQuestion(id int64, answer string, answerNumber *float64) (sql.Result, error) {
+	return m.db.Exec("UPDATE prediction_questions SET answer=?, answer_number=? WHERE id=?", answer, answerNumber, id)
+}
+
+// InsertPrediction overwrites an earlier prediction of the user for the same question
+func (m *MySqlDB) InsertPrediction(prediction databasestructs.Prediction) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO predictions(questionid, userid, value, number) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE value=?, number=?",
+		prediction.QuestionID, prediction.UserID, prediction.Value, prediction.Number, prediction.Value, prediction.Number)
+}
+
+func (m *MySqlDB) GetQuestionPredictions(ctx context.Context, questionid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT questionid, userid, value, number, score FROM predictions WHERE questionid=?", questionid)
+}
+
+func (m *MySqlDB) GetUserPredictions(ctx context.Context, contestid, userid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT p.questionid, p.userid, p.value, p.number, p.score FROM predictions p
+		INNER JOIN prediction_questions q ON q.id = p.questionid
+		WHERE q.contestid=? AND p.userid=? ORDER BY q.position`, contestid, userid)
+}
+
+func (m *MySqlDB) SetPredictionScore(questionid, userid int64, score float64) (sql.Result, error) {
+	return m.db.Exec("UPDATE predictions SET score=? WHERE questionid=? AND userid=?", score, questionid, userid)
+}
+
+func (m *MySqlDB) GetContestLeaderboard(ctx context.Context, contestid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT u.id, u.username, COALESCE(SUM(p.score), 0) AS total, COUNT(p.score) FROM predictions p
+		INNER JOIN prediction_questions q ON q.id = p.questionid
+		INNER JOIN users u ON u.id = p.userid
+		WHERE q.contestid=?
+		GROUP BY u.id, u.username
+		ORDER BY total DESC, u.username`, contestid)
+}
@@ -0,0 +1,41 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// GetVotesForFraudCheck returns the votes cast since the given unix time with the age and signup address of the voter
+func (m *MySqlDB) GetVotesForFraudCheck(ctx context.Context, since int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT v.pollid, v.userid, COALESCE(v.playerid, v.goatplayerid, CAST(v.candidateid AS CHAR)), v.created_at, v.ip, v.switches, u.created_at, u.signup_ip
+		FROM player_votes v
+		INNER JOIN users u ON u.id = v.userid
+		WHERE v.created_at >= ?
+		ORDER BY v.pollid, v.created_at`, since)
+}
+
+// InsertVoteFlag keeps the first flag for the same reason, so cleared flags aren't opened again by the next run
+func (m *MySqlDB) InsertVoteFlag(flag databasestructs.VoteFlag) (sql.Result, error) {
+	return m.db.Exec("INSERT IGNORE INTO vote_flags(pollid, userid, reason, details, status, created_at) VALUES (?, ?, ?, ?, 'open', ?)", flag.PollID, flag.UserID, flag.Reason, flag.Details, flag.CreatedAt)
+}
+
+func (m *MySqlDB) GetVoteFlags(ctx context.Context, status string, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT f.id, f.pollid, f.userid, u.username, f.reason, f.details, f.status, f.created_at, COALESCE(f.reviewed_by, 0)
+		FROM vote_flags f
+		INNER JOIN users u ON u.id = f.userid
+		WHERE f.status=? AND (? = 0 OR f.pollid=?)
+		ORDER BY f.created_at DESC, f.id`, status, pollid, pollid)
+}
+
+func (m *MySqlDB) GetVoteFlagByID(id int64) *sql.Row {
+	return m.db.QueryRow(`SELECT f.id, f.pollid, f.userid, u.username, f.reason, f.details, f.status, f.created_at, COALESCE(f.reviewed_by, 0)
+		FROM vote_flags f
+		INNER JOIN users u ON u.id = f.userid
+		WHERE f.id=?`, id)
+}
+
+// UpdateVoteFlagStatus reviews every flag of the user's ballot in the poll at once, since the ballot is either counted or not
+func (m *MySqlDB) UpdateVoteFlagStatus(pollid, userid int64, status string, reviewedBy int64) (sql.Result, error) {
+	return m.db.Exec("UPDATE vote_flags SET status=?, reviewed_by=? WHERE pollid=? AND userid=?", status, reviewedBy, pollid, userid)
+}
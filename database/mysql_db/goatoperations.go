@@ -1,6 +1,7 @@
 package mysql_db
 
 import (
+	"context"
 	"database/sql"
 	"sportsvoting/databasestructs"
 )
@@ -14,11 +15,11 @@ func (m *MySqlDB) UpdateGOATPlayer(info databasestructs.GoatPlayers) (sql.Result
 }
 
 func (m *MySqlDB) UpdateGOATStats(stats databasestructs.GoatStats) (sql.Result, error) {
-	return m.db.Exec("UPDATE goat_stats SET pointspergame=?, reboundspergame=?, assistspergame=?, stealspergame=?, blockspergame=?, turnoverspergame=?, fgpercentage=?, ftpercentage=?, threeptpercentage=?, per=?, ows=?, dws=?, ws=?, obpm=?, dbpm=?, bpm=?, vorp=?, offrtg=?, defrtg=?, totalpoints=?, totalrebounds=?, totalassists=?, totalsteals=?, totalblocks=?, position=? WHERE playerid=? AND isplayoffs=?", stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.PER, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TotalPoints, stats.TotalRebounds, stats.TotalAssists, stats.TotalSteals, stats.TotalBlocks, stats.Position, stats.PlayerID, stats.IsPlayoffs)
+	return m.db.Exec("UPDATE goat_stats SET pointspergame=?, reboundspergame=?, assistspergame=?, stealspergame=?, blockspergame=?, turnoverspergame=?, fgpercentage=?, ftpercentage=?, threeptpercentage=?, per=?, ows=?, dws=?, ws=?, obpm=?, dbpm=?, bpm=?, vorp=?, offrtg=?, defrtg=?, totalpoints=?, totalrebounds=?, totalassists=?, totalsteals=?, totalblocks=?, position=?, minutespergame=?, tspct=? WHERE playerid=? AND isplayoffs=?", stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.PER, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TotalPoints, stats.TotalRebounds, stats.TotalAssists, stats.TotalSteals, stats.TotalBlocks, stats.Position, stats.Minutes, stats.TSPct, stats.PlayerID, stats.IsPlayoffs)
 }
 
 func (m *MySqlDB) InsertGOATStats(stats databasestructs.GoatStats) (sql.Result, error) {
-	return m.db.Exec("INSERT IGNORE INTO goat_stats (playerid, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, turnoverspergame, fgpercentage, ftpercentage, threeptpercentage, per, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg, totalpoints, totalrebounds, totalassists, totalsteals, totalblocks, isplayoffs, position, isactive) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", stats.PlayerID, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.PER, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TotalPoints, stats.TotalRebounds, stats.TotalAssists, stats.TotalSteals, stats.TotalBlocks, stats.IsPlayoffs, stats.Position, stats.IsActive)
+	return m.db.Exec("INSERT IGNORE INTO goat_stats (playerid, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, turnoverspergame, fgpercentage, ftpercentage, threeptpercentage, per, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg, totalpoints, totalrebounds, totalassists, totalsteals, totalblocks, isplayoffs, position, isactive, minutespergame, tspct) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", stats.PlayerID, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.PER, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TotalPoints, stats.TotalRebounds, stats.TotalAssists, stats.TotalSteals, stats.TotalBlocks, stats.IsPlayoffs, stats.Position, stats.IsActive, stats.Minutes, stats.TSPct)
 }
 
 func (m *MySqlDB) GetGOATStats() (*sql.Rows, error) {
@@ -28,3 +29,40 @@ func (m *MySqlDB) GetGOATStats() (*sql.Rows, error) {
 func (m *MySqlDB) GetActivePlayers() (*sql.Rows, error) {
 	return m.db.Query("SELECT playerid FROM goat_players WHERE isactive = 1")
 }
+
+func (m *MySqlDB) GetGOATPlayerStats(ctx context.Context, playerid string) (*sql.Rows, error) {
+	query := `
+        SELECT p.name, p.allstar, p.allnba, p.alldefense, p.championships, p.dpoy, p.sixman, p.roy, p.finalsmvp, p.mvp, p.isactive, s.pointspergame, s.reboundspergame, s.assistspergame, s.stealspergame, s.blockspergame, s.turnoverspergame, s.fgpercentage, s.threeptpercentage, s.ftpercentage, s.per, s.ows, s.dws, s.ws, s.obpm, s.dbpm, s.bpm, s.vorp, s.offrtg, s.defrtg, s.totalpoints, s.totalrebounds, s.totalassists, s.totalsteals, s.totalblocks, s.position, s.isplayoffs
+        FROM goat_players p
+        INNER JOIN goat_stats s ON p.playerid = s.playerid
+        WHERE p.playerid = ?`
+	return m.db.QueryContext(ctx, query, playerid)
+}
+
+func (m *MySqlDB) InsertGOATPlayerSeason(season databasestructs.GoatPlayerSeason) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO goat_player_seasons(playerid, season, gamesplayed) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE gamesplayed=VALUES(gamesplayed)", season.PlayerID, season.Season, season.Games)
+}
+
+func (m *MySqlDB) GetGOATEraStats(ctx context.Context) (*sql.Rows, error) {
+	query := `
+        SELECT s.playerid, ps.gamesplayed, s.ppg, s.rpg, s.apg, s.mpg, s.ts, COALESCE(la.pace, 0), COALESCE(la.pointspergame, 0), COALESCE(la.reboundspergame, 0), COALESCE(la.assistspergame, 0), COALESCE(la.tspct, 0)
+        FROM (
+            SELECT playerid, AVG(pointspergame) AS ppg, AVG(reboundspergame) AS rpg, AVG(assistspergame) AS apg, MAX(COALESCE(minutespergame, 0)) AS mpg, MAX(COALESCE(tspct, 0)) AS ts
+            FROM goat_stats
+            WHERE isplayoffs = 0
+            GROUP BY playerid
+        ) s
+        INNER JOIN goat_player_seasons ps ON s.playerid = ps.playerid
+        INNER JOIN league_averages la ON ps.season = la.season`
+	return m.db.QueryContext(ctx, query)
+}
+
+func (m *MySqlDB) GetGOATCareerStats(ctx context.Context) (*sql.Rows, error) {
+	query := `
+        SELECT p.playerid, p.name, MAX(s.position), AVG(COALESCE(s.minutespergame, 0)), AVG(s.pointspergame), AVG(s.reboundspergame), AVG(s.assistspergame), AVG(s.stealspergame), AVG(s.blockspergame), AVG(s.turnoverspergame), AVG(s.fgpercentage), AVG(s.threeptpercentage), AVG(s.ftpercentage), AVG(s.per), AVG(COALESCE(s.tspct, 0)), AVG(s.obpm), AVG(s.dbpm), AVG(s.bpm), AVG(s.offrtg), AVG(s.defrtg)
+        FROM goat_players p
+        INNER JOIN goat_stats s ON p.playerid = s.playerid
+        WHERE s.isplayoffs = 0
+        GROUP BY p.playerid, p.name`
+	return m.db.QueryContext(ctx, query)
+}
@@ -0,0 +1,47 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertGuestVote replaces the earlier vote of the device in the poll
+func (m *MySqlDB) InsertGuestVote(vote databasestructs.GuestVote) (sql.Result, error) {
+	var playerID interface{}
+	if vote.PlayerID != "" {
+		playerID = vote.PlayerID
+	}
+
+	var candidateID interface{}
+	if vote.CandidateID != 0 {
+		candidateID = vote.CandidateID
+	}
+
+	return m.db.Exec(`INSERT INTO guest_votes(pollid, deviceid, ip, playerid, candidateid, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE ip=VALUES(ip), playerid=VALUES(playerid), candidateid=VALUES(candidateid), created_at=VALUES(created_at)`,
+		vote.PollID, vote.DeviceID, vote.IP, playerID, candidateID, vote.CreatedAt)
+}
+
+func (m *MySqlDB) CountGuestVotesByIP(pollid int64, ip string) *sql.Row {
+	return m.db.QueryRow("SELECT COUNT(*) FROM guest_votes WHERE pollid=? AND ip=?", pollid, ip)
+}
+
+func (m *MySqlDB) GetGuestDeviceVote(pollid int64, deviceid string) *sql.Row {
+	return m.db.QueryRow("SELECT COUNT(*) FROM guest_votes WHERE pollid=? AND deviceid=?", pollid, deviceid)
+}
+
+func (m *MySqlDB) GetGuestPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT COALESCE(p.name, gp.name, c.name) AS player_name, COUNT(*) AS votes_for
+		FROM guest_votes g
+		LEFT JOIN players p ON g.playerid = p.playerid
+		LEFT JOIN goat_players gp ON g.playerid = gp.playerid
+		LEFT JOIN poll_candidates c ON g.candidateid = c.id
+		WHERE g.pollid=?
+		GROUP BY player_name
+		ORDER BY votes_for DESC`, pollid)
+}
+
+func (m *MySqlDB) ResetGuestVotes(pollid int64) (sql.Result, error) {
+	return m.db.Exec("DELETE FROM guest_votes WHERE pollid=?", pollid)
+}
@@ -0,0 +1,10 @@
+package mysql_db
+
+import (
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (m *MySqlDB) InsertLeagueAverages(averages databasestructs.LeagueAverages) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO league_averages(season, pace, pointspergame, reboundspergame, assistspergame, tspct) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE pace=VALUES(pace), pointspergame=VALUES(pointspergame), reboundspergame=VALUES(reboundspergame), assistspergame=VALUES(assistspergame), tspct=VALUES(tspct)", averages.Season, averages.Pace, averages.Points, averages.Rebounds, averages.Assists, averages.TSPct)
+}
@@ -0,0 +1,53 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+const initialMatchupRating = 1500
+
+func (m *MySqlDB) InsertMatchupVote(vote databasestructs.MatchupVote) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO matchup_votes(pollid, userid, winnerid, loserid, rating_change) VALUES (?, ?, ?, ?, ?)", vote.PollID, vote.UserID, vote.WinnerID, vote.LoserID, vote.RatingChange)
+}
+
+// UpdateMatchupRating applies the change on top of the stored rating, so concurrent votes don't overwrite each other
+func (m *MySqlDB) UpdateMatchupRating(pollid int64, playerid string, change float64, won bool) (sql.Result, error) {
+	wins := 0
+	if won {
+		wins = 1
+	}
+
+	return m.db.Exec("INSERT INTO matchup_ratings(pollid, playerid, rating, matches, wins) VALUES (?, ?, ?, 1, ?) ON DUPLICATE KEY UPDATE rating=rating+?, matches=matches+1, wins=wins+?", pollid, playerid, initialMatchupRating+change, wins, change, wins)
+}
+
+func (m *MySqlDB) GetMatchupRatings(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT playerid, rating, matches, wins FROM matchup_ratings WHERE pollid=?", pollid)
+}
+
+func (m *MySqlDB) GetMatchupVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, winnerid, loserid, rating_change FROM matchup_votes WHERE pollid=? ORDER BY id", pollid)
+}
+
+func (m *MySqlDB) GetUserMatchupVotes(ctx context.Context, pollid, userid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT winnerid, loserid FROM matchup_votes WHERE pollid=? AND userid=?", pollid, userid)
+}
+
+func (m *MySqlDB) ResetMatchups(pollid int64) error {
+	_, err := m.db.Exec("DELETE FROM matchup_votes WHERE pollid=?", pollid)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.db.Exec("DELETE FROM matchup_ratings WHERE pollid=?", pollid)
+	return err
+}
+
+func (m *MySqlDB) GetPollsWithoutMatchupRatings(ctx context.Context) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT DISTINCT pollid FROM matchup_votes v WHERE NOT EXISTS (SELECT 1 FROM matchup_ratings r WHERE r.pollid = v.pollid) ORDER BY pollid")
+}
+
+func (m *MySqlDB) SetMatchupVoteChange(id int64, change float64) (sql.Result, error) {
+	return m.db.Exec("UPDATE matchup_votes SET rating_change=? WHERE id=?", change, id)
+}
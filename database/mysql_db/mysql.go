@@ -1,6 +1,7 @@
 package mysql_db
 
 import (
+	"context"
 	"database/sql"
 	"os"
 
@@ -8,7 +9,18 @@ import (
 )
 
 type MySqlDB struct {
-	db *sql.DB
+	conn *sql.DB
+	// db runs the statements, the connection pool or a transaction on it
+	db querier
+}
+
+// querier is what *sql.DB and *sql.Tx have in common, so the statements run the same in a transaction
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 func NewDB(dbname string, addr string) (*MySqlDB, error) {
@@ -31,13 +43,18 @@ func NewDB(dbname string, addr string) (*MySqlDB, error) {
 		return nil, pingErr
 	}
 
-	return &MySqlDB{db: db}, nil
+	return &MySqlDB{conn: db, db: db}, nil
 }
 
 func (m *MySqlDB) CloseConnection() {
-	m.db.Close()
+	m.conn.Close()
 }
 
 func (m *MySqlDB) GetDB() *sql.DB {
-	return m.db
+	return m.conn
+}
+
+// InTx returns a copy of the database that runs its statements in the transaction
+func (m *MySqlDB) InTx(tx *sql.Tx) *MySqlDB {
+	return &MySqlDB{conn: m.conn, db: tx}
 }
@@ -1,6 +1,7 @@
 package mysql_db
 
 import (
+	"context"
 	"database/sql"
 	"sportsvoting/databasestructs"
 )
@@ -20,3 +21,14 @@ func (m *MySqlDB) SelectPlayerGamesPlayed(season string) (*sql.Rows, error) {
 func (m *MySqlDB) CheckPlayerExists(playerid string) *sql.Row {
 	return m.db.QueryRow("SELECT 1 FROM players WHERE playerid=?", playerid)
 }
+
+func (m *MySqlDB) GetPlayersForSearch(ctx context.Context, season string) (*sql.Rows, error) {
+	query := `
+        SELECT p.playerid, p.name, COALESCE(s.minutespergame, 0), 0 AS isgoat
+        FROM players p
+        LEFT JOIN stats s ON p.playerid = s.playerid AND s.season = ?
+        UNION ALL
+        SELECT g.playerid, g.name, 0, 1 AS isgoat
+        FROM goat_players g`
+	return m.db.QueryContext(ctx, query, season)
+}
@@ -3,6 +3,7 @@ package mysql_db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"sportsvoting/databasestructs"
 	"strings"
 )
@@ -18,26 +19,42 @@ func (m *MySqlDB) GetPlayerStatsForPoll(ctx context.Context, season string) (*sq
 	return m.db.QueryContext(ctx, query, season, season)
 }
 
-func (m *MySqlDB) GetPolls(ctx context.Context) (*sql.Rows, error) {
-	return m.db.QueryContext(ctx, "SELECT id, name, description, image, selected_stats, season, userid FROM polls")
+// GetPolls returns the public polls and the polls of the groups the user is in
+func (m *MySqlDB) GetPolls(ctx context.Context, userid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE groupid IS NULL OR groupid IN (SELECT groupid FROM group_members WHERE userid=?)", userid)
 }
 
 func (m *MySqlDB) GetPollByID(id int64) *sql.Row {
-	return m.db.QueryRow("SELECT name, description, image, selected_stats, season, userid FROM polls WHERE id=?", id)
+	return m.db.QueryRow("SELECT name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE id=?", id)
 }
 
-func (m *MySqlDB) GetPollByUserID(userid int64) (*sql.Rows, error) {
-	return m.db.Query("SELECT id, name, description, image, selected_stats, season FROM polls WHERE userid=?", userid)
+// GetPollByUserID returns the polls the user created that the viewer can see
+func (m *MySqlDB) GetPollByUserID(userid, viewerid int64) (*sql.Rows, error) {
+	return m.db.Query("SELECT id, name, description, image, selected_stats, season, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE userid=? AND (groupid IS NULL OR groupid IN (SELECT groupid FROM group_members WHERE userid=?))", userid, viewerid)
 }
 
 func (m *MySqlDB) InsertPolls(poll databasestructs.Poll) (sql.Result, error) {
-	return m.db.Exec("INSERT IGNORE INTO polls(name, description, image, selected_stats, season, userid) VALUES (?, ?, ?, ?, ?, ?)", poll.Name, poll.Description, poll.Image, poll.SelectedStats, poll.Season, poll.UserID)
+	return m.db.Exec("INSERT IGNORE INTO polls(name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, groupid, panel_weight, allow_guests) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", poll.Name, poll.Description, poll.Image, poll.SelectedStats, poll.Season, poll.UserID, poll.ScoreFormula, poll.AllowWriteIns, pollGroupID(poll), poll.PanelWeight, poll.AllowGuests)
 }
 
 func (m *MySqlDB) InsertPollsWithId(poll databasestructs.Poll) (sql.Result, error) {
-	return m.db.Exec("INSERT IGNORE INTO polls(id, name, description, image, selected_stats, season, userid) VALUES (?, ?, ?, ?, ?, ?, ?)", poll.ID, poll.Name, poll.Description, poll.Image, poll.SelectedStats, poll.Season, poll.UserID)
+	return m.db.Exec("INSERT IGNORE INTO polls(id, name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, groupid, panel_weight, allow_guests) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", poll.ID, poll.Name, poll.Description, poll.Image, poll.SelectedStats, poll.Season, poll.UserID, poll.ScoreFormula, poll.AllowWriteIns, pollGroupID(poll), poll.PanelWeight, poll.AllowGuests)
 }
 
+// pollGroupID stores public polls without a group
+func pollGroupID(poll databasestructs.Poll) interface{} {
+	if poll.GroupID == 0 {
+		return nil
+	}
+	return poll.GroupID
+}
+
+// leaves out the ballots admins excluded from the official result after a fraud review
+const excludedVotesFilter = " AND NOT EXISTS (SELECT 1 FROM vote_flags f WHERE f.pollid = v.pollid AND f.userid = v.userid AND f.status = 'excluded')"
+
+// votes for curated candidates and write-ins, which are counted next to the stat based candidates
+const candidateVotesQuery = "SELECT c.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN poll_candidates c ON v.candidateid=c.id INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=?" + excludedVotesFilter + " GROUP BY c.name, po.name"
+
 func (m *MySqlDB) GetPlayerPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
 	var stats string
 	err := m.db.QueryRow("SELECT selected_stats FROM polls WHERE id=?", pollid).Scan(&stats)
@@ -46,14 +63,74 @@ func (m *MySqlDB) GetPlayerPollVotes(ctx context.Context, pollid int64) (*sql.Ro
 	}
 
 	if strings.Contains(stats, "GOAT") {
-		return m.db.QueryContext(ctx, "SELECT p.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN goat_players p ON v.goatplayerid=p.playerid INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=? GROUP BY p.name, po.name ORDER BY COUNT(v.votes_for) DESC", pollid)
+		return m.db.QueryContext(ctx, "SELECT p.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN goat_players p ON v.goatplayerid=p.playerid INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=?"+excludedVotesFilter+" GROUP BY p.name, po.name UNION ALL "+candidateVotesQuery+" ORDER BY votes_for DESC", pollid, pollid)
 	}
 
-	return m.db.QueryContext(ctx, "SELECT p.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN players p ON v.playerid=p.playerid INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=? GROUP BY p.name, po.name ORDER BY COUNT(v.votes_for) DESC", pollid)
-}
+	return m.db.QueryContext(ctx, "SELECT p.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN players p ON v.playerid=p.playerid INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=?"+excludedVotesFilter+" GROUP BY p.name, po.name UNION ALL "+candidateVotesQuery+" ORDER BY votes_for DESC", pollid, pollid)
+}
+
+// GetPanelPollVotes tallies the votes of the poll per candidate, split between panelists and fans
+func (m *MySqlDB) GetPanelPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT COALESCE(p.name, gp.name, c.name) AS player_name, FIND_IN_SET('panelist', COALESCE(r.role, '')) > 0 AS is_panelist, COUNT(v.votes_for) AS votes_for
+		FROM player_votes v
+		LEFT JOIN players p ON v.playerid = p.playerid
+		LEFT JOIN goat_players gp ON v.goatplayerid = gp.playerid
+		LEFT JOIN poll_candidates c ON v.candidateid = c.id
+		LEFT JOIN user_roles r ON r.user_id = v.userid
+		WHERE v.pollid=?`+excludedVotesFilter+`
+		GROUP BY player_name, is_panelist
+		ORDER BY votes_for DESC`, pollid)
+}
+
+// GetPollVoteTimeline replays the vote history of the poll, per time bucket every candidate gains the votes cast or changed to them
+// and loses the ones changed away, retracted or reset, votes from before the history was kept aren't in it
+func (m *MySqlDB) GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT v.created_at - MOD(v.created_at, ?) AS bucket, COALESCE(p.name, gp.name, c.name) AS player_name, SUM(v.delta) AS votes_for
+		FROM (
+			SELECT pollid, userid, created_at, choice AS pick, 1 AS delta FROM vote_events WHERE pollid=? AND choice IS NOT NULL
+			UNION ALL
+			SELECT pollid, userid, created_at, previous AS pick, -1 AS delta FROM vote_events WHERE pollid=? AND previous IS NOT NULL
+		) v
+		LEFT JOIN players p ON v.pick = p.playerid
+		LEFT JOIN goat_players gp ON v.pick = gp.playerid
+		LEFT JOIN poll_candidates c ON c.pollid = v.pollid AND CAST(c.id AS CHAR) = v.pick
+		WHERE v.pollid=?`+excludedVotesFilter+`
+		GROUP BY bucket, player_name
+		HAVING player_name IS NOT NULL
+		ORDER BY bucket`, bucketSeconds, pollid, pollid, pollid)
+}
+
+// voter segments results can be broken down by, account age is taken at the time of the vote
+var segmentColumns = map[string]string{
+	"team":        "COALESCE(u.favorite_team, '')",
+	"region":      "COALESCE(u.region, '')",
+	"account_age": "CASE WHEN u.created_at = 0 OR v.created_at = 0 THEN '' WHEN v.created_at - u.created_at < 30*86400 THEN 'under 30 days' WHEN v.created_at - u.created_at < 365*86400 THEN '30 days to 1 year' ELSE 'over 1 year' END",
+	"panel":       "CASE WHEN FIND_IN_SET('panelist', COALESCE(r.role, '')) > 0 THEN 'panel' ELSE 'fans' END",
+}
+
+// GetPollVotesBySegment tallies the votes of the poll per candidate within each value of the voter segment, unknown values are empty
+func (m *MySqlDB) GetPollVotesBySegment(ctx context.Context, pollid int64, segment string) (*sql.Rows, error) {
+	column, ok := segmentColumns[segment]
+	if !ok {
+		return nil, fmt.Errorf("unknown segment %q", segment)
+	}
 
-func (m *MySqlDB) InsertPlayerVotes(pollid, userid int64, playerid string) (sql.Result, error) {
-	var id int64
+	return m.db.QueryContext(ctx, `SELECT `+column+` AS segment, COALESCE(p.name, gp.name, c.name) AS player_name, COUNT(v.votes_for) AS votes_for
+		FROM player_votes v
+		INNER JOIN users u ON u.id = v.userid
+		LEFT JOIN user_roles r ON r.user_id = v.userid
+		LEFT JOIN players p ON v.playerid = p.playerid
+		LEFT JOIN goat_players gp ON v.goatplayerid = gp.playerid
+		LEFT JOIN poll_candidates c ON v.candidateid = c.id
+		WHERE v.pollid=?`+excludedVotesFilter+`
+		GROUP BY segment, player_name
+		HAVING player_name IS NOT NULL
+		ORDER BY segment, votes_for DESC`, pollid)
+}
+
+// InsertPlayerVotes replaces the user's earlier vote in the poll, counting how often they switched for the fraud checks
+func (m *MySqlDB) InsertPlayerVotes(pollid, userid int64, playerid, ip string) (sql.Result, error) {
+	var id, switches int64
 	var playerIdDB string
 
 	var stats string
@@ -62,24 +139,33 @@ func (m *MySqlDB) InsertPlayerVotes(pollid, userid int64, playerid string) (sql.
 		return nil, err
 	}
 
+	column := "playerid"
 	if strings.Contains(stats, "GOAT") {
-		rows := m.db.QueryRow("SELECT id, goatplayerid FROM player_votes WHERE pollid=? AND userid=?", pollid, userid)
-		err := rows.Scan(&id, &playerIdDB)
-		if err == sql.ErrNoRows {
-			return m.db.Exec("INSERT IGNORE INTO player_votes(goatplayerid, pollid, userid, votes_for) VALUES (?, ?, ?, 1)", playerid, pollid, userid)
-		} else if playerIdDB != playerid {
-			m.db.Exec("DELETE FROM player_votes WHERE id=?", id)
-			return m.db.Exec("INSERT IGNORE INTO player_votes(goatplayerid, pollid, userid, votes_for) VALUES (?, ?, ?, 1)", playerid, pollid, userid)
+		column = "goatplayerid"
+	}
+
+	// a plain insert, skipping a failed one would keep the event and, when changing the vote, drop the earlier vote
+	insert := "INSERT INTO player_votes(" + column + ", pollid, userid, votes_for, created_at, ip, switches) VALUES (?, ?, ?, 1, UNIX_TIMESTAMP(), ?, ?)"
+	var previous string
+	rows := m.db.QueryRow("SELECT id, COALESCE("+column+", ''), COALESCE(playerid, goatplayerid, CAST(candidateid AS CHAR), ''), switches FROM player_votes WHERE pollid=? AND userid=?", pollid, userid)
+	err = rows.Scan(&id, &playerIdDB, &previous, &switches)
+	if err == sql.ErrNoRows {
+		res, err := m.db.Exec(insert, playerid, pollid, userid, ip, 0)
+		if err != nil {
+			return nil, err
+		}
+		return res, m.insertVoteEvent(pollid, userid, "cast", playerid, "")
+	} else if err != nil {
+		return nil, err
+	} else if playerIdDB != playerid {
+		if _, err := m.db.Exec("DELETE FROM player_votes WHERE id=?", id); err != nil {
+			return nil, err
 		}
-	} else {
-		rows := m.db.QueryRow("SELECT id, playerid FROM player_votes WHERE pollid=? AND userid=?", pollid, userid)
-		err := rows.Scan(&id, &playerIdDB)
-		if err == sql.ErrNoRows {
-			return m.db.Exec("INSERT IGNORE INTO player_votes(playerid, pollid, userid, votes_for) VALUES (?, ?, ?, 1)", playerid, pollid, userid)
-		} else if playerIdDB != playerid {
-			m.db.Exec("DELETE FROM player_votes WHERE id=?", id)
-			return m.db.Exec("INSERT IGNORE INTO player_votes(playerid, pollid, userid, votes_for) VALUES (?, ?, ?, 1)", playerid, pollid, userid)
+		res, err := m.db.Exec(insert, playerid, pollid, userid, ip, switches+1)
+		if err != nil {
+			return nil, err
 		}
+		return res, m.insertVoteEvent(pollid, userid, "changed", playerid, previous)
 	}
 
 	return nil, nil
@@ -90,10 +176,16 @@ func (m *MySqlDB) DeletePollByID(pollid int64) (sql.Result, error) {
 }
 
 func (m *MySqlDB) UpdatePollByID(poll databasestructs.Poll) (sql.Result, error) {
-	return m.db.Exec("UPDATE polls SET name=?, description=?, selected_stats=?, season=? WHERE id=?", poll.Name, poll.Description, poll.SelectedStats, poll.Season, poll.ID)
+	return m.db.Exec("UPDATE polls SET name=?, description=?, selected_stats=?, season=?, score_formula=?, allow_write_ins=?, panel_weight=?, allow_guests=? WHERE id=?", poll.Name, poll.Description, poll.SelectedStats, poll.Season, poll.ScoreFormula, poll.AllowWriteIns, poll.PanelWeight, poll.AllowGuests, poll.ID)
 }
 
+// ResetPollVotes removes every vote of the poll, the vote history keeps a reset event for each of them
 func (m *MySqlDB) ResetPollVotes(pollid int64) (sql.Result, error) {
+	_, err := m.db.Exec("INSERT INTO vote_events(pollid, userid, event, previous, created_at) SELECT pollid, userid, 'reset', COALESCE(playerid, goatplayerid, CAST(candidateid AS CHAR)), UNIX_TIMESTAMP() FROM player_votes WHERE pollid=?", pollid)
+	if err != nil {
+		return nil, err
+	}
+
 	return m.db.Exec("DELETE FROM player_votes WHERE pollid=?", pollid)
 }
 
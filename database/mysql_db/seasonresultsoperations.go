@@ -0,0 +1,24 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (m *MySqlDB) InsertTeamStanding(standing databasestructs.TeamStanding) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO team_standings(season, teamabbr, wins, losses) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE wins=?, losses=?",
+		standing.Season, standing.TeamAbbr, standing.Wins, standing.Losses, standing.Wins, standing.Losses)
+}
+
+func (m *MySqlDB) GetTeamStandings(ctx context.Context, season string) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT season, teamabbr, wins, losses FROM team_standings WHERE season=?", season)
+}
+
+func (m *MySqlDB) InsertSeasonAward(award databasestructs.SeasonAward) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO season_awards(season, award, playerid) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE playerid=?", award.Season, award.Award, award.PlayerID, award.PlayerID)
+}
+
+func (m *MySqlDB) GetSeasonAwards(ctx context.Context, season string) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT season, award, playerid FROM season_awards WHERE season=?", season)
+}
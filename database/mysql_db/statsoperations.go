@@ -3,9 +3,39 @@ package mysql_db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"sportsvoting/databasestructs"
+	"strings"
 )
 
+// leader columns are interpolated into the query, so only names from this list are accepted
+var statColumns = map[string]string{
+	"g":          "stats.gamesplayed",
+	"gs":         "stats.gamesstarted",
+	"mpg":        "stats.minutespergame",
+	"ppg":        "stats.pointspergame",
+	"rpg":        "stats.reboundspergame",
+	"apg":        "stats.assistspergame",
+	"spg":        "stats.stealspergame",
+	"bpg":        "stats.blockspergame",
+	"topg":       "stats.turnoverspergame",
+	"fgpct":      "stats.fgpercentage",
+	"threefgpct": "stats.threeptpercentage",
+	"ftpct":      "stats.ftpercentage",
+	"per":        "advancedstats.per",
+	"ts":         "advancedstats.tspct",
+	"usg":        "advancedstats.usgpct",
+	"ows":        "advancedstats.ows",
+	"dws":        "advancedstats.dws",
+	"ws":         "advancedstats.ws",
+	"obpm":       "advancedstats.obpm",
+	"dbpm":       "advancedstats.dbpm",
+	"bpm":        "advancedstats.bpm",
+	"vorp":       "advancedstats.vorp",
+	"offrtg":     "advancedstats.offrtg",
+	"defrtg":     "advancedstats.defrtg",
+}
+
 func (m *MySqlDB) UpdateStats(stats databasestructs.PlayerStats) (sql.Result, error) {
 	return m.db.Exec("UPDATE stats SET gamesplayed=?, gamesstarted=?, minutespergame=?, pointspergame=?, reboundspergame=?, assistspergame=?, stealspergame=?, blockspergame=?, turnoverspergame=?, fgpercentage=?, ftpercentage=?, threeptpercentage=?, position=?, teamabbr=? WHERE playerid=? AND season=?", stats.Games, stats.GamesStarted, stats.Minutes, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.Position, stats.TeamAbbr, stats.PlayerID, stats.Season)
 }
@@ -49,3 +79,76 @@ func (m *MySqlDB) GetROYStats(ctx context.Context, season string) (*sql.Rows, er
 func (m *MySqlDB) SetRookieStatus(id string) (sql.Result, error) {
 	return m.db.Exec("UPDATE stats set rookieseason=1 WHERE playerid=?", id)
 }
+
+func (m *MySqlDB) GetSeasonStats(ctx context.Context, season string) (*sql.Rows, error) {
+	query := `
+        SELECT players.playerid, name, stats.teamabbr, gamesplayed, gamesstarted, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, tspct, usgpct, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE advancedstats.season = ? AND stats.season = ?`
+	return m.db.QueryContext(ctx, query, season, season)
+}
+
+func (m *MySqlDB) GetStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) (*sql.Rows, error) {
+	column, ok := statColumns[filter.Stat]
+	if !ok {
+		return nil, fmt.Errorf("unknown stat %q", filter.Stat)
+	}
+
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+
+	conditions, args := leaderConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+        SELECT RANK() OVER (ORDER BY %[1]s %[2]s) AS leader_rank, players.playerid, name, COALESCE(stats.teamabbr, ''), COALESCE(stats.position, ''), stats.gamesplayed, stats.minutespergame, %[1]s
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE %[3]s
+        ORDER BY %[1]s %[2]s, name
+        LIMIT ? OFFSET ?`, column, direction, conditions)
+	return m.db.QueryContext(ctx, query, args...)
+}
+
+// CountStatLeaders counts the players the filter qualifies, apart from the page so it's right past the last one too
+func (m *MySqlDB) CountStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) *sql.Row {
+	conditions, args := leaderConditions(filter)
+	query := `
+        SELECT COUNT(*)
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE ` + conditions
+	return m.db.QueryRowContext(ctx, query, args...)
+}
+
+// leaderConditions builds the WHERE clause the leaders and their count share
+func leaderConditions(filter databasestructs.LeadersFilter) (string, []interface{}) {
+	conditions := []string{"stats.season = ?", "advancedstats.season = ?", "stats.gamesplayed >= ?", "stats.minutespergame >= ?"}
+	args := []interface{}{filter.Season, filter.Season, filter.MinGames, filter.MinMinutes}
+	if filter.Position != "" {
+		// hybrid positions are stored as e.g. "PF-C", one of the parts has to match exactly
+		conditions = append(conditions, "INSTR(CONCAT('-', stats.position, '-'), ?) > 0")
+		args = append(args, "-"+filter.Position+"-")
+	}
+	if filter.Team != "" {
+		conditions = append(conditions, "stats.teamabbr = ?")
+		args = append(args, filter.Team)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (m *MySqlDB) GetAllSeasonStats(ctx context.Context) (*sql.Rows, error) {
+	query := `
+        SELECT players.playerid, name, stats.season, stats.teamabbr, gamesplayed, gamesstarted, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, tspct, usgpct, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid AND stats.season = advancedstats.season`
+	return m.db.QueryContext(ctx, query)
+}
@@ -0,0 +1,44 @@
+package mysql_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (m *MySqlDB) InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO poll_questions(pollid, position, prompt, type, picks, min_value, max_value) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		question.PollID, question.Position, question.Prompt, question.Type, question.Picks, question.MinValue, question.MaxValue)
+}
+
+func (m *MySqlDB) GetPollQuestions(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT id, pollid, position, prompt, type, picks, min_value, max_value FROM poll_questions WHERE pollid=? ORDER BY position", pollid)
+}
+
+func (m *MySqlDB) DeletePollQuestions(pollid int64) (sql.Result, error) {
+	return m.db.Exec("DELETE FROM poll_questions WHERE pollid=?", pollid)
+}
+
+func (m *MySqlDB) CountSurveyBallots(pollid int64) *sql.Row {
+	return m.db.QueryRow("SELECT COUNT(*) FROM survey_ballots WHERE pollid=?", pollid)
+}
+
+func (m *MySqlDB) InsertSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO survey_ballots(pollid, userid) VALUES (?, ?)", pollid, userid)
+}
+
+// DeleteSurveyBallot removes the user's previous ballot together with its answers
+func (m *MySqlDB) DeleteSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	return m.db.Exec("DELETE FROM survey_ballots WHERE pollid=? AND userid=?", pollid, userid)
+}
+
+func (m *MySqlDB) InsertSurveyAnswer(answer databasestructs.SurveyAnswer) (sql.Result, error) {
+	return m.db.Exec("INSERT INTO survey_answers(ballotid, questionid, position, value, number) VALUES (?, ?, ?, ?, ?)", answer.BallotID, answer.QuestionID, answer.Position, answer.Value, answer.Number)
+}
+
+func (m *MySqlDB) GetSurveyAnswers(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, `SELECT sa.ballotid, sa.questionid, sa.position, sa.value, sa.number FROM survey_answers sa
+		INNER JOIN survey_ballots sb ON sb.id = sa.ballotid
+		WHERE sb.pollid=?
+		ORDER BY sa.questionid, sa.ballotid, sa.position`, pollid)
+}
@@ -53,7 +53,7 @@ func (m *MySqlDB) GetUserByUsername(username string) *sql.Row {
 }
 
 func (m *MySqlDB) GetUserByID(id int64) *sql.Row {
-	return m.db.QueryRow("SELECT username, email, profile_pic FROM users WHERE id=?", id)
+	return m.db.QueryRow("SELECT username, email, profile_pic, COALESCE(favorite_team, ''), COALESCE(region, '') FROM users WHERE id=?", id)
 }
 
 func (m *MySqlDB) GetUserRolesByID(id int64) *sql.Row {
@@ -77,7 +77,7 @@ func (m *MySqlDB) GetUserByRefreshToken(refresh_token string) *sql.Row {
 }
 
 func (m *MySqlDB) InsertNewUser(user databasestructs.User) (sql.Result, error) {
-	return m.db.Exec("INSERT INTO users(username, email, password, refresh_token) VALUES (?, ?, ?, ?)", user.Username, user.Email, user.Password, user.RefreshToken)
+	return m.db.Exec("INSERT INTO users(username, email, password, refresh_token, created_at, signup_ip) VALUES (?, ?, ?, ?, ?, ?)", user.Username, user.Email, user.Password, user.RefreshToken, user.CreatedAt, user.SignupIP)
 }
 
 func (m *MySqlDB) UpdateUserRefreshToken(username, refresh_token string) (sql.Result, error) {
@@ -96,6 +96,11 @@ func (m *MySqlDB) UpdateUserEmail(username, email string) (sql.Result, error) {
 	return m.db.Exec("UPDATE users SET email=? WHERE username=?", email, username)
 }
 
+// UpdateUserProfile sets the self-reported profile fields, empty ones are cleared
+func (m *MySqlDB) UpdateUserProfile(id int64, favoriteTeam, region string) (sql.Result, error) {
+	return m.db.Exec("UPDATE users SET favorite_team=NULLIF(?, ''), region=NULLIF(?, '') WHERE id=?", favoriteTeam, region, id)
+}
+
 func (m *MySqlDB) UpdateUserUsername(oldusername, username string) (sql.Result, error) {
 	return m.db.Exec("UPDATE users SET username=? WHERE username=?", username, oldusername)
 }
@@ -104,6 +109,7 @@ func (m *MySqlDB) UpdateUserProfilePic(username, profile_pic string) (sql.Result
 	return m.db.Exec("UPDATE users SET profile_pic=? WHERE username=?", profile_pic, username)
 }
 
-func (m *MySqlDB) GetVotesOfUser(ctx context.Context, userid int64) (*sql.Rows, error) {
-	return m.db.QueryContext(ctx, "SELECT po.id, COALESCE(v.playerid, v.goatplayerid) AS playerid, COALESCE(p.name, gp.name) AS player_name, po.name, po.image FROM player_votes v INNER JOIN polls po ON v.pollid = po.id LEFT JOIN players p ON v.playerid = p.playerid LEFT JOIN   goat_players gp ON v.goatplayerid = gp.playerid WHERE v.userid=?", userid)
+// GetVotesOfUser returns the votes of the user in the polls the viewer can see
+func (m *MySqlDB) GetVotesOfUser(ctx context.Context, userid, viewerid int64) (*sql.Rows, error) {
+	return m.db.QueryContext(ctx, "SELECT po.id, COALESCE(v.playerid, v.goatplayerid, CAST(v.candidateid AS CHAR)) AS playerid, COALESCE(p.name, gp.name, c.name) AS player_name, po.name, po.image FROM player_votes v INNER JOIN polls po ON v.pollid = po.id LEFT JOIN players p ON v.playerid = p.playerid LEFT JOIN   goat_players gp ON v.goatplayerid = gp.playerid LEFT JOIN poll_candidates c ON v.candidateid = c.id WHERE v.userid=? AND (po.groupid IS NULL OR po.groupid IN (SELECT groupid FROM group_members WHERE userid=?))", userid, viewerid)
 }
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) SelectPlayerGamesPlayed(season string) ([]databasestructs.PlayerStats, error) {
+	rows, err := r.Queries.SelectPlayerGamesPlayed(season)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerStats, error) {
+		stats := databasestructs.PlayerStats{Season: season}
+		err := row.Scan(&stats.PlayerID, &stats.Games)
+		return stats, err
+	})
+}
+
+// CheckPlayerExists reports whether the player is in the database
+func (r repository) CheckPlayerExists(playerid string) (bool, error) {
+	_, err := one(r.Queries.CheckPlayerExists(playerid), scanCount)
+	if err == ErrNotFound {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// GetPlayersForSearch returns the players of the season and the GOAT candidates, active players show up in both
+func (r repository) GetPlayersForSearch(ctx context.Context, season string) ([]databasestructs.PlayerSearchResult, error) {
+	rows, err := r.Queries.GetPlayersForSearch(ctx, season)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerSearchResult, error) {
+		var player databasestructs.PlayerSearchResult
+		err := row.Scan(&player.ID, &player.Name, &player.Minutes, &player.IsGoat)
+		return player, err
+	})
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) GetPolls(ctx context.Context, userid int64) ([]databasestructs.Poll, error) {
+	rows, err := r.Queries.GetPolls(ctx, userid)
+	return collect(rows, err, scanPoll)
+}
+
+func (r repository) GetPollByID(id int64) (databasestructs.Poll, error) {
+	return one(r.Queries.GetPollByID(id), func(row scanner) (databasestructs.Poll, error) {
+		poll := databasestructs.Poll{ID: id}
+		var image sql.NullString
+		err := row.Scan(&poll.Name, &poll.Description, &image, &poll.SelectedStats, &poll.Season, &poll.UserID, &poll.ScoreFormula, &poll.AllowWriteIns, &poll.GroupID, &poll.PanelWeight, &poll.AllowGuests)
+		poll.Image = image.String
+		return poll, err
+	})
+}
+
+func (r repository) GetPollByUserID(userid, viewerid int64) ([]databasestructs.Poll, error) {
+	rows, err := r.Queries.GetPollByUserID(userid, viewerid)
+	return collect(rows, err, func(row scanner) (databasestructs.Poll, error) {
+		poll := databasestructs.Poll{UserID: userid}
+		var image sql.NullString
+		err := row.Scan(&poll.ID, &poll.Name, &poll.Description, &image, &poll.SelectedStats, &poll.Season, &poll.ScoreFormula, &poll.AllowWriteIns, &poll.GroupID, &poll.PanelWeight, &poll.AllowGuests)
+		poll.Image = image.String
+		return poll, err
+	})
+}
+
+// ResetPollVotes deletes the votes together with writing their reset events
+func (r repository) ResetPollVotes(pollid int64) (sql.Result, error) {
+	return r.execInTransaction(func(tx Queries) (sql.Result, error) {
+		return tx.ResetPollVotes(pollid)
+	})
+}
+
+// InsertPlayerVotes swaps the earlier vote for the new one together with writing the event, a failed insert keeps the earlier vote
+func (r repository) InsertPlayerVotes(pollid, userid int64, playerid, ip string) (sql.Result, error) {
+	return r.execInTransaction(func(tx Queries) (sql.Result, error) {
+		return tx.InsertPlayerVotes(pollid, userid, playerid, ip)
+	})
+}
+
+func (r repository) SelectSeasonsAvailable() ([]string, error) {
+	rows, err := r.Queries.SelectSeasonsAvailable()
+	return collect(rows, err, scanString)
+}
+
+func (r repository) SelectSeasonsForNonGOATStats() ([]string, error) {
+	rows, err := r.Queries.SelectSeasonsForNonGOATStats()
+	return collect(rows, err, scanString)
+}
+
+func (r repository) GetPlayerStatsForPoll(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetPlayerStatsForPoll(ctx, season)
+	return collect(rows, err, scanPollPlayer)
+}
+
+func (r repository) GetPlayerPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error) {
+	rows, err := r.Queries.GetPlayerPollVotes(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.VoteTally, error) {
+		var tally databasestructs.VoteTally
+		err := row.Scan(&tally.Name, &tally.Votes, &tally.PollName)
+		return tally, err
+	})
+}
+
+func (r repository) GetPanelPollVotes(ctx context.Context, pollid int64) ([]databasestructs.PanelVoteTally, error) {
+	rows, err := r.Queries.GetPanelPollVotes(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.PanelVoteTally, error) {
+		var tally databasestructs.PanelVoteTally
+		err := row.Scan(&tally.Name, &tally.IsPanelist, &tally.Votes)
+		return tally, err
+	})
+}
+
+func (r repository) GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) ([]databasestructs.TimelineBucket, error) {
+	rows, err := r.Queries.GetPollVoteTimeline(ctx, pollid, bucketSeconds)
+	return collect(rows, err, func(row scanner) (databasestructs.TimelineBucket, error) {
+		var bucket databasestructs.TimelineBucket
+		err := row.Scan(&bucket.Start, &bucket.Name, &bucket.Votes)
+		return bucket, err
+	})
+}
+
+func (r repository) GetPollVotesBySegment(ctx context.Context, pollid int64, segment string) ([]databasestructs.SegmentVoteTally, error) {
+	rows, err := r.Queries.GetPollVotesBySegment(ctx, pollid, segment)
+	return collect(rows, err, func(row scanner) (databasestructs.SegmentVoteTally, error) {
+		var tally databasestructs.SegmentVoteTally
+		err := row.Scan(&tally.Segment, &tally.Name, &tally.Votes)
+		return tally, err
+	})
+}
+
+func (r repository) GetTeamPollVotes(ctx context.Context, pollid int64) ([]databasestructs.VoteTally, error) {
+	rows, err := r.Queries.GetTeamPollVotes(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.VoteTally, error) {
+		var tally databasestructs.VoteTally
+		err := row.Scan(&tally.Name, &tally.Votes)
+		return tally, err
+	})
+}
+
+func scanPoll(row scanner) (databasestructs.Poll, error) {
+	var poll databasestructs.Poll
+	var image sql.NullString
+	err := row.Scan(&poll.ID, &poll.Name, &poll.Description, &image, &poll.SelectedStats, &poll.Season, &poll.UserID, &poll.ScoreFormula, &poll.AllowWriteIns, &poll.GroupID, &poll.PanelWeight, &poll.AllowGuests)
+	poll.Image = image.String
+	return poll, err
+}
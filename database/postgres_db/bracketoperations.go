@@ -0,0 +1,61 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (p *PostgresDB) InsertBracket(bracket databasestructs.Bracket) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO brackets(pollid, size, round_hours, current_round, round_ends_at) VALUES ($1, $2, $3, $4, $5)", bracket.PollID, bracket.Size, bracket.RoundHours, bracket.CurrentRound, bracket.RoundEndsAt)
+}
+
+func (p *PostgresDB) GetBracket(pollid int64) *sql.Row {
+	return p.db.QueryRow("SELECT pollid, size, round_hours, current_round, round_ends_at, COALESCE(championid, '') FROM brackets WHERE pollid=$1", pollid)
+}
+
+// GetDueBrackets returns the unfinished brackets whose current round closed before now
+func (p *PostgresDB) GetDueBrackets(ctx context.Context, now int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT pollid, size, round_hours, current_round, round_ends_at FROM brackets WHERE championid IS NULL AND round_ends_at <= $1", now)
+}
+
+// AdvanceBracketRound only moves the bracket forward from the previous round, so a round can't be opened twice
+func (p *PostgresDB) AdvanceBracketRound(pollid, round, roundEndsAt int64) (sql.Result, error) {
+	return p.db.Exec("UPDATE brackets SET current_round=$1, round_ends_at=$2 WHERE pollid=$3 AND current_round=$4", round, roundEndsAt, pollid, round-1)
+}
+
+func (p *PostgresDB) SetBracketChampion(pollid int64, championid string) (sql.Result, error) {
+	return p.db.Exec("UPDATE brackets SET championid=$1 WHERE pollid=$2", championid, pollid)
+}
+
+func (p *PostgresDB) InsertBracketMatchup(matchup databasestructs.BracketMatchup) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO bracket_matchups(pollid, round, position, seed1, player1id, seed2, player2id) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING", matchup.PollID, matchup.Round, matchup.Position, matchup.Seed1, matchup.Player1ID, matchup.Seed2, matchup.Player2ID)
+}
+
+func (p *PostgresDB) GetBracketMatchup(id int64) *sql.Row {
+	return p.db.QueryRow("SELECT id, pollid, round, position, seed1, player1id, seed2, player2id, COALESCE(winnerid, '') FROM bracket_matchups WHERE id=$1", id)
+}
+
+// GetBracketMatchups returns every matchup of the bracket with the player names and the vote tally of both sides
+func (p *PostgresDB) GetBracketMatchups(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT bm.id, bm.round, bm.position, bm.seed1, bm.player1id, COALESCE(g1.name, p1.name, ''), bm.seed2, bm.player2id, COALESCE(g2.name, p2.name, ''), COALESCE(bm.winnerid, ''),
+		COUNT(bv.playerid) FILTER (WHERE bv.playerid = bm.player1id), COUNT(bv.playerid) FILTER (WHERE bv.playerid = bm.player2id)
+		FROM bracket_matchups bm
+		LEFT JOIN goat_players g1 ON g1.playerid = bm.player1id
+		LEFT JOIN players p1 ON p1.playerid = bm.player1id
+		LEFT JOIN goat_players g2 ON g2.playerid = bm.player2id
+		LEFT JOIN players p2 ON p2.playerid = bm.player2id
+		LEFT JOIN bracket_votes bv ON bv.matchupid = bm.id
+		WHERE bm.pollid=$1
+		GROUP BY bm.id, bm.round, bm.position, bm.seed1, bm.player1id, g1.name, p1.name, bm.seed2, bm.player2id, g2.name, p2.name, bm.winnerid
+		ORDER BY bm.round, bm.position`, pollid)
+}
+
+func (p *PostgresDB) SetBracketMatchupWinner(id int64, winnerid string) (sql.Result, error) {
+	return p.db.Exec("UPDATE bracket_matchups SET winnerid=$1 WHERE id=$2", winnerid, id)
+}
+
+// InsertBracketVote lets the user switch their pick while the matchup is open
+func (p *PostgresDB) InsertBracketVote(vote databasestructs.BracketVote) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO bracket_votes(matchupid, userid, playerid) VALUES ($1, $2, $3) ON CONFLICT (matchupid, userid) DO UPDATE SET playerid=EXCLUDED.playerid", vote.MatchupID, vote.UserID, vote.PlayerID)
+}
@@ -0,0 +1,74 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+	"strconv"
+)
+
+func (p *PostgresDB) InsertPollCandidate(candidate databasestructs.PollCandidate, nameKey string) (sql.Result, error) {
+	var playerID interface{}
+	if candidate.PlayerID != "" {
+		playerID = candidate.PlayerID
+	}
+
+	var submittedBy interface{}
+	if candidate.SubmittedBy != 0 {
+		submittedBy = candidate.SubmittedBy
+	}
+
+	return p.insertReturningID("INSERT INTO poll_candidates(pollid, playerid, name, name_key, status, is_write_in, submitted_by) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		candidate.PollID, playerID, candidate.Name, nameKey, candidate.Status, candidate.IsWriteIn, submittedBy)
+}
+
+func (p *PostgresDB) GetPollCandidates(ctx context.Context, pollid int64, status string) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE pollid=$1 AND status=$2 ORDER BY is_write_in, name", pollid, status)
+}
+
+func (p *PostgresDB) GetPollCandidateByID(id int64) *sql.Row {
+	return p.db.QueryRow("SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE id=$1", id)
+}
+
+func (p *PostgresDB) GetPollCandidateByKey(pollid int64, nameKey string) *sql.Row {
+	return p.db.QueryRow("SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE pollid=$1 AND name_key=$2", pollid, nameKey)
+}
+
+func (p *PostgresDB) UpdatePollCandidateStatus(id int64, status string) (sql.Result, error) {
+	return p.db.Exec("UPDATE poll_candidates SET status=$1 WHERE id=$2", status, id)
+}
+
+func (p *PostgresDB) CountUserWriteIns(pollid, userid int64) *sql.Row {
+	return p.db.QueryRow("SELECT COUNT(*) FROM poll_candidates WHERE pollid=$1 AND submitted_by=$2 AND is_write_in=true", pollid, userid)
+}
+
+// InsertCandidateVote replaces whatever the user voted for in the poll before
+func (p *PostgresDB) InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error) {
+	choice := strconv.FormatInt(candidateid, 10)
+	event := "cast"
+
+	var previous string
+	var switches int64
+	err := p.db.QueryRow("SELECT "+voteChoice+", switches FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid).Scan(&previous, &switches)
+	if err == nil {
+		if previous == choice {
+			return nil, nil
+		}
+		event = "changed"
+		switches++
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	_, err = p.db.Exec("DELETE FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.db.Exec("INSERT INTO player_votes(candidateid, pollid, userid, votes_for, created_at, ip, switches) VALUES ($1, $2, $3, 1, "+unixNow+", $4, $5)", candidateid, pollid, userid, ip, switches)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, p.insertVoteEvent(pollid, userid, event, choice, previous)
+}
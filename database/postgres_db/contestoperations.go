@@ -0,0 +1,69 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (p *PostgresDB) InsertContest(contest databasestructs.PredictionContest) (sql.Result, error) {
+	return p.insertReturningID("INSERT INTO prediction_contests(name, description, season, deadline, userid) VALUES ($1, $2, $3, $4, $5) RETURNING id", contest.Name, contest.Description, contest.Season, contest.Deadline, contest.UserID)
+}
+
+func (p *PostgresDB) GetContests(ctx context.Context) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, name, description, season, deadline, resolved, userid FROM prediction_contests ORDER BY deadline DESC")
+}
+
+func (p *PostgresDB) GetContestByID(id int64) *sql.Row {
+	return p.db.QueryRow("SELECT id, name, description, season, deadline, resolved, userid FROM prediction_contests WHERE id=$1", id)
+}
+
+// GetUnresolvedContests returns the contests that are locked but not graded yet
+func (p *PostgresDB) GetUnresolvedContests(ctx context.Context, now int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, name, description, season, deadline, resolved, userid FROM prediction_contests WHERE resolved=false AND deadline <= $1", now)
+}
+
+func (p *PostgresDB) SetContestResolved(id int64) (sql.Result, error) {
+	return p.db.Exec("UPDATE prediction_contests SET resolved=true WHERE id=$1", id)
+}
+
+func (p *PostgresDB) InsertContestQuestion(question databasestructs.PredictionQuestion) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO prediction_questions(contestid, position, prompt, type, target, points) VALUES ($1, $2, $3, $4, $5, $6)", question.ContestID, question.Position, question.Prompt, question.Type, question.Target, question.Points)
+}
+
+func (p *PostgresDB) GetContestQuestions(ctx context.Context, contestid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, contestid, position, prompt, type, target, points, answer, answer_number FROM prediction_questions WHERE contestid=$1 ORDER BY position", contestid)
+}
+
+func (p *PostgresDB) ResolveContestQuestion(id int64, answer string, answerNumber *float64) (sql.Result, error) {
+	return p.db.Exec("UPDATE prediction_questions SET answer=$1, answer_number=$2 WHERE id=$3", answer, answerNumber, id)
+}
+
+// InsertPrediction overwrites an earlier prediction of the user for the same question
+func (p *PostgresDB) InsertPrediction(prediction databasestructs.Prediction) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO predictions(questionid, userid, value, number) VALUES ($1, $2, $3, $4) ON CONFLICT (questionid, userid) DO UPDATE SET value=EXCLUDED.value, number=EXCLUDED.number",
+		prediction.QuestionID, prediction.UserID, prediction.Value, prediction.Number)
+}
+
+func (p *PostgresDB) GetQuestionPredictions(ctx context.Context, questionid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT questionid, userid, value, number, score FROM predictions WHERE questionid=$1", questionid)
+}
+
+func (p *PostgresDB) GetUserPredictions(ctx context.Context, contestid, userid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT p.questionid, p.userid, p.value, p.number, p.score FROM predictions p
+		INNER JOIN prediction_questions q ON q.id = p.questionid
+		WHERE q.contestid=$1 AND p.userid=$2 ORDER BY q.position`, contestid, userid)
+}
+
+func (p *PostgresDB) SetPredictionScore(questionid, userid int64, score float64) (sql.Result, error) {
+	return p.db.Exec("UPDATE predictions SET score=$1 WHERE questionid=$2 AND userid=$3", score, questionid, userid)
+}
+
+func (p *PostgresDB) GetContestLeaderboard(ctx context.Context, contestid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT u.id, u.username, COALESCE(SUM(p.score), 0) AS total, COUNT(p.score) FROM predictions p
+		INNER JOIN prediction_questions q ON q.id = p.questionid
+		INNER JOIN users u ON u.id = p.userid
+		WHERE q.contestid=$1
+		GROUP BY u.id, u.username
+		ORDER BY total DESC, u.username`, contestid)
+}
@@ -0,0 +1,47 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertGuestVote replaces the earlier vote of the device in the poll
+func (p *PostgresDB) InsertGuestVote(vote databasestructs.GuestVote) (sql.Result, error) {
+	var playerID interface{}
+	if vote.PlayerID != "" {
+		playerID = vote.PlayerID
+	}
+
+	var candidateID interface{}
+	if vote.CandidateID != 0 {
+		candidateID = vote.CandidateID
+	}
+
+	return p.db.Exec(`INSERT INTO guest_votes(pollid, deviceid, ip, playerid, candidateid, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (pollid, deviceid) DO UPDATE SET ip=EXCLUDED.ip, playerid=EXCLUDED.playerid, candidateid=EXCLUDED.candidateid, created_at=EXCLUDED.created_at`,
+		vote.PollID, vote.DeviceID, vote.IP, playerID, candidateID, vote.CreatedAt)
+}
+
+func (p *PostgresDB) CountGuestVotesByIP(pollid int64, ip string) *sql.Row {
+	return p.db.QueryRow("SELECT COUNT(*) FROM guest_votes WHERE pollid=$1 AND ip=$2", pollid, ip)
+}
+
+func (p *PostgresDB) GetGuestDeviceVote(pollid int64, deviceid string) *sql.Row {
+	return p.db.QueryRow("SELECT COUNT(*) FROM guest_votes WHERE pollid=$1 AND deviceid=$2", pollid, deviceid)
+}
+
+func (p *PostgresDB) GetGuestPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT COALESCE(p.name, gp.name, c.name) AS player_name, COUNT(*) AS votes_for
+		FROM guest_votes g
+		LEFT JOIN players p ON g.playerid = p.playerid
+		LEFT JOIN goat_players gp ON g.playerid = gp.playerid
+		LEFT JOIN poll_candidates c ON g.candidateid = c.id
+		WHERE g.pollid=$1
+		GROUP BY player_name
+		ORDER BY votes_for DESC`, pollid)
+}
+
+func (p *PostgresDB) ResetGuestVotes(pollid int64) (sql.Result, error) {
+	return p.db.Exec("DELETE FROM guest_votes WHERE pollid=$1", pollid)
+}
@@ -0,0 +1,53 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+const initialMatchupRating = 1500
+
+func (p *PostgresDB) InsertMatchupVote(vote databasestructs.MatchupVote) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO matchup_votes(pollid, userid, winnerid, loserid, rating_change) VALUES ($1, $2, $3, $4, $5)", vote.PollID, vote.UserID, vote.WinnerID, vote.LoserID, vote.RatingChange)
+}
+
+// UpdateMatchupRating applies the change on top of the stored rating, so concurrent votes don't overwrite each other
+func (p *PostgresDB) UpdateMatchupRating(pollid int64, playerid string, change float64, won bool) (sql.Result, error) {
+	wins := 0
+	if won {
+		wins = 1
+	}
+
+	return p.db.Exec("INSERT INTO matchup_ratings(pollid, playerid, rating, matches, wins) VALUES ($1, $2, $3, 1, $4) ON CONFLICT (pollid, playerid) DO UPDATE SET rating=matchup_ratings.rating+$5, matches=matchup_ratings.matches+1, wins=matchup_ratings.wins+$4", pollid, playerid, initialMatchupRating+change, wins, change)
+}
+
+func (p *PostgresDB) GetMatchupRatings(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT playerid, rating, matches, wins FROM matchup_ratings WHERE pollid=$1", pollid)
+}
+
+func (p *PostgresDB) GetMatchupVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, winnerid, loserid, rating_change FROM matchup_votes WHERE pollid=$1 ORDER BY id", pollid)
+}
+
+func (p *PostgresDB) GetUserMatchupVotes(ctx context.Context, pollid, userid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT winnerid, loserid FROM matchup_votes WHERE pollid=$1 AND userid=$2", pollid, userid)
+}
+
+func (p *PostgresDB) ResetMatchups(pollid int64) error {
+	_, err := p.db.Exec("DELETE FROM matchup_votes WHERE pollid=$1", pollid)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec("DELETE FROM matchup_ratings WHERE pollid=$1", pollid)
+	return err
+}
+
+func (p *PostgresDB) GetPollsWithoutMatchupRatings(ctx context.Context) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT DISTINCT pollid FROM matchup_votes v WHERE NOT EXISTS (SELECT 1 FROM matchup_ratings r WHERE r.pollid = v.pollid) ORDER BY pollid")
+}
+
+func (p *PostgresDB) SetMatchupVoteChange(id int64, change float64) (sql.Result, error) {
+	return p.db.Exec("UPDATE matchup_votes SET rating_change=$1 WHERE id=$2", change, id)
+}
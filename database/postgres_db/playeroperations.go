@@ -0,0 +1,34 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (p *PostgresDB) InsertPlayer(info databasestructs.PlayerInfo) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO players(playerid, name, college, teamabbr, height, weight, age) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING", info.ID, info.Name, info.College, info.TeamAbbr, info.Height, info.Weight, info.Age)
+}
+
+func (p *PostgresDB) UpdatePlayerAge(playerid string, age int64) (sql.Result, error) {
+	return p.db.Exec("UPDATE players set age=$1 WHERE playerid=$2", age, playerid)
+}
+
+func (p *PostgresDB) SelectPlayerGamesPlayed(season string) (*sql.Rows, error) {
+	return p.db.Query("SELECT playerid, gamesplayed FROM stats WHERE season=$1", season)
+}
+
+func (p *PostgresDB) CheckPlayerExists(playerid string) *sql.Row {
+	return p.db.QueryRow("SELECT 1 FROM players WHERE playerid=$1", playerid)
+}
+
+func (p *PostgresDB) GetPlayersForSearch(ctx context.Context, season string) (*sql.Rows, error) {
+	query := `
+        SELECT p.playerid, p.name, COALESCE(s.minutespergame, 0), false AS isgoat
+        FROM players p
+        LEFT JOIN stats s ON p.playerid = s.playerid AND s.season = $1
+        UNION ALL
+        SELECT g.playerid, g.name, 0, true AS isgoat
+        FROM goat_players g`
+	return p.db.QueryContext(ctx, query, season)
+}
@@ -0,0 +1,221 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sportsvoting/databasestructs"
+	"strings"
+)
+
+// unix time of the current transaction, what UNIX_TIMESTAMP() is in mysql
+const unixNow = "CAST(EXTRACT(EPOCH FROM NOW()) AS INT)"
+
+func (p *PostgresDB) GetPlayerStatsForPoll(ctx context.Context, season string) (*sql.Rows, error) {
+	query := `
+        SELECT players.playerid, name, gamesplayed, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE advancedstats.season = $1 AND stats.season = $1 AND minutespergame > 20
+        ORDER BY per DESC`
+	return p.db.QueryContext(ctx, query, season)
+}
+
+// GetPolls returns the public polls and the polls of the groups the user is in
+func (p *PostgresDB) GetPolls(ctx context.Context, userid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE groupid IS NULL OR groupid IN (SELECT groupid FROM group_members WHERE userid=$1)", userid)
+}
+
+func (p *PostgresDB) GetPollByID(id int64) *sql.Row {
+	return p.db.QueryRow("SELECT name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE id=$1", id)
+}
+
+// GetPollByUserID returns the polls the user created that the viewer can see
+func (p *PostgresDB) GetPollByUserID(userid, viewerid int64) (*sql.Rows, error) {
+	return p.db.Query("SELECT id, name, description, image, selected_stats, season, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE userid=$1 AND (groupid IS NULL OR groupid IN (SELECT groupid FROM group_members WHERE userid=$2))", userid, viewerid)
+}
+
+func (p *PostgresDB) InsertPolls(poll databasestructs.Poll) (sql.Result, error) {
+	return p.insertReturningID("INSERT INTO polls(name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, groupid, panel_weight, allow_guests) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT DO NOTHING RETURNING id", poll.Name, poll.Description, poll.Image, poll.SelectedStats, poll.Season, poll.UserID, poll.ScoreFormula, poll.AllowWriteIns, pollGroupID(poll), poll.PanelWeight, poll.AllowGuests)
+}
+
+func (p *PostgresDB) InsertPollsWithId(poll databasestructs.Poll) (sql.Result, error) {
+	res, err := p.db.Exec("INSERT INTO polls(id, name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, groupid, panel_weight, allow_guests) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT DO NOTHING", poll.ID, poll.Name, poll.Description, poll.Image, poll.SelectedStats, poll.Season, poll.UserID, poll.ScoreFormula, poll.AllowWriteIns, pollGroupID(poll), poll.PanelWeight, poll.AllowGuests)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, p.syncSequence("polls")
+}
+
+// pollGroupID stores public polls without a group
+func pollGroupID(poll databasestructs.Poll) interface{} {
+	if poll.GroupID == 0 {
+		return nil
+	}
+	return poll.GroupID
+}
+
+// leaves out the ballots admins excluded from the official result after a fraud review
+const excludedVotesFilter = " AND NOT EXISTS (SELECT 1 FROM vote_flags f WHERE f.pollid = v.pollid AND f.userid = v.userid AND f.status = 'excluded')"
+
+// votes for curated candidates and write-ins, which are counted next to the stat based candidates
+const candidateVotesQuery = "SELECT c.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN poll_candidates c ON v.candidateid=c.id INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=$1" + excludedVotesFilter + " GROUP BY c.name, po.name"
+
+// whatever the vote is for, as text like the choices in the vote history
+const voteChoice = "COALESCE(playerid, goatplayerid, CAST(candidateid AS TEXT), '')"
+
+func (p *PostgresDB) GetPlayerPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	var stats string
+	err := p.db.QueryRow("SELECT selected_stats FROM polls WHERE id=$1", pollid).Scan(&stats)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(stats, "GOAT") {
+		return p.db.QueryContext(ctx, "SELECT p.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN goat_players p ON v.goatplayerid=p.playerid INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=$1"+excludedVotesFilter+" GROUP BY p.name, po.name UNION ALL "+candidateVotesQuery+" ORDER BY votes_for DESC", pollid)
+	}
+
+	return p.db.QueryContext(ctx, "SELECT p.name, COUNT(v.votes_for) as votes_for, po.name FROM player_votes v INNER JOIN players p ON v.playerid=p.playerid INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=$1"+excludedVotesFilter+" GROUP BY p.name, po.name UNION ALL "+candidateVotesQuery+" ORDER BY votes_for DESC", pollid)
+}
+
+// GetPanelPollVotes tallies the votes of the poll per candidate, split between panelists and fans
+func (p *PostgresDB) GetPanelPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT COALESCE(p.name, gp.name, c.name) AS player_name, 'panelist' = ANY(string_to_array(COALESCE(r.role, ''), ',')) AS is_panelist, COUNT(v.votes_for) AS votes_for
+		FROM player_votes v
+		LEFT JOIN players p ON v.playerid = p.playerid
+		LEFT JOIN goat_players gp ON v.goatplayerid = gp.playerid
+		LEFT JOIN poll_candidates c ON v.candidateid = c.id
+		LEFT JOIN user_roles r ON r.user_id = v.userid
+		WHERE v.pollid=$1`+excludedVotesFilter+`
+		GROUP BY player_name, is_panelist
+		ORDER BY votes_for DESC`, pollid)
+}
+
+// GetPollVoteTimeline replays the vote history of the poll, per time bucket every candidate gains the votes cast or changed to them
+// and loses the ones changed away, retracted or reset, votes from before the history was kept aren't in it
+func (p *PostgresDB) GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT v.created_at - MOD(v.created_at, $1) AS bucket, COALESCE(p.name, gp.name, c.name) AS player_name, SUM(v.delta) AS votes_for
+		FROM (
+			SELECT pollid, userid, created_at, choice AS pick, 1 AS delta FROM vote_events WHERE pollid=$2 AND choice IS NOT NULL
+			UNION ALL
+			SELECT pollid, userid, created_at, previous AS pick, -1 AS delta FROM vote_events WHERE pollid=$2 AND previous IS NOT NULL
+		) v
+		LEFT JOIN players p ON v.pick = p.playerid
+		LEFT JOIN goat_players gp ON v.pick = gp.playerid
+		LEFT JOIN poll_candidates c ON c.pollid = v.pollid AND CAST(c.id AS TEXT) = v.pick
+		WHERE v.pollid=$2`+excludedVotesFilter+`
+		GROUP BY bucket, player_name
+		HAVING COALESCE(p.name, gp.name, c.name) IS NOT NULL
+		ORDER BY bucket`, bucketSeconds, pollid)
+}
+
+// voter segments results can be broken down by, account age is taken at the time of the vote
+var segmentColumns = map[string]string{
+	"team":        "COALESCE(u.favorite_team, '')",
+	"region":      "COALESCE(u.region, '')",
+	"account_age": "CASE WHEN u.created_at = 0 OR v.created_at = 0 THEN '' WHEN v.created_at - u.created_at < 30*86400 THEN 'under 30 days' WHEN v.created_at - u.created_at < 365*86400 THEN '30 days to 1 year' ELSE 'over 1 year' END",
+	"panel":       "CASE WHEN 'panelist' = ANY(string_to_array(COALESCE(r.role, ''), ',')) THEN 'panel' ELSE 'fans' END",
+}
+
+// GetPollVotesBySegment tallies the votes of the poll per candidate within each value of the voter segment, unknown values are empty
+func (p *PostgresDB) GetPollVotesBySegment(ctx context.Context, pollid int64, segment string) (*sql.Rows, error) {
+	column, ok := segmentColumns[segment]
+	if !ok {
+		return nil, fmt.Errorf("unknown segment %q", segment)
+	}
+
+	return p.db.QueryContext(ctx, `SELECT `+column+` AS segment, COALESCE(p.name, gp.name, c.name) AS player_name, COUNT(v.votes_for) AS votes_for
+		FROM player_votes v
+		INNER JOIN users u ON u.id = v.userid
+		LEFT JOIN user_roles r ON r.user_id = v.userid
+		LEFT JOIN players p ON v.playerid = p.playerid
+		LEFT JOIN goat_players gp ON v.goatplayerid = gp.playerid
+		LEFT JOIN poll_candidates c ON v.candidateid = c.id
+		WHERE v.pollid=$1`+excludedVotesFilter+`
+		GROUP BY segment, player_name
+		HAVING COALESCE(p.name, gp.name, c.name) IS NOT NULL
+		ORDER BY segment, votes_for DESC`, pollid)
+}
+
+// InsertPlayerVotes replaces the user's earlier vote in the poll, counting how often they switched for the fraud checks
+func (p *PostgresDB) InsertPlayerVotes(pollid, userid int64, playerid, ip string) (sql.Result, error) {
+	var id, switches int64
+	var playerIdDB string
+
+	var stats string
+	err := p.db.QueryRow("SELECT selected_stats FROM polls WHERE id=$1", pollid).Scan(&stats)
+	if err != nil {
+		return nil, err
+	}
+
+	column := "playerid"
+	if strings.Contains(stats, "GOAT") {
+		column = "goatplayerid"
+	}
+
+	// a plain insert, skipping a failed one would keep the event and, when changing the vote, drop the earlier vote
+	insert := "INSERT INTO player_votes(" + column + ", pollid, userid, votes_for, created_at, ip, switches) VALUES ($1, $2, $3, 1, " + unixNow + ", $4, $5)"
+	var previous string
+	rows := p.db.QueryRow("SELECT id, COALESCE("+column+", ''), "+voteChoice+", switches FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid)
+	err = rows.Scan(&id, &playerIdDB, &previous, &switches)
+	if err == sql.ErrNoRows {
+		res, err := p.db.Exec(insert, playerid, pollid, userid, ip, 0)
+		if err != nil {
+			return nil, err
+		}
+		return res, p.insertVoteEvent(pollid, userid, "cast", playerid, "")
+	} else if err != nil {
+		return nil, err
+	} else if playerIdDB != playerid {
+		if _, err := p.db.Exec("DELETE FROM player_votes WHERE id=$1", id); err != nil {
+			return nil, err
+		}
+		res, err := p.db.Exec(insert, playerid, pollid, userid, ip, switches+1)
+		if err != nil {
+			return nil, err
+		}
+		return res, p.insertVoteEvent(pollid, userid, "changed", playerid, previous)
+	}
+
+	return nil, nil
+}
+
+func (p *PostgresDB) DeletePollByID(pollid int64) (sql.Result, error) {
+	return p.db.Exec("DELETE FROM polls WHERE id=$1", pollid)
+}
+
+func (p *PostgresDB) UpdatePollByID(poll databasestructs.Poll) (sql.Result, error) {
+	return p.db.Exec("UPDATE polls SET name=$1, description=$2, selected_stats=$3, season=$4, score_formula=$5, allow_write_ins=$6, panel_weight=$7, allow_guests=$8 WHERE id=$9", poll.Name, poll.Description, poll.SelectedStats, poll.Season, poll.ScoreFormula, poll.AllowWriteIns, poll.PanelWeight, poll.AllowGuests, poll.ID)
+}
+
+// ResetPollVotes removes every vote of the poll, the vote history keeps a reset event for each of them
+func (p *PostgresDB) ResetPollVotes(pollid int64) (sql.Result, error) {
+	_, err := p.db.Exec("INSERT INTO vote_events(pollid, userid, event, previous, created_at) SELECT pollid, userid, 'reset', COALESCE(playerid, goatplayerid, CAST(candidateid AS TEXT)), "+unixNow+" FROM player_votes WHERE pollid=$1", pollid)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.db.Exec("DELETE FROM player_votes WHERE pollid=$1", pollid)
+}
+
+func (p *PostgresDB) GetTeamPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT t.name, v.votes_for FROM team_votes v INNER JOIN teams t ON v.teamabbr=t.teamabbr INNER JOIN polls po ON v.pollid=po.id WHERE v.pollid=$1 ORDER BY v.votes_for DESC", pollid)
+}
+
+func (p *PostgresDB) UpdatePollImage(image databasestructs.Image) (sql.Result, error) {
+	return p.db.Exec("UPDATE polls SET image=$1 WHERE id=$2", image.ImageURL, image.ID)
+}
+
+func (p *PostgresDB) InsertSeasonEntered(season string) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO seasons_entered(season) VALUES ($1) ON CONFLICT DO NOTHING", season)
+}
+
+func (p *PostgresDB) SelectSeasonsAvailable() (*sql.Rows, error) {
+	return p.db.Query("SELECT season FROM seasons_entered")
+}
+
+func (p *PostgresDB) SelectSeasonsForNonGOATStats() (*sql.Rows, error) {
+	return p.db.Query("SELECT season FROM seasons_entered WHERE season NOT IN ('All', 'Playoffs', 'Career')")
+}
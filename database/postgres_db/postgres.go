@@ -0,0 +1,98 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+type PostgresDB struct {
+	conn *sql.DB
+	// db runs the statements, the connection pool or a transaction on it
+	db querier
+}
+
+// querier is what *sql.DB and *sql.Tx have in common, so the statements run the same in a transaction
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func NewDB(dbname string, addr string) (*PostgresDB, error) {
+	sslMode := os.Getenv("DBSSLMODE")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(os.Getenv("DBUSER"), os.Getenv("DBPASS")),
+		Host:     addr,
+		Path:     dbname,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		return nil, err
+	}
+
+	pingErr := db.Ping()
+	if pingErr != nil {
+		return nil, pingErr
+	}
+
+	return &PostgresDB{conn: db, db: db}, nil
+}
+
+func (p *PostgresDB) CloseConnection() {
+	p.conn.Close()
+}
+
+func (p *PostgresDB) GetDB() *sql.DB {
+	return p.conn
+}
+
+// InTx returns a copy of the database that runs its statements in the transaction
+func (p *PostgresDB) InTx(tx *sql.Tx) *PostgresDB {
+	return &PostgresDB{conn: p.conn, db: tx}
+}
+
+// insertResult carries the id from a RETURNING clause, since the postgres driver doesn't support LastInsertId
+type insertResult struct {
+	id       int64
+	affected int64
+}
+
+func (r insertResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r insertResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// insertReturningID runs an insert ending in RETURNING id, an insert that was skipped by ON CONFLICT DO NOTHING affects no rows
+func (p *PostgresDB) insertReturningID(query string, args ...interface{}) (sql.Result, error) {
+	var id int64
+	err := p.db.QueryRow(query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return insertResult{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return insertResult{id: id, affected: 1}, nil
+}
+
+// syncSequence moves the id sequence of the table past rows that were inserted with an explicit id
+func (p *PostgresDB) syncSequence(table string) error {
+	_, err := p.db.Exec("SELECT setval(pg_get_serial_sequence($1, 'id'), COALESCE(MAX(id), 1)) FROM "+table, table)
+	return err
+}
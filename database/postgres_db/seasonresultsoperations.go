@@ -0,0 +1,24 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (p *PostgresDB) InsertTeamStanding(standing databasestructs.TeamStanding) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO team_standings(season, teamabbr, wins, losses) VALUES ($1, $2, $3, $4) ON CONFLICT (season, teamabbr) DO UPDATE SET wins=EXCLUDED.wins, losses=EXCLUDED.losses",
+		standing.Season, standing.TeamAbbr, standing.Wins, standing.Losses)
+}
+
+func (p *PostgresDB) GetTeamStandings(ctx context.Context, season string) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT season, teamabbr, wins, losses FROM team_standings WHERE season=$1", season)
+}
+
+func (p *PostgresDB) InsertSeasonAward(award databasestructs.SeasonAward) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO season_awards(season, award, playerid) VALUES ($1, $2, $3) ON CONFLICT (season, award) DO UPDATE SET playerid=EXCLUDED.playerid", award.Season, award.Award, award.PlayerID)
+}
+
+func (p *PostgresDB) GetSeasonAwards(ctx context.Context, season string) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT season, award, playerid FROM season_awards WHERE season=$1", season)
+}
@@ -0,0 +1,44 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (p *PostgresDB) InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO poll_questions(pollid, position, prompt, type, picks, min_value, max_value) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		question.PollID, question.Position, question.Prompt, question.Type, question.Picks, question.MinValue, question.MaxValue)
+}
+
+func (p *PostgresDB) GetPollQuestions(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, "SELECT id, pollid, position, prompt, type, picks, min_value, max_value FROM poll_questions WHERE pollid=$1 ORDER BY position", pollid)
+}
+
+func (p *PostgresDB) DeletePollQuestions(pollid int64) (sql.Result, error) {
+	return p.db.Exec("DELETE FROM poll_questions WHERE pollid=$1", pollid)
+}
+
+func (p *PostgresDB) CountSurveyBallots(pollid int64) *sql.Row {
+	return p.db.QueryRow("SELECT COUNT(*) FROM survey_ballots WHERE pollid=$1", pollid)
+}
+
+func (p *PostgresDB) InsertSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	return p.insertReturningID("INSERT INTO survey_ballots(pollid, userid) VALUES ($1, $2) RETURNING id", pollid, userid)
+}
+
+// DeleteSurveyBallot removes the user's previous ballot together with its answers
+func (p *PostgresDB) DeleteSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	return p.db.Exec("DELETE FROM survey_ballots WHERE pollid=$1 AND userid=$2", pollid, userid)
+}
+
+func (p *PostgresDB) InsertSurveyAnswer(answer databasestructs.SurveyAnswer) (sql.Result, error) {
+	return p.db.Exec("INSERT INTO survey_answers(ballotid, questionid, position, value, number) VALUES ($1, $2, $3, $4, $5)", answer.BallotID, answer.QuestionID, answer.Position, answer.Value, answer.Number)
+}
+
+func (p *PostgresDB) GetSurveyAnswers(ctx context.Context, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT sa.ballotid, sa.questionid, sa.position, sa.value, sa.number FROM survey_answers sa
+		INNER JOIN survey_ballots sb ON sb.id = sa.ballotid
+		WHERE sb.pollid=$1
+		ORDER BY sa.questionid, sa.ballotid, sa.position`, pollid)
+}
@@ -0,0 +1,23 @@
+package postgres_db
+
+import "time"
+
+func (p *PostgresDB) GetLastSyncTime(name string) (time.Time, error) {
+	var lastSyncTime int64
+	err := p.db.QueryRow("SELECT last_sync_time FROM sync_time WHERE name = $1", name).Scan(&lastSyncTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(lastSyncTime, 0), nil
+}
+
+func (p *PostgresDB) InsertLastSyncTime(newTime time.Time, name string) error {
+	_, err := p.db.Exec("INSERT INTO sync_time(name, last_sync_time) VALUES ($1, $2)", name, newTime.Unix())
+	return err
+}
+
+func (p *PostgresDB) UpdateLastSyncTime(newTime time.Time, name string) error {
+	_, err := p.db.Exec("UPDATE sync_time SET last_sync_time=$1 WHERE name = $2", newTime.Unix(), name)
+	return err
+}
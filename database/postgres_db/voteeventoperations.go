@@ -0,0 +1,57 @@
+package postgres_db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// vote events are only ever appended, so the history survives votes being changed, retracted or reset
+func (p *PostgresDB) insertVoteEvent(pollid, userid int64, event, choice, previous string) error {
+	_, err := p.db.Exec("INSERT INTO vote_events(pollid, userid, event, choice, previous, created_at) VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), "+unixNow+")", pollid, userid, event, choice, previous)
+	return err
+}
+
+// RetractPlayerVote takes back the user's vote in the poll
+func (p *PostgresDB) RetractPlayerVote(pollid, userid int64) (sql.Result, error) {
+	var previous string
+	err := p.db.QueryRow("SELECT "+voteChoice+" FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid).Scan(&previous)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.db.Exec("DELETE FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, p.insertVoteEvent(pollid, userid, "retracted", "", previous)
+}
+
+// GetUserVoteEvents returns the vote history of the user in the polls the viewer can see, newest first, optionally only in one poll
+func (p *PostgresDB) GetUserVoteEvents(ctx context.Context, userid, viewerid, pollid int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT e.id, e.pollid, po.name, e.event, COALESCE(e.choice, ''), COALESCE(cp.name, cgp.name, cc.name, ''), COALESCE(e.previous, ''), COALESCE(pp.name, pgp.name, pc.name, ''), e.created_at
+		FROM vote_events e
+		INNER JOIN polls po ON po.id = e.pollid
+		LEFT JOIN players cp ON cp.playerid = e.choice
+		LEFT JOIN goat_players cgp ON cgp.playerid = e.choice
+		LEFT JOIN poll_candidates cc ON cc.pollid = e.pollid AND CAST(cc.id AS TEXT) = e.choice
+		LEFT JOIN players pp ON pp.playerid = e.previous
+		LEFT JOIN goat_players pgp ON pgp.playerid = e.previous
+		LEFT JOIN poll_candidates pc ON pc.pollid = e.pollid AND CAST(pc.id AS TEXT) = e.previous
+		WHERE e.userid=$1 AND (po.groupid IS NULL OR po.groupid IN (SELECT groupid FROM group_members WHERE userid=$2)) AND ($3 = 0 OR e.pollid=$3)
+		ORDER BY e.created_at DESC, e.id DESC`, userid, viewerid, pollid)
+}
+
+// GetPollVoteEvents returns the vote events of the poll since the given unix time, oldest first
+func (p *PostgresDB) GetPollVoteEvents(ctx context.Context, pollid, since int64) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, `SELECT e.event, COALESCE(cp.name, cgp.name, cc.name, ''), COALESCE(pp.name, pgp.name, pc.name, ''), e.created_at
+		FROM vote_events e
+		LEFT JOIN players cp ON cp.playerid = e.choice
+		LEFT JOIN goat_players cgp ON cgp.playerid = e.choice
+		LEFT JOIN poll_candidates cc ON cc.pollid = e.pollid AND CAST(cc.id AS TEXT) = e.choice
+		LEFT JOIN players pp ON pp.playerid = e.previous
+		LEFT JOIN goat_players pgp ON pgp.playerid = e.previous
+		LEFT JOIN poll_candidates pc ON pc.pollid = e.pollid AND CAST(pc.id AS TEXT) = e.previous
+		WHERE e.pollid=$1 AND e.created_at >= $2
+		ORDER BY e.created_at, e.id`, pollid, since)
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+	"time"
+)
+
+// Queries is what every backend implements, the raw statements the repository turns into domain structs
+type Queries interface {
+	PlayerQueries
+	TeamQueries
+	StatsQueries
+	PollQueries
+	MatchupQueries
+	BracketQueries
+	SurveyQueries
+	ContestQueries
+	CandidateQueries
+	GroupQueries
+	GuestVoteQueries
+	FraudQueries
+	VoteEventQueries
+	SeasonResultsQueries
+	UserQueries
+	GoatQueries
+	LeagueQueries
+	SyncQueries
+	CloseConnection()
+	GetDB() *sql.DB
+}
+
+type PlayerQueries interface {
+	InsertPlayer(info databasestructs.PlayerInfo) (sql.Result, error)
+	UpdatePlayerAge(playerid string, age int64) (sql.Result, error)
+	SelectPlayerGamesPlayed(season string) (*sql.Rows, error)
+	CheckPlayerExists(playerid string) *sql.Row
+	GetPlayersForSearch(ctx context.Context, season string) (*sql.Rows, error)
+}
+
+type TeamQueries interface {
+	InsertTeam(info databasestructs.TeamInfo) (sql.Result, error)
+	UpdateTeamForPlayer(teamabbr, playerid string) (sql.Result, error)
+	SelectTeamByAbbrevation(teamabbr string) *sql.Row
+}
+
+type StatsQueries interface {
+	UpdateStats(stats databasestructs.PlayerStats) (sql.Result, error)
+	InsertStats(stats databasestructs.PlayerStats) (sql.Result, error)
+	UpdateTradedPlayerStats(stats databasestructs.PlayerStats) (sql.Result, error)
+	UpdateAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error)
+	UpdateTradedPlayerAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error)
+	InsertAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error)
+	UpdateOffAndDefRtg(offrtg, defrtg float64, playerid, season string) (sql.Result, error)
+	GetSixManStats(ctx context.Context, season string) (*sql.Rows, error)
+	GetDPOYStats(ctx context.Context, season string) (*sql.Rows, error)
+	GetROYStats(ctx context.Context, season string) (*sql.Rows, error)
+	SetRookieStatus(id string) (sql.Result, error)
+	GetSeasonStats(ctx context.Context, season string) (*sql.Rows, error)
+	GetStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) (*sql.Rows, error)
+	CountStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) *sql.Row
+	GetAllSeasonStats(ctx context.Context) (*sql.Rows, error)
+}
+
+type PollQueries interface {
+	GetPolls(ctx context.Context, userid int64) (*sql.Rows, error)
+	GetPollByID(id int64) *sql.Row
+	GetPollByUserID(userid, viewerid int64) (*sql.Rows, error)
+	InsertPolls(poll databasestructs.Poll) (sql.Result, error)
+	InsertPollsWithId(poll databasestructs.Poll) (sql.Result, error)
+	DeletePollByID(pollid int64) (sql.Result, error)
+	ResetPollVotes(pollid int64) (sql.Result, error)
+	UpdatePollByID(poll databasestructs.Poll) (sql.Result, error)
+	InsertSeasonEntered(season string) (sql.Result, error)
+	SelectSeasonsAvailable() (*sql.Rows, error)
+	SelectSeasonsForNonGOATStats() (*sql.Rows, error)
+	GetPlayerStatsForPoll(ctx context.Context, season string) (*sql.Rows, error)
+	GetPlayerPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
+	GetPanelPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
+	GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) (*sql.Rows, error)
+	GetPollVotesBySegment(ctx context.Context, pollid int64, segment string) (*sql.Rows, error)
+	InsertPlayerVotes(pollid, userid int64, playerid, ip string) (sql.Result, error)
+	GetTeamPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
+	UpdatePollImage(image databasestructs.Image) (sql.Result, error)
+}
+
+type MatchupQueries interface {
+	InsertMatchupVote(vote databasestructs.MatchupVote) (sql.Result, error)
+	UpdateMatchupRating(pollid int64, playerid string, change float64, won bool) (sql.Result, error)
+	GetMatchupRatings(ctx context.Context, pollid int64) (*sql.Rows, error)
+	GetMatchupVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
+	GetUserMatchupVotes(ctx context.Context, pollid, userid int64) (*sql.Rows, error)
+	ResetMatchups(pollid int64) error
+	GetPollsWithoutMatchupRatings(ctx context.Context) (*sql.Rows, error)
+	SetMatchupVoteChange(id int64, change float64) (sql.Result, error)
+}
+
+type BracketQueries interface {
+	InsertBracket(bracket databasestructs.Bracket) (sql.Result, error)
+	GetBracket(pollid int64) *sql.Row
+	GetDueBrackets(ctx context.Context, now int64) (*sql.Rows, error)
+	AdvanceBracketRound(pollid, round, roundEndsAt int64) (sql.Result, error)
+	SetBracketChampion(pollid int64, championid string) (sql.Result, error)
+	InsertBracketMatchup(matchup databasestructs.BracketMatchup) (sql.Result, error)
+	GetBracketMatchup(id int64) *sql.Row
+	GetBracketMatchups(ctx context.Context, pollid int64) (*sql.Rows, error)
+	SetBracketMatchupWinner(id int64, winnerid string) (sql.Result, error)
+	InsertBracketVote(vote databasestructs.BracketVote) (sql.Result, error)
+}
+
+type SurveyQueries interface {
+	InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error)
+	GetPollQuestions(ctx context.Context, pollid int64) (*sql.Rows, error)
+	DeletePollQuestions(pollid int64) (sql.Result, error)
+	CountSurveyBallots(pollid int64) *sql.Row
+	InsertSurveyBallot(pollid, userid int64) (sql.Result, error)
+	DeleteSurveyBallot(pollid, userid int64) (sql.Result, error)
+	InsertSurveyAnswer(answer databasestructs.SurveyAnswer) (sql.Result, error)
+	GetSurveyAnswers(ctx context.Context, pollid int64) (*sql.Rows, error)
+}
+
+type ContestQueries interface {
+	InsertContest(contest databasestructs.PredictionContest) (sql.Result, error)
+	GetContests(ctx context.Context) (*sql.Rows, error)
+	GetContestByID(id int64) *sql.Row
+	GetUnresolvedContests(ctx context.Context, now int64) (*sql.Rows, error)
+	SetContestResolved(id int64) (sql.Result, error)
+	InsertContestQuestion(question databasestructs.PredictionQuestion) (sql.Result, error)
+	GetContestQuestions(ctx context.Context, contestid int64) (*sql.Rows, error)
+	ResolveContestQuestion(id int64, answer string, answerNumber *float64) (sql.Result, error)
+	InsertPrediction(prediction databasestructs.Prediction) (sql.Result, error)
+	GetQuestionPredictions(ctx context.Context, questionid int64) (*sql.Rows, error)
+	GetUserPredictions(ctx context.Context, contestid, userid int64) (*sql.Rows, error)
+	SetPredictionScore(questionid, userid int64, score float64) (sql.Result, error)
+	GetContestLeaderboard(ctx context.Context, contestid int64) (*sql.Rows, error)
+}
+
+type CandidateQueries interface {
+	InsertPollCandidate(candidate databasestructs.PollCandidate, nameKey string) (sql.Result, error)
+	GetPollCandidates(ctx context.Context, pollid int64, status string) (*sql.Rows, error)
+	GetPollCandidateByID(id int64) *sql.Row
+	GetPollCandidateByKey(pollid int64, nameKey string) *sql.Row
+	UpdatePollCandidateStatus(id int64, status string) (sql.Result, error)
+	CountUserWriteIns(pollid, userid int64) *sql.Row
+	InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error)
+}
+
+type GroupQueries interface {
+	InsertGroup(group databasestructs.Group) (sql.Result, error)
+	GetGroupByID(id int64) *sql.Row
+	GetGroupByInviteCode(code string) *sql.Row
+	GetUserGroups(ctx context.Context, userid int64) (*sql.Rows, error)
+	UpdateGroupInviteCode(id int64, code string) (sql.Result, error)
+	DeleteGroup(id int64) (sql.Result, error)
+	InsertGroupMember(member databasestructs.GroupMember) (sql.Result, error)
+	DeleteGroupMember(groupid, userid int64) (sql.Result, error)
+	GetGroupMembers(ctx context.Context, groupid int64) (*sql.Rows, error)
+	GetGroupMemberRole(groupid, userid int64) *sql.Row
+	GetGroupPolls(ctx context.Context, groupid int64) (*sql.Rows, error)
+	GetGroupLeaderboard(ctx context.Context, groupid int64) (*sql.Rows, error)
+}
+
+type GuestVoteQueries interface {
+	InsertGuestVote(vote databasestructs.GuestVote) (sql.Result, error)
+	CountGuestVotesByIP(pollid int64, ip string) *sql.Row
+	GetGuestDeviceVote(pollid int64, deviceid string) *sql.Row
+	GetGuestPollVotes(ctx context.Context, pollid int64) (*sql.Rows, error)
+	ResetGuestVotes(pollid int64) (sql.Result, error)
+}
+
+type FraudQueries interface {
+	GetVotesForFraudCheck(ctx context.Context, since int64) (*sql.Rows, error)
+	InsertVoteFlag(flag databasestructs.VoteFlag) (sql.Result, error)
+	GetVoteFlags(ctx context.Context, status string, pollid int64) (*sql.Rows, error)
+	GetVoteFlagByID(id int64) *sql.Row
+	UpdateVoteFlagStatus(pollid, userid int64, status string, reviewedBy int64) (sql.Result, error)
+}
+
+type VoteEventQueries interface {
+	RetractPlayerVote(pollid, userid int64) (sql.Result, error)
+	GetUserVoteEvents(ctx context.Context, userid, viewerid, pollid int64) (*sql.Rows, error)
+	GetPollVoteEvents(ctx context.Context, pollid, since int64) (*sql.Rows, error)
+}
+
+type SeasonResultsQueries interface {
+	InsertTeamStanding(standing databasestructs.TeamStanding) (sql.Result, error)
+	GetTeamStandings(ctx context.Context, season string) (*sql.Rows, error)
+	InsertSeasonAward(award databasestructs.SeasonAward) (sql.Result, error)
+	GetSeasonAwards(ctx context.Context, season string) (*sql.Rows, error)
+}
+
+type UserQueries interface {
+	GetUserByUsername(username string) *sql.Row
+	GetUserByRefreshToken(refresh_token string) *sql.Row
+	GetUserByID(id int64) *sql.Row
+	GetUserRolesByID(id int64) *sql.Row
+	InsertUserRoles(role databasestructs.Role) (sql.Result, error)
+	UpdateUserRoles(roles string, user_id int64) (sql.Result, error)
+	InsertNewUser(user databasestructs.User) (sql.Result, error)
+	UpdateUserRefreshToken(username, refresh_token string) (sql.Result, error)
+	UpdateUserIsAdmin(username string, is_admin bool) (sql.Result, error)
+	UpdateUserPassword(username, password string) (sql.Result, error)
+	UpdateUserEmail(username, email string) (sql.Result, error)
+	UpdateUserUsername(oldusername, username string) (sql.Result, error)
+	UpdateUserProfilePic(username, profile_pic string) (sql.Result, error)
+	UpdateUserProfile(id int64, favoriteTeam, region string) (sql.Result, error)
+	DeleteUser(id int64) (sql.Result, error)
+	GetAllUsers() (*sql.Rows, error)
+	GetVotesOfUser(ctx context.Context, userid, viewerid int64) (*sql.Rows, error)
+	CreateAdminUser() error
+	GetCurrentProfilePic(id int64) *sql.Row
+}
+
+type GoatQueries interface {
+	InsertGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error)
+	UpdateGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error)
+	UpdateGOATStats(stats databasestructs.GoatStats) (sql.Result, error)
+	InsertGOATStats(stats databasestructs.GoatStats) (sql.Result, error)
+	GetGOATStats() (*sql.Rows, error)
+	GetActivePlayers() (*sql.Rows, error)
+	GetGOATPlayerStats(ctx context.Context, playerid string) (*sql.Rows, error)
+	InsertGOATPlayerSeason(season databasestructs.GoatPlayerSeason) (sql.Result, error)
+	GetGOATEraStats(ctx context.Context) (*sql.Rows, error)
+	GetGOATCareerStats(ctx context.Context) (*sql.Rows, error)
+}
+
+type LeagueQueries interface {
+	InsertLeagueAverages(averages databasestructs.LeagueAverages) (sql.Result, error)
+}
+
+type SyncQueries interface {
+	GetLastSyncTime(name string) (time.Time, error)
+	InsertLastSyncTime(newTime time.Time, name string) error
+	UpdateLastSyncTime(newTime time.Time, name string) error
+}
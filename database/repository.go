@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// repository turns the rows of the backend into domain structs, writes are passed through unless their errors need translating
+type repository struct {
+	Queries
+	// inTx binds the backend to a transaction, it's nil when the repository already runs in one
+	inTx func(tx *sql.Tx) Queries
+}
+
+func (r repository) WithTx(ctx context.Context, fn func(tx Database) error) error {
+	return r.transaction(ctx, func(tx repository) error { return fn(tx) })
+}
+
+// transaction runs fn on a repository bound to a new transaction, nested calls join the one already open
+func (r repository) transaction(ctx context.Context, fn func(tx repository) error) error {
+	if r.inTx == nil {
+		return fn(r)
+	}
+
+	tx, err := r.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// rolling back after the commit does nothing, this only covers the errors and panics of fn
+	defer tx.Rollback()
+
+	if err := fn(repository{Queries: r.inTx(tx)}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// execInTransaction runs a write of several statements in one transaction, so either all of it is kept or none of it
+func (r repository) execInTransaction(exec func(tx Queries) (sql.Result, error)) (sql.Result, error) {
+	var res sql.Result
+	err := r.transaction(context.Background(), func(tx repository) error {
+		var err error
+		res, err = exec(tx.Queries)
+		return err
+	})
+
+	return res, err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// collect scans every row of the query and closes the rows, no rows is an empty slice so the JSON is a list
+func collect[T any](rows *sql.Rows, err error, scan func(scanner) (T, error)) ([]T, error) {
+	if err != nil {
+		return nil, notFound(err)
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// one scans the single row of the query, a missing row is ErrNotFound
+func one[T any](row *sql.Row, scan func(scanner) (T, error)) (T, error) {
+	item, err := scan(row)
+	if err != nil {
+		var zero T
+		return zero, notFound(err)
+	}
+
+	return item, nil
+}
+
+func scanString(row scanner) (string, error) {
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+func scanCount(row scanner) (int64, error) {
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
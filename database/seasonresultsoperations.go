@@ -0,0 +1,24 @@
+package database
+
+import (
+	"context"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) GetTeamStandings(ctx context.Context, season string) ([]databasestructs.TeamStanding, error) {
+	rows, err := r.Queries.GetTeamStandings(ctx, season)
+	return collect(rows, err, func(row scanner) (databasestructs.TeamStanding, error) {
+		var standing databasestructs.TeamStanding
+		err := row.Scan(&standing.Season, &standing.TeamAbbr, &standing.Wins, &standing.Losses)
+		return standing, err
+	})
+}
+
+func (r repository) GetSeasonAwards(ctx context.Context, season string) ([]databasestructs.SeasonAward, error) {
+	rows, err := r.Queries.GetSeasonAwards(ctx, season)
+	return collect(rows, err, func(row scanner) (databasestructs.SeasonAward, error) {
+		var award databasestructs.SeasonAward
+		err := row.Scan(&award.Season, &award.Award, &award.PlayerID)
+		return award, err
+	})
+}
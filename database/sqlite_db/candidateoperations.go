@@ -0,0 +1,74 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+	"strconv"
+)
+
+func (s *SQLiteDB) InsertPollCandidate(candidate databasestructs.PollCandidate, nameKey string) (sql.Result, error) {
+	var playerID interface{}
+	if candidate.PlayerID != "" {
+		playerID = candidate.PlayerID
+	}
+
+	var submittedBy interface{}
+	if candidate.SubmittedBy != 0 {
+		submittedBy = candidate.SubmittedBy
+	}
+
+	return s.db.Exec("INSERT INTO poll_candidates(pollid, playerid, name, name_key, status, is_write_in, submitted_by) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		candidate.PollID, playerID, candidate.Name, nameKey, candidate.Status, candidate.IsWriteIn, submittedBy)
+}
+
+func (s *SQLiteDB) GetPollCandidates(ctx context.Context, pollid int64, status string) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE pollid=$1 AND status=$2 ORDER BY is_write_in, name", pollid, status)
+}
+
+func (s *SQLiteDB) GetPollCandidateByID(id int64) *sql.Row {
+	return s.db.QueryRow("SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE id=$1", id)
+}
+
+func (s *SQLiteDB) GetPollCandidateByKey(pollid int64, nameKey string) *sql.Row {
+	return s.db.QueryRow("SELECT id, pollid, COALESCE(playerid, ''), name, status, is_write_in, COALESCE(submitted_by, 0) FROM poll_candidates WHERE pollid=$1 AND name_key=$2", pollid, nameKey)
+}
+
+func (s *SQLiteDB) UpdatePollCandidateStatus(id int64, status string) (sql.Result, error) {
+	return s.db.Exec("UPDATE poll_candidates SET status=$1 WHERE id=$2", status, id)
+}
+
+func (s *SQLiteDB) CountUserWriteIns(pollid, userid int64) *sql.Row {
+	return s.db.QueryRow("SELECT COUNT(*) FROM poll_candidates WHERE pollid=$1 AND submitted_by=$2 AND is_write_in=true", pollid, userid)
+}
+
+// InsertCandidateVote replaces whatever the user voted for in the poll before
+func (s *SQLiteDB) InsertCandidateVote(pollid, userid, candidateid int64, ip string) (sql.Result, error) {
+	choice := strconv.FormatInt(candidateid, 10)
+	event := "cast"
+
+	var previous string
+	var switches int64
+	err := s.db.QueryRow("SELECT "+voteChoice+", switches FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid).Scan(&previous, &switches)
+	if err == nil {
+		if previous == choice {
+			return nil, nil
+		}
+		event = "changed"
+		switches++
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	_, err = s.db.Exec("DELETE FROM player_votes WHERE pollid=$1 AND userid=$2", pollid, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.db.Exec("INSERT INTO player_votes(candidateid, pollid, userid, votes_for, created_at, ip, switches) VALUES ($1, $2, $3, 1, "+unixNow+", $4, $5)", candidateid, pollid, userid, ip, switches)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, s.insertVoteEvent(pollid, userid, event, choice, previous)
+}
@@ -0,0 +1,41 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// GetVotesForFraudCheck returns the votes cast since the given unix time with the age and signup address of the voter
+func (s *SQLiteDB) GetVotesForFraudCheck(ctx context.Context, since int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, `SELECT v.pollid, v.userid, COALESCE(v.playerid, v.goatplayerid, CAST(v.candidateid AS TEXT)), v.created_at, v.ip, v.switches, u.created_at, u.signup_ip
+		FROM player_votes v
+		INNER JOIN users u ON u.id = v.userid
+		WHERE v.created_at >= $1
+		ORDER BY v.pollid, v.created_at`, since)
+}
+
+// InsertVoteFlag keeps the first flag for the same reason, so cleared flags aren't opened again by the next run
+func (s *SQLiteDB) InsertVoteFlag(flag databasestructs.VoteFlag) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO vote_flags(pollid, userid, reason, details, status, created_at) VALUES ($1, $2, $3, $4, 'open', $5) ON CONFLICT DO NOTHING", flag.PollID, flag.UserID, flag.Reason, flag.Details, flag.CreatedAt)
+}
+
+func (s *SQLiteDB) GetVoteFlags(ctx context.Context, status string, pollid int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, `SELECT f.id, f.pollid, f.userid, u.username, f.reason, f.details, f.status, f.created_at, COALESCE(f.reviewed_by, 0)
+		FROM vote_flags f
+		INNER JOIN users u ON u.id = f.userid
+		WHERE f.status=$1 AND ($2 = 0 OR f.pollid=$2)
+		ORDER BY f.created_at DESC, f.id`, status, pollid)
+}
+
+func (s *SQLiteDB) GetVoteFlagByID(id int64) *sql.Row {
+	return s.db.QueryRow(`SELECT f.id, f.pollid, f.userid, u.username, f.reason, f.details, f.status, f.created_at, COALESCE(f.reviewed_by, 0)
+		FROM vote_flags f
+		INNER JOIN users u ON u.id = f.userid
+		WHERE f.id=$1`, id)
+}
+
+// UpdateVoteFlagStatus reviews every flag of the user's ballot in the poll at once, since the ballot is either counted or not
+func (s *SQLiteDB) UpdateVoteFlagStatus(pollid, userid int64, status string, reviewedBy int64) (sql.Result, error) {
+	return s.db.Exec("UPDATE vote_flags SET status=$1, reviewed_by=$2 WHERE pollid=$3 AND userid=$4", status, reviewedBy, pollid, userid)
+}
@@ -0,0 +1,68 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (s *SQLiteDB) InsertGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO goat_players(playerid, name, allstar, allnba, alldefense, championships, dpoy, sixman, roy, finalsmvp, mvp, isactive) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT DO NOTHING", info.ID, info.Name, info.AllStar, info.AllNba, info.AllDefense, info.Championships, info.Dpoy, info.SixMan, info.ROY, info.FMVP, info.MVP, info.IsActive)
+}
+
+func (s *SQLiteDB) UpdateGOATPlayer(info databasestructs.GoatPlayers) (sql.Result, error) {
+	return s.db.Exec("UPDATE goat_players SET allstar=$1, allnba=$2, alldefense=$3, championships=$4, dpoy=$5, sixman=$6, roy=$7, finalsmvp=$8, mvp=$9, isactive=$10 WHERE playerid=$11", info.AllStar, info.AllNba, info.AllDefense, info.Championships, info.Dpoy, info.SixMan, info.ROY, info.FMVP, info.MVP, info.IsActive, info.ID)
+}
+
+func (s *SQLiteDB) UpdateGOATStats(stats databasestructs.GoatStats) (sql.Result, error) {
+	return s.db.Exec("UPDATE goat_stats SET pointspergame=$1, reboundspergame=$2, assistspergame=$3, stealspergame=$4, blockspergame=$5, turnoverspergame=$6, fgpercentage=$7, ftpercentage=$8, threeptpercentage=$9, per=$10, ows=$11, dws=$12, ws=$13, obpm=$14, dbpm=$15, bpm=$16, vorp=$17, offrtg=$18, defrtg=$19, totalpoints=$20, totalrebounds=$21, totalassists=$22, totalsteals=$23, totalblocks=$24, position=$25, minutespergame=$26, tspct=$27 WHERE playerid=$28 AND isplayoffs=$29", stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.PER, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TotalPoints, stats.TotalRebounds, stats.TotalAssists, stats.TotalSteals, stats.TotalBlocks, stats.Position, stats.Minutes, stats.TSPct, stats.PlayerID, stats.IsPlayoffs)
+}
+
+func (s *SQLiteDB) InsertGOATStats(stats databasestructs.GoatStats) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO goat_stats (playerid, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, turnoverspergame, fgpercentage, ftpercentage, threeptpercentage, per, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg, totalpoints, totalrebounds, totalassists, totalsteals, totalblocks, isplayoffs, position, isactive, minutespergame, tspct) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30) ON CONFLICT DO NOTHING", stats.PlayerID, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.PER, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TotalPoints, stats.TotalRebounds, stats.TotalAssists, stats.TotalSteals, stats.TotalBlocks, stats.IsPlayoffs, stats.Position, stats.IsActive, stats.Minutes, stats.TSPct)
+}
+
+func (s *SQLiteDB) GetGOATStats() (*sql.Rows, error) {
+	return s.db.Query("SELECT p.playerid, p.name, sr.position, ROUND(AVG(sr.pointspergame), 1) as avg_pointspergame, ROUND(AVG(sr.reboundspergame), 1) as avg_reboundspergame, ROUND(AVG(sr.assistspergame), 1) as avg_assistspergame, ROUND(AVG(sr.stealspergame), 1) as avg_stealspergame, ROUND(AVG(sr.blockspergame), 1) as avg_blockspergame, p.allstar, p.allnba, p.alldefense, p.championships, p.dpoy, p.finalsmvp, p.mvp, ROUND(AVG(sr.per), 1) as avg_per, ROUND(AVG(sr.ows), 1) as avg_ows, ROUND(AVG(sr.dws), 1) as avg_dws, ROUND(AVG(sr.ws), 1) as avg_ws, ROUND(AVG(sr.dbpm), 1) as avg_dbpm, ROUND(AVG(sr.obpm), 1) as avg_obpm, ROUND(AVG(sr.bpm), 1) as avg_bpm, ROUND(AVG(sr.defrtg), 1) as avg_defrtg, ROUND(AVG(sr.offrtg), 1) as avg_offrtg, ROUND(AVG(sp.pointspergame), 1) as avg_playoff_pointspergame, ROUND(AVG(sp.reboundspergame), 1) as avg_playoff_reboundspergame, ROUND(AVG(sp.assistspergame), 1) as avg_playoff_assistspergame FROM goat_players p INNER JOIN goat_stats sr ON p.playerid=sr.playerid INNER JOIN goat_stats sp ON p.playerid=sp.playerid WHERE sp.isplayoffs GROUP BY p.playerid, p.name, sr.position, p.allstar, p.allnba, p.alldefense, p.championships, p.dpoy, p.finalsmvp, p.mvp ORDER BY avg_per DESC")
+}
+
+func (s *SQLiteDB) GetActivePlayers() (*sql.Rows, error) {
+	return s.db.Query("SELECT playerid FROM goat_players WHERE isactive")
+}
+
+func (s *SQLiteDB) GetGOATPlayerStats(ctx context.Context, playerid string) (*sql.Rows, error) {
+	query := `
+        SELECT p.name, p.allstar, p.allnba, p.alldefense, p.championships, p.dpoy, p.sixman, p.roy, p.finalsmvp, p.mvp, p.isactive, s.pointspergame, s.reboundspergame, s.assistspergame, s.stealspergame, s.blockspergame, s.turnoverspergame, s.fgpercentage, s.threeptpercentage, s.ftpercentage, s.per, s.ows, s.dws, s.ws, s.obpm, s.dbpm, s.bpm, s.vorp, s.offrtg, s.defrtg, s.totalpoints, s.totalrebounds, s.totalassists, s.totalsteals, s.totalblocks, s.position, s.isplayoffs
+        FROM goat_players p
+        INNER JOIN goat_stats s ON p.playerid = s.playerid
+        WHERE p.playerid = $1`
+	return s.db.QueryContext(ctx, query, playerid)
+}
+
+func (s *SQLiteDB) InsertGOATPlayerSeason(season databasestructs.GoatPlayerSeason) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO goat_player_seasons(playerid, season, gamesplayed) VALUES ($1, $2, $3) ON CONFLICT (playerid, season) DO UPDATE SET gamesplayed=EXCLUDED.gamesplayed", season.PlayerID, season.Season, season.Games)
+}
+
+func (s *SQLiteDB) GetGOATEraStats(ctx context.Context) (*sql.Rows, error) {
+	query := `
+        SELECT s.playerid, ps.gamesplayed, s.ppg, s.rpg, s.apg, s.mpg, s.ts, COALESCE(la.pace, 0), COALESCE(la.pointspergame, 0), COALESCE(la.reboundspergame, 0), COALESCE(la.assistspergame, 0), COALESCE(la.tspct, 0)
+        FROM (
+            SELECT playerid, AVG(pointspergame) AS ppg, AVG(reboundspergame) AS rpg, AVG(assistspergame) AS apg, MAX(COALESCE(minutespergame, 0)) AS mpg, MAX(COALESCE(tspct, 0)) AS ts
+            FROM goat_stats
+            WHERE NOT isplayoffs
+            GROUP BY playerid
+        ) s
+        INNER JOIN goat_player_seasons ps ON s.playerid = ps.playerid
+        INNER JOIN league_averages la ON ps.season = la.season`
+	return s.db.QueryContext(ctx, query)
+}
+
+func (s *SQLiteDB) GetGOATCareerStats(ctx context.Context) (*sql.Rows, error) {
+	query := `
+        SELECT p.playerid, p.name, MAX(s.position), AVG(COALESCE(s.minutespergame, 0)), AVG(s.pointspergame), AVG(s.reboundspergame), AVG(s.assistspergame), AVG(s.stealspergame), AVG(s.blockspergame), AVG(s.turnoverspergame), AVG(s.fgpercentage), AVG(s.threeptpercentage), AVG(s.ftpercentage), AVG(s.per), AVG(COALESCE(s.tspct, 0)), AVG(s.obpm), AVG(s.dbpm), AVG(s.bpm), AVG(s.offrtg), AVG(s.defrtg)
+        FROM goat_players p
+        INNER JOIN goat_stats s ON p.playerid = s.playerid
+        WHERE NOT s.isplayoffs
+        GROUP BY p.playerid, p.name`
+	return s.db.QueryContext(ctx, query)
+}
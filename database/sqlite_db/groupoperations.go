@@ -0,0 +1,68 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (s *SQLiteDB) InsertGroup(group databasestructs.Group) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO poll_groups(name, description, invite_code, ownerid, created_at) VALUES ($1, $2, $3, $4, $5)", group.Name, group.Description, group.InviteCode, group.OwnerID, group.CreatedAt)
+}
+
+func (s *SQLiteDB) GetGroupByID(id int64) *sql.Row {
+	return s.db.QueryRow("SELECT g.id, g.name, g.description, g.invite_code, g.ownerid, g.created_at, COUNT(gm.userid) FROM poll_groups g LEFT JOIN group_members gm ON gm.groupid = g.id WHERE g.id=$1 GROUP BY g.id", id)
+}
+
+func (s *SQLiteDB) GetGroupByInviteCode(code string) *sql.Row {
+	return s.db.QueryRow("SELECT g.id, g.name, g.description, g.invite_code, g.ownerid, g.created_at, COUNT(gm.userid) FROM poll_groups g LEFT JOIN group_members gm ON gm.groupid = g.id WHERE g.invite_code=$1 GROUP BY g.id", code)
+}
+
+func (s *SQLiteDB) GetUserGroups(ctx context.Context, userid int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, `SELECT g.id, g.name, g.description, g.invite_code, g.ownerid, g.created_at, COUNT(all_members.userid) FROM poll_groups g
+		INNER JOIN group_members gm ON gm.groupid = g.id
+		INNER JOIN group_members all_members ON all_members.groupid = g.id
+		WHERE gm.userid=$1
+		GROUP BY g.id
+		ORDER BY g.name`, userid)
+}
+
+func (s *SQLiteDB) UpdateGroupInviteCode(id int64, code string) (sql.Result, error) {
+	return s.db.Exec("UPDATE poll_groups SET invite_code=$1 WHERE id=$2", code, id)
+}
+
+func (s *SQLiteDB) DeleteGroup(id int64) (sql.Result, error) {
+	return s.db.Exec("DELETE FROM poll_groups WHERE id=$1", id)
+}
+
+func (s *SQLiteDB) InsertGroupMember(member databasestructs.GroupMember) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO group_members(groupid, userid, role, joined_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING", member.GroupID, member.UserID, member.Role, member.JoinedAt)
+}
+
+func (s *SQLiteDB) DeleteGroupMember(groupid, userid int64) (sql.Result, error) {
+	return s.db.Exec("DELETE FROM group_members WHERE groupid=$1 AND userid=$2", groupid, userid)
+}
+
+func (s *SQLiteDB) GetGroupMembers(ctx context.Context, groupid int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT gm.groupid, gm.userid, u.username, gm.role, gm.joined_at FROM group_members gm INNER JOIN users u ON u.id = gm.userid WHERE gm.groupid=$1 ORDER BY gm.joined_at", groupid)
+}
+
+func (s *SQLiteDB) GetGroupMemberRole(groupid, userid int64) *sql.Row {
+	return s.db.QueryRow("SELECT role FROM group_members WHERE groupid=$1 AND userid=$2", groupid, userid)
+}
+
+func (s *SQLiteDB) GetGroupPolls(ctx context.Context, groupid int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT id, name, description, image, selected_stats, season, userid, score_formula, allow_write_ins, COALESCE(groupid, 0), panel_weight, allow_guests FROM polls WHERE groupid=$1", groupid)
+}
+
+// GetGroupLeaderboard ranks the members by their prediction contest points, then by how many of the group's polls they voted in
+func (s *SQLiteDB) GetGroupLeaderboard(ctx context.Context, groupid int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, `SELECT u.id, u.username,
+		COALESCE((SELECT SUM(p.score) FROM predictions p WHERE p.userid = u.id), 0) AS total,
+		(SELECT COUNT(DISTINCT v.pollid) FROM player_votes v INNER JOIN polls po ON po.id = v.pollid WHERE v.userid = u.id AND po.groupid = gm.groupid) AS polls_voted,
+		(SELECT COUNT(p.score) FROM predictions p WHERE p.userid = u.id) AS graded
+		FROM group_members gm
+		INNER JOIN users u ON u.id = gm.userid
+		WHERE gm.groupid=$1
+		ORDER BY total DESC, polls_voted DESC, u.username`, groupid)
+}
@@ -0,0 +1,10 @@
+package sqlite_db
+
+import (
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (s *SQLiteDB) InsertLeagueAverages(averages databasestructs.LeagueAverages) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO league_averages(season, pace, pointspergame, reboundspergame, assistspergame, tspct) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (season) DO UPDATE SET pace=EXCLUDED.pace, pointspergame=EXCLUDED.pointspergame, reboundspergame=EXCLUDED.reboundspergame, assistspergame=EXCLUDED.assistspergame, tspct=EXCLUDED.tspct", averages.Season, averages.Pace, averages.Points, averages.Rebounds, averages.Assists, averages.TSPct)
+}
@@ -0,0 +1,34 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (s *SQLiteDB) InsertPlayer(info databasestructs.PlayerInfo) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO players(playerid, name, college, teamabbr, height, weight, age) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING", info.ID, info.Name, info.College, info.TeamAbbr, info.Height, info.Weight, info.Age)
+}
+
+func (s *SQLiteDB) UpdatePlayerAge(playerid string, age int64) (sql.Result, error) {
+	return s.db.Exec("UPDATE players set age=$1 WHERE playerid=$2", age, playerid)
+}
+
+func (s *SQLiteDB) SelectPlayerGamesPlayed(season string) (*sql.Rows, error) {
+	return s.db.Query("SELECT playerid, gamesplayed FROM stats WHERE season=$1", season)
+}
+
+func (s *SQLiteDB) CheckPlayerExists(playerid string) *sql.Row {
+	return s.db.QueryRow("SELECT 1 FROM players WHERE playerid=$1", playerid)
+}
+
+func (s *SQLiteDB) GetPlayersForSearch(ctx context.Context, season string) (*sql.Rows, error) {
+	query := `
+        SELECT p.playerid, p.name, COALESCE(s.minutespergame, 0), false AS isgoat
+        FROM players p
+        LEFT JOIN stats s ON p.playerid = s.playerid AND s.season = $1
+        UNION ALL
+        SELECT g.playerid, g.name, 0, true AS isgoat
+        FROM goat_players g`
+	return s.db.QueryContext(ctx, query, season)
+}
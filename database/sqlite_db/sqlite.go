@@ -0,0 +1,73 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+
+	_ "modernc.org/sqlite"
+)
+
+// MEMORY is the path that opens a fresh database living only as long as the connection pool
+const MEMORY = ":memory:"
+
+// memoryDBs numbers the in-memory databases, so every NewDB gets its own
+var memoryDBs int64
+
+type SQLiteDB struct {
+	conn *sql.DB
+	// db runs the statements, the connection pool or a transaction on it
+	db querier
+}
+
+// querier is what *sql.DB and *sql.Tx have in common, so the statements run the same in a transaction
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func NewDB(path string) (*SQLiteDB, error) {
+	query := url.Values{"_pragma": {"foreign_keys(1)", "busy_timeout(5000)"}}
+
+	dsn := "file:" + path
+	if path == MEMORY {
+		// every connection to :memory: would get an empty database, a named shared cache lets the pool share one,
+		// reading uncommitted keeps open result sets from locking out writes on the other connections
+		dsn = fmt.Sprintf("file:memdb%d", atomic.AddInt64(&memoryDBs, 1))
+		query.Set("mode", "memory")
+		query.Set("cache", "shared")
+		query.Add("_pragma", "read_uncommitted(1)")
+	} else {
+		query.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	db, err := sql.Open("sqlite", dsn+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	pingErr := db.Ping()
+	if pingErr != nil {
+		return nil, pingErr
+	}
+
+	return &SQLiteDB{conn: db, db: db}, nil
+}
+
+func (s *SQLiteDB) CloseConnection() {
+	s.conn.Close()
+}
+
+func (s *SQLiteDB) GetDB() *sql.DB {
+	return s.conn
+}
+
+// InTx returns a copy of the database that runs its statements in the transaction
+func (s *SQLiteDB) InTx(tx *sql.Tx) *SQLiteDB {
+	return &SQLiteDB{conn: s.conn, db: tx}
+}
@@ -0,0 +1,158 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sportsvoting/databasestructs"
+	"strings"
+)
+
+// leader columns are interpolated into the query, so only names from this list are accepted
+var statColumns = map[string]string{
+	"g":          "stats.gamesplayed",
+	"gs":         "stats.gamesstarted",
+	"mpg":        "stats.minutespergame",
+	"ppg":        "stats.pointspergame",
+	"rpg":        "stats.reboundspergame",
+	"apg":        "stats.assistspergame",
+	"spg":        "stats.stealspergame",
+	"bpg":        "stats.blockspergame",
+	"topg":       "stats.turnoverspergame",
+	"fgpct":      "stats.fgpercentage",
+	"threefgpct": "stats.threeptpercentage",
+	"ftpct":      "stats.ftpercentage",
+	"per":        "advancedstats.per",
+	"ts":         "advancedstats.tspct",
+	"usg":        "advancedstats.usgpct",
+	"ows":        "advancedstats.ows",
+	"dws":        "advancedstats.dws",
+	"ws":         "advancedstats.ws",
+	"obpm":       "advancedstats.obpm",
+	"dbpm":       "advancedstats.dbpm",
+	"bpm":        "advancedstats.bpm",
+	"vorp":       "advancedstats.vorp",
+	"offrtg":     "advancedstats.offrtg",
+	"defrtg":     "advancedstats.defrtg",
+}
+
+func (s *SQLiteDB) UpdateStats(stats databasestructs.PlayerStats) (sql.Result, error) {
+	return s.db.Exec("UPDATE stats SET gamesplayed=$1, gamesstarted=$2, minutespergame=$3, pointspergame=$4, reboundspergame=$5, assistspergame=$6, stealspergame=$7, blockspergame=$8, turnoverspergame=$9, fgpercentage=$10, ftpercentage=$11, threeptpercentage=$12, position=$13, teamabbr=$14 WHERE playerid=$15 AND season=$16", stats.Games, stats.GamesStarted, stats.Minutes, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.Position, stats.TeamAbbr, stats.PlayerID, stats.Season)
+}
+
+func (s *SQLiteDB) UpdateTradedPlayerStats(stats databasestructs.PlayerStats) (sql.Result, error) {
+	return s.db.Exec("UPDATE stats SET gamesplayed=$1, gamesstarted=$2, minutespergame=$3, pointspergame=$4, reboundspergame=$5, assistspergame=$6, stealspergame=$7, blockspergame=$8, turnoverspergame=$9, fgpercentage=$10, ftpercentage=$11, threeptpercentage=$12, position=$13 WHERE playerid=$14 AND season=$15", stats.Games, stats.GamesStarted, stats.Minutes, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.Position, stats.PlayerID, stats.Season)
+}
+
+func (s *SQLiteDB) InsertStats(stats databasestructs.PlayerStats) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO stats (gamesplayed, gamesstarted, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, turnoverspergame, fgpercentage, ftpercentage, threeptpercentage, season, position, playerid, teamabbr) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)", stats.Games, stats.GamesStarted, stats.Minutes, stats.Points, stats.Rebounds, stats.Assists, stats.Steals, stats.Blocks, stats.Turnovers, stats.FGPercentage, stats.FTPercentage, stats.ThreeFGPercentage, stats.Season, stats.Position, stats.PlayerID, stats.TeamAbbr)
+}
+
+func (s *SQLiteDB) UpdateAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error) {
+	return s.db.Exec("UPDATE advancedstats SET per=$1, tspct=$2, usgpct=$3, ows=$4, dws=$5, ws=$6, obpm=$7, dbpm=$8, bpm=$9, vorp=$10, offrtg=$11, defrtg=$12, teamabbr=$13 WHERE playerid=$14 AND season=$15", stats.PER, stats.TSPct, stats.USGPCt, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TeamAbbr, stats.PlayerID, stats.Season)
+}
+
+func (s *SQLiteDB) UpdateTradedPlayerAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error) {
+	return s.db.Exec("UPDATE advancedstats SET per=$1, tspct=$2, usgpct=$3, ows=$4, dws=$5, ws=$6, obpm=$7, dbpm=$8, bpm=$9, vorp=$10 WHERE playerid=$11 AND season=$12", stats.PER, stats.TSPct, stats.USGPCt, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.PlayerID, stats.Season)
+}
+
+func (s *SQLiteDB) UpdateOffAndDefRtg(offrtg, defrtg float64, playerid, season string) (sql.Result, error) {
+	return s.db.Exec("UPDATE advancedstats SET offrtg=$1, defrtg=$2 WHERE playerid=$3 AND season=$4", offrtg, defrtg, playerid, season)
+}
+
+func (s *SQLiteDB) InsertAdvancedStats(stats databasestructs.AdvancedStats) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO advancedstats (per, tspct, usgpct, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg, teamabbr, playerid, season) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)", stats.PER, stats.TSPct, stats.USGPCt, stats.OffWS, stats.DefWS, stats.WS, stats.OffBPM, stats.DefBPM, stats.BPM, stats.VORP, stats.OffRtg, stats.DefRtg, stats.TeamAbbr, stats.PlayerID, stats.Season)
+}
+
+func (s *SQLiteDB) GetDPOYStats(ctx context.Context, season string) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT players.playerid, name, gamesplayed, minutespergame, reboundspergame, stealspergame, blockspergame, position, dws, dbpm, defrtg FROM players INNER JOIN stats ON players.playerid=stats.playerid INNER JOIN advancedstats ON players.playerid=advancedstats.playerid WHERE advancedstats.season=$1 AND stats.season=$1 AND minutespergame > 20 ORDER BY dws DESC", season)
+}
+
+func (s *SQLiteDB) GetSixManStats(ctx context.Context, season string) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT players.playerid, name, gamesplayed, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg FROM players INNER JOIN stats ON players.playerid=stats.playerid INNER JOIN advancedstats ON players.playerid=advancedstats.playerid WHERE advancedstats.season=$1 AND stats.season=$1 AND gamesplayed - gamesstarted > gamesstarted ORDER BY per DESC", season)
+}
+
+func (s *SQLiteDB) GetROYStats(ctx context.Context, season string) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT players.playerid, name, gamesplayed, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, ws, bpm, offrtg, defrtg FROM players INNER JOIN stats ON players.playerid=stats.playerid INNER JOIN advancedstats ON players.playerid=advancedstats.playerid WHERE advancedstats.season=$1 AND stats.season=$1 AND rookieseason AND minutespergame > 10 ORDER BY per DESC", season)
+}
+
+func (s *SQLiteDB) SetRookieStatus(id string) (sql.Result, error) {
+	return s.db.Exec("UPDATE stats set rookieseason=true WHERE playerid=$1", id)
+}
+
+func (s *SQLiteDB) GetSeasonStats(ctx context.Context, season string) (*sql.Rows, error) {
+	query := `
+        SELECT players.playerid, name, stats.teamabbr, gamesplayed, gamesstarted, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, tspct, usgpct, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE advancedstats.season = $1 AND stats.season = $1`
+	return s.db.QueryContext(ctx, query, season)
+}
+
+func (s *SQLiteDB) GetStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) (*sql.Rows, error) {
+	column, ok := statColumns[filter.Stat]
+	if !ok {
+		return nil, fmt.Errorf("unknown stat %q", filter.Stat)
+	}
+
+	direction := "DESC"
+	if filter.Ascending {
+		direction = "ASC"
+	}
+
+	conditions, args := leaderConditions(filter)
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+        SELECT RANK() OVER (ORDER BY %[1]s %[2]s) AS leader_rank, players.playerid, name, COALESCE(stats.teamabbr, ''), COALESCE(stats.position, ''), stats.gamesplayed, stats.minutespergame, %[1]s
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE %[3]s
+        ORDER BY %[1]s %[2]s, name
+        LIMIT $%[4]d OFFSET $%[5]d`, column, direction, conditions, len(args)-1, len(args))
+	return s.db.QueryContext(ctx, query, args...)
+}
+
+// CountStatLeaders counts the players the filter qualifies, apart from the page so it's right past the last one too
+func (s *SQLiteDB) CountStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) *sql.Row {
+	conditions, args := leaderConditions(filter)
+	query := `
+        SELECT COUNT(*)
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid
+        WHERE ` + conditions
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+// leaderConditions builds the WHERE clause the leaders and their count share
+func leaderConditions(filter databasestructs.LeadersFilter) (string, []interface{}) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	season := arg(filter.Season)
+	conditions := []string{"stats.season = " + season, "advancedstats.season = " + season, "stats.gamesplayed >= " + arg(filter.MinGames), "stats.minutespergame >= " + arg(filter.MinMinutes)}
+	if filter.Position != "" {
+		// hybrid positions are stored as e.g. "PF-C", one of the parts has to match exactly
+		conditions = append(conditions, fmt.Sprintf("instr('-' || stats.position || '-', %s) > 0", arg("-"+filter.Position+"-")))
+	}
+	if filter.Team != "" {
+		conditions = append(conditions, "stats.teamabbr = "+arg(filter.Team))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (s *SQLiteDB) GetAllSeasonStats(ctx context.Context) (*sql.Rows, error) {
+	query := `
+        SELECT players.playerid, name, stats.season, stats.teamabbr, gamesplayed, gamesstarted, minutespergame, pointspergame, reboundspergame, assistspergame, stealspergame, blockspergame, fgpercentage, threeptpercentage, ftpercentage, turnoverspergame, position, per, tspct, usgpct, ows, dws, ws, obpm, dbpm, bpm, vorp, offrtg, defrtg
+        FROM players
+        INNER JOIN stats ON players.playerid = stats.playerid
+        INNER JOIN advancedstats ON players.playerid = advancedstats.playerid AND stats.season = advancedstats.season`
+	return s.db.QueryContext(ctx, query)
+}
@@ -0,0 +1,23 @@
+package sqlite_db
+
+import "time"
+
+func (s *SQLiteDB) GetLastSyncTime(name string) (time.Time, error) {
+	var lastSyncTime int64
+	err := s.db.QueryRow("SELECT last_sync_time FROM sync_time WHERE name = $1", name).Scan(&lastSyncTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(lastSyncTime, 0), nil
+}
+
+func (s *SQLiteDB) InsertLastSyncTime(newTime time.Time, name string) error {
+	_, err := s.db.Exec("INSERT INTO sync_time(name, last_sync_time) VALUES ($1, $2)", name, newTime.Unix())
+	return err
+}
+
+func (s *SQLiteDB) UpdateLastSyncTime(newTime time.Time, name string) error {
+	_, err := s.db.Exec("UPDATE sync_time SET last_sync_time=$1 WHERE name = $2", newTime.Unix(), name)
+	return err
+}
@@ -0,0 +1,18 @@
+package sqlite_db
+
+import (
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (s *SQLiteDB) InsertTeam(info databasestructs.TeamInfo) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO teams(teamabbr, name, logo, winlosspct, playoffs, divisiontitles, conferencetitles, championships) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT DO NOTHING", info.TeamAbbr, info.Name, info.Logo, info.WinLossPct, info.Playoffs, info.DivisionTitles, info.ConferenceTitles, info.Championships)
+}
+
+func (s *SQLiteDB) UpdateTeamForPlayer(teamabbr, playerid string) (sql.Result, error) {
+	return s.db.Exec("UPDATE players set teamabbr=$1 WHERE playerid=$2", teamabbr, playerid)
+}
+
+func (s *SQLiteDB) SelectTeamByAbbrevation(teamabbr string) *sql.Row {
+	return s.db.QueryRow("SELECT teamabbr from teams where teamabbr=$1", teamabbr)
+}
@@ -0,0 +1,115 @@
+package sqlite_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sportsvoting/databasestructs"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (s *SQLiteDB) CreateAdminUser() error {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE id = 1").Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	dbPass := strings.TrimSpace(os.Getenv("DBPASS"))
+	if count == 0 {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(dbPass), 14)
+		if err != nil {
+			return err
+		}
+
+		dbPass = string(bytes)
+		_, err = s.db.Exec("INSERT INTO users(id, username, password) VALUES (1, 'admin', $1)", dbPass)
+		if err != nil {
+			return fmt.Errorf("error inserting admin user: %v", err)
+		}
+
+		_, err = s.db.Exec("INSERT INTO user_roles (user_id, role) VALUES ($1, $2)", 1, "user,admin")
+		if err != nil {
+			return fmt.Errorf("error inserting user role for admin: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteDB) DeleteUser(id int64) (sql.Result, error) {
+	return s.db.Exec("DELETE FROM users WHERE id=$1", id)
+}
+
+func (s *SQLiteDB) GetAllUsers() (*sql.Rows, error) {
+	return s.db.Query("SELECT id, username, email, password, refresh_token, profile_pic FROM users")
+}
+
+func (s *SQLiteDB) GetUserByUsername(username string) *sql.Row {
+	return s.db.QueryRow("SELECT id, username, email, password, refresh_token, profile_pic FROM users WHERE username=$1", username)
+}
+
+func (s *SQLiteDB) GetUserByID(id int64) *sql.Row {
+	return s.db.QueryRow("SELECT username, email, profile_pic, COALESCE(favorite_team, ''), COALESCE(region, '') FROM users WHERE id=$1", id)
+}
+
+func (s *SQLiteDB) GetUserRolesByID(id int64) *sql.Row {
+	return s.db.QueryRow("SELECT role FROM user_roles WHERE user_id=$1", id)
+}
+
+func (s *SQLiteDB) InsertUserRoles(role databasestructs.Role) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO user_roles (user_id, role) VALUES ($1, $2)", role.UserID, role.Role)
+}
+
+func (s *SQLiteDB) UpdateUserRoles(roles string, user_id int64) (sql.Result, error) {
+	return s.db.Exec("UPDATE user_roles SET role=$1 WHERE user_id=$2", roles, user_id)
+}
+
+func (s *SQLiteDB) GetCurrentProfilePic(id int64) *sql.Row {
+	return s.db.QueryRow("SELECT profile_pic FROM users WHERE id=$1", id)
+}
+
+func (s *SQLiteDB) GetUserByRefreshToken(refresh_token string) *sql.Row {
+	return s.db.QueryRow("SELECT id, username, email FROM users WHERE refresh_token=$1", refresh_token)
+}
+
+func (s *SQLiteDB) InsertNewUser(user databasestructs.User) (sql.Result, error) {
+	return s.db.Exec("INSERT INTO users(username, email, password, refresh_token, created_at, signup_ip) VALUES ($1, $2, $3, $4, $5, $6)", user.Username, user.Email, user.Password, user.RefreshToken, user.CreatedAt, user.SignupIP)
+}
+
+func (s *SQLiteDB) UpdateUserRefreshToken(username, refresh_token string) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET refresh_token=$1 WHERE username=$2", refresh_token, username)
+}
+
+func (s *SQLiteDB) UpdateUserIsAdmin(username string, is_admin bool) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET is_admin=$1 WHERE username=$2", is_admin, username)
+}
+
+func (s *SQLiteDB) UpdateUserPassword(username, password string) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET password=$1 WHERE username=$2", password, username)
+}
+
+func (s *SQLiteDB) UpdateUserEmail(username, email string) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET email=$1 WHERE username=$2", email, username)
+}
+
+// UpdateUserProfile sets the self-reported profile fields, empty ones are cleared
+func (s *SQLiteDB) UpdateUserProfile(id int64, favoriteTeam, region string) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET favorite_team=NULLIF($1, ''), region=NULLIF($2, '') WHERE id=$3", favoriteTeam, region, id)
+}
+
+func (s *SQLiteDB) UpdateUserUsername(oldusername, username string) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET username=$1 WHERE username=$2", username, oldusername)
+}
+
+func (s *SQLiteDB) UpdateUserProfilePic(username, profile_pic string) (sql.Result, error) {
+	return s.db.Exec("UPDATE users SET profile_pic=$1 WHERE username=$2", profile_pic, username)
+}
+
+// GetVotesOfUser returns the votes of the user in the polls the viewer can see
+func (s *SQLiteDB) GetVotesOfUser(ctx context.Context, userid, viewerid int64) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, "SELECT po.id, COALESCE(v.playerid, v.goatplayerid, CAST(v.candidateid AS TEXT)) AS playerid, COALESCE(p.name, gp.name, c.name) AS player_name, po.name, po.image FROM player_votes v INNER JOIN polls po ON v.pollid = po.id LEFT JOIN players p ON v.playerid = p.playerid LEFT JOIN goat_players gp ON v.goatplayerid = gp.playerid LEFT JOIN poll_candidates c ON v.candidateid = c.id WHERE v.userid=$1 AND (po.groupid IS NULL OR po.groupid IN (SELECT groupid FROM group_members WHERE userid=$2))", userid, viewerid)
+}
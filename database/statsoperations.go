@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) GetSixManStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetSixManStats(ctx, season)
+	return collect(rows, err, scanPollPlayer)
+}
+
+func (r repository) GetDPOYStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetDPOYStats(ctx, season)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerInfo, error) {
+		var p databasestructs.PlayerInfo
+		err := row.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Rebounds, &p.Steals, &p.Blocks, &p.Position, &p.DefWS, &p.DefBPM, &p.DefRtg)
+		return p, err
+	})
+}
+
+func (r repository) GetROYStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetROYStats(ctx, season)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerInfo, error) {
+		var p databasestructs.PlayerInfo
+		err := row.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.WS, &p.BPM, &p.OffRtg, &p.DefRtg)
+		return p, err
+	})
+}
+
+// GetSeasonStats returns the regular and advanced stats of every player who played in the season
+func (r repository) GetSeasonStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetSeasonStats(ctx, season)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerInfo, error) {
+		var p databasestructs.PlayerInfo
+		err := row.Scan(&p.ID, &p.Name, &p.TeamAbbr, &p.Games, &p.GamesStarted, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.TSPct, &p.USGPCt, &p.OffWS, &p.DefWS, &p.WS, &p.OffBPM, &p.DefBPM, &p.BPM, &p.VORP, &p.OffRtg, &p.DefRtg)
+		p.PlayerStats.PlayerID = p.ID
+		p.PlayerStats.TeamAbbr = p.TeamAbbr
+		p.PlayerStats.Season = season
+		p.AdvancedStats.PlayerID = p.ID
+		p.AdvancedStats.TeamAbbr = p.TeamAbbr
+		p.AdvancedStats.Season = season
+		return p, err
+	})
+}
+
+// GetStatLeaders returns the page of leaders the filter asks for and how many players qualify in total
+func (r repository) GetStatLeaders(ctx context.Context, filter databasestructs.LeadersFilter) ([]databasestructs.StatLeader, int64, error) {
+	rows, err := r.Queries.GetStatLeaders(ctx, filter)
+	leaders, err := collect(rows, err, func(row scanner) (databasestructs.StatLeader, error) {
+		var leader databasestructs.StatLeader
+		err := row.Scan(&leader.Rank, &leader.ID, &leader.Name, &leader.TeamAbbr, &leader.Position, &leader.Games, &leader.Minutes, &leader.Value)
+		return leader, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := one(r.Queries.CountStatLeaders(ctx, filter), scanCount)
+	return leaders, total, err
+}
+
+// GetAllSeasonStats returns every player season that has both regular and advanced stats
+func (r repository) GetAllSeasonStats(ctx context.Context) ([]databasestructs.PlayerInfo, error) {
+	rows, err := r.Queries.GetAllSeasonStats(ctx)
+	return collect(rows, err, func(row scanner) (databasestructs.PlayerInfo, error) {
+		var p databasestructs.PlayerInfo
+		err := row.Scan(&p.ID, &p.Name, &p.PlayerStats.Season, &p.TeamAbbr, &p.Games, &p.GamesStarted, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.TSPct, &p.USGPCt, &p.OffWS, &p.DefWS, &p.WS, &p.OffBPM, &p.DefBPM, &p.BPM, &p.VORP, &p.OffRtg, &p.DefRtg)
+		p.PlayerStats.PlayerID = p.ID
+		p.PlayerStats.TeamAbbr = p.TeamAbbr
+		p.AdvancedStats.PlayerID = p.ID
+		p.AdvancedStats.TeamAbbr = p.TeamAbbr
+		p.AdvancedStats.Season = p.PlayerStats.Season
+		return p, err
+	})
+}
+
+// scanPollPlayer scans the season stats the award polls are voted on
+func scanPollPlayer(row scanner) (databasestructs.PlayerInfo, error) {
+	var p databasestructs.PlayerInfo
+	err := row.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.OffWS, &p.DefWS, &p.WS, &p.OffBPM, &p.DefBPM, &p.BPM, &p.VORP, &p.OffRtg, &p.DefRtg)
+	return p, err
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// InsertPollQuestion returns ErrConflict when the poll already has a question in that position
+func (r repository) InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error) {
+	res, err := r.Queries.InsertPollQuestion(question)
+	return res, conflict(err)
+}
+
+func (r repository) GetPollQuestions(ctx context.Context, pollid int64) ([]databasestructs.PollQuestion, error) {
+	rows, err := r.Queries.GetPollQuestions(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.PollQuestion, error) {
+		var question databasestructs.PollQuestion
+		err := row.Scan(&question.ID, &question.PollID, &question.Position, &question.Prompt, &question.Type, &question.Picks, &question.MinValue, &question.MaxValue)
+		return question, err
+	})
+}
+
+func (r repository) CountSurveyBallots(pollid int64) (int64, error) {
+	return one(r.Queries.CountSurveyBallots(pollid), scanCount)
+}
+
+// InsertSurveyBallot returns ErrConflict when the user already filled in the survey
+func (r repository) InsertSurveyBallot(pollid, userid int64) (sql.Result, error) {
+	res, err := r.Queries.InsertSurveyBallot(pollid, userid)
+	return res, conflict(err)
+}
+
+func (r repository) GetSurveyAnswers(ctx context.Context, pollid int64) ([]databasestructs.SurveyAnswer, error) {
+	rows, err := r.Queries.GetSurveyAnswers(ctx, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.SurveyAnswer, error) {
+		var answer databasestructs.SurveyAnswer
+		err := row.Scan(&answer.BallotID, &answer.QuestionID, &answer.Position, &answer.Value, &answer.Number)
+		return answer, err
+	})
+}
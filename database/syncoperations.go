@@ -0,0 +1,9 @@
+package database
+
+import "time"
+
+// GetLastSyncTime returns ErrNotFound when the sync never ran
+func (r repository) GetLastSyncTime(name string) (time.Time, error) {
+	syncTime, err := r.Queries.GetLastSyncTime(name)
+	return syncTime, notFound(err)
+}
@@ -0,0 +1,5 @@
+package database
+
+func (r repository) SelectTeamByAbbrevation(teamabbr string) (string, error) {
+	return one(r.Queries.SelectTeamByAbbrevation(teamabbr), scanString)
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+func (r repository) GetUserByUsername(username string) (databasestructs.User, error) {
+	return one(r.Queries.GetUserByUsername(username), scanUser)
+}
+
+func (r repository) GetUserByRefreshToken(refresh_token string) (databasestructs.User, error) {
+	return one(r.Queries.GetUserByRefreshToken(refresh_token), func(row scanner) (databasestructs.User, error) {
+		user := databasestructs.User{RefreshToken: refresh_token}
+		err := row.Scan(&user.ID, &user.Username, &user.Email)
+		return user, err
+	})
+}
+
+// GetUserByID returns the public profile of the user, without the password or tokens
+func (r repository) GetUserByID(id int64) (databasestructs.User, error) {
+	return one(r.Queries.GetUserByID(id), func(row scanner) (databasestructs.User, error) {
+		user := databasestructs.User{ID: id}
+		var profilePic sql.NullString
+		err := row.Scan(&user.Username, &user.Email, &profilePic, &user.FavoriteTeam, &user.Region)
+		user.ProfilePic = profilePic.String
+		return user, err
+	})
+}
+
+// GetUserRolesByID returns the comma separated roles of the user
+func (r repository) GetUserRolesByID(id int64) (string, error) {
+	return one(r.Queries.GetUserRolesByID(id), scanString)
+}
+
+func (r repository) GetAllUsers() ([]databasestructs.User, error) {
+	rows, err := r.Queries.GetAllUsers()
+	return collect(rows, err, scanUser)
+}
+
+// CreateAdminUser inserts the admin and their roles in one transaction, so a failed start doesn't leave an admin without roles
+func (r repository) CreateAdminUser() error {
+	return r.transaction(context.Background(), func(tx repository) error {
+		return tx.Queries.CreateAdminUser()
+	})
+}
+
+func (r repository) GetVotesOfUser(ctx context.Context, userid, viewerid int64) ([]databasestructs.UserVote, error) {
+	rows, err := r.Queries.GetVotesOfUser(ctx, userid, viewerid)
+	return collect(rows, err, func(row scanner) (databasestructs.UserVote, error) {
+		var vote databasestructs.UserVote
+		var playerName, pollImage sql.NullString
+		err := row.Scan(&vote.PollID, &vote.PlayerID, &playerName, &vote.PollName, &pollImage)
+		vote.PlayerName = playerName.String
+		vote.PollImage = pollImage.String
+		return vote, err
+	})
+}
+
+func (r repository) GetCurrentProfilePic(id int64) (string, error) {
+	return one(r.Queries.GetCurrentProfilePic(id), func(row scanner) (string, error) {
+		var profilePic sql.NullString
+		err := row.Scan(&profilePic)
+		return profilePic.String, err
+	})
+}
+
+func scanUser(row scanner) (databasestructs.User, error) {
+	var user databasestructs.User
+	var password, refreshToken, profilePic sql.NullString
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &password, &refreshToken, &profilePic)
+	user.Password = password.String
+	user.RefreshToken = refreshToken.String
+	user.ProfilePic = profilePic.String
+	return user, err
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sportsvoting/databasestructs"
+)
+
+// RetractPlayerVote returns ErrNotFound when the user has no vote in the poll, the vote goes together with writing the event
+func (r repository) RetractPlayerVote(pollid, userid int64) (sql.Result, error) {
+	res, err := r.execInTransaction(func(tx Queries) (sql.Result, error) {
+		return tx.RetractPlayerVote(pollid, userid)
+	})
+	return res, notFound(err)
+}
+
+func (r repository) GetUserVoteEvents(ctx context.Context, userid, viewerid, pollid int64) ([]databasestructs.VoteEvent, error) {
+	rows, err := r.Queries.GetUserVoteEvents(ctx, userid, viewerid, pollid)
+	return collect(rows, err, func(row scanner) (databasestructs.VoteEvent, error) {
+		var event databasestructs.VoteEvent
+		err := row.Scan(&event.ID, &event.PollID, &event.PollName, &event.Event, &event.Choice, &event.ChoiceName, &event.Previous, &event.PreviousName, &event.CreatedAt)
+		return event, err
+	})
+}
+
+// GetPollVoteEvents only fills in the event, the names of the choices and when it happened
+func (r repository) GetPollVoteEvents(ctx context.Context, pollid, since int64) ([]databasestructs.VoteEvent, error) {
+	rows, err := r.Queries.GetPollVoteEvents(ctx, pollid, since)
+	return collect(rows, err, func(row scanner) (databasestructs.VoteEvent, error) {
+		event := databasestructs.VoteEvent{PollID: pollid}
+		err := row.Scan(&event.Event, &event.ChoiceName, &event.PreviousName, &event.CreatedAt)
+		return event, err
+	})
+}
@@ -46,6 +46,10 @@ type User struct {
 	RefreshToken string `json:"refresh_token"`
 	ProfilePic   string `json:"profile_pic"`
 	IsAdmin      bool   `json:"is_admin"`
+	CreatedAt    int64  `json:"created_at,omitempty"`
+	SignupIP     string `json:"-"`
+	FavoriteTeam string `json:"favorite_team,omitempty"`
+	Region       string `json:"region,omitempty"`
 }
 
 type Role struct {
@@ -65,25 +69,38 @@ type TeamInfo struct {
 }
 
 type PlayerInfo struct {
-	Name          string `json:"name,omitempty"`
-	ID            string `json:"playerid,omitempty"`
-	College       string `json:"college,omitempty"`
-	TeamAbbr      string `json:"team,omitempty"`
-	Height        string `json:"height,omitempty"`
-	Weight        string `json:"weight,omitempty"`
-	Age           int64  `json:"age,omitempty"`
-	PlayerStats   `json:"stats,omitempty"`
-	AdvancedStats `json:"advstats,omitempty"`
+	Name           string `json:"name,omitempty"`
+	ID             string `json:"playerid,omitempty"`
+	College        string `json:"college,omitempty"`
+	TeamAbbr       string `json:"team,omitempty"`
+	Height         string `json:"height,omitempty"`
+	Weight         string `json:"weight,omitempty"`
+	Age            int64  `json:"age,omitempty"`
+	PlayerStats    `json:"stats,omitempty"`
+	AdvancedStats  `json:"advstats,omitempty"`
+	PositionGroup  string                 `json:"position_group,omitempty"`
+	CompositeScore *float64               `json:"composite_score,omitempty"`
+	LeagueContext  map[string]StatContext `json:"context,omitempty"`
+}
+
+type StatContext struct {
+	Percentile float64 `json:"percentile"`
+	ZScore     float64 `json:"zscore"`
 }
 
 type Poll struct {
-	ID            int64  `json:"id"`
-	Name          string `json:"name"`
-	Description   string `json:"description"`
-	Image         string `json:"image"`
-	SelectedStats string `json:"selected_stats"`
-	Season        string `json:"season"`
-	UserID        int64  `json:"user_id,omitempty"`
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Image         string   `json:"image"`
+	SelectedStats string   `json:"selected_stats"`
+	Season        string   `json:"season"`
+	UserID        int64    `json:"user_id,omitempty"`
+	ScoreFormula  string   `json:"score_formula"`
+	AllowWriteIns bool     `json:"allow_write_ins"`
+	GroupID       int64    `json:"groupid,omitempty"`
+	PanelWeight   *float64 `json:"panel_weight,omitempty"`
+	AllowGuests   bool     `json:"allow_guests"`
 }
 
 type Image struct {
@@ -118,6 +135,8 @@ type GoatStats struct {
 	TotalAssists      int64   `json:"total_assists,omitempty"`
 	TotalSteals       int64   `json:"total_steals,omitempty"`
 	TotalBlocks       int64   `json:"total_blocks,omitempty"`
+	Minutes           float64 `json:"mpg,omitempty"`
+	TSPct             float64 `json:"ts,omitempty"`
 	Position          string  `json:"position,omitempty"`
 	IsPlayoffs        bool    `json:"is_playoffs,omitempty"`
 	IsActive          bool    `json:"isactive,omitempty"`
@@ -156,4 +175,327 @@ type PollResponse struct {
 	TotalStats        *TotalStats    `json:"totalstats,omitempty"`
 	TotalPlayoffStats *TotalStats    `json:"totalplayoffstats,omitempty"`
 	GoatPlayers       *GoatPlayers   `json:"accolades,omitempty"`
+	EraAdjusted       *EraAdjusted   `json:"eraadjusted,omitempty"`
+	CompositeScore    *float64       `json:"composite_score,omitempty"`
+}
+
+type PlayerSearchResult struct {
+	ID        string  `json:"playerid"`
+	Name      string  `json:"name"`
+	Minutes   float64 `json:"mpg,omitempty"`
+	IsCurrent bool    `json:"current"`
+	IsGoat    bool    `json:"goat"`
+	Score     float64 `json:"score"`
+}
+
+type PlayerComparison struct {
+	ID           string             `json:"playerid"`
+	Name         string             `json:"name"`
+	Season       string             `json:"season,omitempty"`
+	Stats        *PlayerStats       `json:"stats,omitempty"`
+	AdvStats     *AdvancedStats     `json:"advstats,omitempty"`
+	CareerStats  *GoatStats         `json:"careerstats,omitempty"`
+	PlayoffStats *GoatStats         `json:"playoffstats,omitempty"`
+	Accolades    *GoatPlayers       `json:"accolades,omitempty"`
+	Qualified    bool               `json:"qualified"`
+	Percentiles  map[string]float64 `json:"percentiles,omitempty"`
+}
+
+type LeadersFilter struct {
+	Season     string
+	Stat       string
+	Ascending  bool
+	MinGames   int64
+	MinMinutes float64
+	Position   string
+	Team       string
+	Limit      int64
+	Offset     int64
+}
+
+type StatLeader struct {
+	Rank     int64   `json:"rank"`
+	ID       string  `json:"playerid"`
+	Name     string  `json:"name"`
+	TeamAbbr string  `json:"team"`
+	Position string  `json:"position"`
+	Games    int64   `json:"g"`
+	Minutes  float64 `json:"mpg"`
+	Value    float64 `json:"value"`
+}
+
+type LeagueAverages struct {
+	Season   string  `json:"season"`
+	Pace     float64 `json:"pace,omitempty"`
+	Points   float64 `json:"ppg,omitempty"`
+	Rebounds float64 `json:"rpg,omitempty"`
+	Assists  float64 `json:"apg,omitempty"`
+	TSPct    float64 `json:"ts,omitempty"`
+}
+
+type GoatPlayerSeason struct {
+	PlayerID string
+	Season   string
+	Games    int64
+}
+
+// EraAdjusted holds a player's career numbers per 100 possessions and relative to the league averages of the seasons played.
+// Relative counting stats are the player's per minute rate as a percentage of the league's, 100 being average, relative TS% is the difference in percentage points.
+type EraAdjusted struct {
+	PointsPer100     *float64       `json:"ppg_per100,omitempty"`
+	ReboundsPer100   *float64       `json:"rpg_per100,omitempty"`
+	AssistsPer100    *float64       `json:"apg_per100,omitempty"`
+	RelativePoints   *float64       `json:"ppg_relative,omitempty"`
+	RelativeRebounds *float64       `json:"rpg_relative,omitempty"`
+	RelativeAssists  *float64       `json:"apg_relative,omitempty"`
+	RelativeTSPct    *float64       `json:"ts_relative,omitempty"`
+	LeagueAverages   LeagueAverages `json:"league_averages"`
+}
+
+type MatchupVote struct {
+	ID           int64   `json:"id,omitempty"`
+	PollID       int64   `json:"pollid"`
+	UserID       int64   `json:"userid"`
+	WinnerID     string  `json:"winnerid"`
+	LoserID      string  `json:"loserid"`
+	RatingChange float64 `json:"rating_change,omitempty"`
+}
+
+type MatchupRating struct {
+	PlayerID string
+	Rating   float64
+	Matches  int64
+	Wins     int64
+}
+
+type Bracket struct {
+	PollID       int64  `json:"pollid"`
+	Size         int64  `json:"size"`
+	RoundHours   int64  `json:"round_hours"`
+	CurrentRound int64  `json:"current_round"`
+	RoundEndsAt  int64  `json:"round_ends_at"`
+	ChampionID   string `json:"championid,omitempty"`
+}
+
+type BracketMatchup struct {
+	ID        int64  `json:"id"`
+	PollID    int64  `json:"pollid"`
+	Round     int64  `json:"round"`
+	Position  int64  `json:"position"`
+	Seed1     int64  `json:"seed1"`
+	Player1ID string `json:"player1id"`
+	Seed2     int64  `json:"seed2"`
+	Player2ID string `json:"player2id"`
+	WinnerID  string `json:"winnerid,omitempty"`
+}
+
+type BracketVote struct {
+	MatchupID int64  `json:"matchupid"`
+	UserID    int64  `json:"userid"`
+	PlayerID  string `json:"playerid"`
+}
+
+type PollQuestion struct {
+	ID       int64    `json:"id"`
+	PollID   int64    `json:"pollid"`
+	Position int64    `json:"position"`
+	Prompt   string   `json:"prompt"`
+	Type     string   `json:"type"`
+	Picks    int64    `json:"picks,omitempty"`
+	MinValue *float64 `json:"min_value,omitempty"`
+	MaxValue *float64 `json:"max_value,omitempty"`
+}
+
+type SurveyAnswer struct {
+	BallotID   int64    `json:"-"`
+	QuestionID int64    `json:"questionid"`
+	Position   int64    `json:"position"`
+	Value      string   `json:"value,omitempty"`
+	Number     *float64 `json:"number,omitempty"`
+}
+
+type TeamStanding struct {
+	Season   string `json:"season"`
+	TeamAbbr string `json:"team"`
+	Wins     int64  `json:"wins"`
+	Losses   int64  `json:"losses"`
+}
+
+type SeasonAward struct {
+	Season   string `json:"season"`
+	Award    string `json:"award"`
+	PlayerID string `json:"playerid"`
+}
+
+type PredictionContest struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Season      string `json:"season"`
+	Deadline    int64  `json:"deadline"`
+	Resolved    bool   `json:"resolved"`
+	UserID      int64  `json:"userid"`
+}
+
+type PredictionQuestion struct {
+	ID           int64    `json:"id"`
+	ContestID    int64    `json:"contestid"`
+	Position     int64    `json:"position"`
+	Prompt       string   `json:"prompt"`
+	Type         string   `json:"type"`
+	Target       string   `json:"target"`
+	Points       int64    `json:"points"`
+	Answer       *string  `json:"answer,omitempty"`
+	AnswerNumber *float64 `json:"answer_number,omitempty"`
+}
+
+type Prediction struct {
+	QuestionID int64    `json:"questionid"`
+	UserID     int64    `json:"userid"`
+	Value      string   `json:"value,omitempty"`
+	Number     *float64 `json:"number,omitempty"`
+	Score      *float64 `json:"score,omitempty"`
+}
+
+type ContestStanding struct {
+	Rank     int64   `json:"rank"`
+	UserID   int64   `json:"userid"`
+	Username string  `json:"username"`
+	Score    float64 `json:"score"`
+	Graded   int64   `json:"graded"`
+}
+
+type PollCandidate struct {
+	ID          int64  `json:"id"`
+	PollID      int64  `json:"pollid"`
+	PlayerID    string `json:"playerid,omitempty"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	IsWriteIn   bool   `json:"is_write_in"`
+	SubmittedBy int64  `json:"submitted_by,omitempty"`
+}
+
+type Group struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InviteCode  string `json:"invite_code,omitempty"`
+	OwnerID     int64  `json:"ownerid"`
+	CreatedAt   int64  `json:"created_at"`
+	Members     int64  `json:"members"`
+}
+
+type GroupMember struct {
+	GroupID  int64  `json:"groupid"`
+	UserID   int64  `json:"userid"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	JoinedAt int64  `json:"joined_at"`
+}
+
+type GroupStanding struct {
+	Rank        int64   `json:"rank"`
+	UserID      int64   `json:"userid"`
+	Username    string  `json:"username"`
+	Score       float64 `json:"score"`
+	PollsVoted  int64   `json:"polls_voted"`
+	Predictions int64   `json:"predictions"`
+}
+
+type GuestVote struct {
+	PollID      int64  `json:"pollid"`
+	DeviceID    string `json:"deviceid"`
+	IP          string `json:"ip"`
+	PlayerID    string `json:"playerid"`
+	CandidateID int64  `json:"candidateid"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+type VoteFlag struct {
+	ID         int64  `json:"id"`
+	PollID     int64  `json:"pollid"`
+	UserID     int64  `json:"userid"`
+	Username   string `json:"username"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+	Status     string `json:"status"`
+	CreatedAt  int64  `json:"created_at"`
+	ReviewedBy int64  `json:"reviewed_by,omitempty"`
+}
+
+type VoteEvent struct {
+	ID           int64  `json:"id"`
+	PollID       int64  `json:"pollid"`
+	PollName     string `json:"poll_name"`
+	Event        string `json:"event"`
+	Choice       string `json:"choice,omitempty"`
+	ChoiceName   string `json:"choice_name,omitempty"`
+	Previous     string `json:"previous,omitempty"`
+	PreviousName string `json:"previous_name,omitempty"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+type VoteTally struct {
+	Name     string `json:"name"`
+	Votes    int64  `json:"votes"`
+	PollName string `json:"pollname,omitempty"`
+}
+
+type PanelVoteTally struct {
+	Name       string `json:"name"`
+	IsPanelist bool   `json:"is_panelist"`
+	Votes      int64  `json:"votes"`
+}
+
+type TimelineBucket struct {
+	Start int64  `json:"start"`
+	Name  string `json:"name"`
+	Votes int64  `json:"votes"`
+}
+
+type SegmentVoteTally struct {
+	Segment string `json:"segment"`
+	Name    string `json:"name"`
+	Votes   int64  `json:"votes"`
+}
+
+type UserVote struct {
+	PollID     int64  `json:"pollid"`
+	PlayerID   string `json:"playerid"`
+	PlayerName string `json:"player_name"`
+	PollName   string `json:"poll_name"`
+	PollImage  string `json:"poll_image"`
+}
+
+// BracketMatchupTally is a matchup with the names of both players and the votes each got so far
+type BracketMatchupTally struct {
+	BracketMatchup
+	Player1Name string `json:"player1name"`
+	Player2Name string `json:"player2name"`
+	Votes1      int64  `json:"votes1"`
+	Votes2      int64  `json:"votes2"`
+}
+
+// GoatPlayerCareer is the accolades of a GOAT candidate next to their regular season or playoff career numbers
+type GoatPlayerCareer struct {
+	Accolades GoatPlayers
+	Stats     GoatStats
+}
+
+// GoatEraSeason is one season of a GOAT candidate, with their career numbers and the league averages of that season
+type GoatEraSeason struct {
+	Career GoatStats
+	Games  int64
+	League LeagueAverages
+}
+
+type FraudCheckVote struct {
+	PollID         int64
+	UserID         int64
+	Choice         string
+	VotedAt        int64
+	IP             string
+	Switches       int64
+	AccountCreated int64
+	SignupIP       string
 }
@@ -0,0 +1,285 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// limits keep user supplied formulas cheap to evaluate for every candidate of a poll
+const (
+	MaxLength = 500
+	maxNodes  = 200
+)
+
+// Formula is a parsed weighted expression over stat names, e.g. "0.4*bpm + 0.3*ws + 2*mvp"
+type Formula struct {
+	root      node
+	Variables []string
+}
+
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type number float64
+
+type variable string
+
+type unary struct {
+	operand node
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (n number) eval(vars map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (v variable) eval(vars map[string]float64) (float64, error) {
+	value, ok := vars[string(v)]
+	if !ok {
+		return 0, fmt.Errorf("unknown stat %q", string(v))
+	}
+
+	return value, nil
+}
+
+func (u unary) eval(vars map[string]float64) (float64, error) {
+	value, err := u.operand.eval(vars)
+	return -value, err
+}
+
+func (b binary) eval(vars map[string]float64) (float64, error) {
+	left, err := b.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	right, err := b.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	case '/':
+		// a player with a zero stat shouldn't break the whole ranking
+		if right == 0 {
+			return 0, nil
+		}
+		return left / right, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator %q", b.op)
+}
+
+// Eval computes the formula with the given stat values, the result is rounded to two decimals
+func (f *Formula) Eval(vars map[string]float64) (float64, error) {
+	value, err := f.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, nil
+	}
+
+	return math.Round(value*100) / 100, nil
+}
+
+// Validate checks that the formula only uses stats from the allowed list
+func (f *Formula) Validate(allowed []string) error {
+	known := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		known[name] = true
+	}
+
+	for _, name := range f.Variables {
+		if !known[name] {
+			return fmt.Errorf("unknown stat %q in formula", name)
+		}
+	}
+
+	return nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+	nodes int
+	vars  map[string]bool
+	f     *Formula
+}
+
+// Parse supports numbers, stat names, +, -, *, / and parentheses. Stat names are case insensitive.
+func Parse(expr string) (*Formula, error) {
+	if len(expr) > MaxLength {
+		return nil, fmt.Errorf("formula is longer than %d characters", MaxLength)
+	}
+
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New("formula is empty")
+	}
+
+	p := &parser{input: []rune(expr), vars: make(map[string]bool), f: &Formula{}}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+
+	p.f.root = root
+	return p.f, nil
+}
+
+func (p *parser) parseExpression() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.consumeOperator('+', '-')
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		if left, err = p.newNode(binary{op: op, left: left, right: right}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.consumeOperator('*', '/')
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		if left, err = p.newNode(binary{op: op, left: left, right: right}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseFactor() (node, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, errors.New("unexpected end of formula")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '-' || c == '+':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		if c == '+' {
+			return operand, nil
+		}
+		return p.newNode(unary{operand: operand})
+	case c == '(':
+		p.pos++
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	case unicode.IsDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+
+		value, err := strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", string(p.input[start:p.pos]))
+		}
+		return p.newNode(number(value))
+	case unicode.IsLetter(c) || c == '_':
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+
+		name := strings.ToLower(string(p.input[start:p.pos]))
+		if !p.vars[name] {
+			p.vars[name] = true
+			p.f.Variables = append(p.f.Variables, name)
+		}
+		return p.newNode(variable(name))
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+}
+
+func (p *parser) consumeOperator(ops ...byte) (byte, bool) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0, false
+	}
+
+	for _, op := range ops {
+		if p.input[p.pos] == rune(op) {
+			p.pos++
+			return op, true
+		}
+	}
+
+	return 0, false
+}
+
+func (p *parser) newNode(n node) (node, error) {
+	p.nodes++
+	if p.nodes > maxNodes {
+		return nil, errors.New("formula is too complex")
+	}
+
+	return n, nil
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
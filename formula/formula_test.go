@@ -0,0 +1,106 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+var stats = map[string]float64{"ppg": 30, "rpg": 10, "apg": 5, "bpm": 8, "zero": 0}
+
+func TestEval(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want float64
+	}{
+		{"ppg + rpg * apg", 80},
+		{"(ppg + rpg) * apg", 200},
+		{"ppg - rpg - apg", 15},
+		{"ppg / rpg / apg", 0.6},
+		{"ppg - rpg * 2 / 4", 25},
+		{"-ppg + rpg", -20},
+		{"-(ppg + rpg)", -40},
+		{"--ppg", 30},
+		{"+ppg", 30},
+		{"rpg * -apg", -50},
+		{"0.4*bpm + 0.3*ppg", 12.2},
+		{"PPG + Rpg", 40},
+		{"ppg / 3", 10},
+		{"rpg / 3", 3.33},
+		// a zero stat doesn't break the ranking of the other players
+		{"ppg / zero", 0},
+		{"ppg / (rpg - 10)", 0},
+		{"ppg + apg / zero", 30},
+	} {
+		f, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.expr, err)
+			continue
+		}
+
+		got, err := f.Eval(stats)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Eval(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{"", "formula is empty"},
+		{"   ", "formula is empty"},
+		{strings.Repeat("1", MaxLength+1), "longer than"},
+		{strings.Repeat("1+", maxNodes/2) + "1", "too complex"},
+		{strings.Repeat("-", maxNodes+1) + "1", "too complex"},
+		{"ppg +", "unexpected end"},
+		{"(ppg + rpg", "missing closing parenthesis"},
+		{"ppg + rpg)", `unexpected ')' at position 10`},
+		{"ppg % rpg", `unexpected '%' at position 5`},
+		{"1.2.3", "invalid number"},
+		{"ppg rpg", `unexpected 'r' at position 5`},
+	} {
+		_, err := Parse(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%.20q) error = %v, want one containing %q", tc.expr, err, tc.want)
+		}
+	}
+}
+
+func TestParseAtTheLimits(t *testing.T) {
+	if _, err := Parse("ppg" + strings.Repeat(" ", MaxLength-3)); err != nil {
+		t.Errorf("formula of %d characters: %v", MaxLength, err)
+	}
+
+	// n+1 numbers and n operators make 2n+1 nodes, the most an odd count can get under the limit
+	if _, err := Parse(strings.Repeat("1+", (maxNodes-1)/2) + "1"); err != nil {
+		t.Errorf("formula of %d nodes: %v", maxNodes-1, err)
+	}
+}
+
+func TestUnknownStats(t *testing.T) {
+	f, err := Parse("ppg + 2*Steals + ppg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := strings.Join(f.Variables, " "), "ppg steals"; got != want {
+		t.Errorf("variables = %s, want %s", got, want)
+	}
+
+	if err := f.Validate([]string{"ppg", "rpg"}); err == nil || !strings.Contains(err.Error(), `unknown stat "steals"`) {
+		t.Errorf("Validate error = %v, want unknown stat steals", err)
+	}
+	if err := f.Validate([]string{"ppg", "steals"}); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	if _, err := f.Eval(stats); err == nil || !strings.Contains(err.Error(), `unknown stat "steals"`) {
+		t.Errorf("Eval error = %v, want unknown stat steals", err)
+	}
+}
@@ -0,0 +1,174 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"time"
+)
+
+const (
+	detectionTimeout = 2 * time.Minute
+	// only votes from this far back are checked, older ones have already been looked at
+	lookback = 7 * 24 * time.Hour
+
+	// accounts younger than this when they voted count as new
+	newAccountAge = 24 * time.Hour
+	burstWindow   = time.Hour
+	minBurstSize  = 5
+
+	minIPCluster = 4
+	maxSwitches  = 5
+)
+
+// DetectFraud flags the ballots of the recent votes that look like stuffing, admins review the flags and decide if the ballots count
+func DetectFraud(db database.Database, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), detectionTimeout)
+	defer cancel()
+
+	votes, err := db.GetVotesForFraudCheck(ctx, now.Add(-lookback).Unix())
+	if err != nil {
+		return err
+	}
+
+	var flags []databasestructs.VoteFlag
+	flags = append(flags, findNewAccountBursts(votes)...)
+	flags = append(flags, findIPClusters(votes)...)
+	flags = append(flags, findVoteSwitching(votes)...)
+
+	for _, flag := range flags {
+		flag.CreatedAt = now.Unix()
+		if _, err := db.InsertVoteFlag(flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findNewAccountBursts flags new accounts that picked the same candidate within a short window of each other
+func findNewAccountBursts(votes []databasestructs.FraudCheckVote) []databasestructs.VoteFlag {
+	byChoice := make(map[string][]databasestructs.FraudCheckVote)
+	for _, vote := range votes {
+		if vote.AccountCreated == 0 || vote.VotedAt-vote.AccountCreated > int64(newAccountAge.Seconds()) {
+			continue
+		}
+
+		key := fmt.Sprintf("%d|%s", vote.PollID, vote.Choice)
+		byChoice[key] = append(byChoice[key], vote)
+	}
+
+	var flags []databasestructs.VoteFlag
+	for _, group := range byChoice {
+		sort.Slice(group, func(i, j int) bool { return group[i].VotedAt < group[j].VotedAt })
+
+		flagged := make(map[int64]bool)
+		start := 0
+		for end := range group {
+			for group[end].VotedAt-group[start].VotedAt > int64(burstWindow.Seconds()) {
+				start++
+			}
+
+			if end-start+1 < minBurstSize {
+				continue
+			}
+
+			for _, vote := range group[start : end+1] {
+				flagged[vote.UserID] = true
+			}
+		}
+
+		if len(flagged) == 0 {
+			continue
+		}
+
+		for userID := range flagged {
+			flags = append(flags, databasestructs.VoteFlag{
+				PollID:  group[0].PollID,
+				UserID:  userID,
+				Reason:  "new_account_burst",
+				Details: fmt.Sprintf("%d new accounts voted for %s within an hour", len(flagged), group[0].Choice),
+			})
+		}
+	}
+
+	return flags
+}
+
+type clusterKey struct {
+	pollID int64
+	ip     string
+}
+
+type ballotKey struct {
+	pollID int64
+	userID int64
+}
+
+// findIPClusters flags ballots when several accounts voted in the same poll from the same address or signed up from it
+func findIPClusters(votes []databasestructs.FraudCheckVote) []databasestructs.VoteFlag {
+	clusters := make(map[clusterKey]map[int64]bool)
+	add := func(pollID, userID int64, ip string) {
+		if ip == "" {
+			return
+		}
+
+		key := clusterKey{pollID: pollID, ip: ip}
+		if clusters[key] == nil {
+			clusters[key] = make(map[int64]bool)
+		}
+		clusters[key][userID] = true
+	}
+
+	for _, vote := range votes {
+		add(vote.PollID, vote.UserID, vote.IP)
+		add(vote.PollID, vote.UserID, vote.SignupIP)
+	}
+
+	// one flag per ballot even when it's in more than one cluster
+	seen := make(map[ballotKey]bool)
+	var flags []databasestructs.VoteFlag
+	for key, users := range clusters {
+		if len(users) < minIPCluster {
+			continue
+		}
+
+		for userID := range users {
+			ballot := ballotKey{pollID: key.pollID, userID: userID}
+			if seen[ballot] {
+				continue
+			}
+			seen[ballot] = true
+
+			flags = append(flags, databasestructs.VoteFlag{
+				PollID:  key.pollID,
+				UserID:  userID,
+				Reason:  "ip_cluster",
+				Details: fmt.Sprintf("%d accounts voted or signed up from the same address", len(users)),
+			})
+		}
+	}
+
+	return flags
+}
+
+// findVoteSwitching flags users that keep changing their vote, which is how coordinated accounts get moved between candidates
+func findVoteSwitching(votes []databasestructs.FraudCheckVote) []databasestructs.VoteFlag {
+	var flags []databasestructs.VoteFlag
+	for _, vote := range votes {
+		if vote.Switches < maxSwitches {
+			continue
+		}
+
+		flags = append(flags, databasestructs.VoteFlag{
+			PollID:  vote.PollID,
+			UserID:  vote.UserID,
+			Reason:  "vote_switching",
+			Details: fmt.Sprintf("vote changed %d times", vote.Switches),
+		})
+	}
+
+	return flags
+}
@@ -0,0 +1,126 @@
+package fraud
+
+import (
+	"fmt"
+	"sort"
+	"sportsvoting/databasestructs"
+	"testing"
+	"time"
+)
+
+const day = int64(24 * time.Hour / time.Second)
+
+// flagged lists the flags as poll/user, sorted so the map order of the checks doesn't matter
+func flagged(flags []databasestructs.VoteFlag) string {
+	list := []string{}
+	for _, flag := range flags {
+		list = append(list, fmt.Sprintf("%d/%d", flag.PollID, flag.UserID))
+	}
+	sort.Strings(list)
+	return fmt.Sprint(list)
+}
+
+// newAccountVotes are votes for the choice in poll 1 by accounts created an hour before, cast the given minutes apart from the first one
+func newAccountVotes(choice string, minutes ...int64) []databasestructs.FraudCheckVote {
+	var votes []databasestructs.FraudCheckVote
+	for i, m := range minutes {
+		votedAt := 10*day + m*60
+		votes = append(votes, databasestructs.FraudCheckVote{PollID: 1, UserID: int64(i + 1), Choice: choice, VotedAt: votedAt, AccountCreated: votedAt - 3600})
+	}
+	return votes
+}
+
+func TestFindNewAccountBursts(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		votes func() []databasestructs.FraudCheckVote
+		want  string
+	}{
+		{"burst", func() []databasestructs.FraudCheckVote {
+			return newAccountVotes("p1", 0, 5, 10, 15, 20)
+		}, "[1/1 1/2 1/3 1/4 1/5]"},
+		{"too few accounts", func() []databasestructs.FraudCheckVote {
+			return newAccountVotes("p1", 0, 5, 10, 15)
+		}, "[]"},
+		{"exactly an hour", func() []databasestructs.FraudCheckVote {
+			return newAccountVotes("p1", 0, 15, 30, 45, 60)
+		}, "[1/1 1/2 1/3 1/4 1/5]"},
+		{"longer than an hour", func() []databasestructs.FraudCheckVote {
+			return newAccountVotes("p1", 0, 20, 40, 60, 80)
+		}, "[]"},
+		{"only the votes in the window", func() []databasestructs.FraudCheckVote {
+			return newAccountVotes("p1", 0, 10, 20, 30, 40, 200)
+		}, "[1/1 1/2 1/3 1/4 1/5]"},
+		{"different choices", func() []databasestructs.FraudCheckVote {
+			votes := newAccountVotes("p1", 0, 5, 10, 15, 20)
+			votes[4].Choice = "p2"
+			return votes
+		}, "[]"},
+		{"different polls", func() []databasestructs.FraudCheckVote {
+			votes := newAccountVotes("p1", 0, 5, 10, 15, 20)
+			votes[4].PollID = 2
+			return votes
+		}, "[]"},
+		{"an account older than a day", func() []databasestructs.FraudCheckVote {
+			votes := newAccountVotes("p1", 0, 5, 10, 15, 20)
+			votes[4].AccountCreated = votes[4].VotedAt - day - 1
+			return votes
+		}, "[]"},
+		{"an account without a signup time", func() []databasestructs.FraudCheckVote {
+			votes := newAccountVotes("p1", 0, 5, 10, 15, 20)
+			votes[4].AccountCreated = 0
+			return votes
+		}, "[]"},
+	} {
+		if got := flagged(findNewAccountBursts(tc.votes())); got != tc.want {
+			t.Errorf("%s: flagged = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFindIPClusters(t *testing.T) {
+	vote := func(pollID, userID int64, ip, signupIP string) databasestructs.FraudCheckVote {
+		return databasestructs.FraudCheckVote{PollID: pollID, UserID: userID, IP: ip, SignupIP: signupIP}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		votes []databasestructs.FraudCheckVote
+		want  string
+	}{
+		{"one address", []databasestructs.FraudCheckVote{
+			vote(1, 1, "a", ""), vote(1, 2, "a", ""), vote(1, 3, "a", ""), vote(1, 4, "a", ""),
+		}, "[1/1 1/2 1/3 1/4]"},
+		{"too few accounts", []databasestructs.FraudCheckVote{
+			vote(1, 1, "a", ""), vote(1, 2, "a", ""), vote(1, 3, "a", ""), vote(1, 1, "a", ""),
+		}, "[]"},
+		{"signed up from the address", []databasestructs.FraudCheckVote{
+			vote(1, 1, "a", ""), vote(1, 2, "a", ""), vote(1, 3, "b", "a"), vote(1, 4, "c", "a"),
+		}, "[1/1 1/2 1/3 1/4]"},
+		{"one flag per ballot in two clusters", []databasestructs.FraudCheckVote{
+			vote(1, 1, "a", "b"), vote(1, 2, "a", "b"), vote(1, 3, "a", "b"), vote(1, 4, "a", "b"),
+		}, "[1/1 1/2 1/3 1/4]"},
+		{"different polls", []databasestructs.FraudCheckVote{
+			vote(1, 1, "a", ""), vote(1, 2, "a", ""), vote(1, 3, "a", ""), vote(2, 4, "a", ""),
+		}, "[]"},
+		{"unknown addresses", []databasestructs.FraudCheckVote{
+			vote(1, 1, "", ""), vote(1, 2, "", ""), vote(1, 3, "", ""), vote(1, 4, "", ""),
+		}, "[]"},
+	} {
+		if got := flagged(findIPClusters(tc.votes)); got != tc.want {
+			t.Errorf("%s: flagged = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFindVoteSwitching(t *testing.T) {
+	votes := []databasestructs.FraudCheckVote{
+		{PollID: 1, UserID: 1, Switches: maxSwitches - 1},
+		{PollID: 1, UserID: 2, Switches: maxSwitches},
+		{PollID: 2, UserID: 1, Switches: maxSwitches + 3},
+	}
+
+	if got, want := flagged(findVoteSwitching(votes)), "[1/2 2/1]"; got != want {
+		t.Errorf("flagged = %s, want %s", got, want)
+	}
+}
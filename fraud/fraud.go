@@ -0,0 +1,115 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sportsvoting/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FraudHandler struct {
+	DB database.Database
+}
+
+type ReviewPayload struct {
+	FlagID int64  `json:"flagid"`
+	UserID int64  `json:"userid"`
+	Status string `json:"status"`
+}
+
+// GetFlags lists the flagged ballots for the admins, optionally only the ones of a poll
+func (f FraudHandler) GetFlags(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !f.isAdmin(userID) {
+		http.Error(w, "only admins can review flagged votes", http.StatusForbidden)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+
+	if status != "open" && status != "excluded" && status != "cleared" {
+		http.Error(w, "status has to be open, excluded or cleared", http.StatusBadRequest)
+		return
+	}
+
+	pollID, _ := strconv.ParseInt(r.URL.Query().Get("pollid"), 10, 64)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	flags, err := f.DB.GetVoteFlags(ctx, status, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(flags)
+}
+
+// ReviewFlag excludes the flagged ballot from the poll's official result or clears it, the vote itself is kept either way
+func (f FraudHandler) ReviewFlag(w http.ResponseWriter, r *http.Request) {
+	var payload ReviewPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !f.isAdmin(payload.UserID) {
+		http.Error(w, "only admins can review flagged votes", http.StatusForbidden)
+		return
+	}
+
+	if payload.Status != "excluded" && payload.Status != "cleared" && payload.Status != "open" {
+		http.Error(w, "status has to be excluded, cleared or open", http.StatusBadRequest)
+		return
+	}
+
+	flag, err := f.DB.GetVoteFlagByID(payload.FlagID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "flag not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = f.DB.UpdateVoteFlagStatus(flag.PollID, flag.UserID, payload.Status, payload.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Ballot of %s in poll %d is %s", flag.Username, flag.PollID, payload.Status)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (f FraudHandler) isAdmin(userID int64) bool {
+	if userID == 0 {
+		return false
+	}
+
+	roles, err := f.DB.GetUserRolesByID(userID)
+	if err != nil {
+		return false
+	}
+
+	for _, role := range strings.Split(roles, ",") {
+		if strings.TrimSpace(role) == "admin" {
+			return true
+		}
+	}
+
+	return false
+}
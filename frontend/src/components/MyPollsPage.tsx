@@ -41,7 +41,7 @@ const MyPollsPage: React.FC = () => {
 	}, []);
 
 	const fetchPolls = () => {
-		axiosInstance.get<Poll[]>(`/polls/users/get/${auth.id}`)
+		axiosInstance.get<Poll[]>(`/polls/users/get/${auth.id}?userid=${auth.id}`)
 			.then(response => {
 				setPolls(response.data);
 			})
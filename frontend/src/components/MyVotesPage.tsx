@@ -20,7 +20,7 @@ const MyVotesPage: React.FC = () => {
 
 	const fetchUserVotes = useCallback(async () => {
 		try {
-			const response = await axiosInstance.get<MyVotesResponse[]>(`/votes/users/get/${auth.id}`);
+			const response = await axiosInstance.get<MyVotesResponse[]>(`/votes/users/get/${auth.id}?userid=${auth.id}`);
 			setUserVotes(response.data);
 		} catch (error) {
 			console.error('Error fetching user votes:', error);
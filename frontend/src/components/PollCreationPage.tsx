@@ -21,7 +21,10 @@ const PollCreationPage: React.FC = () => {
 	const [description, setDescription] = useState<string>('');
 	const [season, setSeason] = useState<string>('');
 	const [selectedStats, setSelectedStats] = useState<string>('');
-	const [statsOptions] = useState<string[]>(["All stats", "Defensive", "Sixth man", "Rookie", "GOAT stats"]);
+	const [scoreFormula, setScoreFormula] = useState<string>('');
+	const [statsOptions] = useState<string[]>(["All stats", "Defensive", "Sixth man", "Rookie", "GOAT stats", "Custom"]);
+	const [allowWriteIns, setAllowWriteIns] = useState<boolean>(false);
+	const [allowGuests, setAllowGuests] = useState<boolean>(false);
 	const [seasonOptions, setSeasonOptions] = useState<string[]>([]);
 	const [fetchedSeasonOptions, setFetchedSeasonOptions] = useState<string[]>([]);
 	const [selectedFile, setSelectedFile] = useState<File | null>(null);
@@ -51,6 +54,10 @@ const PollCreationPage: React.FC = () => {
 		setDescription(event.target.value);
 	};
 
+	const handleScoreFormulaChange = (event: React.ChangeEvent<HTMLInputElement>) => {
+		setScoreFormula(event.target.value);
+	};
+
 	const handleSeasonChange = (
 		event: SelectChangeEvent<string>
 	) => {
@@ -93,6 +100,9 @@ const PollCreationPage: React.FC = () => {
 		data.append('description', description);
 		data.append('season', season);
 		data.append('selectedStats', selectedStats);
+		data.append('scoreFormula', scoreFormula);
+		data.append('allowWriteIns', allowWriteIns.toString());
+		data.append('allowGuests', allowGuests.toString());
 		data.append('photo', selectedFile);
 		data.append('userid', auth.id.toString());
 		try {
@@ -164,6 +174,35 @@ const PollCreationPage: React.FC = () => {
 								</Select>
 							</FormControl>
 						</Grid>
+						<Grid item md={12}>
+							<div className="label">Score formula (optional, e.g. 0.4*bpm + 0.3*ws + 2*mvp):</div>
+							<TextField
+								fullWidth
+								variant="outlined"
+								value={scoreFormula}
+								onChange={handleScoreFormulaChange}
+							/>
+						</Grid>
+						<Grid item md={12}>
+							<label className="label">
+								<input
+									type="checkbox"
+									checked={allowWriteIns}
+									onChange={(e) => setAllowWriteIns(e.target.checked)}
+								/>
+								Allow write-in candidates
+							</label>
+						</Grid>
+						<Grid item md={12}>
+							<label className="label">
+								<input
+									type="checkbox"
+									checked={allowGuests}
+									onChange={(e) => setAllowGuests(e.target.checked)}
+								/>
+								Allow guest votes without an account
+							</label>
+						</Grid>
 						<Grid item md={12}>
 							<div className="label">Upload new photo:</div>
 							<input
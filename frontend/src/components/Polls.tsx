@@ -10,12 +10,13 @@ const Polls = () => {
     const [polls, setPolls] = useState([]);
     const fetchData = useCallback(async () => {
         try {
-            const response = await axiosInstance.get('/polls/get');
+            // group polls are only returned for their members
+            const response = await axiosInstance.get('/polls/get', { params: { userid: auth?.id } });
             setPolls(response.data);
         } catch (error) {
             console.error('Error fetching data:', error);
         }
-    }, []);
+    }, [auth?.id]);
 
     useEffect(() => {
         // Fetch data from Go server
@@ -53,7 +53,7 @@ func GetGoatPlayersList() map[string]bool {
 
 func InsertGoatPlayerStats(playerIds map[string]bool, db database.Database) {
 	for playerID := range playerIds {
-		goatPlayer, goatStatsRegular, goatStatsPlayoffs := scrapePlayerInfo(playerID)
+		goatPlayer, goatStatsRegular, goatStatsPlayoffs, seasons := scrapePlayerInfo(playerID)
 
 		if goatPlayer.ID != "" {
 			_, err := db.InsertGOATPlayer(goatPlayer)
@@ -77,21 +77,23 @@ func InsertGoatPlayerStats(playerIds map[string]bool, db database.Database) {
 				continue
 			}
 
+			insertPlayerSeasons(db, seasons)
 			time.Sleep(4 * time.Second)
 		}
 	}
 }
 
-func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestructs.GoatStats, databasestructs.GoatStats) {
+func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestructs.GoatStats, databasestructs.GoatStats, []databasestructs.GoatPlayerSeason) {
 	var goatPlayer databasestructs.GoatPlayers
 	var goatStatsRegular, goatStatsPlayoffs databasestructs.GoatStats
+	var seasons []databasestructs.GoatPlayerSeason
 	url := fmt.Sprintf("https://www.basketball-reference.com/players/%s/%s.html", string(playerID[0]), playerID)
 	log.Println(url)
 	doc, err := request.GetDocumentFromURL(url)
 	if err != nil {
 		log.Println(err)
 		time.Sleep(4 * time.Second)
-		return goatPlayer, goatStatsRegular, goatStatsPlayoffs
+		return goatPlayer, goatStatsRegular, goatStatsPlayoffs, seasons
 	}
 
 	goatPlayer.ID = playerID
@@ -117,6 +119,7 @@ func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestr
 	doc.Find("table#per_game tfoot tr").Each(func(i int, s *goquery.Selection) {
 		league := s.Find("td[data-stat='lg_id']").Text()
 		if league == "NBA" && goatStatsRegular.Points == 0 {
+			goatStatsRegular.Minutes = scraper.GetTDDataStatFloat(s, "mp_per_g")
 			goatStatsRegular.Points = scraper.GetTDDataStatFloat(s, "pts_per_g")
 			goatStatsRegular.Rebounds = scraper.GetTDDataStatFloat(s, "trb_per_g")
 			goatStatsRegular.Assists = scraper.GetTDDataStatFloat(s, "ast_per_g")
@@ -129,9 +132,21 @@ func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestr
 		}
 	})
 
+	// traded players have a total row first and then one per team, only the first row of a season is kept
+	seen := make(map[string]bool)
+	doc.Find("table#per_game tbody tr").Each(func(i int, s *goquery.Selection) {
+		league := s.Find("td[data-stat='lg_id']").Text()
+		season := scraper.GetSeasonEndYear(s)
+		if league == "NBA" && season != "" && !seen[season] {
+			seen[season] = true
+			seasons = append(seasons, databasestructs.GoatPlayerSeason{PlayerID: playerID, Season: season, Games: scraper.GetTDDataStatInt(s, "g")})
+		}
+	})
+
 	doc.Find("table#playoffs_per_game tfoot tr").Each(func(i int, s *goquery.Selection) {
 		league := s.Find("td[data-stat='lg_id']").Text()
 		if league == "NBA" && goatStatsPlayoffs.Points == 0 {
+			goatStatsPlayoffs.Minutes = scraper.GetTDDataStatFloat(s, "mp_per_g")
 			goatStatsPlayoffs.Points = scraper.GetTDDataStatFloat(s, "pts_per_g")
 			goatStatsPlayoffs.Rebounds = scraper.GetTDDataStatFloat(s, "trb_per_g")
 			goatStatsPlayoffs.Assists = scraper.GetTDDataStatFloat(s, "ast_per_g")
@@ -170,6 +185,7 @@ func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestr
 		league := s.Find("td[data-stat='lg_id']").Text()
 		if league == "NBA" && goatStatsRegular.PER == 0 {
 			goatStatsRegular.PER = scraper.GetTDDataStatFloat(s, "per")
+			goatStatsRegular.TSPct = scraper.GetTDDataStatFloat(s, "ts_pct")
 			goatStatsRegular.OffBPM = scraper.GetTDDataStatFloat(s, "obpm")
 			goatStatsRegular.DefBPM = scraper.GetTDDataStatFloat(s, "dbpm")
 			goatStatsRegular.BPM = scraper.GetTDDataStatFloat(s, "bpm")
@@ -184,6 +200,7 @@ func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestr
 		league := s.Find("td[data-stat='lg_id']").Text()
 		if league == "NBA" && goatStatsPlayoffs.PER == 0 {
 			goatStatsPlayoffs.PER = scraper.GetTDDataStatFloat(s, "per")
+			goatStatsPlayoffs.TSPct = scraper.GetTDDataStatFloat(s, "ts_pct")
 			goatStatsPlayoffs.OffBPM = scraper.GetTDDataStatFloat(s, "obpm")
 			goatStatsPlayoffs.DefBPM = scraper.GetTDDataStatFloat(s, "dbpm")
 			goatStatsPlayoffs.BPM = scraper.GetTDDataStatFloat(s, "bpm")
@@ -230,24 +247,26 @@ func scrapePlayerInfo(playerID string) (databasestructs.GoatPlayers, databasestr
 		}
 	})
 
-	return goatPlayer, goatStatsRegular, goatStatsPlayoffs
+	return goatPlayer, goatStatsRegular, goatStatsPlayoffs, seasons
+}
+
+func insertPlayerSeasons(db database.Database, seasons []databasestructs.GoatPlayerSeason) {
+	for _, season := range seasons {
+		_, err := db.InsertGOATPlayerSeason(season)
+		if err != nil {
+			log.Println(err)
+		}
+	}
 }
 
 func UpdateActiveGOATStats(db database.Database) error {
-	rows, err := db.GetActivePlayers()
+	playerIDs, err := db.GetActivePlayers()
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var playerID string
-		err := rows.Scan(&playerID)
-		if err != nil {
-			return err
-		}
 
-		goatPlayer, goatStatsRegular, goatStatsPlayoffs := scrapePlayerInfo(playerID)
+	for _, playerID := range playerIDs {
+		goatPlayer, goatStatsRegular, goatStatsPlayoffs, seasons := scrapePlayerInfo(playerID)
 		if goatPlayer.ID != "" {
 			_, err = db.UpdateGOATPlayer(goatPlayer)
 			if err != nil {
@@ -266,6 +285,7 @@ func UpdateActiveGOATStats(db database.Database) error {
 				log.Println(err)
 			}
 
+			insertPlayerSeasons(db, seasons)
 			time.Sleep(4 * time.Second)
 		}
 	}
@@ -0,0 +1,411 @@
+package groups
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	inviteCodeLength = 8
+	// no 0/O or 1/I so codes can be read out loud
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	maxGroupNameLength = 128
+)
+
+type GroupsHandler struct {
+	DB database.Database
+}
+
+type GroupPayload struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	UserID      int64  `json:"userid"`
+}
+
+type MembershipPayload struct {
+	GroupID    int64  `json:"groupid"`
+	InviteCode string `json:"invite_code"`
+	UserID     int64  `json:"userid"`
+}
+
+type GroupResponse struct {
+	databasestructs.Group
+	Role string `json:"role"`
+}
+
+// CanView tells if the user can see a poll of the group, polls without a group are public
+func CanView(db database.Database, groupID, userID int64) (bool, error) {
+	if groupID == 0 {
+		return true, nil
+	}
+
+	role, err := MemberRole(db, groupID, userID)
+	return role != "", err
+}
+
+// MemberRole returns the role of the user in the group, or an empty string when they aren't a member
+func MemberRole(db database.Database, groupID, userID int64) (string, error) {
+	role, err := db.GetGroupMemberRole(groupID, userID)
+	if errors.Is(err, database.ErrNotFound) {
+		return "", nil
+	}
+
+	return role, err
+}
+
+func (g GroupsHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var payload GroupPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload.Name = strings.TrimSpace(payload.Name)
+	if payload.Name == "" || len(payload.Name) > maxGroupNameLength {
+		http.Error(w, fmt.Sprintf("name has to be between 1 and %d characters", maxGroupNameLength), http.StatusBadRequest)
+		return
+	}
+
+	if payload.UserID == 0 {
+		http.Error(w, "missing user id", http.StatusBadRequest)
+		return
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	group := databasestructs.Group{Name: payload.Name, Description: payload.Description, InviteCode: code, OwnerID: payload.UserID, CreatedAt: time.Now().Unix(), Members: 1}
+	result, err := g.DB.InsertGroup(group)
+	if err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			http.Error(w, "invite code is taken, try again", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	group.ID, _ = result.LastInsertId()
+	_, err = g.DB.InsertGroupMember(databasestructs.GroupMember{GroupID: group.ID, UserID: payload.UserID, Role: "owner", JoinedAt: group.CreatedAt})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(GroupResponse{Group: group, Role: "owner"})
+}
+
+func (g GroupsHandler) GetUserGroups(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseID(r, "userid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	groups, err := g.DB.GetUserGroups(ctx, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(groups)
+}
+
+func (g GroupsHandler) GetGroupByID(w http.ResponseWriter, r *http.Request) {
+	group, role, ok := g.getMemberGroup(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(GroupResponse{Group: group, Role: role})
+}
+
+// JoinGroup adds the user to the group the invite code belongs to
+func (g GroupsHandler) JoinGroup(w http.ResponseWriter, r *http.Request) {
+	var payload MembershipPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.UserID == 0 {
+		http.Error(w, "missing user id", http.StatusBadRequest)
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(payload.InviteCode))
+	group, err := g.DB.GetGroupByInviteCode(code)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "invalid invite code", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := g.DB.InsertGroupMember(databasestructs.GroupMember{GroupID: group.ID, UserID: payload.UserID, Role: "member", JoinedAt: time.Now().Unix()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if joined, _ := result.RowsAffected(); joined > 0 {
+		group.Members++
+	}
+
+	role, err := MemberRole(g.DB, group.ID, payload.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GroupResponse{Group: group, Role: role})
+}
+
+// LeaveGroup removes the user from the group, the owner has to delete the group instead
+func (g GroupsHandler) LeaveGroup(w http.ResponseWriter, r *http.Request) {
+	var payload MembershipPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := MemberRole(g.DB, payload.GroupID, payload.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role == "" {
+		http.Error(w, "user isn't a member of the group", http.StatusNotFound)
+		return
+	}
+
+	if role == "owner" {
+		http.Error(w, "the owner can't leave the group, delete it instead", http.StatusConflict)
+		return
+	}
+
+	_, err = g.DB.DeleteGroupMember(payload.GroupID, payload.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("User %d left group %d", payload.UserID, payload.GroupID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// ResetInviteCode replaces the invite code so the old one stops working
+func (g GroupsHandler) ResetInviteCode(w http.ResponseWriter, r *http.Request) {
+	var payload MembershipPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := MemberRole(g.DB, payload.GroupID, payload.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role != "owner" {
+		http.Error(w, "only the owner can reset the invite code", http.StatusForbidden)
+		return
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = g.DB.UpdateGroupInviteCode(payload.GroupID, code)
+	if err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			http.Error(w, "invite code is taken, try again", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(code))
+}
+
+func (g GroupsHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "groupid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	role, err := MemberRole(g.DB, id, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if role != "owner" {
+		http.Error(w, "only the owner can delete the group", http.StatusForbidden)
+		return
+	}
+
+	_, err = g.DB.DeleteGroup(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g GroupsHandler) GetGroupMembers(w http.ResponseWriter, r *http.Request) {
+	group, _, ok := g.getMemberGroup(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	members, err := g.DB.GetGroupMembers(ctx, group.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(members)
+}
+
+func (g GroupsHandler) GetGroupPolls(w http.ResponseWriter, r *http.Request) {
+	group, _, ok := g.getMemberGroup(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	polls, err := g.DB.GetGroupPolls(ctx, group.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(polls)
+}
+
+// GetGroupLeaderboard ranks the members by their prediction contest points and their participation in the group polls
+func (g GroupsHandler) GetGroupLeaderboard(w http.ResponseWriter, r *http.Request) {
+	group, _, ok := g.getMemberGroup(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	standings, err := g.DB.GetGroupLeaderboard(ctx, group.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range standings {
+		standing := &standings[i]
+		standing.Score = math.Round(standing.Score*10) / 10
+
+		// members with the same score and participation share the rank
+		standing.Rank = int64(i + 1)
+		if i > 0 && standings[i-1].Score == standing.Score && standings[i-1].PollsVoted == standing.PollsVoted {
+			standing.Rank = standings[i-1].Rank
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// getMemberGroup loads the group of the request, writing a not found error when the user isn't a member so private groups stay hidden
+func (g GroupsHandler) getMemberGroup(w http.ResponseWriter, r *http.Request) (databasestructs.Group, string, bool) {
+	id, err := parseID(r, "groupid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return databasestructs.Group{}, "", false
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	role, err := MemberRole(g.DB, id, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return databasestructs.Group{}, "", false
+	}
+
+	if role == "" {
+		http.Error(w, "group not found", http.StatusNotFound)
+		return databasestructs.Group{}, "", false
+	}
+
+	group, err := g.DB.GetGroupByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return databasestructs.Group{}, "", false
+	}
+
+	return group, role, true
+}
+
+func newInviteCode() (string, error) {
+	code := make([]byte, inviteCodeLength)
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+func parseID(r *http.Request, key string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[key], 10, 64)
+}
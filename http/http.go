@@ -6,7 +6,11 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sportsvoting/contests"
 	"sportsvoting/database"
+	"sportsvoting/fraud"
+	"sportsvoting/groups"
+	"sportsvoting/players"
 	"sportsvoting/polls"
 	"sportsvoting/syncer"
 	"sportsvoting/users"
@@ -22,6 +26,10 @@ func SetupHandlers(db database.Database) *mux.Router {
 	usersHandler := users.UsersHandler{DB: db}
 	votesHandler := votes.VotesHandler{DB: db}
 	pollsHandler := polls.PollsHandler{DB: db}
+	playersHandler := players.PlayersHandler{DB: db}
+	contestsHandler := contests.ContestsHandler{DB: db}
+	groupsHandler := groups.GroupsHandler{DB: db}
+	fraudHandler := fraud.FraudHandler{DB: db}
 
 	r := mux.NewRouter()
 	api := r.PathPrefix("/api").Subrouter()
@@ -38,6 +46,8 @@ func SetupHandlers(db database.Database) *mux.Router {
 	api.HandleFunc("/users/username/update", usersHandler.UpdateUsername).Methods("POST")
 	api.HandleFunc("/users/password/update", usersHandler.UpdatePassword).Methods("POST")
 	api.HandleFunc("/users/admin/update", usersHandler.UpdateAdmin).Methods("POST")
+	api.HandleFunc("/users/panelist/update", usersHandler.UpdatePanelist).Methods("POST")
+	api.HandleFunc("/users/profile/update", usersHandler.UpdateProfile).Methods("POST")
 	api.HandleFunc("/users/image/update", usersHandler.UploadProfilePicHandler).Methods("POST")
 	api.HandleFunc("/users/admin/create", usersHandler.CreateUserAdmin).Methods("POST")
 
@@ -50,12 +60,61 @@ func SetupHandlers(db database.Database) *mux.Router {
 	api.HandleFunc("/polls/image/update", pollsHandler.UpdatePollImage).Methods("POST")
 	api.HandleFunc("/polls/votes/reset", pollsHandler.ResetPollVotes).Methods("POST")
 	api.HandleFunc("/polls/users/get/{userid}", pollsHandler.GetUserPolls)
+	api.HandleFunc("/polls/{pollid:[0-9]+}/matchup", pollsHandler.GetMatchup)
+	api.HandleFunc("/polls/{pollid:[0-9]+}/matchup/rankings", pollsHandler.GetMatchupRankings)
+	api.HandleFunc("/polls/matchup/vote", pollsHandler.InsertMatchupVote).Methods("POST")
+	api.HandleFunc("/polls/{pollid:[0-9]+}/bracket", pollsHandler.GetBracket).Methods("GET")
+	api.HandleFunc("/polls/bracket/create", pollsHandler.CreateBracket).Methods("POST")
+	api.HandleFunc("/polls/bracket/vote", pollsHandler.InsertBracketVote).Methods("POST")
+	api.HandleFunc("/polls/{pollid:[0-9]+}/questions", pollsHandler.GetPollQuestions).Methods("GET")
+	api.HandleFunc("/polls/questions/update", pollsHandler.SetPollQuestions).Methods("POST")
+	api.HandleFunc("/polls/ballot", pollsHandler.SubmitBallot).Methods("POST")
+	api.HandleFunc("/polls/{pollid:[0-9]+}/survey/results", pollsHandler.GetSurveyResults).Methods("GET")
+	api.HandleFunc("/polls/{pollid:[0-9]+}/candidates", pollsHandler.GetPollCandidates).Methods("GET")
+	api.HandleFunc("/polls/candidates/add", pollsHandler.AddPollCandidates).Methods("POST")
+	api.HandleFunc("/polls/candidates/writein", pollsHandler.SubmitWriteIn).Methods("POST")
+	api.HandleFunc("/polls/candidates/moderate", pollsHandler.ModerateCandidate).Methods("POST")
+
+	api.HandleFunc("/groups/create", groupsHandler.CreateGroup).Methods("POST")
+	api.HandleFunc("/groups/join", groupsHandler.JoinGroup).Methods("POST")
+	api.HandleFunc("/groups/leave", groupsHandler.LeaveGroup).Methods("POST")
+	api.HandleFunc("/groups/invite/reset", groupsHandler.ResetInviteCode).Methods("POST")
+	api.HandleFunc("/groups/users/get/{userid:[0-9]+}", groupsHandler.GetUserGroups).Methods("GET")
+	api.HandleFunc("/groups/get/{groupid:[0-9]+}", groupsHandler.GetGroupByID).Methods("GET")
+	api.HandleFunc("/groups/delete/{groupid:[0-9]+}", groupsHandler.DeleteGroup).Methods("DELETE")
+	api.HandleFunc("/groups/{groupid:[0-9]+}/members", groupsHandler.GetGroupMembers).Methods("GET")
+	api.HandleFunc("/groups/{groupid:[0-9]+}/polls", groupsHandler.GetGroupPolls).Methods("GET")
+	api.HandleFunc("/groups/{groupid:[0-9]+}/leaderboard", groupsHandler.GetGroupLeaderboard).Methods("GET")
 
 	api.HandleFunc("/votes/users/get/{userid}", votesHandler.GetUserVotes)
+	api.HandleFunc("/votes/users/{userid:[0-9]+}/history", votesHandler.GetUserVoteHistory).Methods("GET")
 	api.HandleFunc("/votes/players/{id:[0-9]+}", votesHandler.PlayerVotes).Methods("GET")
+	api.HandleFunc("/votes/players/{id:[0-9]+}/panel", votesHandler.PanelVotes).Methods("GET")
+	api.HandleFunc("/votes/players/{id:[0-9]+}/guests", votesHandler.GuestVotes).Methods("GET")
+	api.HandleFunc("/votes/players/{id:[0-9]+}/momentum", votesHandler.PollMomentum).Methods("GET")
+	api.HandleFunc("/votes/players/{id:[0-9]+}/timeline", votesHandler.PollTimeline).Methods("GET")
+	api.HandleFunc("/votes/players/{id:[0-9]+}/segments", votesHandler.SegmentVotes).Methods("GET")
+	api.HandleFunc("/votes/players/retract", votesHandler.RetractVote).Methods("POST")
+	api.HandleFunc("/votes/guest/token", votesHandler.IssueGuestToken).Methods("POST")
+	api.HandleFunc("/votes/guest", votesHandler.InsertGuestVote).Methods("POST")
 	api.HandleFunc("/votes/players", votesHandler.InsertPlayerVotes).Methods("POST")
 	api.HandleFunc("/votes/teams/{id:[0-9]+}", votesHandler.TeamVotes)
 
+	api.HandleFunc("/players/search", playersHandler.SearchPlayers).Methods("GET")
+	api.HandleFunc("/players/compare", playersHandler.ComparePlayers).Methods("GET")
+	api.HandleFunc("/players/{id}/similar", playersHandler.GetSimilarPlayers).Methods("GET")
+	api.HandleFunc("/leaders", playersHandler.GetLeaders).Methods("GET")
+
+	api.HandleFunc("/contests/get", contestsHandler.GetContests).Methods("GET")
+	api.HandleFunc("/contests/get/{contestid:[0-9]+}", contestsHandler.GetContestByID).Methods("GET")
+	api.HandleFunc("/contests/create", contestsHandler.CreateContest).Methods("POST")
+	api.HandleFunc("/contests/predictions", contestsHandler.SubmitPredictions).Methods("POST")
+	api.HandleFunc("/contests/{contestid:[0-9]+}/predictions/{userid:[0-9]+}", contestsHandler.GetUserPredictions).Methods("GET")
+	api.HandleFunc("/contests/{contestid:[0-9]+}/leaderboard", contestsHandler.GetLeaderboard).Methods("GET")
+
+	api.HandleFunc("/fraud/flags", fraudHandler.GetFlags).Methods("GET")
+	api.HandleFunc("/fraud/flags/review", fraudHandler.ReviewFlag).Methods("POST")
+
 	api.HandleFunc("/seasons/get", pollsHandler.GetSeasons)
 
 	return r
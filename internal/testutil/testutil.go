@@ -0,0 +1,106 @@
+// Package testutil holds the fixtures and request helpers the handler and repository tests share
+package testutil
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sportsvoting/database"
+	"sportsvoting/database/memory_db"
+	"sportsvoting/databasestructs"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// the users and the group NewDB seeds
+const (
+	OwnerID    int64 = 1
+	MemberID   int64 = 2
+	OutsiderID int64 = 3
+
+	GroupID int64 = 1
+)
+
+// NewDB returns an in-memory database with three users, owner, member and outsider, and a group the first two are in
+func NewDB(t *testing.T) database.Database {
+	t.Helper()
+	db := memory_db.NewDB()
+	exec := Must(t)
+
+	for _, name := range []string{"owner", "member", "outsider"} {
+		exec(db.InsertNewUser(databasestructs.User{Username: name, CreatedAt: time.Now().Unix()}))
+	}
+
+	exec(db.InsertGroup(databasestructs.Group{Name: "league", InviteCode: "invite", OwnerID: OwnerID, CreatedAt: time.Now().Unix()}))
+	exec(db.InsertGroupMember(databasestructs.GroupMember{GroupID: GroupID, UserID: OwnerID, Role: "owner"}))
+	exec(db.InsertGroupMember(databasestructs.GroupMember{GroupID: GroupID, UserID: MemberID, Role: "member"}))
+
+	return db
+}
+
+// Must returns a check for the result of a seeding write, which fails the test when the write does
+func Must(t *testing.T) func(sql.Result, error) {
+	return func(_ sql.Result, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Route is a handler the way http.SetupHandlers registers it, without the /api prefix
+type Route struct {
+	Path    string
+	Handler http.HandlerFunc
+	Methods []string // any method when empty
+}
+
+// NewRouter registers the routes under test
+func NewRouter(routes ...Route) *mux.Router {
+	r := mux.NewRouter()
+	for _, route := range routes {
+		handler := r.HandleFunc(route.Path, route.Handler)
+		if len(route.Methods) > 0 {
+			handler.Methods(route.Methods...)
+		}
+	}
+	return r
+}
+
+// JSONRequest returns a request with the payload encoded as its body, a nil payload sends none
+func JSONRequest(t *testing.T, method, target string, payload interface{}) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return httptest.NewRequest(method, target, &body)
+}
+
+func Serve(t *testing.T, router http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ServeJSON serves a request with the payload encoded as its body
+func ServeJSON(t *testing.T, router http.Handler, method, target string, payload interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return Serve(t, router, JSONRequest(t, method, target, payload))
+}
+
+func Decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,66 @@
+package leagueaverages
+
+import (
+	"log"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/request"
+	"sportsvoting/scraper"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func GetLeagueAverages() ([]databasestructs.LeagueAverages, error) {
+	url := "https://www.basketball-reference.com/leagues/NBA_stats_per_game.html"
+	doc, err := request.GetDocumentFromURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var averages []databasestructs.LeagueAverages
+	doc.Find("table#stats > tbody > tr").Each(func(i int, row *goquery.Selection) {
+		season := scraper.GetSeasonEndYear(row)
+		if season == "" {
+			return
+		}
+
+		avg := databasestructs.LeagueAverages{
+			Season:   season,
+			Pace:     scraper.GetTDDataStatFloat(row, "pace"),
+			Points:   scraper.GetTDDataStatFloat(row, "pts_per_g"),
+			Rebounds: scraper.GetTDDataStatFloat(row, "trb_per_g"),
+			Assists:  scraper.GetTDDataStatFloat(row, "ast_per_g"),
+			TSPct:    scraper.GetTDDataStatFloat(row, "ts_pct"),
+		}
+
+		// older seasons don't list TS%, but it can be worked out from the shooting averages
+		if avg.TSPct == 0 {
+			fga := scraper.GetTDDataStatFloat(row, "fga_per_g")
+			fta := scraper.GetTDDataStatFloat(row, "fta_per_g")
+			if fga+0.44*fta > 0 {
+				avg.TSPct = avg.Points / (2 * (fga + 0.44*fta))
+			}
+		}
+
+		averages = append(averages, avg)
+	})
+
+	return averages, nil
+}
+
+func InsertLeagueAverages(db database.Database) error {
+	log.Println("Getting league averages")
+	averages, err := GetLeagueAverages()
+	if err != nil {
+		return err
+	}
+
+	for _, avg := range averages {
+		_, err := db.InsertLeagueAverages(avg)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
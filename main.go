@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"sportsvoting/database"
 	"sportsvoting/http"
 	"sportsvoting/migrate"
+	"sportsvoting/polls"
 	"sportsvoting/syncer"
 	"time"
 )
@@ -13,9 +15,20 @@ import (
 var db database.Database
 
 func main() {
+	db_type := os.Getenv("DBTYPE")
+	if db_type == "" {
+		db_type = database.MYSQL
+	}
+
 	db_addr := os.Getenv("DBADDRESS")
 	if db_addr == "" {
 		db_addr = "localhost:3306"
+		switch db_type {
+		case database.POSTGRES:
+			db_addr = "localhost:5432"
+		case database.SQLITE:
+			db_addr = "nba.db"
+		}
 	}
 
 	var err error
@@ -25,7 +38,7 @@ func main() {
 	for i := 0; i < retries; i++ {
 		log.Println("Attempting to connect to database")
 
-		db, err = database.NewDB(database.Config{DbType: "mysql", DbName: "nba", Addr: db_addr})
+		db, err = database.NewDB(database.Config{DbType: db_type, DbName: "nba", Addr: db_addr})
 		if err == nil {
 			break
 		}
@@ -44,7 +57,7 @@ func main() {
 
 	defer db.CloseConnection()
 
-	if err := migrate.RunMigrations(db.GetDB()); err != nil {
+	if err := migrate.RunMigrations(db.GetDB(), db_type); err != nil {
 		log.Fatalf("Error running migrations: %v", err)
 	}
 
@@ -54,6 +67,10 @@ func main() {
 		log.Fatalf("Error creating admin user: %v", err)
 	}
 
+	if err := polls.RebuildMatchupRatings(context.Background(), db); err != nil {
+		log.Fatalf("Error rebuilding matchup ratings: %v", err)
+	}
+
 	syncer.SyncRegular(db)
 	syncer.SyncGOAT(db)
 	syncer.SetupSyncSchedules(db)
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"math"
+	"sportsvoting/databasestructs"
+	"strings"
+)
+
+type Distribution struct {
+	values        []float64
+	mean          float64
+	stddev        float64
+	lowerIsBetter bool
+}
+
+func NewDistribution(values []float64, lowerIsBetter bool) Distribution {
+	d := Distribution{values: values, lowerIsBetter: lowerIsBetter}
+	if len(values) == 0 {
+		return d
+	}
+
+	for _, v := range values {
+		d.mean += v
+	}
+	d.mean /= float64(len(values))
+
+	for _, v := range values {
+		d.stddev += (v - d.mean) * (v - d.mean)
+	}
+	d.stddev = math.Sqrt(d.stddev / float64(len(values)))
+
+	return d
+}
+
+func (d Distribution) Percentile(value float64) float64 {
+	return Percentile(d.values, value, d.lowerIsBetter)
+}
+
+// Standardize returns how many standard deviations the value is from the mean, without rounding
+func (d Distribution) Standardize(value float64) float64 {
+	if d.stddev == 0 {
+		return 0
+	}
+
+	return (value - d.mean) / d.stddev
+}
+
+// ZScore is flipped for stats where lower is better, so a positive score always means above average
+func (d Distribution) ZScore(value float64) float64 {
+	if d.stddev == 0 {
+		return 0
+	}
+
+	z := d.Standardize(value)
+	if d.lowerIsBetter {
+		z = -z
+	}
+
+	return round(z, 2)
+}
+
+// PositionGroup maps a position like "SG" or "PF-C" to guard, forward or center by its primary position
+func PositionGroup(position string) string {
+	primary := strings.Split(strings.TrimSpace(position), "-")[0]
+	switch {
+	case primary == "C":
+		return "C"
+	case strings.HasSuffix(primary, "G"):
+		return "G"
+	case strings.HasSuffix(primary, "F"):
+		return "F"
+	}
+
+	return ""
+}
+
+// AddLeagueContext fills in the percentile and z-score of the named stats for every player, measured against the pool
+func AddLeagueContext(players []databasestructs.PlayerInfo, pool []databasestructs.PlayerInfo, names []string, byPosition bool) {
+	groups := map[string][]databasestructs.PlayerInfo{"": pool}
+	if byPosition {
+		groups = make(map[string][]databasestructs.PlayerInfo)
+		for _, p := range pool {
+			group := PositionGroup(p.Position)
+			groups[group] = append(groups[group], p)
+		}
+	}
+
+	distributions := make(map[string]map[string]Distribution, len(groups))
+	for group, groupPool := range groups {
+		distributions[group] = make(map[string]Distribution, len(names))
+		for _, name := range names {
+			if m, ok := Find(name); ok {
+				distributions[group][name] = NewDistribution(Values(groupPool, m), m.LowerIsBetter)
+			}
+		}
+	}
+
+	for i := range players {
+		group := ""
+		if byPosition {
+			group = PositionGroup(players[i].Position)
+			players[i].PositionGroup = group
+		}
+
+		groupDistributions, ok := distributions[group]
+		if !ok {
+			continue
+		}
+
+		players[i].LeagueContext = make(map[string]databasestructs.StatContext, len(names))
+		for _, name := range names {
+			m, ok := Find(name)
+			if !ok {
+				continue
+			}
+
+			value := m.Value(players[i])
+			d := groupDistributions[name]
+			players[i].LeagueContext[name] = databasestructs.StatContext{Percentile: d.Percentile(value), ZScore: d.ZScore(value)}
+		}
+	}
+}
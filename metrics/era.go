@@ -0,0 +1,77 @@
+package metrics
+
+import "sportsvoting/databasestructs"
+
+// teamMinutesPerGame is the player minutes a team plays in a regulation game
+const teamMinutesPerGame = 5 * 48
+
+// EraBaseline accumulates the league averages of the seasons a player played, weighted by games played in each.
+// Early seasons are missing pace and some shooting numbers, so every average keeps its own weight.
+type EraBaseline struct {
+	sums    [5]float64
+	weights [5]float64
+}
+
+func (b *EraBaseline) Add(games int64, avg databasestructs.LeagueAverages) {
+	for i, v := range []float64{avg.Pace, avg.Points, avg.Rebounds, avg.Assists, avg.TSPct} {
+		if v > 0 && games > 0 {
+			b.sums[i] += v * float64(games)
+			b.weights[i] += float64(games)
+		}
+	}
+}
+
+func (b *EraBaseline) average(i int) float64 {
+	if b.weights[i] == 0 {
+		return 0
+	}
+
+	return b.sums[i] / b.weights[i]
+}
+
+func (b *EraBaseline) Averages() databasestructs.LeagueAverages {
+	return databasestructs.LeagueAverages{
+		Pace:     round(b.average(0), 1),
+		Points:   round(b.average(1), 1),
+		Rebounds: round(b.average(2), 1),
+		Assists:  round(b.average(3), 1),
+		TSPct:    round(b.average(4), 3),
+	}
+}
+
+// EraAdjust compares career per game numbers to the league environment they were put up in,
+// the relative stats are per minute so a starter and a bench player of the same rate are both at 100
+func EraAdjust(career databasestructs.GoatStats, baseline EraBaseline) *databasestructs.EraAdjusted {
+	league := baseline.Averages()
+	adjusted := &databasestructs.EraAdjusted{LeagueAverages: league}
+
+	if pace := baseline.average(0); pace > 0 && career.Minutes > 0 {
+		possessions := career.Minutes / 48 * pace
+		adjusted.PointsPer100 = roundedPtr(career.Points/possessions*100, 1)
+		adjusted.ReboundsPer100 = roundedPtr(career.Rebounds/possessions*100, 1)
+		adjusted.AssistsPer100 = roundedPtr(career.Assists/possessions*100, 1)
+	}
+
+	// the league averages are team totals per game, spread over the minutes of the five players on the floor they're a per minute rate of the average player
+	relative := func(perGame float64, i int) *float64 {
+		avg := baseline.average(i)
+		if avg <= 0 || career.Minutes <= 0 {
+			return nil
+		}
+		return roundedPtr(perGame/career.Minutes/(avg/teamMinutesPerGame)*100, 1)
+	}
+	adjusted.RelativePoints = relative(career.Points, 1)
+	adjusted.RelativeRebounds = relative(career.Rebounds, 2)
+	adjusted.RelativeAssists = relative(career.Assists, 3)
+
+	if avg := baseline.average(4); avg > 0 && career.TSPct > 0 {
+		adjusted.RelativeTSPct = roundedPtr((career.TSPct-avg)*100, 1)
+	}
+
+	return adjusted
+}
+
+func roundedPtr(value float64, places int) *float64 {
+	v := round(value, places)
+	return &v
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"sportsvoting/databasestructs"
+	"strconv"
+	"testing"
+)
+
+func TestEraAdjust(t *testing.T) {
+	// weighted by games the seasons average to a pace of 100, 120 points, 48 rebounds, 24 assists and .560 TS%,
+	// per minute of the average player that's .5 points, .2 rebounds and .1 assists, per 100 possessions 24, 9.6 and 4.8
+	var baseline EraBaseline
+	baseline.Add(60, databasestructs.LeagueAverages{Pace: 95, Points: 110, Rebounds: 46, Assists: 22, TSPct: 0.55})
+	baseline.Add(20, databasestructs.LeagueAverages{Pace: 115, Points: 150, Rebounds: 54, Assists: 30, TSPct: 0.59})
+
+	for _, tc := range []struct {
+		name   string
+		career databasestructs.GoatStats
+		want   string
+	}{
+		{"league average starter", databasestructs.GoatStats{Minutes: 36, Points: 18, Rebounds: 7.2, Assists: 3.6, TSPct: 0.56}, "24.0 9.6 4.8 | 100.0 100.0 100.0 0.0"},
+		{"league average bench player", databasestructs.GoatStats{Minutes: 12, Points: 6, Rebounds: 2.4, Assists: 1.2, TSPct: 0.56}, "24.0 9.6 4.8 | 100.0 100.0 100.0 0.0"},
+		{"star", databasestructs.GoatStats{Minutes: 30, Points: 30, Rebounds: 12, Assists: 3, TSPct: 0.6}, "48.0 19.2 4.8 | 200.0 200.0 100.0 4.0"},
+		{"no minutes", databasestructs.GoatStats{Points: 30, TSPct: 0.5}, "- - - | - - - -6.0"},
+	} {
+		adjusted := EraAdjust(tc.career, baseline)
+
+		got := ""
+		for i, v := range []*float64{adjusted.PointsPer100, adjusted.ReboundsPer100, adjusted.AssistsPer100, adjusted.RelativePoints, adjusted.RelativeRebounds, adjusted.RelativeAssists, adjusted.RelativeTSPct} {
+			switch {
+			case i == 3:
+				got += " | "
+			case i > 0:
+				got += " "
+			}
+			if v == nil {
+				got += "-"
+			} else {
+				got += formatFloat(*v)
+			}
+		}
+		if got != tc.want {
+			t.Errorf("%s: adjusted = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	if league := baseline.Averages(); league.Pace != 100 || league.Points != 120 || league.TSPct != 0.56 {
+		t.Errorf("league averages = %+v, want the seasons weighted by games", league)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"math"
+	"sportsvoting/databasestructs"
+)
+
+// players under this many minutes per game are left out of league comparisons and the rate stat leaderboards
+const MinQualifyingMinutes = 20.0
+
+type Metric struct {
+	Name          string
+	LowerIsBetter bool
+	// season totals rather than per game or per possession rates, so they don't need a minutes qualifier
+	Cumulative bool
+	Value      func(p databasestructs.PlayerInfo) float64
+}
+
+var PlayerMetrics = []Metric{
+	{Name: "g", Cumulative: true, Value: func(p databasestructs.PlayerInfo) float64 { return float64(p.Games) }},
+	{Name: "gs", Cumulative: true, Value: func(p databasestructs.PlayerInfo) float64 { return float64(p.GamesStarted) }},
+	{Name: "mpg", Value: func(p databasestructs.PlayerInfo) float64 { return p.Minutes }},
+	{Name: "ppg", Value: func(p databasestructs.PlayerInfo) float64 { return p.Points }},
+	{Name: "rpg", Value: func(p databasestructs.PlayerInfo) float64 { return p.Rebounds }},
+	{Name: "apg", Value: func(p databasestructs.PlayerInfo) float64 { return p.Assists }},
+	{Name: "spg", Value: func(p databasestructs.PlayerInfo) float64 { return p.Steals }},
+	{Name: "bpg", Value: func(p databasestructs.PlayerInfo) float64 { return p.Blocks }},
+	{Name: "topg", LowerIsBetter: true, Value: func(p databasestructs.PlayerInfo) float64 { return p.Turnovers }},
+	{Name: "fgpct", Value: func(p databasestructs.PlayerInfo) float64 { return p.FGPercentage }},
+	{Name: "threefgpct", Value: func(p databasestructs.PlayerInfo) float64 { return p.ThreeFGPercentage }},
+	{Name: "ftpct", Value: func(p databasestructs.PlayerInfo) float64 { return p.FTPercentage }},
+	{Name: "per", Value: func(p databasestructs.PlayerInfo) float64 { return p.PER }},
+	{Name: "ts", Value: func(p databasestructs.PlayerInfo) float64 { return p.TSPct }},
+	{Name: "usg", Value: func(p databasestructs.PlayerInfo) float64 { return p.USGPCt }},
+	{Name: "ows", Cumulative: true, Value: func(p databasestructs.PlayerInfo) float64 { return p.OffWS }},
+	{Name: "dws", Cumulative: true, Value: func(p databasestructs.PlayerInfo) float64 { return p.DefWS }},
+	{Name: "ws", Cumulative: true, Value: func(p databasestructs.PlayerInfo) float64 { return p.WS }},
+	{Name: "obpm", Value: func(p databasestructs.PlayerInfo) float64 { return p.OffBPM }},
+	{Name: "dbpm", Value: func(p databasestructs.PlayerInfo) float64 { return p.DefBPM }},
+	{Name: "bpm", Value: func(p databasestructs.PlayerInfo) float64 { return p.BPM }},
+	{Name: "vorp", Cumulative: true, Value: func(p databasestructs.PlayerInfo) float64 { return p.VORP }},
+	{Name: "offrtg", Value: func(p databasestructs.PlayerInfo) float64 { return p.OffRtg }},
+	{Name: "defrtg", LowerIsBetter: true, Value: func(p databasestructs.PlayerInfo) float64 { return p.DefRtg }},
+}
+
+func Find(name string) (Metric, bool) {
+	for _, m := range PlayerMetrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return Metric{}, false
+}
+
+// IsQualified is the minutes qualifier the leaderboards filter on, a player right at the minimum counts
+func IsQualified(p databasestructs.PlayerInfo) bool {
+	return p.Minutes >= MinQualifyingMinutes
+}
+
+func Qualified(players []databasestructs.PlayerInfo) []databasestructs.PlayerInfo {
+	var qualified []databasestructs.PlayerInfo
+	for _, p := range players {
+		if IsQualified(p) {
+			qualified = append(qualified, p)
+		}
+	}
+
+	return qualified
+}
+
+func Values(players []databasestructs.PlayerInfo, m Metric) []float64 {
+	values := make([]float64, 0, len(players))
+	for _, p := range players {
+		values = append(values, m.Value(p))
+	}
+
+	return values
+}
+
+// Percentile returns the share of the pool the value is better than, counting ties as half
+func Percentile(pool []float64, value float64, lowerIsBetter bool) float64 {
+	if len(pool) == 0 {
+		return 0
+	}
+
+	var better, equal int
+	for _, v := range pool {
+		switch {
+		case v == value:
+			equal++
+		case lowerIsBetter && v > value, !lowerIsBetter && v < value:
+			better++
+		}
+	}
+
+	return round((float64(better)+0.5*float64(equal))/float64(len(pool))*100, 1)
+}
+
+// PlayerPercentiles ranks every metric of the player against the pool
+func PlayerPercentiles(p databasestructs.PlayerInfo, pool []databasestructs.PlayerInfo) map[string]float64 {
+	percentiles := make(map[string]float64, len(PlayerMetrics))
+	for _, m := range PlayerMetrics {
+		percentiles[m.Name] = Percentile(Values(pool, m), m.Value(p), m.LowerIsBetter)
+	}
+
+	return percentiles
+}
+
+func round(value float64, places int) float64 {
+	shift := math.Pow(10, float64(places))
+	return math.Round(value*shift) / shift
+}
@@ -0,0 +1,14 @@
+package metrics
+
+import (
+	"sportsvoting/databasestructs"
+	"testing"
+)
+
+func TestIsQualifiedAtTheMinimum(t *testing.T) {
+	for minutes, want := range map[float64]bool{MinQualifyingMinutes - 0.1: false, MinQualifyingMinutes: true, MinQualifyingMinutes + 0.1: true} {
+		if got := IsQualified(databasestructs.PlayerInfo{PlayerStats: databasestructs.PlayerStats{Minutes: minutes}}); got != want {
+			t.Errorf("%.1f minutes: qualified = %t, want %t", minutes, got, want)
+		}
+	}
+}
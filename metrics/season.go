@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"context"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+)
+
+// SeasonPlayers loads the regular and advanced stats of every player who played in the season
+func SeasonPlayers(ctx context.Context, db database.Database, season string) ([]databasestructs.PlayerInfo, error) {
+	return db.GetSeasonStats(ctx, season)
+}
+
+// AllSeasonPlayers loads every player season that has both regular and advanced stats
+func AllSeasonPlayers(ctx context.Context, db database.Database) ([]databasestructs.PlayerInfo, error) {
+	return db.GetAllSeasonStats(ctx)
+}
+
+// GOATCareers loads the regular season career averages of the GOAT players in the same shape as a season, so the same metrics apply
+func GOATCareers(ctx context.Context, db database.Database) ([]databasestructs.PlayerInfo, error) {
+	return db.GetGOATCareerStats(ctx)
+}
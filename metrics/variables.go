@@ -0,0 +1,86 @@
+package metrics
+
+import "sportsvoting/databasestructs"
+
+// PlayerVariables exposes the season stats of a player by their metric names, for use in score formulas
+func PlayerVariables(p databasestructs.PlayerInfo) map[string]float64 {
+	vars := make(map[string]float64, len(PlayerMetrics))
+	for _, m := range PlayerMetrics {
+		vars[m.Name] = m.Value(p)
+	}
+
+	return vars
+}
+
+func PlayerVariableNames() []string {
+	names := make([]string, 0, len(PlayerMetrics))
+	for _, m := range PlayerMetrics {
+		names = append(names, m.Name)
+	}
+
+	return names
+}
+
+// GOATVariables exposes career averages, playoff averages and accolades of a GOAT poll candidate
+func GOATVariables(p *databasestructs.PollResponse) map[string]float64 {
+	vars := make(map[string]float64, 24)
+	if p.Stats != nil {
+		vars["ppg"] = p.Stats.Points
+		vars["rpg"] = p.Stats.Rebounds
+		vars["apg"] = p.Stats.Assists
+		vars["spg"] = p.Stats.Steals
+		vars["bpg"] = p.Stats.Blocks
+	}
+	if p.AdvStats != nil {
+		vars["per"] = p.AdvStats.PER
+		vars["ows"] = p.AdvStats.OffWS
+		vars["dws"] = p.AdvStats.DefWS
+		vars["ws"] = p.AdvStats.WS
+		vars["obpm"] = p.AdvStats.OffBPM
+		vars["dbpm"] = p.AdvStats.DefBPM
+		vars["bpm"] = p.AdvStats.BPM
+		vars["offrtg"] = p.AdvStats.OffRtg
+		vars["defrtg"] = p.AdvStats.DefRtg
+	}
+	if p.PlayoffStats != nil {
+		vars["playoff_ppg"] = p.PlayoffStats.Points
+		vars["playoff_rpg"] = p.PlayoffStats.Rebounds
+		vars["playoff_apg"] = p.PlayoffStats.Assists
+	}
+	if p.GoatPlayers != nil {
+		vars["allstar"] = float64(p.GoatPlayers.AllStar)
+		vars["allnba"] = float64(p.GoatPlayers.AllNba)
+		vars["alldefense"] = float64(p.GoatPlayers.AllDefense)
+		vars["championships"] = float64(p.GoatPlayers.Championships)
+		vars["dpoy"] = float64(p.GoatPlayers.Dpoy)
+		vars["fmvp"] = float64(p.GoatPlayers.FMVP)
+		vars["mvp"] = float64(p.GoatPlayers.MVP)
+	}
+
+	// era adjusted numbers are missing for players whose seasons aren't synced yet, those count as zero
+	if p.EraAdjusted != nil {
+		for name, value := range map[string]*float64{
+			"ppg_per100": p.EraAdjusted.PointsPer100, "rpg_per100": p.EraAdjusted.ReboundsPer100, "apg_per100": p.EraAdjusted.AssistsPer100,
+			"ppg_relative": p.EraAdjusted.RelativePoints, "rpg_relative": p.EraAdjusted.RelativeRebounds, "apg_relative": p.EraAdjusted.RelativeAssists, "ts_relative": p.EraAdjusted.RelativeTSPct,
+		} {
+			if value != nil {
+				vars[name] = *value
+			}
+		}
+	}
+	for _, name := range eraVariableNames {
+		if _, ok := vars[name]; !ok {
+			vars[name] = 0
+		}
+	}
+
+	return vars
+}
+
+var eraVariableNames = []string{"ppg_per100", "rpg_per100", "apg_per100", "ppg_relative", "rpg_relative", "apg_relative", "ts_relative"}
+
+func GOATVariableNames() []string {
+	names := []string{"ppg", "rpg", "apg", "spg", "bpg", "per", "ows", "dws", "ws", "obpm", "dbpm", "bpm", "offrtg", "defrtg",
+		"playoff_ppg", "playoff_rpg", "playoff_apg", "allstar", "allnba", "alldefense", "championships", "dpoy", "fmvp", "mvp"}
+	return append(names, eraVariableNames...)
+}
@@ -2,36 +2,61 @@ package migrate
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
 
 	migrateV4 "github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-func RunMigrations(db *sql.DB) error {
+// migrationsFolders maps every database type to the folder holding its migrations
+var migrationsFolders = map[string]string{
+	"mysql":    "migrations",
+	"postgres": "postgres_migrations",
+	"sqlite":   "sqlite_migrations",
+}
+
+func RunMigrations(db *sql.DB, dbType string) error {
 	log.Println("Running migrations")
 
-	driver, err := mysql.WithInstance(db, &mysql.Config{})
+	folder, ok := migrationsFolders[dbType]
+	if !ok {
+		return fmt.Errorf("no migrations for database type %q", dbType)
+	}
+
+	var driver database.Driver
+	var err error
+	switch dbType {
+	case "postgres":
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	case "sqlite":
+		driver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	default:
+		driver, err = mysql.WithInstance(db, &mysql.Config{})
+	}
 	if err != nil {
 		return err
 	}
 
-	filePath := "file://./migrations/"
+	filePath := "file://./" + folder + "/"
 	isDev := true
 	if isdevEnv, exists := os.LookupEnv("IS_DEVELOPMENT"); exists {
 		isDev, _ = strconv.ParseBool(isdevEnv)
 	}
 
 	if !isDev {
-		filePath = "file:///migrations"
+		filePath = "file:///" + folder
 	}
 
 	m, err := migrateV4.NewWithDatabaseInstance(
 		filePath,
-		"mysql", driver,
+		dbType, driver,
 	)
 	if err != nil {
 		return err
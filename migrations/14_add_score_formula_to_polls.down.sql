@@ -0,0 +1 @@
+ALTER TABLE `polls` DROP COLUMN score_formula;
@@ -0,0 +1 @@
+ALTER TABLE `polls` ADD COLUMN score_formula VARCHAR(500) DEFAULT "";
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `league_averages` (
+    season          VARCHAR(25) PRIMARY KEY NOT NULL, /* year the season ended */
+    pace            FLOAT,
+    pointspergame   FLOAT,
+    reboundspergame FLOAT,
+    assistspergame  FLOAT,
+    tspct           FLOAT
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `goat_player_seasons` (
+    id          INT PRIMARY KEY AUTO_INCREMENT NOT NULL,
+    playerid    VARCHAR(128) NOT NULL,
+    season      VARCHAR(25) NOT NULL, /* year the season ended */
+    gamesplayed INT,
+    UNIQUE(playerid, season),
+    FOREIGN KEY(playerid) REFERENCES `goat_players`(playerid)
+);
@@ -0,0 +1 @@
+ALTER TABLE `goat_stats` DROP COLUMN minutespergame, DROP COLUMN tspct;
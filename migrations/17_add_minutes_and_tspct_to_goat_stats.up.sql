@@ -0,0 +1 @@
+ALTER TABLE `goat_stats` ADD COLUMN minutespergame FLOAT DEFAULT 0, ADD COLUMN tspct FLOAT DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `matchup_votes` (
+  id            INT PRIMARY KEY AUTO_INCREMENT,
+  pollid        INT NOT NULL,
+  userid        INT NOT NULL,
+  winnerid      VARCHAR(128) NOT NULL,
+  loserid       VARCHAR(128) NOT NULL,
+  rating_change FLOAT DEFAULT 0, /* elo points the winner gained */
+  created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE
+);
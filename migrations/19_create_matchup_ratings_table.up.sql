@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `matchup_ratings` (
+  pollid    INT NOT NULL,
+  playerid  VARCHAR(128) NOT NULL,
+  rating    FLOAT NOT NULL,
+  matches   INT NOT NULL DEFAULT 0,
+  wins      INT NOT NULL DEFAULT 0,
+  PRIMARY KEY(pollid, playerid),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `brackets` (
+  pollid         INT PRIMARY KEY NOT NULL,
+  size           INT NOT NULL,
+  round_hours    INT NOT NULL,
+  current_round  INT NOT NULL DEFAULT 1,
+  round_ends_at  INT NOT NULL, /* unix time the current round closes */
+  championid     VARCHAR(128) DEFAULT NULL,
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `bracket_matchups` (
+  id         INT PRIMARY KEY AUTO_INCREMENT,
+  pollid     INT NOT NULL,
+  round      INT NOT NULL,
+  position   INT NOT NULL,
+  seed1      INT NOT NULL,
+  player1id  VARCHAR(128) NOT NULL,
+  seed2      INT NOT NULL,
+  player2id  VARCHAR(128) NOT NULL,
+  winnerid   VARCHAR(128) DEFAULT NULL,
+  UNIQUE(pollid, round, position),
+  FOREIGN KEY(pollid) REFERENCES `brackets`(pollid) ON DELETE CASCADE
+);
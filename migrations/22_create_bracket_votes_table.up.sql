@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `bracket_votes` (
+  matchupid  INT NOT NULL,
+  userid     INT NOT NULL,
+  playerid   VARCHAR(128) NOT NULL,
+  PRIMARY KEY(matchupid, userid),
+  FOREIGN KEY(matchupid) REFERENCES `bracket_matchups`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `poll_questions` (
+  id         INT PRIMARY KEY AUTO_INCREMENT,
+  pollid     INT NOT NULL,
+  position   INT NOT NULL,
+  prompt     VARCHAR(256) NOT NULL,
+  type       ENUM('player', 'team', 'ranked', 'yesno', 'numeric') NOT NULL,
+  picks      INT NOT NULL DEFAULT 1, /* length of the list for ranked questions */
+  min_value  DOUBLE DEFAULT NULL,
+  max_value  DOUBLE DEFAULT NULL,
+  UNIQUE(pollid, position),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE
+);
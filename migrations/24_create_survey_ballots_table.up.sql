@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `survey_ballots` (
+  id          INT PRIMARY KEY AUTO_INCREMENT,
+  pollid      INT NOT NULL,
+  userid      INT NOT NULL,
+  created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(pollid, userid),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE
+);
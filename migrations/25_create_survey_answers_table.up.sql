@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `survey_answers` (
+  ballotid    INT NOT NULL,
+  questionid  INT NOT NULL,
+  position    INT NOT NULL DEFAULT 0, /* place in the list for ranked questions */
+  value       VARCHAR(128) DEFAULT "",
+  number      DOUBLE DEFAULT NULL,
+  PRIMARY KEY(ballotid, questionid, position),
+  FOREIGN KEY(ballotid) REFERENCES `survey_ballots`(id) ON DELETE CASCADE,
+  FOREIGN KEY(questionid) REFERENCES `poll_questions`(id) ON DELETE CASCADE
+);
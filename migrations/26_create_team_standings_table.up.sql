@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `team_standings` (
+  season    VARCHAR(25) NOT NULL, /* year of the season */
+  teamabbr  VARCHAR(3) NOT NULL,
+  wins      INT NOT NULL,
+  losses    INT NOT NULL,
+  PRIMARY KEY(season, teamabbr),
+  FOREIGN KEY(teamabbr) REFERENCES `teams`(teamabbr)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `prediction_questions` (
+  id             INT PRIMARY KEY AUTO_INCREMENT,
+  contestid      INT NOT NULL,
+  position       INT NOT NULL,
+  prompt         VARCHAR(256) NOT NULL,
+  type           ENUM('win_total', 'award', 'stat_leader') NOT NULL,
+  target         VARCHAR(128) NOT NULL, /* team abbreviation, award or stat the question is about */
+  points         INT NOT NULL DEFAULT 10,
+  answer         VARCHAR(128) DEFAULT NULL,
+  answer_number  DOUBLE DEFAULT NULL,
+  UNIQUE(contestid, position),
+  FOREIGN KEY(contestid) REFERENCES `prediction_contests`(id) ON DELETE CASCADE
+);
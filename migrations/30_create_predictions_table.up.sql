@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `predictions` (
+  questionid  INT NOT NULL,
+  userid      INT NOT NULL,
+  value       VARCHAR(128) DEFAULT "",
+  number      DOUBLE DEFAULT NULL,
+  score       DOUBLE DEFAULT NULL,
+  PRIMARY KEY(questionid, userid),
+  FOREIGN KEY(questionid) REFERENCES `prediction_questions`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE
+);
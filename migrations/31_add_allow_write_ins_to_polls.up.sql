@@ -0,0 +1 @@
+ALTER TABLE `polls` ADD COLUMN allow_write_ins BOOLEAN DEFAULT false;
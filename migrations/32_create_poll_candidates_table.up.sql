@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `poll_candidates` (
+  id            INT PRIMARY KEY AUTO_INCREMENT,
+  pollid        INT NOT NULL,
+  playerid      VARCHAR(128) DEFAULT NULL, /* id from players or goat_players, empty for free text entries */
+  name          VARCHAR(128) NOT NULL,
+  name_key      VARCHAR(128) NOT NULL, /* normalized name so the same write-in isn't added twice */
+  status        ENUM('approved', 'pending', 'rejected') NOT NULL DEFAULT 'approved',
+  is_write_in   BOOLEAN DEFAULT false,
+  submitted_by  INT DEFAULT NULL,
+  UNIQUE(pollid, name_key),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE,
+  FOREIGN KEY(submitted_by) REFERENCES `users`(id) ON DELETE SET NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE `player_votes`
+  DROP FOREIGN KEY fk_player_votes_candidate,
+  DROP COLUMN candidateid;
@@ -0,0 +1,3 @@
+ALTER TABLE `player_votes`
+  ADD COLUMN candidateid INT DEFAULT NULL,
+  ADD CONSTRAINT fk_player_votes_candidate FOREIGN KEY(candidateid) REFERENCES `poll_candidates`(id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `poll_groups` (
+  id           INT PRIMARY KEY AUTO_INCREMENT,
+  name         VARCHAR(128) NOT NULL,
+  description  VARCHAR(256) DEFAULT "",
+  invite_code  VARCHAR(16) NOT NULL UNIQUE,
+  ownerid      INT NOT NULL,
+  created_at   INT NOT NULL, /* unix time */
+  FOREIGN KEY(ownerid) REFERENCES `users`(id) ON DELETE CASCADE
+);
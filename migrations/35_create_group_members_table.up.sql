@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `group_members` (
+  groupid    INT NOT NULL,
+  userid     INT NOT NULL,
+  role       ENUM('owner', 'member') NOT NULL DEFAULT 'member',
+  joined_at  INT NOT NULL, /* unix time */
+  PRIMARY KEY(groupid, userid),
+  FOREIGN KEY(groupid) REFERENCES `poll_groups`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE
+);
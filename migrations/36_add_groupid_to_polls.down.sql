@@ -0,0 +1,3 @@
+ALTER TABLE `polls`
+  DROP FOREIGN KEY fk_polls_group,
+  DROP COLUMN groupid;
@@ -0,0 +1,3 @@
+ALTER TABLE `polls`
+  ADD COLUMN groupid INT DEFAULT NULL,
+  ADD CONSTRAINT fk_polls_group FOREIGN KEY(groupid) REFERENCES `poll_groups`(id) ON DELETE CASCADE;
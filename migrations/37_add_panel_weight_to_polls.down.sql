@@ -0,0 +1 @@
+ALTER TABLE `polls` DROP COLUMN panel_weight;
@@ -0,0 +1 @@
+ALTER TABLE `polls` ADD COLUMN panel_weight DOUBLE DEFAULT NULL; /* share of the blended result given to the panelists, NULL when the poll isn't blended */
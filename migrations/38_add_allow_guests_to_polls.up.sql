@@ -0,0 +1 @@
+ALTER TABLE `polls` ADD COLUMN allow_guests BOOLEAN DEFAULT false;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `guest_votes` (
+  id           INT PRIMARY KEY AUTO_INCREMENT,
+  pollid       INT NOT NULL,
+  deviceid     VARCHAR(64) NOT NULL, /* from the signed device token */
+  ip           VARCHAR(45) NOT NULL,
+  playerid     VARCHAR(128) DEFAULT NULL, /* id from players or goat_players */
+  candidateid  INT DEFAULT NULL,
+  created_at   INT NOT NULL, /* unix time */
+  UNIQUE(pollid, deviceid),
+  INDEX(pollid, ip),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE,
+  FOREIGN KEY(candidateid) REFERENCES `poll_candidates`(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE `users`
+  DROP COLUMN created_at,
+  DROP COLUMN signup_ip;
@@ -0,0 +1,3 @@
+ALTER TABLE `users`
+  ADD COLUMN created_at INT DEFAULT 0, /* unix time, 0 for accounts from before it was stored */
+  ADD COLUMN signup_ip VARCHAR(45) DEFAULT "";
@@ -0,0 +1,4 @@
+ALTER TABLE `player_votes`
+  DROP COLUMN created_at,
+  DROP COLUMN ip,
+  DROP COLUMN switches;
@@ -0,0 +1,4 @@
+ALTER TABLE `player_votes`
+  ADD COLUMN created_at INT DEFAULT 0, /* unix time */
+  ADD COLUMN ip VARCHAR(45) DEFAULT "",
+  ADD COLUMN switches INT DEFAULT 0; /* how often the user changed their vote in the poll */
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `vote_flags` (
+  id           INT PRIMARY KEY AUTO_INCREMENT,
+  pollid       INT NOT NULL,
+  userid       INT NOT NULL,
+  reason       ENUM('new_account_burst', 'ip_cluster', 'vote_switching') NOT NULL,
+  details      VARCHAR(256) DEFAULT "",
+  status       ENUM('open', 'excluded', 'cleared') NOT NULL DEFAULT 'open',
+  created_at   INT NOT NULL, /* unix time */
+  reviewed_by  INT DEFAULT NULL,
+  UNIQUE(pollid, userid, reason),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE,
+  FOREIGN KEY(reviewed_by) REFERENCES `users`(id) ON DELETE SET NULL
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `vote_events` (
+  id          BIGINT PRIMARY KEY AUTO_INCREMENT,
+  pollid      INT NOT NULL,
+  userid      INT NOT NULL,
+  event       ENUM('cast', 'changed', 'retracted', 'reset') NOT NULL,
+  choice      VARCHAR(128) DEFAULT NULL, /* player, GOAT player or candidate id voted for, NULL when the vote was taken away */
+  previous    VARCHAR(128) DEFAULT NULL, /* the pick the event replaced or removed */
+  created_at  INT NOT NULL, /* unix time */
+  INDEX(pollid, created_at),
+  INDEX(userid, created_at),
+  FOREIGN KEY(pollid) REFERENCES `polls`(id) ON DELETE CASCADE,
+  FOREIGN KEY(userid) REFERENCES `users`(id) ON DELETE CASCADE
+);
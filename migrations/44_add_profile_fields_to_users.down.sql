@@ -0,0 +1,3 @@
+ALTER TABLE `users`
+  DROP COLUMN favorite_team,
+  DROP COLUMN region;
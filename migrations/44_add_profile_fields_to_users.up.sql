@@ -0,0 +1,3 @@
+ALTER TABLE `users`
+  ADD COLUMN favorite_team VARCHAR(3) DEFAULT NULL, /* teamabbr, self-reported */
+  ADD COLUMN region VARCHAR(64) DEFAULT NULL; /* self-reported */
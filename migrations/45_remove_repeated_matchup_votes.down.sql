@@ -0,0 +1,2 @@
+/* the removed repeats can't be brought back and the replayed ratings match the votes that are left, so there's nothing to undo */
+DO 0;
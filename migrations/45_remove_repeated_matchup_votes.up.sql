@@ -0,0 +1,8 @@
+/* keeps the first vote of a user on every pair of players, so the pair can be made unique, the removed repeats can't be brought back.
+   The ratings of the polls they were in are dropped with them and replayed from the remaining votes when the server starts */
+DELETE v, r FROM `matchup_votes` v
+  INNER JOIN `matchup_votes` e ON e.pollid = v.pollid AND e.userid = v.userid
+    AND LEAST(e.winnerid, e.loserid) = LEAST(v.winnerid, v.loserid)
+    AND GREATEST(e.winnerid, e.loserid) = GREATEST(v.winnerid, v.loserid)
+    AND e.id < v.id
+  LEFT JOIN `matchup_ratings` r ON r.pollid = v.pollid;
@@ -0,0 +1,4 @@
+ALTER TABLE `matchup_votes`
+  DROP INDEX matchup_votes_pair,
+  DROP COLUMN player_low,
+  DROP COLUMN player_high;
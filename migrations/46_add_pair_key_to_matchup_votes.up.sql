@@ -0,0 +1,4 @@
+ALTER TABLE `matchup_votes`
+  ADD COLUMN player_low VARCHAR(128) AS (LEAST(winnerid, loserid)) STORED, /* the pair in order, whoever won */
+  ADD COLUMN player_high VARCHAR(128) AS (GREATEST(winnerid, loserid)) STORED,
+  ADD UNIQUE KEY matchup_votes_pair (pollid, userid, player_low, player_high);
@@ -0,0 +1,103 @@
+package players
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sportsvoting/databasestructs"
+	"sportsvoting/metrics"
+	"strings"
+	"time"
+)
+
+const maxComparedPlayers = 5
+
+func (p PlayersHandler) ComparePlayers(w http.ResponseWriter, r *http.Request) {
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < 2 || len(ids) > maxComparedPlayers {
+		http.Error(w, fmt.Sprintf("between 2 and %d player ids are needed", maxComparedPlayers), http.StatusBadRequest)
+		return
+	}
+
+	season := r.URL.Query().Get("season")
+	if season == "" {
+		season = GetEndYearOfTheSeason()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	seasonPlayers, err := metrics.SeasonPlayers(ctx, p.DB, season)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bySeason := make(map[string]databasestructs.PlayerInfo, len(seasonPlayers))
+	for _, player := range seasonPlayers {
+		bySeason[player.ID] = player
+	}
+	pool := metrics.Qualified(seasonPlayers)
+
+	comparison := make([]databasestructs.PlayerComparison, 0, len(ids))
+	for _, id := range ids {
+		player := databasestructs.PlayerComparison{ID: id}
+		if info, ok := bySeason[id]; ok {
+			stats, advStats := info.PlayerStats, info.AdvancedStats
+			player.Name = info.Name
+			player.Season = season
+			player.Stats = &stats
+			player.AdvStats = &advStats
+			player.Qualified = metrics.IsQualified(info)
+			player.Percentiles = metrics.PlayerPercentiles(info, pool)
+		}
+
+		err := p.fillGOATStats(ctx, &player)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if player.Name == "" {
+			http.Error(w, fmt.Sprintf("player %s not found", id), http.StatusNotFound)
+			return
+		}
+
+		comparison = append(comparison, player)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// fillGOATStats adds career and playoff numbers for players that are tracked in goat_stats
+func (p PlayersHandler) fillGOATStats(ctx context.Context, player *databasestructs.PlayerComparison) error {
+	careers, err := p.DB.GetGOATPlayerStats(ctx, player.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range careers {
+		career := &careers[i]
+		if player.Name == "" {
+			player.Name = career.Accolades.Name
+		}
+		player.Accolades = &career.Accolades
+
+		if career.Stats.IsPlayoffs {
+			player.PlayoffStats = &career.Stats
+		} else {
+			player.CareerStats = &career.Stats
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package players
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sportsvoting/databasestructs"
+	"sportsvoting/metrics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLeadersLimit = 25
+	maxLeadersLimit     = 100
+)
+
+type LeadersResponse struct {
+	Season  string                       `json:"season"`
+	Stat    string                       `json:"stat"`
+	Total   int64                        `json:"total"`
+	Limit   int64                        `json:"limit"`
+	Offset  int64                        `json:"offset"`
+	Leaders []databasestructs.StatLeader `json:"leaders"`
+}
+
+func (p PlayersHandler) GetLeaders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLeadersFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	leaders, total, err := p.DB.GetStatLeaders(ctx, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := LeadersResponse{Season: filter.Season, Stat: filter.Stat, Limit: filter.Limit, Offset: filter.Offset, Total: total, Leaders: leaders}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+func parseLeadersFilter(query url.Values) (databasestructs.LeadersFilter, error) {
+	filter := databasestructs.LeadersFilter{
+		Season:   query.Get("season"),
+		Stat:     strings.ToLower(query.Get("stat")),
+		Position: strings.ToUpper(query.Get("position")),
+		Team:     strings.ToUpper(query.Get("team")),
+		Limit:    defaultLeadersLimit,
+	}
+
+	if filter.Season == "" {
+		filter.Season = GetEndYearOfTheSeason()
+	}
+	if filter.Stat == "" {
+		filter.Stat = "ppg"
+	}
+
+	metric, ok := metrics.Find(filter.Stat)
+	if !ok {
+		return filter, fmt.Errorf("unknown stat %q", filter.Stat)
+	}
+	filter.Ascending = metric.LowerIsBetter
+
+	// rate stats need a minutes qualifier, otherwise someone with two garbage time minutes leads the league
+	if !metric.Cumulative {
+		filter.MinMinutes = metrics.MinQualifyingMinutes
+	}
+
+	var err error
+	if v := query.Get("min_games"); v != "" {
+		if filter.MinGames, err = strconv.ParseInt(v, 10, 64); err != nil || filter.MinGames < 0 {
+			return filter, fmt.Errorf("invalid min_games %q", v)
+		}
+	}
+	if v := query.Get("min_minutes"); v != "" {
+		if filter.MinMinutes, err = strconv.ParseFloat(v, 64); err != nil || filter.MinMinutes < 0 {
+			return filter, fmt.Errorf("invalid min_minutes %q", v)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.ParseInt(v, 10, 64); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", v)
+		}
+		if filter.Limit > maxLeadersLimit {
+			filter.Limit = maxLeadersLimit
+		}
+	}
+	if v := query.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.ParseInt(v, 10, 64); err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("invalid offset %q", v)
+		}
+	}
+
+	return filter, nil
+}
@@ -1,7 +1,7 @@
 package players
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"sportsvoting/advancedstats"
 	"sportsvoting/database"
@@ -127,25 +127,14 @@ func UpdatePlayersWhoPlayedAGame(db database.Database) error {
 	fmt.Println("Updating players who played")
 	season := GetEndYearOfTheSeason()
 
-	rows, err := db.SelectPlayerGamesPlayed(season)
+	gamesPlayed, err := db.SelectPlayerGamesPlayed(season)
 	if err != nil {
-		return err
+		fmt.Println(err)
 	}
-	defer rows.Close()
 
 	players := make(map[string]int64, 600)
-	for rows.Next() {
-		var id string
-		var gamesplayed int64
-		err := rows.Scan(&id, &gamesplayed)
-		if err != nil {
-			fmt.Println(err)
-		}
-		players[id] = gamesplayed
-	}
-
-	if err := rows.Err(); err != nil {
-		fmt.Println(err)
+	for _, stats := range gamesPlayed {
+		players[stats.PlayerID] = stats.Games
 	}
 
 	url := fmt.Sprintf("https://www.basketball-reference.com/leagues/NBA_%s_per_game.html", season)
@@ -171,8 +160,8 @@ func UpdatePlayersWhoPlayedAGame(db database.Database) error {
 
 		entry, ok := players[id]
 		if !ok && id != "" {
-			err = db.CheckPlayerExists(id).Scan()
-			if err == sql.ErrNoRows {
+			exists, err := db.CheckPlayerExists(id)
+			if err == nil && !exists {
 				newplayers[id] = player
 				players[id] = player.Games
 			} else {
@@ -250,19 +239,26 @@ func GetEndYearOfTheSeason() string {
 
 func UpdatePlayerStats(db database.Database, rosters map[string]databasestructs.PlayerInfo, season string) error {
 	fmt.Println("Updating stats")
-	for _, player := range rosters {
-		err := stats.UpdateStats(db, player.PlayerStats)
-		if err != nil {
-			return err
-		}
+	// the rosters are written as a whole, the corrections below scrape their pages first so they run on their own
+	err := db.WithTx(context.Background(), func(tx database.Database) error {
+		for _, player := range rosters {
+			err := stats.UpdateStats(tx, player.PlayerStats)
+			if err != nil {
+				return err
+			}
 
-		err = advancedstats.UpdateStats(db, player.AdvancedStats)
-		if err != nil {
-			return err
+			err = advancedstats.UpdateStats(tx, player.AdvancedStats)
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	err := stats.UpdateTradedPlayerStats(db, season)
+	err = stats.UpdateTradedPlayerStats(db, season)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,248 @@
+package players
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+type PlayersHandler struct {
+	DB database.Database
+}
+
+// letters that don't decompose into a base letter and a combining mark
+var nameReplacer = strings.NewReplacer("đ", "d", "ł", "l", "ø", "o", "æ", "ae", "œ", "oe", "ß", "ss", "ı", "i")
+
+func (p PlayersHandler) SearchPlayers(w http.ResponseWriter, r *http.Request) {
+	query := NormalizeName(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "missing search query", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	results, err := p.searchPlayers(ctx, query, GetEndYearOfTheSeason())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (p PlayersHandler) searchPlayers(ctx context.Context, query, season string) ([]databasestructs.PlayerSearchResult, error) {
+	players, err := p.DB.GetPlayersForSearch(ctx, season)
+	if err != nil {
+		return nil, err
+	}
+
+	// active players are in both tables, so merge them by id
+	candidates := make(map[string]*databasestructs.PlayerSearchResult)
+	for _, player := range players {
+		entry, ok := candidates[player.ID]
+		if !ok {
+			entry = &databasestructs.PlayerSearchResult{ID: player.ID, Name: player.Name}
+			candidates[player.ID] = entry
+		}
+
+		if player.IsGoat {
+			entry.IsGoat = true
+		} else {
+			entry.IsCurrent = true
+			entry.Name = player.Name
+			entry.Minutes = player.Minutes
+		}
+	}
+
+	queryTokens := strings.Fields(query)
+	var results []databasestructs.PlayerSearchResult
+	for _, candidate := range candidates {
+		score, ok := matchScore(query, queryTokens, NormalizeName(candidate.Name))
+		if !ok {
+			continue
+		}
+
+		candidate.Score = score
+		results = append(results, *candidate)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Minutes != results[j].Minutes {
+			return results[i].Minutes > results[j].Minutes
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// NormalizeName lowercases the name, strips diacritics and punctuation, so "Nikola Jokić" becomes "nikola jokic"
+func NormalizeName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, strings.ToLower(name))
+	if err != nil {
+		folded = strings.ToLower(name)
+	}
+	folded = nameReplacer.Replace(folded)
+
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		if r == '\'' || r == '.' {
+			return -1
+		}
+		return ' '
+	}, folded)
+
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// matchScore ranks exact matches first, then prefixes, substrings and finally names within a few typos of the query
+func matchScore(query string, queryTokens []string, name string) (float64, bool) {
+	switch {
+	case name == query:
+		return 100, true
+	case strings.HasPrefix(name, query):
+		return 90, true
+	}
+
+	nameTokens := strings.Fields(name)
+	if tokensMatch(queryTokens, nameTokens, func(q, n string) bool { return strings.HasPrefix(n, q) }) {
+		return 80, true
+	}
+
+	if strings.Contains(name, query) {
+		return 60, true
+	}
+
+	distance := 0
+	for _, q := range queryTokens {
+		best := -1
+		queryLen := len([]rune(q))
+		for _, n := range nameTokens {
+			d := editDistance(q, n)
+			// typing the start of a misspelled name should still match
+			if nameRunes := []rune(n); len(nameRunes) > queryLen {
+				if pd := editDistance(q, string(nameRunes[:queryLen])); pd < d {
+					d = pd
+				}
+			}
+			if best == -1 || d < best {
+				best = d
+			}
+		}
+
+		if best == -1 || best > allowedTypos(q) {
+			return 0, false
+		}
+		distance += best
+	}
+
+	return 50 - 10*float64(distance), true
+}
+
+func tokensMatch(queryTokens, nameTokens []string, match func(q, n string) bool) bool {
+	for _, q := range queryTokens {
+		found := false
+		for _, n := range nameTokens {
+			if match(q, n) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return len(queryTokens) > 0
+}
+
+func allowedTypos(token string) int {
+	switch l := len([]rune(token)); {
+	case l <= 3:
+		return 0
+	case l <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance counts insertions, deletions, substitutions and swaps of adjacent letters, since "jokci" is a common typo
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = minInt(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package players
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"Nikola Jokić", "nikola jokic"},
+		{"Kristaps Porziņģis", "kristaps porzingis"},
+		{"Dražen Petrović", "drazen petrovic"},
+		{"Đorđe Gagić", "dorde gagic"},
+		{"Ömer Aşık", "omer asik"},
+		{"Shaquille O'Neal", "shaquille oneal"},
+		{"P.J. Tucker", "pj tucker"},
+		{"Jaren Jackson Jr.", "jaren jackson jr"},
+		{"Karl-Anthony Towns", "karl anthony towns"},
+		{"  Giannis   Antetokounmpo ", "giannis antetokounmpo"},
+		{"", ""},
+	} {
+		if got := NormalizeName(tc.name); got != tc.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"jokic", "", 5},
+		{"jokic", "jokic", 0},
+		{"jokci", "jokic", 1},
+		{"jokic", "jokič", 1},
+		{"jokc", "jokic", 1},
+		{"kitten", "sitting", 3},
+		{"ab", "ba", 1},
+		// a swapped pair isn't edited again
+		{"ca", "abc", 3},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := editDistance(tc.b, tc.a); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.b, tc.a, got, tc.want)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	for _, tc := range []struct {
+		query, name string
+		want        float64
+		ok          bool
+	}{
+		{"lebron james", "lebron james", 100, true},
+		{"lebron", "lebron james", 90, true},
+		{"james leb", "lebron james", 80, true},
+		{"jok", "nikola jokic", 80, true},
+		{"bron", "lebron james", 60, true},
+		{"jokci", "nikola jokic", 40, true},
+		{"giannis antetokunmpo", "giannis antetokounmpo", 40, true},
+		// the start of a misspelled name
+		{"antetokuon", "giannis antetokounmpo", 40, true},
+		{"nikloa jokci", "nikola jokic", 30, true},
+		{"jokcx", "nikola jokic", 0, false},
+		{"lbj", "lebron james", 0, false},
+		{"curry", "lebron james", 0, false},
+	} {
+		got, ok := matchScore(tc.query, strings.Fields(tc.query), tc.name)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("matchScore(%q, %q) = %v, %t, want %v, %t", tc.query, tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
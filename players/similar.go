@@ -0,0 +1,242 @@
+package players
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sportsvoting/databasestructs"
+	"sportsvoting/metrics"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultSimilarCount = 10
+	maxSimilarCount     = 50
+)
+
+// per game and rate stats that exist both for single seasons and GOAT careers, season totals like WS wouldn't compare to career totals
+var similarityMetrics = []string{"mpg", "ppg", "rpg", "apg", "spg", "bpg", "topg", "fgpct", "threefgpct", "ftpct", "per", "ts", "obpm", "dbpm", "bpm", "offrtg", "defrtg"}
+
+var defaultSimilarityMetrics = []string{"ppg", "rpg", "apg", "spg", "bpg", "fgpct", "threefgpct", "ftpct", "per", "bpm"}
+
+type SimilarPlayer struct {
+	ID         string             `json:"playerid"`
+	Name       string             `json:"name"`
+	Season     string             `json:"season"`
+	IsCareer   bool               `json:"career"`
+	Distance   float64            `json:"distance"`
+	Similarity float64            `json:"similarity"`
+	Stats      map[string]float64 `json:"stats"`
+}
+
+type SimilarResponse struct {
+	ID      string             `json:"playerid"`
+	Name    string             `json:"name"`
+	Season  string             `json:"season"`
+	Metrics []string           `json:"metrics"`
+	Stats   map[string]float64 `json:"stats"`
+	Similar []SimilarPlayer    `json:"similar"`
+}
+
+type profile struct {
+	info     databasestructs.PlayerInfo
+	season   string
+	isCareer bool
+}
+
+func (p PlayersHandler) GetSimilarPlayers(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	season := r.URL.Query().Get("season")
+	if season == "" {
+		season = GetEndYearOfTheSeason()
+	}
+
+	count := defaultSimilarCount
+	if k := r.URL.Query().Get("k"); k != "" {
+		parsed, err := strconv.Atoi(k)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid k", http.StatusBadRequest)
+			return
+		}
+		count = parsed
+	}
+	if count > maxSimilarCount {
+		count = maxSimilarCount
+	}
+
+	selected, err := parseSimilarityMetrics(r.URL.Query().Get("metrics"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	profiles, err := p.similarityProfiles(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	target, ok := findProfile(profiles, id, season)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no stats for player %s in season %s", id, season), http.StatusNotFound)
+		return
+	}
+
+	response := SimilarResponse{ID: target.info.ID, Name: target.info.Name, Season: target.season, Metrics: names(selected), Stats: profileStats(target, selected)}
+	response.Similar = mostSimilar(target, profiles, selected, count)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+func parseSimilarityMetrics(param string) ([]metrics.Metric, error) {
+	requested := defaultSimilarityMetrics
+	if param != "" {
+		requested = strings.Split(strings.ToLower(param), ",")
+	}
+
+	allowed := make(map[string]bool, len(similarityMetrics))
+	for _, name := range similarityMetrics {
+		allowed[name] = true
+	}
+
+	var selected []metrics.Metric
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		m, ok := metrics.Find(name)
+		if !ok || !allowed[name] {
+			return nil, fmt.Errorf("stat %q can't be used for similarity, use one of %s", name, strings.Join(similarityMetrics, ", "))
+		}
+		selected = append(selected, m)
+	}
+
+	return selected, nil
+}
+
+// similarityProfiles returns every qualified player season and every GOAT career
+func (p PlayersHandler) similarityProfiles(ctx context.Context) ([]profile, error) {
+	seasons, err := metrics.AllSeasonPlayers(ctx, p.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	careers, err := metrics.GOATCareers(ctx, p.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]profile, 0, len(seasons)+len(careers))
+	for _, info := range seasons {
+		profiles = append(profiles, profile{info: info, season: info.PlayerStats.Season})
+	}
+	for _, info := range careers {
+		profiles = append(profiles, profile{info: info, season: "Career", isCareer: true})
+	}
+
+	return profiles, nil
+}
+
+// findProfile prefers the player's season, players who aren't in the season stats are compared by their career
+func findProfile(profiles []profile, id, season string) (profile, bool) {
+	var career *profile
+	for i, pr := range profiles {
+		if pr.info.ID != id {
+			continue
+		}
+
+		if !pr.isCareer && pr.season == season {
+			return pr, true
+		}
+		if pr.isCareer {
+			career = &profiles[i]
+		}
+	}
+
+	if career != nil {
+		return *career, true
+	}
+
+	return profile{}, false
+}
+
+func mostSimilar(target profile, profiles []profile, selected []metrics.Metric, count int) []SimilarPlayer {
+	var pool []profile
+	for _, pr := range profiles {
+		if pr.isCareer || metrics.IsQualified(pr.info) {
+			pool = append(pool, pr)
+		}
+	}
+
+	distributions := make([]metrics.Distribution, len(selected))
+	for i, m := range selected {
+		values := make([]float64, 0, len(pool))
+		for _, pr := range pool {
+			values = append(values, m.Value(pr.info))
+		}
+		distributions[i] = metrics.NewDistribution(values, m.LowerIsBetter)
+	}
+
+	similar := []SimilarPlayer{}
+	for _, pr := range pool {
+		if pr.info.ID == target.info.ID {
+			continue
+		}
+
+		var sum float64
+		for i, m := range selected {
+			diff := distributions[i].Standardize(m.Value(pr.info)) - distributions[i].Standardize(m.Value(target.info))
+			sum += diff * diff
+		}
+
+		// averaged over the metrics so the distance doesn't grow with the number of selected stats
+		distance := math.Sqrt(sum / float64(len(selected)))
+		similar = append(similar, SimilarPlayer{
+			ID:         pr.info.ID,
+			Name:       pr.info.Name,
+			Season:     pr.season,
+			IsCareer:   pr.isCareer,
+			Distance:   math.Round(distance*1000) / 1000,
+			Similarity: math.Round(100/(1+distance)*10) / 10,
+			Stats:      profileStats(pr, selected),
+		})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Distance < similar[j].Distance
+	})
+
+	if len(similar) > count {
+		similar = similar[:count]
+	}
+
+	return similar
+}
+
+func profileStats(pr profile, selected []metrics.Metric) map[string]float64 {
+	stats := make(map[string]float64, len(selected))
+	for _, m := range selected {
+		stats[m.Name] = m.Value(pr.info)
+	}
+
+	return stats
+}
+
+func names(selected []metrics.Metric) []string {
+	result := make([]string, 0, len(selected))
+	for _, m := range selected {
+		result = append(result, m.Name)
+	}
+
+	return result
+}
@@ -0,0 +1,438 @@
+package polls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strconv"
+	"time"
+)
+
+const (
+	minBracketSize        = 4
+	maxBracketSize        = 64
+	defaultBracketHours   = 24
+	maxBracketRoundHours  = 14 * 24
+	bracketAdvanceTimeout = 30 * time.Second
+)
+
+type BracketPayload struct {
+	PollID     int64    `json:"pollid"`
+	UserID     int64    `json:"userid"`
+	Size       int64    `json:"size"`
+	RoundHours int64    `json:"round_hours"`
+	Players    []string `json:"players"` // optional seeding, first id is the top seed
+}
+
+type BracketSide struct {
+	ID    string `json:"playerid"`
+	Name  string `json:"name"`
+	Seed  int64  `json:"seed"`
+	Votes int64  `json:"votes"`
+}
+
+type BracketMatchupResponse struct {
+	ID       int64       `json:"id"`
+	Position int64       `json:"position"`
+	Player1  BracketSide `json:"player1"`
+	Player2  BracketSide `json:"player2"`
+	WinnerID string      `json:"winnerid,omitempty"`
+	IsOpen   bool        `json:"is_open"`
+}
+
+type BracketRound struct {
+	Round    int64                    `json:"round"`
+	Matchups []BracketMatchupResponse `json:"matchups"`
+}
+
+type BracketResponse struct {
+	PollID       int64          `json:"pollid"`
+	Size         int64          `json:"size"`
+	TotalRounds  int64          `json:"total_rounds"`
+	CurrentRound int64          `json:"current_round"`
+	RoundEndsAt  time.Time      `json:"round_ends_at"`
+	ChampionID   string         `json:"championid,omitempty"`
+	Rounds       []BracketRound `json:"rounds"`
+}
+
+func (p PollsHandler) CreateBracket(w http.ResponseWriter, r *http.Request) {
+	var payload BracketPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.RoundHours == 0 {
+		payload.RoundHours = defaultBracketHours
+	}
+
+	if payload.RoundHours < 1 || payload.RoundHours > maxBracketRoundHours {
+		http.Error(w, fmt.Sprintf("round_hours has to be between 1 and %d", maxBracketRoundHours), http.StatusBadRequest)
+		return
+	}
+
+	if len(payload.Players) > 0 && payload.Size == 0 {
+		payload.Size = int64(len(payload.Players))
+	}
+
+	if !isBracketSize(payload.Size) {
+		http.Error(w, fmt.Sprintf("size has to be a power of two between %d and %d", minBracketSize, maxBracketSize), http.StatusBadRequest)
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(payload.PollID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "poll not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if poll.UserID != payload.UserID {
+		http.Error(w, "only the poll creator can start a bracket", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pool, err := p.getCandidatePool(ctx, poll)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	seeds, err := seedBracket(pool, payload.Players, payload.Size)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bracket := databasestructs.Bracket{
+		PollID:       payload.PollID,
+		Size:         payload.Size,
+		RoundHours:   payload.RoundHours,
+		CurrentRound: 1,
+		RoundEndsAt:  time.Now().Add(time.Duration(payload.RoundHours) * time.Hour).Unix(),
+	}
+
+	// a bracket without all of its first round matchups can't be played, nor replaced because of the conflict
+	err = p.DB.WithTx(ctx, func(tx database.Database) error {
+		if _, err := tx.InsertBracket(bracket); err != nil {
+			return err
+		}
+
+		order := seedOrder(payload.Size)
+		for i := 0; i < len(order); i += 2 {
+			matchup := databasestructs.BracketMatchup{
+				PollID:    payload.PollID,
+				Round:     1,
+				Position:  int64(i / 2),
+				Seed1:     order[i],
+				Player1ID: seeds[order[i]-1],
+				Seed2:     order[i+1],
+				Player2ID: seeds[order[i+1]-1],
+			}
+
+			if _, err := tx.InsertBracketMatchup(matchup); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			http.Error(w, "poll already has a bracket", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Created bracket of %d players for poll %d", payload.Size, payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (p PollsHandler) GetBracket(w http.ResponseWriter, r *http.Request) {
+	pollID, err := parseID(r, "pollid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, pollID, userID) {
+		return
+	}
+
+	bracket, err := p.DB.GetBracket(pollID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "bracket not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	matchups, err := p.DB.GetBracketMatchups(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := BracketResponse{
+		PollID:       bracket.PollID,
+		Size:         bracket.Size,
+		TotalRounds:  bracketRounds(bracket.Size),
+		CurrentRound: bracket.CurrentRound,
+		RoundEndsAt:  time.Unix(bracket.RoundEndsAt, 0).UTC(),
+		ChampionID:   bracket.ChampionID,
+		Rounds:       []BracketRound{},
+	}
+
+	for _, m := range matchups {
+		round := m.Round
+		matchup := BracketMatchupResponse{
+			ID:       m.ID,
+			Position: m.Position,
+			Player1:  BracketSide{ID: m.Player1ID, Name: m.Player1Name, Seed: m.Seed1, Votes: m.Votes1},
+			Player2:  BracketSide{ID: m.Player2ID, Name: m.Player2Name, Seed: m.Seed2, Votes: m.Votes2},
+			WinnerID: m.WinnerID,
+		}
+
+		matchup.IsOpen = matchup.WinnerID == "" && round == bracket.CurrentRound && bracket.ChampionID == ""
+		if len(response.Rounds) == 0 || response.Rounds[len(response.Rounds)-1].Round != round {
+			response.Rounds = append(response.Rounds, BracketRound{Round: round})
+		}
+		last := &response.Rounds[len(response.Rounds)-1]
+		last.Matchups = append(last.Matchups, matchup)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (p PollsHandler) InsertBracketVote(w http.ResponseWriter, r *http.Request) {
+	var vote databasestructs.BracketVote
+	if err := json.NewDecoder(r.Body).Decode(&vote); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	matchup, err := p.DB.GetBracketMatchup(vote.MatchupID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "matchup not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if vote.PlayerID != matchup.Player1ID && vote.PlayerID != matchup.Player2ID {
+		http.Error(w, "player is not part of the matchup", http.StatusBadRequest)
+		return
+	}
+
+	if !p.canViewPoll(w, matchup.PollID, vote.UserID) {
+		return
+	}
+
+	bracket, err := p.DB.GetBracket(matchup.PollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if matchup.WinnerID != "" || matchup.Round != bracket.CurrentRound || time.Now().Unix() >= bracket.RoundEndsAt {
+		http.Error(w, "voting for this matchup is closed", http.StatusConflict)
+		return
+	}
+
+	_, err = p.DB.InsertBracketVote(vote)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Voted for player %s in matchup %d", vote.PlayerID, vote.MatchupID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// AdvanceBrackets closes every bracket round that ran out of time, picking the matchup winners and opening the next round
+func AdvanceBrackets(db database.Database, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), bracketAdvanceTimeout)
+	defer cancel()
+
+	due, err := db.GetDueBrackets(ctx, now.Unix())
+	if err != nil {
+		return err
+	}
+
+	for _, bracket := range due {
+		// the winners and the next round are written together, a failure in between would leave winners in a round that never opens
+		err := db.WithTx(ctx, func(tx database.Database) error {
+			return advanceBracket(ctx, tx, bracket, now)
+		})
+		if err != nil {
+			log.Printf("Couldn't advance bracket of poll %d: %v\n", bracket.PollID, err)
+		}
+	}
+
+	return nil
+}
+
+func advanceBracket(ctx context.Context, db database.Database, bracket databasestructs.Bracket, now time.Time) error {
+	matchups, err := db.GetBracketMatchups(ctx, bracket.PollID)
+	if err != nil {
+		return err
+	}
+
+	var tallies []databasestructs.BracketMatchupTally
+	for _, t := range matchups {
+		if t.Round == bracket.CurrentRound {
+			tallies = append(tallies, t)
+		}
+	}
+
+	current := make([]databasestructs.BracketMatchup, 0, len(tallies))
+	for _, t := range tallies {
+		if t.WinnerID == "" {
+			t.WinnerID = matchupWinner(t.BracketMatchup, t.Votes1, t.Votes2)
+			if _, err := db.SetBracketMatchupWinner(t.ID, t.WinnerID); err != nil {
+				return err
+			}
+		}
+		current = append(current, t.BracketMatchup)
+	}
+
+	if len(current) == 1 {
+		_, err := db.SetBracketChampion(bracket.PollID, current[0].WinnerID)
+		return err
+	}
+
+	nextRound := bracket.CurrentRound + 1
+	for i := 0; i+1 < len(current); i += 2 {
+		top, bottom := current[i], current[i+1]
+		matchup := databasestructs.BracketMatchup{
+			PollID:    bracket.PollID,
+			Round:     nextRound,
+			Position:  top.Position / 2,
+			Seed1:     winnerSeed(top),
+			Player1ID: top.WinnerID,
+			Seed2:     winnerSeed(bottom),
+			Player2ID: bottom.WinnerID,
+		}
+
+		if _, err := db.InsertBracketMatchup(matchup); err != nil {
+			return err
+		}
+	}
+
+	roundEndsAt := now.Add(time.Duration(bracket.RoundHours) * time.Hour).Unix()
+	_, err = db.AdvanceBracketRound(bracket.PollID, nextRound, roundEndsAt)
+	return err
+}
+
+// matchupWinner picks the side with more votes, ties go to the better seed
+func matchupWinner(matchup databasestructs.BracketMatchup, votes1, votes2 int64) string {
+	switch {
+	case votes1 > votes2:
+		return matchup.Player1ID
+	case votes2 > votes1:
+		return matchup.Player2ID
+	case matchup.Seed1 <= matchup.Seed2:
+		return matchup.Player1ID
+	default:
+		return matchup.Player2ID
+	}
+}
+
+func winnerSeed(matchup databasestructs.BracketMatchup) int64 {
+	if matchup.WinnerID == matchup.Player1ID {
+		return matchup.Seed1
+	}
+
+	return matchup.Seed2
+}
+
+// seedBracket returns the player ids by seed, either the requested ones or the top of the poll's candidate pool
+func seedBracket(pool []pollCandidate, requested []string, size int64) ([]string, error) {
+	if len(requested) == 0 {
+		if int64(len(pool)) < size {
+			return nil, fmt.Errorf("poll only has %d candidates", len(pool))
+		}
+
+		seeds := make([]string, 0, size)
+		for _, c := range pool[:size] {
+			seeds = append(seeds, c.ID)
+		}
+		return seeds, nil
+	}
+
+	if int64(len(requested)) != size {
+		return nil, fmt.Errorf("expected %d players, got %d", size, len(requested))
+	}
+
+	inPool := make(map[string]bool, len(pool))
+	for _, c := range pool {
+		inPool[c.ID] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	for _, id := range requested {
+		if !inPool[id] {
+			return nil, fmt.Errorf("player %s is not a candidate of the poll", id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("player %s is seeded twice", id)
+		}
+		seen[id] = true
+	}
+
+	return requested, nil
+}
+
+// seedOrder lists the seeds in bracket order so consecutive pairs are the first round matchups (1 v 8, 4 v 5, 2 v 7, 3 v 6)
+// and the top seeds can only meet in the later rounds
+func seedOrder(size int64) []int64 {
+	order := []int64{1}
+	for int64(len(order)) < size {
+		sum := int64(len(order))*2 + 1
+		next := make([]int64, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, sum-seed)
+		}
+		order = next
+	}
+
+	return order
+}
+
+func bracketRounds(size int64) int64 {
+	var rounds int64
+	for size > 1 {
+		size /= 2
+		rounds++
+	}
+
+	return rounds
+}
+
+func isBracketSize(size int64) bool {
+	return size >= minBracketSize && size <= maxBracketSize && size&(size-1) == 0
+}
@@ -0,0 +1,370 @@
+package polls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/players"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// poll type whose candidates are picked by the creator instead of coming from the stats
+const customPollType = "Custom"
+
+const (
+	maxCandidateNameLength = 64
+	maxCuratedCandidates   = 64
+	maxWriteInsPerUser     = 3
+)
+
+type CandidatesPayload struct {
+	PollID     int64                           `json:"pollid"`
+	UserID     int64                           `json:"userid"`
+	Candidates []databasestructs.PollCandidate `json:"candidates"`
+}
+
+type WriteInPayload struct {
+	PollID   int64  `json:"pollid"`
+	UserID   int64  `json:"userid"`
+	PlayerID string `json:"playerid"`
+	Name     string `json:"name"`
+}
+
+type ModerationPayload struct {
+	CandidateID int64  `json:"candidateid"`
+	UserID      int64  `json:"userid"`
+	Status      string `json:"status"`
+}
+
+// AddPollCandidates adds players or free text entries to the poll's curated list
+func (p PollsHandler) AddPollCandidates(w http.ResponseWriter, r *http.Request) {
+	var payload CandidatesPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(payload.Candidates) == 0 || len(payload.Candidates) > maxCuratedCandidates {
+		http.Error(w, fmt.Sprintf("add between 1 and %d candidates", maxCuratedCandidates), http.StatusBadRequest)
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(payload.PollID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "poll not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !p.isPollModerator(poll, payload.UserID) {
+		http.Error(w, "only the poll creator or an admin can change the candidates", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	known, err := p.getKnownPlayers(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range payload.Candidates {
+		candidate := &payload.Candidates[i]
+		candidate.PollID = payload.PollID
+		candidate.Status = "approved"
+		candidate.IsWriteIn = false
+		candidate.SubmittedBy = payload.UserID
+		if err := resolveCandidateName(candidate, known); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	added := 0
+	for _, candidate := range payload.Candidates {
+		_, err := p.DB.InsertPollCandidate(candidate, players.NormalizeName(candidate.Name))
+		if err != nil {
+			// the same name is already on the list
+			if errors.Is(err, database.ErrConflict) {
+				continue
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		added++
+	}
+
+	response := fmt.Sprintf("Added %d candidates to poll %d", added, payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// GetPollCandidates lists the approved curated candidates and write-ins, the moderation queue is only shown to moderators
+func (p PollsHandler) GetPollCandidates(w http.ResponseWriter, r *http.Request) {
+	pollID, err := parseID(r, "pollid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "approved"
+	}
+
+	if status != "approved" && status != "pending" && status != "rejected" {
+		http.Error(w, "status has to be approved, pending or rejected", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, pollID, userID) {
+		return
+	}
+
+	if status != "approved" {
+		poll, err := p.DB.GetPollByID(pollID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !p.isPollModerator(poll, userID) {
+			http.Error(w, "only the poll creator or an admin can see unapproved candidates", http.StatusForbidden)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	candidates, err := p.getPollCandidates(ctx, pollID, status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(candidates)
+}
+
+// SubmitWriteIn lets a voter add a candidate missing from the list, known players are approved right away and free text waits for moderation
+func (p PollsHandler) SubmitWriteIn(w http.ResponseWriter, r *http.Request) {
+	var payload WriteInPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !p.canViewPoll(w, payload.PollID, payload.UserID) {
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(payload.PollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !poll.AllowWriteIns {
+		http.Error(w, "poll doesn't accept write-ins", http.StatusForbidden)
+		return
+	}
+
+	submitted, err := p.DB.CountUserWriteIns(payload.PollID, payload.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if submitted >= maxWriteInsPerUser {
+		http.Error(w, fmt.Sprintf("only %d write-ins per poll are allowed", maxWriteInsPerUser), http.StatusTooManyRequests)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	known, err := p.getKnownPlayers(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	candidate := databasestructs.PollCandidate{PollID: payload.PollID, PlayerID: payload.PlayerID, Name: payload.Name, Status: "pending", IsWriteIn: true, SubmittedBy: payload.UserID}
+	if err := resolveCandidateName(&candidate, known); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if candidate.PlayerID != "" {
+		candidate.Status = "approved"
+
+		// stat based polls already list their players
+		if poll.SelectedStats != customPollType {
+			pool, err := p.getCandidatePool(ctx, poll)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			for _, c := range pool {
+				if c.ID == candidate.PlayerID {
+					http.Error(w, "player is already a candidate", http.StatusConflict)
+					return
+				}
+			}
+		}
+	}
+
+	nameKey := players.NormalizeName(candidate.Name)
+	existing, err := p.DB.GetPollCandidateByKey(payload.PollID, nameKey)
+	if err == nil {
+		if existing.Status == "rejected" {
+			http.Error(w, "candidate was rejected by the poll moderators", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(existing)
+		return
+	}
+
+	if !errors.Is(err, database.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := p.DB.InsertPollCandidate(candidate, nameKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	candidate.ID, _ = result.LastInsertId()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(candidate)
+}
+
+func (p PollsHandler) ModerateCandidate(w http.ResponseWriter, r *http.Request) {
+	var payload ModerationPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.Status != "approved" && payload.Status != "rejected" {
+		http.Error(w, "status has to be approved or rejected", http.StatusBadRequest)
+		return
+	}
+
+	candidate, err := p.DB.GetPollCandidateByID(payload.CandidateID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "candidate not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(candidate.PollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !p.isPollModerator(poll, payload.UserID) {
+		http.Error(w, "only the poll creator or an admin can moderate candidates", http.StatusForbidden)
+		return
+	}
+
+	_, err = p.DB.UpdatePollCandidateStatus(candidate.ID, payload.Status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Candidate %d is %s", candidate.ID, payload.Status)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (p PollsHandler) getPollCandidates(ctx context.Context, pollID int64, status string) ([]databasestructs.PollCandidate, error) {
+	return p.DB.GetPollCandidates(ctx, pollID, status)
+}
+
+// getKnownPlayers maps the ids of every current and GOAT player to their name
+func (p PollsHandler) getKnownPlayers(ctx context.Context) (map[string]string, error) {
+	results, err := p.DB.GetPlayersForSearch(ctx, players.GetEndYearOfTheSeason())
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]string)
+	for _, player := range results {
+		known[player.ID] = player.Name
+	}
+
+	return known, nil
+}
+
+// isPollModerator allows the poll creator and admins to manage the candidates
+func (p PollsHandler) isPollModerator(poll databasestructs.Poll, userID int64) bool {
+	if userID == 0 {
+		return false
+	}
+
+	if poll.UserID == userID {
+		return true
+	}
+
+	roles, err := p.DB.GetUserRolesByID(userID)
+	if err != nil {
+		return false
+	}
+
+	for _, role := range strings.Split(roles, ",") {
+		if strings.TrimSpace(role) == "admin" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveCandidateName takes the name of known players from the database and checks free text names
+func resolveCandidateName(candidate *databasestructs.PollCandidate, known map[string]string) error {
+	if candidate.PlayerID != "" {
+		name, ok := known[candidate.PlayerID]
+		if !ok {
+			return fmt.Errorf("unknown player %q", candidate.PlayerID)
+		}
+		candidate.Name = name
+		return nil
+	}
+
+	candidate.Name = strings.Join(strings.Fields(candidate.Name), " ")
+	if len(candidate.Name) < 2 || len(candidate.Name) > maxCandidateNameLength {
+		return fmt.Errorf("name has to be between 2 and %d characters", maxCandidateNameLength)
+	}
+
+	if players.NormalizeName(candidate.Name) == "" {
+		return fmt.Errorf("name %q has no letters", candidate.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,423 @@
+package polls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/ratings"
+	"strconv"
+	"time"
+)
+
+// number of most recent matchups used to measure how much the ratings still move
+const volatilityWindow = 50
+
+type MatchupCandidate struct {
+	ID      string  `json:"playerid"`
+	Name    string  `json:"name"`
+	Rating  float64 `json:"rating"`
+	Matches int64   `json:"matches"`
+}
+
+type MatchupRanking struct {
+	Rank         int64   `json:"rank"`
+	ID           string  `json:"playerid"`
+	Name         string  `json:"name"`
+	Rating       float64 `json:"rating"`
+	BradleyTerry float64 `json:"bradley_terry"`
+	Matches      int64   `json:"matches"`
+	Wins         int64   `json:"wins"`
+	WinPct       float64 `json:"win_pct"`
+}
+
+type MatchupConvergence struct {
+	TotalVotes       int64   `json:"total_votes"`
+	PairCoverage     float64 `json:"pair_coverage"`
+	AverageMatches   float64 `json:"average_matches"`
+	RecentVolatility float64 `json:"recent_volatility"`
+	Iterations       int     `json:"bradley_terry_iterations"`
+	Converged        bool    `json:"bradley_terry_converged"`
+}
+
+type MatchupRankingsResponse struct {
+	PollID      int64              `json:"pollid"`
+	Convergence MatchupConvergence `json:"convergence"`
+	Rankings    []MatchupRanking   `json:"rankings"`
+}
+
+func (p PollsHandler) GetMatchup(w http.ResponseWriter, r *http.Request) {
+	pollID, err := parseID(r, "pollid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if err != nil {
+		http.Error(w, "Unable to parse user id", http.StatusBadRequest)
+		return
+	}
+
+	if !p.canViewPoll(w, pollID, userID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	candidates, err := p.getMatchupCandidates(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(candidates) < 2 {
+		http.Error(w, "poll needs at least two candidates for matchups", http.StatusBadRequest)
+		return
+	}
+
+	seen, err := p.getUserMatchupPairs(ctx, pollID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	first, second, ok := pickMatchup(candidates, seen, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if !ok {
+		http.Error(w, "every matchup of the poll was already judged", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode([]MatchupCandidate{first, second})
+}
+
+func (p PollsHandler) InsertMatchupVote(w http.ResponseWriter, r *http.Request) {
+	var vote databasestructs.MatchupVote
+	if err := json.NewDecoder(r.Body).Decode(&vote); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if vote.WinnerID == "" || vote.LoserID == "" || vote.WinnerID == vote.LoserID {
+		http.Error(w, "a matchup needs two different players", http.StatusBadRequest)
+		return
+	}
+
+	if !p.canViewPoll(w, vote.PollID, vote.UserID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	candidates, err := p.getMatchupCandidates(ctx, vote.PollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	byID := make(map[string]MatchupCandidate, len(candidates))
+	for _, c := range candidates {
+		byID[c.ID] = c
+	}
+
+	winner, okWinner := byID[vote.WinnerID]
+	loser, okLoser := byID[vote.LoserID]
+	if !okWinner || !okLoser {
+		http.Error(w, "both players have to be candidates of the poll", http.StatusBadRequest)
+		return
+	}
+
+	winnerGain, loserDrop := ratings.EloChange(winner.Rating, loser.Rating, winner.Matches, loser.Matches)
+	vote.RatingChange = winnerGain
+
+	// the vote and both rating changes are kept together, a repeated vote on the pair changes nothing
+	err = p.DB.WithTx(ctx, func(tx database.Database) error {
+		if _, err := tx.InsertMatchupVote(vote); err != nil {
+			return err
+		}
+		if _, err := tx.UpdateMatchupRating(vote.PollID, vote.WinnerID, winnerGain, true); err != nil {
+			return err
+		}
+		_, err := tx.UpdateMatchupRating(vote.PollID, vote.LoserID, -loserDrop, false)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			http.Error(w, "already voted on this matchup", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Voted for player %s over %s in poll %d", vote.WinnerID, vote.LoserID, vote.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (p PollsHandler) GetMatchupRankings(w http.ResponseWriter, r *http.Request) {
+	pollID, err := parseID(r, "pollid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, pollID, userID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	candidates, err := p.getMatchupCandidates(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	comparisons, changes, err := p.getMatchupVotes(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.ID)
+	}
+	bt := ratings.BradleyTerry(ids, comparisons)
+
+	wins := make(map[string]int64)
+	pairs := make(map[string]bool)
+	for _, c := range comparisons {
+		wins[c.Winner]++
+		pairs[pairKey(c.Winner, c.Loser)] = true
+	}
+
+	response := MatchupRankingsResponse{PollID: pollID, Rankings: []MatchupRanking{}}
+	var totalMatches int64
+	for _, c := range candidates {
+		ranking := MatchupRanking{ID: c.ID, Name: c.Name, Rating: round(c.Rating), BradleyTerry: round(bt.Ratings[c.ID]), Matches: c.Matches, Wins: wins[c.ID]}
+		if c.Matches > 0 {
+			ranking.WinPct = round(float64(ranking.Wins) / float64(c.Matches) * 100)
+		}
+		totalMatches += c.Matches
+		response.Rankings = append(response.Rankings, ranking)
+	}
+
+	sort.SliceStable(response.Rankings, func(i, j int) bool {
+		return response.Rankings[i].Rating > response.Rankings[j].Rating
+	})
+	for i := range response.Rankings {
+		response.Rankings[i].Rank = int64(i + 1)
+	}
+
+	response.Convergence = MatchupConvergence{
+		TotalVotes:       int64(len(comparisons)),
+		Iterations:       bt.Iterations,
+		Converged:        bt.Converged,
+		RecentVolatility: recentVolatility(changes),
+	}
+	if n := len(candidates); n > 1 {
+		response.Convergence.PairCoverage = round(float64(len(pairs)) / float64(n*(n-1)/2) * 100)
+		response.Convergence.AverageMatches = round(float64(totalMatches) / float64(n))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getMatchupCandidates returns the poll's candidate pool with their current ratings, candidates without matchups start at the initial rating
+func (p PollsHandler) getMatchupCandidates(ctx context.Context, pollID int64) ([]MatchupCandidate, error) {
+	poll, err := p.DB.GetPollByID(pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := p.getCandidatePool(ctx, poll)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]MatchupCandidate, 0, len(pool))
+	for _, c := range pool {
+		candidates = append(candidates, MatchupCandidate{ID: c.ID, Name: c.Name, Rating: ratings.InitialRating})
+	}
+
+	ratingList, err := p.DB.GetMatchupRatings(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]databasestructs.MatchupRating)
+	for _, rating := range ratingList {
+		stored[rating.PlayerID] = rating
+	}
+
+	for i, c := range candidates {
+		if rating, ok := stored[c.ID]; ok {
+			candidates[i].Rating = rating.Rating
+			candidates[i].Matches = rating.Matches
+		}
+	}
+
+	return candidates, nil
+}
+
+func (p PollsHandler) getMatchupVotes(ctx context.Context, pollID int64) ([]ratings.Comparison, []float64, error) {
+	votes, err := p.DB.GetMatchupVotes(ctx, pollID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comparisons := make([]ratings.Comparison, 0, len(votes))
+	changes := make([]float64, 0, len(votes))
+	for _, vote := range votes {
+		comparisons = append(comparisons, ratings.Comparison{Winner: vote.WinnerID, Loser: vote.LoserID})
+		changes = append(changes, vote.RatingChange)
+	}
+
+	return comparisons, changes, nil
+}
+
+func (p PollsHandler) getUserMatchupPairs(ctx context.Context, pollID, userID int64) (map[string]bool, error) {
+	votes, err := p.DB.GetUserMatchupVotes(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, vote := range votes {
+		seen[pairKey(vote.WinnerID, vote.LoserID)] = true
+	}
+
+	return seen, nil
+}
+
+// pickMatchup pairs the candidate with the fewest matchups with the closest rated candidate the user hasn't judged against them yet.
+// Close ratings make for the most informative votes, once the user has judged every pair there's none left and ok is false.
+func pickMatchup(candidates []MatchupCandidate, seen map[string]bool, rng *rand.Rand) (MatchupCandidate, MatchupCandidate, bool) {
+	order := make([]MatchupCandidate, len(candidates))
+	copy(order, candidates)
+	rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	sort.SliceStable(order, func(i, j int) bool { return order[i].Matches < order[j].Matches })
+
+	for _, first := range order {
+		var best *MatchupCandidate
+		for i, second := range order {
+			if second.ID == first.ID || seen[pairKey(first.ID, second.ID)] {
+				continue
+			}
+
+			if best == nil || math.Abs(second.Rating-first.Rating) < math.Abs(best.Rating-first.Rating) {
+				best = &order[i]
+			}
+		}
+
+		if best != nil {
+			return first, *best, true
+		}
+	}
+
+	return MatchupCandidate{}, MatchupCandidate{}, false
+}
+
+// RebuildMatchupRatings replays the votes of every poll that has matchup votes but no ratings, which is what removing
+// votes from under the ratings leaves behind, like the migration that made every pair unique per user
+func RebuildMatchupRatings(ctx context.Context, db database.Database) error {
+	pollIDs, err := db.GetPollsWithoutMatchupRatings(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, pollID := range pollIDs {
+		err := db.WithTx(ctx, func(tx database.Database) error {
+			return replayMatchupVotes(ctx, tx, pollID)
+		})
+		if err != nil {
+			return fmt.Errorf("rebuilding the matchup ratings of poll %d: %w", pollID, err)
+		}
+	}
+
+	return nil
+}
+
+// replayMatchupVotes scores every vote in the order it was cast against the ratings the votes before it left,
+// the same way InsertMatchupVote did, and stores the new rating changes with the votes
+func replayMatchupVotes(ctx context.Context, tx database.Database, pollID int64) error {
+	votes, err := tx.GetMatchupVotes(ctx, pollID)
+	if err != nil {
+		return err
+	}
+
+	played := make(map[string]*MatchupCandidate)
+	get := func(id string) *MatchupCandidate {
+		if _, ok := played[id]; !ok {
+			played[id] = &MatchupCandidate{ID: id, Rating: ratings.InitialRating}
+		}
+		return played[id]
+	}
+
+	for _, vote := range votes {
+		winner, loser := get(vote.WinnerID), get(vote.LoserID)
+		winnerGain, loserDrop := ratings.EloChange(winner.Rating, loser.Rating, winner.Matches, loser.Matches)
+
+		if _, err := tx.SetMatchupVoteChange(vote.ID, winnerGain); err != nil {
+			return err
+		}
+		if _, err := tx.UpdateMatchupRating(pollID, vote.WinnerID, winnerGain, true); err != nil {
+			return err
+		}
+		if _, err := tx.UpdateMatchupRating(pollID, vote.LoserID, -loserDrop, false); err != nil {
+			return err
+		}
+
+		winner.Rating += winnerGain
+		winner.Matches++
+		loser.Rating -= loserDrop
+		loser.Matches++
+	}
+
+	return nil
+}
+
+func recentVolatility(changes []float64) float64 {
+	if len(changes) == 0 {
+		return 0
+	}
+
+	if len(changes) > volatilityWindow {
+		changes = changes[len(changes)-volatilityWindow:]
+	}
+
+	var sum float64
+	for _, c := range changes {
+		sum += math.Abs(c)
+	}
+
+	return round(sum / float64(len(changes)))
+}
+
+func pairKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+
+	return a + "|" + b
+}
+
+func round(value float64) float64 {
+	return math.Round(value*10) / 10
+}
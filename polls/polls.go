@@ -2,14 +2,16 @@ package polls
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"sportsvoting/database"
 	"sportsvoting/databasestructs"
+	"sportsvoting/groups"
+	"sportsvoting/metrics"
 	"strconv"
 	"time"
 
@@ -20,6 +22,19 @@ type PollsHandler struct {
 	DB database.Database
 }
 
+type pollCandidate struct {
+	ID   string
+	Name string
+}
+
+// stats each poll type returns for its candidates, which are the ones that get league context
+var contextStats = map[string][]string{
+	"Defensive": {"g", "mpg", "rpg", "spg", "bpg", "dws", "dbpm", "defrtg"},
+	"Rookie":    {"g", "mpg", "ppg", "rpg", "apg", "spg", "bpg", "fgpct", "threefgpct", "ftpct", "topg", "per", "ws", "bpm", "offrtg", "defrtg"},
+	"Sixth man": {"g", "mpg", "ppg", "rpg", "apg", "spg", "bpg", "fgpct", "threefgpct", "ftpct", "topg", "per", "ows", "dws", "ws", "obpm", "dbpm", "bpm", "vorp", "offrtg", "defrtg"},
+	"All stats": {"g", "mpg", "ppg", "rpg", "apg", "spg", "bpg", "fgpct", "threefgpct", "ftpct", "topg", "per", "ows", "dws", "ws", "obpm", "dbpm", "bpm", "vorp", "offrtg", "defrtg"},
+}
+
 func parseID(r *http.Request, key string) (int64, error) {
 	pollId := mux.Vars(r)[key]
 	return strconv.ParseInt(pollId, 10, 64)
@@ -35,14 +50,44 @@ func (p PollsHandler) GetPlayerStatsForPoll(w http.ResponseWriter, r *http.Reque
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	poll, err := p.getPollByID(id)
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, id, userID) {
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if poll.SelectedStats == customPollType {
+		candidates, err := p.getPollCandidates(ctx, poll.ID, "approved")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		json.NewEncoder(w).Encode(candidates)
+		return
+	}
+
 	if poll.SelectedStats == "GOAT stats" {
-		goatplayers, err := p.getGOATStats()
+		goatplayers, err := p.DB.GetGOATStats()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = p.addEraAdjustedStats(ctx, goatplayers)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = rankGOATCandidates(goatplayers, poll.ScoreFormula)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -54,17 +99,20 @@ func (p PollsHandler) GetPlayerStatsForPoll(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var players []databasestructs.PlayerInfo
-	switch poll.SelectedStats {
-	case "Defensive":
-		players, err = p.getDefensiveStats(ctx, poll.Season)
-	case "Sixth man":
-		players, err = p.getSixmanStats(ctx, poll.Season)
-	case "Rookie":
-		players, err = p.getRookieStats(ctx, poll.Season)
-	case "All stats":
-		players, err = p.getAllStats(ctx, poll.Season)
+	players, err := p.getSeasonCandidates(ctx, poll)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	byPosition := r.URL.Query().Get("context_group") == "position"
+	err = p.addLeagueContext(ctx, players, poll, byPosition)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+
+	err = rankCandidates(players, poll.ScoreFormula)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -75,6 +123,117 @@ func (p PollsHandler) GetPlayerStatsForPoll(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(players)
 }
 
+func (p PollsHandler) getSeasonCandidates(ctx context.Context, poll databasestructs.Poll) ([]databasestructs.PlayerInfo, error) {
+	switch poll.SelectedStats {
+	case "Defensive":
+		return p.DB.GetDPOYStats(ctx, poll.Season)
+	case "Sixth man":
+		return p.DB.GetSixManStats(ctx, poll.Season)
+	case "Rookie":
+		return p.DB.GetROYStats(ctx, poll.Season)
+	case "All stats":
+		return p.DB.GetPlayerStatsForPoll(ctx, poll.Season)
+	}
+
+	return nil, nil
+}
+
+// IsPoolPlayer reports whether the player is a candidate of a stat based poll, the season and qualification filters of the poll's stat reads decide who is
+func IsPoolPlayer(ctx context.Context, db database.Database, poll databasestructs.Poll, playerID string) (bool, error) {
+	if poll.SelectedStats == "GOAT stats" {
+		goatplayers, err := db.GetGOATStats()
+		if err != nil {
+			return false, err
+		}
+
+		for _, player := range goatplayers {
+			if player.ID == playerID {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	players, err := PollsHandler{DB: db}.getSeasonCandidates(ctx, poll)
+	if err != nil {
+		return false, err
+	}
+
+	for _, player := range players {
+		if player.ID == playerID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getCandidatePool returns the players a poll is choosing between, ordered by the poll's score formula when it has one
+func (p PollsHandler) getCandidatePool(ctx context.Context, poll databasestructs.Poll) ([]pollCandidate, error) {
+	var pool []pollCandidate
+	if poll.SelectedStats == customPollType {
+		candidates, err := p.getPollCandidates(ctx, poll.ID, "approved")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candidate := range candidates {
+			pool = append(pool, pollCandidate{ID: strconv.FormatInt(candidate.ID, 10), Name: candidate.Name})
+		}
+
+		return pool, nil
+	}
+
+	if poll.SelectedStats == "GOAT stats" {
+		goatplayers, err := p.DB.GetGOATStats()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := rankGOATCandidates(goatplayers, poll.ScoreFormula); err != nil {
+			return nil, err
+		}
+
+		for _, player := range goatplayers {
+			pool = append(pool, pollCandidate{ID: player.ID, Name: player.Name})
+		}
+
+		return pool, nil
+	}
+
+	players, err := p.getSeasonCandidates(ctx, poll)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rankCandidates(players, poll.ScoreFormula); err != nil {
+		return nil, err
+	}
+
+	for _, player := range players {
+		pool = append(pool, pollCandidate{ID: player.ID, Name: player.Name})
+	}
+
+	return pool, nil
+}
+
+// addLeagueContext compares the candidates to the qualified players of the poll season, optionally only within their position group
+func (p PollsHandler) addLeagueContext(ctx context.Context, players []databasestructs.PlayerInfo, poll databasestructs.Poll, byPosition bool) error {
+	names, ok := contextStats[poll.SelectedStats]
+	if !ok || len(players) == 0 {
+		return nil
+	}
+
+	seasonPlayers, err := metrics.SeasonPlayers(ctx, p.DB, poll.Season)
+	if err != nil {
+		return err
+	}
+
+	metrics.AddLeagueContext(players, metrics.Qualified(seasonPlayers), names, byPosition)
+	return nil
+}
+
 func (p PollsHandler) GetPollById(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "pollid")
 	if err != nil {
@@ -82,7 +241,12 @@ func (p PollsHandler) GetPollById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	poll, err := p.getPollByID(id)
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, id, userID) {
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -93,36 +257,52 @@ func (p PollsHandler) GetPollById(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(poll)
 }
 
-func (p PollsHandler) getPollByID(id int64) (databasestructs.Poll, error) {
-	var poll databasestructs.Poll
-	err := p.DB.GetPollByID(id).Scan(&poll.Name, &poll.Description, &poll.Image, &poll.SelectedStats, &poll.Season, &poll.UserID)
+// validatePanelWeight checks the share of the blended result given to the panelists
+func validatePanelWeight(weight *float64) error {
+	if weight != nil && (*weight < 0 || *weight > 1) {
+		return fmt.Errorf("panel weight has to be between 0 and 1")
+	}
+
+	return nil
+}
+
+// canViewPoll writes a not found error for group polls the user isn't a member of, so private polls stay hidden
+func (p PollsHandler) canViewPoll(w http.ResponseWriter, pollID, userID int64) bool {
+	poll, err := p.DB.GetPollByID(pollID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "poll not found", http.StatusNotFound)
+			return false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	allowed, err := groups.CanView(p.DB, poll.GroupID, userID)
 	if err != nil {
-		return databasestructs.Poll{}, err
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if !allowed {
+		http.Error(w, "poll not found", http.StatusNotFound)
+		return false
 	}
 
-	return poll, nil
+	return true
 }
 
 func (p PollsHandler) GetPolls(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	rows, err := p.DB.GetPolls(ctx)
+	// group polls are only listed for their members
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	polls, err := p.DB.GetPolls(ctx, userID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	var polls []databasestructs.Poll
-	for rows.Next() {
-		var poll databasestructs.Poll
-		err := rows.Scan(&poll.ID, &poll.Name, &poll.Description, &poll.Image, &poll.SelectedStats, &poll.Season, &poll.UserID)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		polls = append(polls, poll)
-	}
 
 	pollsJSON, err := json.Marshal(polls)
 	if err != nil {
@@ -142,6 +322,28 @@ func (p PollsHandler) CreatePoll(w http.ResponseWriter, r *http.Request) {
 	poll.Description = r.FormValue("description")
 	poll.Season = r.FormValue("season")
 	poll.SelectedStats = r.FormValue("selectedStats")
+	poll.ScoreFormula = r.FormValue("scoreFormula")
+	poll.AllowWriteIns, _ = strconv.ParseBool(r.FormValue("allowWriteIns"))
+	poll.AllowGuests, _ = strconv.ParseBool(r.FormValue("allowGuests"))
+	if err := validateScoreFormula(poll.ScoreFormula, poll.SelectedStats); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if panelWeight := r.FormValue("panelWeight"); panelWeight != "" {
+		weight, err := strconv.ParseFloat(panelWeight, 64)
+		if err != nil {
+			http.Error(w, "Unable to parse panel weight", http.StatusBadRequest)
+			return
+		}
+		poll.PanelWeight = &weight
+	}
+
+	if err := validatePanelWeight(poll.PanelWeight); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	userID, err := strconv.ParseInt(r.FormValue("userid"), 10, 64)
 	if err != nil {
 		http.Error(w, "Unable to parse user id", http.StatusBadRequest)
@@ -149,6 +351,26 @@ func (p PollsHandler) CreatePoll(w http.ResponseWriter, r *http.Request) {
 	}
 
 	poll.UserID = userID
+	if groupID := r.FormValue("groupid"); groupID != "" {
+		poll.GroupID, err = strconv.ParseInt(groupID, 10, 64)
+		if err != nil {
+			http.Error(w, "Unable to parse group id", http.StatusBadRequest)
+			return
+		}
+
+		// only members can add polls to a group
+		role, err := groups.MemberRole(p.DB, poll.GroupID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if role == "" {
+			http.Error(w, "user isn't a member of the group", http.StatusForbidden)
+			return
+		}
+	}
+
 	image, _, err := r.FormFile("photo")
 	if err != nil {
 		http.Error(w, "Unable to parse file", http.StatusBadRequest)
@@ -197,25 +419,55 @@ func (p PollsHandler) UpdatePoll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var pollDB databasestructs.Poll
-	err := p.DB.GetPollByID(poll.ID).Scan(&pollDB.Name, &pollDB.Description, &pollDB.Image, &pollDB.SelectedStats, &pollDB.Season, &pollDB.UserID)
+	if err := validateScoreFormula(poll.ScoreFormula, poll.SelectedStats); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validatePanelWeight(poll.PanelWeight); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pollDB, err := p.DB.GetPollByID(poll.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	_, err = p.DB.UpdatePollByID(poll)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// if the season or stats changed for the poll, reset the votes, the poll only changes together with them
+	err = p.DB.WithTx(ctx, func(tx database.Database) error {
+		if _, err := tx.UpdatePollByID(poll); err != nil {
+			return err
+		}
+
+		if pollDB.Season != poll.Season || pollDB.SelectedStats != poll.SelectedStats {
+			return resetVotes(tx, poll.ID)
+		}
+		return nil
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// if the season or stats changed for the poll, rest the votes
-	if pollDB.Season != poll.Season || pollDB.SelectedStats != poll.SelectedStats {
-		p.DB.ResetPollVotes(poll.ID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// resetVotes removes the player, guest and matchup votes of the poll
+func resetVotes(tx database.Database, pollID int64) error {
+	if _, err := tx.ResetPollVotes(pollID); err != nil {
+		return err
 	}
 
-	w.WriteHeader(http.StatusOK)
+	if _, err := tx.ResetGuestVotes(pollID); err != nil {
+		return err
+	}
+
+	return tx.ResetMatchups(pollID)
 }
 
 func (p PollsHandler) DeletePollByID(w http.ResponseWriter, r *http.Request) {
@@ -258,15 +510,12 @@ func (p PollsHandler) UpdatePollImage(w http.ResponseWriter, r *http.Request) {
 	pollId := r.FormValue("pollId")
 	pollIdInt, _ := strconv.ParseInt(pollId, 10, 64)
 
-	var poll databasestructs.Poll
-	var pollimage sql.NullString
-	err = p.DB.GetPollByID(pollIdInt).Scan(&poll.Name, &poll.Description, &pollimage, &poll.SelectedStats, &poll.Season, &poll.UserID)
+	poll, err := p.DB.GetPollByID(pollIdInt)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	poll.Image = pollimage.String
 	if poll.Image != "" {
 		err := os.Remove(uploadDir + poll.Image)
 		if err != nil {
@@ -311,7 +560,12 @@ func (p PollsHandler) ResetPollVotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := p.DB.ResetPollVotes(id)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	err := p.DB.WithTx(ctx, func(tx database.Database) error {
+		return resetVotes(tx, id)
+	})
 	if err != nil {
 		fmt.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -322,14 +576,16 @@ func (p PollsHandler) ResetPollVotes(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetUserPolls lists the polls the user created, only those the viewer in the userid query parameter can see
 func (p PollsHandler) GetUserPolls(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "userid")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	viewerID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
 
-	polls, err := p.getPollByUserID(id)
+	polls, err := p.DB.GetPollByUserID(id, viewerID)
 	if err != nil {
 		fmt.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -340,45 +596,12 @@ func (p PollsHandler) GetUserPolls(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(polls)
 }
 
-func (p PollsHandler) getPollByUserID(userid int64) ([]databasestructs.Poll, error) {
-	rows, err := p.DB.GetPollByUserID(userid)
-	if err != nil {
-		return nil, err
-	}
-
-	var polls []databasestructs.Poll
-	for rows.Next() {
-		var poll databasestructs.Poll
-		err = rows.Scan(&poll.ID, &poll.Name, &poll.Description, &poll.Image, &poll.SelectedStats, &poll.Season)
-		if err != nil {
-			return nil, err
-		}
-
-		polls = append(polls, poll)
-	}
-
-	return polls, nil
-}
-
 func (p PollsHandler) GetSeasons(w http.ResponseWriter, r *http.Request) {
-	var seasons []string
-
-	rows, err := p.DB.SelectSeasonsForNonGOATStats()
+	seasons, err := p.DB.SelectSeasonsForNonGOATStats()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var season string
-		err := rows.Scan(&season)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-
-		seasons = append(seasons, season)
-	}
 
 	seasonsJson, err := json.Marshal(seasons)
 	if err != nil {
@@ -390,192 +613,30 @@ func (p PollsHandler) GetSeasons(w http.ResponseWriter, r *http.Request) {
 	w.Write(seasonsJson)
 }
 
-func (p PollsHandler) getRookieStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
-	rows, err := p.DB.GetROYStats(ctx, season)
+// addEraAdjustedStats puts every GOAT candidate's career numbers next to the league averages of the seasons they played
+func (p PollsHandler) addEraAdjustedStats(ctx context.Context, players []*databasestructs.PollResponse) error {
+	seasons, err := p.DB.GetGOATEraStats(ctx)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var playerList []databasestructs.PlayerInfo
-
-	for rows.Next() {
-		var p databasestructs.PlayerInfo
-		err = rows.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.WS, &p.BPM, &p.OffRtg, &p.DefRtg)
-		if err != nil {
-			return nil, err
-		}
-		playerList = append(playerList, p)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return playerList, nil
-}
-
-func (p PollsHandler) getAllStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
-	rows, err := p.DB.GetPlayerStatsForPoll(ctx, season)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var playerList []databasestructs.PlayerInfo
-
-	for rows.Next() {
-		var p databasestructs.PlayerInfo
-		err := rows.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.OffWS, &p.DefWS, &p.WS, &p.OffBPM, &p.DefBPM, &p.BPM, &p.VORP, &p.OffRtg, &p.DefRtg)
-		if err != nil {
-			return nil, err
-		}
-		playerList = append(playerList, p)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return playerList, nil
-}
-
-func (p PollsHandler) getGOATStats() ([]*databasestructs.PollResponse, error) {
-	rows, err := p.DB.GetGOATStats()
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	pollResponse := []*databasestructs.PollResponse{}
-
-	for rows.Next() {
-		poll := &databasestructs.PollResponse{}
-		var (
-			playerID        string
-			name            string
-			position        string
-			pointsPerGame   float64
-			reboundsPerGame float64
-			assistsPerGame  float64
-			stealsPerGame   float64
-			blocksPerGame   float64
-			allStar         int64
-			allNBA          int64
-			allDefense      int64
-			championships   int64
-			dpoy            int64
-			finalsmvp       int64
-			mvp             int64
-			per             float64
-			ows             float64
-			dws             float64
-			ws              float64
-			dbpm            float64
-			obpm            float64
-			bpm             float64
-			defRtg          float64
-			offRtg          float64
-			playoffPoints   float64
-			playoffRebounds float64
-			playoffAssists  float64
-		)
-
-		err := rows.Scan(
-			&playerID, &name, &position, &pointsPerGame, &reboundsPerGame, &assistsPerGame, &stealsPerGame, &blocksPerGame,
-			&allStar, &allNBA, &allDefense, &championships, &dpoy, &finalsmvp, &mvp, &per, &ows, &dws, &ws, &dbpm, &obpm, &bpm,
-			&defRtg, &offRtg, &playoffPoints, &playoffRebounds, &playoffAssists)
-		if err != nil {
-			return nil, err
-		}
-
-		poll.ID = playerID
-		poll.Name = name
-		poll.Stats = &databasestructs.PlayerStats{
-			Points:   pointsPerGame,
-			Rebounds: reboundsPerGame,
-			Assists:  assistsPerGame,
-			Steals:   stealsPerGame,
-			Blocks:   blocksPerGame,
-		}
-		poll.GoatPlayers = &databasestructs.GoatPlayers{
-			AllStar:       allStar,
-			AllNba:        allNBA,
-			AllDefense:    allDefense,
-			Championships: championships,
-			Dpoy:          dpoy,
-			FMVP:          finalsmvp,
-			MVP:           mvp,
-		}
-		poll.AdvStats = &databasestructs.AdvancedStats{
-			PER:    per,
-			OffWS:  ows,
-			DefWS:  dws,
-			WS:     ws,
-			DefBPM: dbpm,
-			OffBPM: obpm,
-			BPM:    bpm,
-			DefRtg: defRtg,
-			OffRtg: offRtg,
-		}
-		poll.PlayoffStats = &databasestructs.PlayerStats{
-			Points:   playoffPoints,
-			Rebounds: playoffRebounds,
-			Assists:  playoffAssists,
-		}
-
-		pollResponse = append(pollResponse, poll)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return pollResponse, nil
-}
-
-func (p PollsHandler) getSixmanStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
-	rows, err := p.DB.GetSixManStats(ctx, season)
-	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-	var playerList []databasestructs.PlayerInfo
 
-	for rows.Next() {
-		var p databasestructs.PlayerInfo
-		err := rows.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Points, &p.Rebounds, &p.Assists, &p.Steals, &p.Blocks, &p.FGPercentage, &p.ThreeFGPercentage, &p.FTPercentage, &p.Turnovers, &p.Position, &p.PER, &p.OffWS, &p.DefWS, &p.WS, &p.OffBPM, &p.DefBPM, &p.BPM, &p.VORP, &p.OffRtg, &p.DefRtg)
-		if err != nil {
-			return nil, err
+	careers := make(map[string]databasestructs.GoatStats)
+	baselines := make(map[string]*metrics.EraBaseline)
+	for _, season := range seasons {
+		baseline, ok := baselines[season.Career.PlayerID]
+		if !ok {
+			baseline = &metrics.EraBaseline{}
+			baselines[season.Career.PlayerID] = baseline
+			careers[season.Career.PlayerID] = season.Career
 		}
-		playerList = append(playerList, p)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return playerList, nil
-}
-
-func (p PollsHandler) getDefensiveStats(ctx context.Context, season string) ([]databasestructs.PlayerInfo, error) {
-	rows, err := p.DB.GetDPOYStats(ctx, season)
-	if err != nil {
-		return nil, err
+		baseline.Add(season.Games, season.League)
 	}
-	defer rows.Close()
 
-	var playerList []databasestructs.PlayerInfo
-	for rows.Next() {
-		var p databasestructs.PlayerInfo
-		err := rows.Scan(&p.ID, &p.Name, &p.Games, &p.Minutes, &p.Rebounds, &p.Steals, &p.Blocks, &p.Position, &p.DefWS, &p.DefBPM, &p.DefRtg)
-		if err != nil {
-			return nil, err
+	for _, player := range players {
+		if baseline, ok := baselines[player.ID]; ok {
+			player.EraAdjusted = metrics.EraAdjust(careers[player.ID], *baseline)
 		}
-		playerList = append(playerList, p)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
 	}
 
-	return playerList, nil
+	return nil
 }
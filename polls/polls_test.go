@@ -0,0 +1,503 @@
+package polls
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	publicPollID int64 = 1
+	groupPollID  int64 = 2
+)
+
+// newTestDB returns the users and group of testutil.NewDB with a public custom poll of four candidates
+// and a custom poll of the group with two
+func newTestDB(t *testing.T) database.Database {
+	t.Helper()
+	db := testutil.NewDB(t)
+	exec := testutil.Must(t)
+
+	exec(db.InsertPolls(databasestructs.Poll{Name: "public", SelectedStats: customPollType, UserID: testutil.OwnerID}))
+	exec(db.InsertPolls(databasestructs.Poll{Name: "private", SelectedStats: customPollType, UserID: testutil.OwnerID, GroupID: testutil.GroupID}))
+
+	for _, name := range []string{"Alpha", "Bravo", "Charlie", "Delta"} {
+		exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: publicPollID, Name: name, Status: "approved"}, name))
+	}
+	for _, name := range []string{"Echo", "Foxtrot"} {
+		exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: groupPollID, Name: name, Status: "approved"}, name))
+	}
+
+	return db
+}
+
+// newTestRouter registers the poll routes the way http.SetupHandlers does
+func newTestRouter(db database.Database) *mux.Router {
+	p := PollsHandler{DB: db}
+	get, post := []string{"GET"}, []string{"POST"}
+	return testutil.NewRouter(
+		testutil.Route{Path: "/polls/get/{pollid:[0-9]+}", Handler: p.GetPollById},
+		testutil.Route{Path: "/polls/get", Handler: p.GetPolls},
+		testutil.Route{Path: "/polls/users/get/{userid}", Handler: p.GetUserPolls},
+		testutil.Route{Path: "/polls/update", Handler: p.UpdatePoll, Methods: post},
+		testutil.Route{Path: "/polls/{pollid:[0-9]+}/candidates", Handler: p.GetPollCandidates, Methods: get},
+		testutil.Route{Path: "/polls/{pollid:[0-9]+}/matchup", Handler: p.GetMatchup},
+		testutil.Route{Path: "/polls/{pollid:[0-9]+}/matchup/rankings", Handler: p.GetMatchupRankings},
+		testutil.Route{Path: "/polls/matchup/vote", Handler: p.InsertMatchupVote, Methods: post},
+		testutil.Route{Path: "/polls/{pollid:[0-9]+}/bracket", Handler: p.GetBracket, Methods: get},
+		testutil.Route{Path: "/polls/bracket/create", Handler: p.CreateBracket, Methods: post},
+		testutil.Route{Path: "/polls/{pollid:[0-9]+}/questions", Handler: p.GetPollQuestions, Methods: get},
+		testutil.Route{Path: "/polls/questions/update", Handler: p.SetPollQuestions, Methods: post},
+		testutil.Route{Path: "/polls/ballot", Handler: p.SubmitBallot, Methods: post},
+		testutil.Route{Path: "/polls/{pollid:[0-9]+}/survey/results", Handler: p.GetSurveyResults, Methods: get},
+	)
+}
+
+func TestGetPollByID(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+
+	w := testutil.ServeJSON(t, router, "GET", "/polls/get/1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var poll databasestructs.Poll
+	testutil.Decode(t, w, &poll)
+	if poll.ID != publicPollID || poll.Name != "public" {
+		t.Errorf("poll = %+v, want the public poll", poll)
+	}
+
+	if w := testutil.ServeJSON(t, router, "GET", "/polls/get/99", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing poll: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGroupPollsOnlyVisibleToMembers(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+
+	for _, tc := range []struct {
+		userID int64
+		want   int
+	}{
+		{0, http.StatusNotFound},
+		{testutil.OutsiderID, http.StatusNotFound},
+		{testutil.MemberID, http.StatusOK},
+		{testutil.OwnerID, http.StatusOK},
+	} {
+		w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/polls/get/%d?userid=%d", groupPollID, tc.userID), nil)
+		if w.Code != tc.want {
+			t.Errorf("user %d: status = %d, want %d", tc.userID, w.Code, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		userID int64
+		want   []int64
+	}{
+		{testutil.OutsiderID, []int64{publicPollID}},
+		{testutil.MemberID, []int64{publicPollID, groupPollID}},
+	} {
+		w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/polls/get?userid=%d", tc.userID), nil)
+		var polls []databasestructs.Poll
+		testutil.Decode(t, w, &polls)
+
+		var ids []int64
+		for _, poll := range polls {
+			ids = append(ids, poll.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tc.want) {
+			t.Errorf("user %d: polls = %v, want %v", tc.userID, ids, tc.want)
+		}
+	}
+}
+
+func TestGetPollCandidatesOrderedByName(t *testing.T) {
+	db := newTestDB(t)
+	testutil.Must(t)(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: publicPollID, Name: "Aaron", Status: "approved"}, "aaron"))
+	router := newTestRouter(db)
+
+	w := testutil.ServeJSON(t, router, "GET", "/polls/1/candidates", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var candidates []databasestructs.PollCandidate
+	testutil.Decode(t, w, &candidates)
+
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.Name)
+	}
+	if want := "[Aaron Alpha Bravo Charlie Delta]"; fmt.Sprint(names) != want {
+		t.Errorf("candidates = %v, want %s", names, want)
+	}
+}
+
+func TestCreateBracket(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+	payload := BracketPayload{PollID: publicPollID, UserID: testutil.OwnerID, Size: 4}
+
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/bracket/create", BracketPayload{PollID: publicPollID, UserID: testutil.MemberID, Size: 4}); w.Code != http.StatusForbidden {
+		t.Errorf("bracket by another user: status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/bracket/create", payload); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	w := testutil.ServeJSON(t, router, "GET", "/polls/1/bracket", nil)
+	var bracket BracketResponse
+	testutil.Decode(t, w, &bracket)
+	if len(bracket.Rounds) == 0 || len(bracket.Rounds[0].Matchups) != 2 {
+		t.Fatalf("bracket = %+v, want two first round matchups", bracket)
+	}
+
+	// the top seed meets the bottom seed
+	first := bracket.Rounds[0].Matchups[0]
+	if first.Player1.Seed != 1 || first.Player2.Seed != 4 {
+		t.Errorf("first matchup seeds = %d v %d, want 1 v 4", first.Player1.Seed, first.Player2.Seed)
+	}
+
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/bracket/create", payload); w.Code != http.StatusConflict {
+		t.Errorf("second bracket: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/bracket/create", BracketPayload{PollID: 99, UserID: testutil.OwnerID, Size: 4}); w.Code != http.StatusNotFound {
+		t.Errorf("missing poll: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestUserPollsOnlyVisibleToViewer(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+
+	for _, tc := range []struct {
+		viewerID int64
+		want     string
+	}{
+		{0, "[1]"},
+		{testutil.OutsiderID, "[1]"},
+		{testutil.MemberID, "[1 2]"},
+		{testutil.OwnerID, "[1 2]"},
+	} {
+		w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/polls/users/get/%d?userid=%d", testutil.OwnerID, tc.viewerID), nil)
+		var polls []databasestructs.Poll
+		testutil.Decode(t, w, &polls)
+
+		var ids []int64
+		for _, poll := range polls {
+			ids = append(ids, poll.ID)
+		}
+		if fmt.Sprint(ids) != tc.want {
+			t.Errorf("viewer %d: polls = %v, want %s", tc.viewerID, ids, tc.want)
+		}
+	}
+}
+
+func TestMatchupVoteOncePerPair(t *testing.T) {
+	db := newTestDB(t)
+	router := newTestRouter(db)
+
+	vote := databasestructs.MatchupVote{PollID: publicPollID, UserID: testutil.MemberID, WinnerID: "1", LoserID: "2"}
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/matchup/vote", vote); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	// the pair is the same whoever wins
+	vote.WinnerID, vote.LoserID = vote.LoserID, vote.WinnerID
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/matchup/vote", vote); w.Code != http.StatusConflict {
+		t.Errorf("repeated pair: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	ratings, err := db.GetMatchupRatings(context.Background(), publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range ratings {
+		if r.Matches != 1 {
+			t.Errorf("player %s: matches = %d, want only the first vote counted", r.PlayerID, r.Matches)
+		}
+	}
+	if len(ratings) != 2 {
+		t.Errorf("ratings = %+v, want the two players of the first vote", ratings)
+	}
+
+	vote.UserID = testutil.OwnerID
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/matchup/vote", vote); w.Code != http.StatusOK {
+		t.Errorf("another user on the pair: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+// failingWrites fails the last write of saving the questions or a ballot, inside the transaction as well
+type failingWrites struct {
+	database.Database
+}
+
+func (f failingWrites) WithTx(ctx context.Context, fn func(tx database.Database) error) error {
+	return f.Database.WithTx(ctx, func(tx database.Database) error {
+		return fn(failingWrites{Database: tx})
+	})
+}
+
+func (f failingWrites) InsertPollQuestion(question databasestructs.PollQuestion) (sql.Result, error) {
+	if question.Position > 0 {
+		return nil, errors.New("write failed")
+	}
+	return f.Database.InsertPollQuestion(question)
+}
+
+func (f failingWrites) InsertSurveyAnswer(answer databasestructs.SurveyAnswer) (sql.Result, error) {
+	return nil, errors.New("write failed")
+}
+
+func TestSurveyWritesAreAllOrNothing(t *testing.T) {
+	db := newTestDB(t)
+	router := newTestRouter(db)
+	failing := newTestRouter(failingWrites{Database: db})
+
+	questions := SurveyPayload{PollID: publicPollID, UserID: testutil.OwnerID, Questions: []databasestructs.PollQuestion{
+		{Prompt: "Playoffs?", Type: "yesno"},
+	}}
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/questions/update", questions); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	changed := questions
+	changed.Questions = []databasestructs.PollQuestion{{Prompt: "Finals?", Type: "yesno"}, {Prompt: "Title?", Type: "yesno"}}
+	if w := testutil.ServeJSON(t, failing, "POST", "/polls/questions/update", changed); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed questions: status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	saved, err := db.GetPollQuestions(context.Background(), publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Prompt != "Playoffs?" {
+		t.Fatalf("questions = %+v, want the first question kept", saved)
+	}
+
+	ballot := BallotPayload{PollID: publicPollID, UserID: testutil.MemberID, Answers: []BallotAnswer{{QuestionID: saved[0].ID, Value: "yes"}}}
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/ballot", ballot); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	ballot.Answers[0].Value = "no"
+	if w := testutil.ServeJSON(t, failing, "POST", "/polls/ballot", ballot); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed ballot: status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	answers, err := db.GetSurveyAnswers(context.Background(), publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 1 || answers[0].Value != "yes" {
+		t.Errorf("answers = %+v, want the first ballot kept", answers)
+	}
+
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/questions/update", changed); w.Code != http.StatusConflict {
+		t.Errorf("questions after a ballot: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+func (f failingWrites) InsertBracketMatchup(matchup databasestructs.BracketMatchup) (sql.Result, error) {
+	if matchup.Position > 0 {
+		return nil, errors.New("write failed")
+	}
+	return f.Database.InsertBracketMatchup(matchup)
+}
+
+func TestCreateBracketIsAllOrNothing(t *testing.T) {
+	db := newTestDB(t)
+	payload := BracketPayload{PollID: publicPollID, UserID: testutil.OwnerID, Size: 4}
+
+	if w := testutil.ServeJSON(t, newTestRouter(failingWrites{Database: db}), "POST", "/polls/bracket/create", payload); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed bracket: status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	if _, err := db.GetBracket(publicPollID); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("bracket after a failed matchup: err = %v, want %v", err, database.ErrNotFound)
+	}
+
+	// nothing of the failed attempt is in the way of the next one
+	if w := testutil.ServeJSON(t, newTestRouter(db), "POST", "/polls/bracket/create", payload); w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+func TestGetMatchupOnceEveryPairIsJudged(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+	target := fmt.Sprintf("/polls/%d/matchup?userid=%d", groupPollID, testutil.MemberID)
+
+	w := testutil.ServeJSON(t, router, "GET", target, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var pair []MatchupCandidate
+	testutil.Decode(t, w, &pair)
+	if len(pair) != 2 {
+		t.Fatalf("matchup = %+v, want a pair", pair)
+	}
+
+	// the group poll has two candidates, so this is its only pair
+	vote := databasestructs.MatchupVote{PollID: groupPollID, UserID: testutil.MemberID, WinnerID: pair[0].ID, LoserID: pair[1].ID}
+	if w := testutil.ServeJSON(t, router, "POST", "/polls/matchup/vote", vote); w.Code != http.StatusOK {
+		t.Fatalf("vote: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if w := testutil.ServeJSON(t, router, "GET", target, nil); w.Code != http.StatusNotFound {
+		t.Errorf("every pair judged: status = %d, want %d: %s", w.Code, http.StatusNotFound, w.Body)
+	}
+
+	if w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/polls/%d/matchup?userid=%d", groupPollID, testutil.OwnerID), nil); w.Code != http.StatusOK {
+		t.Errorf("another user: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRebuildMatchupRatingsReplaysTheVotes(t *testing.T) {
+	db := newTestDB(t)
+	router := newTestRouter(db)
+
+	votes := []databasestructs.MatchupVote{
+		{PollID: publicPollID, UserID: testutil.OwnerID, WinnerID: "1", LoserID: "2"},
+		{PollID: publicPollID, UserID: testutil.OwnerID, WinnerID: "1", LoserID: "3"},
+		{PollID: publicPollID, UserID: testutil.MemberID, WinnerID: "2", LoserID: "1"},
+		{PollID: publicPollID, UserID: testutil.MemberID, WinnerID: "4", LoserID: "3"},
+	}
+	for _, vote := range votes {
+		if w := testutil.ServeJSON(t, router, "POST", "/polls/matchup/vote", vote); w.Code != http.StatusOK {
+			t.Fatalf("vote %+v: status = %d, want %d: %s", vote, w.Code, http.StatusOK, w.Body)
+		}
+	}
+
+	ctx := context.Background()
+	want, err := db.GetMatchupRatings(ctx, publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantVotes, err := db.GetMatchupVotes(ctx, publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same votes without their ratings, as the migration that removed repeated votes leaves them
+	rebuilt := newTestDB(t)
+	for _, vote := range votes {
+		testutil.Must(t)(rebuilt.InsertMatchupVote(vote))
+	}
+
+	if pollIDs, err := rebuilt.GetPollsWithoutMatchupRatings(ctx); err != nil || fmt.Sprint(pollIDs) != "[1]" {
+		t.Fatalf("polls without ratings = %v, %v, want [1]", pollIDs, err)
+	}
+
+	if err := RebuildMatchupRatings(ctx, rebuilt); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := rebuilt.GetMatchupRatings(ctx, publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ratings = %+v, want %+v", got, want)
+	}
+
+	gotVotes, err := rebuilt.GetMatchupVotes(ctx, publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(gotVotes) != fmt.Sprint(wantVotes) {
+		t.Errorf("votes = %+v, want the rating changes %+v", gotVotes, wantVotes)
+	}
+
+	if pollIDs, err := rebuilt.GetPollsWithoutMatchupRatings(ctx); err != nil || len(pollIDs) != 0 {
+		t.Errorf("polls without ratings after the rebuild = %v, %v, want none", pollIDs, err)
+	}
+}
+
+func (f failingWrites) ResetMatchups(pollid int64) error {
+	return errors.New("write failed")
+}
+
+func TestUpdatePollResetsVotesWithTheChange(t *testing.T) {
+	db := newTestDB(t)
+	testutil.Must(t)(db.InsertCandidateVote(publicPollID, testutil.MemberID, 1, ""))
+
+	poll, err := db.GetPollByID(publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	poll.Season = "2024"
+
+	if w := testutil.ServeJSON(t, newTestRouter(failingWrites{Database: db}), "POST", "/polls/update", poll); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed reset: status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	ctx := context.Background()
+	if stored, err := db.GetPollByID(publicPollID); err != nil || stored.Season != "" {
+		t.Errorf("poll after a failed reset = %+v, %v, want it unchanged", stored, err)
+	}
+	if tallies, err := db.GetPlayerPollVotes(ctx, publicPollID); err != nil || len(tallies) != 1 {
+		t.Errorf("tallies after a failed reset = %+v, %v, want the vote kept", tallies, err)
+	}
+
+	if w := testutil.ServeJSON(t, newTestRouter(db), "POST", "/polls/update", poll); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if stored, err := db.GetPollByID(publicPollID); err != nil || stored.Season != "2024" {
+		t.Errorf("poll = %+v, %v, want the new season", stored, err)
+	}
+	if tallies, err := db.GetPlayerPollVotes(ctx, publicPollID); err != nil || len(tallies) != 0 {
+		t.Errorf("tallies = %+v, %v, want the votes reset", tallies, err)
+	}
+}
+
+func (f failingWrites) AdvanceBracketRound(pollid, round, roundEndsAt int64) (sql.Result, error) {
+	return nil, errors.New("write failed")
+}
+
+func TestAdvanceBracketIsAllOrNothing(t *testing.T) {
+	db := newTestDB(t)
+	payload := BracketPayload{PollID: publicPollID, UserID: testutil.OwnerID, Size: 4, RoundHours: 1}
+	if w := testutil.ServeJSON(t, newTestRouter(db), "POST", "/polls/bracket/create", payload); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	closed := time.Now().Add(2 * time.Hour)
+
+	// the round can't be opened, so the winners and matchups written before are rolled back
+	if err := AdvanceBrackets(failingWrites{Database: db}, closed); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	matchups, err := db.GetBracketMatchups(ctx, publicPollID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range matchups {
+		if m.Round != 1 || m.WinnerID != "" {
+			t.Errorf("matchup = %+v, want only the first round without winners", m)
+		}
+	}
+	if bracket, err := db.GetBracket(publicPollID); err != nil || bracket.CurrentRound != 1 {
+		t.Errorf("bracket = %+v, %v, want the first round", bracket, err)
+	}
+
+	if err := AdvanceBrackets(db, closed); err != nil {
+		t.Fatal(err)
+	}
+	if matchups, err = db.GetBracketMatchups(ctx, publicPollID); err != nil || len(matchups) != 3 || matchups[0].WinnerID == "" || matchups[2].Round != 2 {
+		t.Errorf("matchups = %+v, %v, want the first round decided and the final", matchups, err)
+	}
+	if bracket, err := db.GetBracket(publicPollID); err != nil || bracket.CurrentRound != 2 {
+		t.Errorf("bracket = %+v, %v, want the second round", bracket, err)
+	}
+}
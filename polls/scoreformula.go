@@ -0,0 +1,81 @@
+package polls
+
+import (
+	"fmt"
+	"sort"
+	"sportsvoting/databasestructs"
+	"sportsvoting/formula"
+	"sportsvoting/metrics"
+)
+
+func validateScoreFormula(scoreFormula, selectedStats string) error {
+	if scoreFormula == "" {
+		return nil
+	}
+
+	if selectedStats == customPollType {
+		return fmt.Errorf("custom polls have no stats to score")
+	}
+
+	f, err := formula.Parse(scoreFormula)
+	if err != nil {
+		return err
+	}
+
+	if selectedStats == "GOAT stats" {
+		return f.Validate(metrics.GOATVariableNames())
+	}
+
+	return f.Validate(metrics.PlayerVariableNames())
+}
+
+// rankCandidates orders the candidates of a season poll by the poll's score formula, keeping the default order without one
+func rankCandidates(players []databasestructs.PlayerInfo, scoreFormula string) error {
+	if scoreFormula == "" {
+		return nil
+	}
+
+	f, err := formula.Parse(scoreFormula)
+	if err != nil {
+		return err
+	}
+
+	for i := range players {
+		score, err := f.Eval(metrics.PlayerVariables(players[i]))
+		if err != nil {
+			return err
+		}
+		players[i].CompositeScore = &score
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		return *players[i].CompositeScore > *players[j].CompositeScore
+	})
+
+	return nil
+}
+
+func rankGOATCandidates(players []*databasestructs.PollResponse, scoreFormula string) error {
+	if scoreFormula == "" {
+		return nil
+	}
+
+	f, err := formula.Parse(scoreFormula)
+	if err != nil {
+		return err
+	}
+
+	for _, player := range players {
+		score, err := f.Eval(metrics.GOATVariables(player))
+		if err != nil {
+			return err
+		}
+		player.CompositeScore = &score
+	}
+
+	sort.SliceStable(players, func(i, j int) bool {
+		return *players[i].CompositeScore > *players[j].CompositeScore
+	})
+
+	return nil
+}
@@ -0,0 +1,592 @@
+package polls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxSurveyQuestions = 20
+	maxPromptLength    = 256
+	defaultRankedPicks = 3
+	maxRankedPicks     = 10
+)
+
+var questionTypes = map[string]bool{"player": true, "team": true, "ranked": true, "yesno": true, "numeric": true}
+
+// errBallotsSubmitted stops a change of the questions, which would make the submitted ballots meaningless
+var errBallotsSubmitted = errors.New("questions can't be changed once ballots were submitted")
+
+type SurveyPayload struct {
+	PollID    int64                          `json:"pollid"`
+	UserID    int64                          `json:"userid"`
+	Questions []databasestructs.PollQuestion `json:"questions"`
+}
+
+type BallotAnswer struct {
+	QuestionID int64    `json:"questionid"`
+	Value      string   `json:"value"`  // player id, team abbreviation or yes/no
+	Values     []string `json:"values"` // ranked list, best first
+	Number     *float64 `json:"number"`
+}
+
+type BallotPayload struct {
+	PollID  int64          `json:"pollid"`
+	UserID  int64          `json:"userid"`
+	Answers []BallotAnswer `json:"answers"`
+}
+
+type ChoiceResult struct {
+	Value       string  `json:"value"`
+	Name        string  `json:"name,omitempty"`
+	Votes       int64   `json:"votes"`
+	Percentage  float64 `json:"percentage"`
+	Points      int64   `json:"points,omitempty"`
+	FirstPlaces int64   `json:"first_places,omitempty"`
+}
+
+type NumericResult struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+}
+
+type QuestionResult struct {
+	Question  databasestructs.PollQuestion `json:"question"`
+	Responses int64                        `json:"responses"`
+	Choices   []ChoiceResult               `json:"choices,omitempty"`
+	Numeric   *NumericResult               `json:"numeric,omitempty"`
+}
+
+type SurveyResultsResponse struct {
+	PollID    int64            `json:"pollid"`
+	Ballots   int64            `json:"ballots"`
+	Questions []QuestionResult `json:"questions"`
+}
+
+func (p PollsHandler) SetPollQuestions(w http.ResponseWriter, r *http.Request) {
+	var payload SurveyPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateQuestions(payload.Questions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(payload.PollID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "poll not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if poll.UserID != payload.UserID {
+		http.Error(w, "only the poll creator can change the questions", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// the old questions are only dropped once all the new ones are saved
+	err = p.DB.WithTx(ctx, func(tx database.Database) error {
+		ballots, err := tx.CountSurveyBallots(payload.PollID)
+		if err != nil {
+			return err
+		}
+		if ballots > 0 {
+			return errBallotsSubmitted
+		}
+
+		if _, err := tx.DeletePollQuestions(payload.PollID); err != nil {
+			return err
+		}
+
+		for i, question := range payload.Questions {
+			question.PollID = payload.PollID
+			question.Position = int64(i)
+			if _, err := tx.InsertPollQuestion(question); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errBallotsSubmitted) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Saved %d questions for poll %d", len(payload.Questions), payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (p PollsHandler) GetPollQuestions(w http.ResponseWriter, r *http.Request) {
+	pollID, err := parseID(r, "pollid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, pollID, userID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	questions, err := p.getPollQuestions(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(questions)
+}
+
+// SubmitBallot stores the answers to every question of the poll, replacing the user's previous ballot
+func (p PollsHandler) SubmitBallot(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1024*1024)
+	var payload BallotPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !p.canViewPoll(w, payload.PollID, payload.UserID) {
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(payload.PollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	questions, err := p.getPollQuestions(ctx, payload.PollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(questions) == 0 {
+		http.Error(w, "poll has no questions", http.StatusBadRequest)
+		return
+	}
+
+	var candidates map[string]string
+	if needsCandidates(questions) {
+		candidates, err = p.getCandidateNames(ctx, poll)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	answers, err := p.ballotAnswers(questions, payload.Answers, candidates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the previous ballot is only replaced once every answer of the new one is saved
+	err = p.DB.WithTx(ctx, func(tx database.Database) error {
+		if _, err := tx.DeleteSurveyBallot(payload.PollID, payload.UserID); err != nil {
+			return err
+		}
+
+		result, err := tx.InsertSurveyBallot(payload.PollID, payload.UserID)
+		if err != nil {
+			return err
+		}
+
+		ballotID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, answer := range answers {
+			answer.BallotID = ballotID
+			if _, err := tx.InsertSurveyAnswer(answer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrConflict) {
+			http.Error(w, "ballot was already submitted", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Submitted ballot for poll %d", payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+func (p PollsHandler) GetSurveyResults(w http.ResponseWriter, r *http.Request) {
+	pollID, err := parseID(r, "pollid")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if !p.canViewPoll(w, pollID, userID) {
+		return
+	}
+
+	poll, err := p.DB.GetPollByID(pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	questions, err := p.getPollQuestions(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	answers, err := p.getSurveyAnswers(ctx, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := map[string]string{}
+	if needsCandidates(questions) {
+		names, err = p.getCandidateNames(ctx, poll)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := SurveyResultsResponse{PollID: pollID, Questions: []QuestionResult{}}
+	response.Ballots, err = p.DB.CountSurveyBallots(pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, question := range questions {
+		response.Questions = append(response.Questions, aggregateQuestion(question, answers[question.ID], names))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (p PollsHandler) getPollQuestions(ctx context.Context, pollID int64) ([]databasestructs.PollQuestion, error) {
+	return p.DB.GetPollQuestions(ctx, pollID)
+}
+
+// getSurveyAnswers groups the answers of all ballots by question, ranked answers stay in ballot order
+func (p PollsHandler) getSurveyAnswers(ctx context.Context, pollID int64) (map[int64][]databasestructs.SurveyAnswer, error) {
+	list, err := p.DB.GetSurveyAnswers(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make(map[int64][]databasestructs.SurveyAnswer)
+	for _, answer := range list {
+		answers[answer.QuestionID] = append(answers[answer.QuestionID], answer)
+	}
+
+	return answers, nil
+}
+
+func (p PollsHandler) getCandidateNames(ctx context.Context, poll databasestructs.Poll) (map[string]string, error) {
+	pool, err := p.getCandidatePool(ctx, poll)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(pool))
+	for _, c := range pool {
+		names[c.ID] = c.Name
+	}
+
+	return names, nil
+}
+
+// ballotAnswers checks that every question is answered once with a valid answer and turns the answers into rows
+func (p PollsHandler) ballotAnswers(questions []databasestructs.PollQuestion, submitted []BallotAnswer, candidates map[string]string) ([]databasestructs.SurveyAnswer, error) {
+	byQuestion := make(map[int64]BallotAnswer, len(submitted))
+	for _, answer := range submitted {
+		if _, ok := byQuestion[answer.QuestionID]; ok {
+			return nil, fmt.Errorf("question %d is answered twice", answer.QuestionID)
+		}
+		byQuestion[answer.QuestionID] = answer
+	}
+
+	if len(byQuestion) != len(questions) {
+		return nil, fmt.Errorf("expected answers to %d questions, got %d", len(questions), len(byQuestion))
+	}
+
+	var answers []databasestructs.SurveyAnswer
+	for _, question := range questions {
+		answer, ok := byQuestion[question.ID]
+		if !ok {
+			return nil, fmt.Errorf("question %d is not answered", question.ID)
+		}
+
+		row := databasestructs.SurveyAnswer{QuestionID: question.ID}
+		switch question.Type {
+		case "player":
+			if _, ok := candidates[answer.Value]; !ok {
+				return nil, fmt.Errorf("question %d: player %s is not a candidate of the poll", question.ID, answer.Value)
+			}
+			row.Value = answer.Value
+		case "team":
+			abbr, err := p.DB.SelectTeamByAbbrevation(answer.Value)
+			if err != nil {
+				return nil, fmt.Errorf("question %d: unknown team %s", question.ID, answer.Value)
+			}
+			row.Value = abbr
+		case "yesno":
+			value := strings.ToLower(answer.Value)
+			if value != "yes" && value != "no" {
+				return nil, fmt.Errorf("question %d: answer has to be yes or no", question.ID)
+			}
+			row.Value = value
+		case "numeric":
+			if err := checkNumber(question, answer.Number); err != nil {
+				return nil, err
+			}
+			row.Number = answer.Number
+		case "ranked":
+			if int64(len(answer.Values)) != question.Picks {
+				return nil, fmt.Errorf("question %d: expected %d ranked players, got %d", question.ID, question.Picks, len(answer.Values))
+			}
+
+			seen := make(map[string]bool, len(answer.Values))
+			for i, value := range answer.Values {
+				if _, ok := candidates[value]; !ok {
+					return nil, fmt.Errorf("question %d: player %s is not a candidate of the poll", question.ID, value)
+				}
+				if seen[value] {
+					return nil, fmt.Errorf("question %d: player %s is ranked twice", question.ID, value)
+				}
+				seen[value] = true
+
+				answers = append(answers, databasestructs.SurveyAnswer{QuestionID: question.ID, Position: int64(i), Value: value})
+			}
+			continue
+		}
+
+		answers = append(answers, row)
+	}
+
+	return answers, nil
+}
+
+func checkNumber(question databasestructs.PollQuestion, number *float64) error {
+	if number == nil || math.IsNaN(*number) || math.IsInf(*number, 0) {
+		return fmt.Errorf("question %d: a number is required", question.ID)
+	}
+
+	if question.MinValue != nil && *number < *question.MinValue {
+		return fmt.Errorf("question %d: answer can't be lower than %g", question.ID, *question.MinValue)
+	}
+
+	if question.MaxValue != nil && *number > *question.MaxValue {
+		return fmt.Errorf("question %d: answer can't be higher than %g", question.ID, *question.MaxValue)
+	}
+
+	return nil
+}
+
+func validateQuestions(questions []databasestructs.PollQuestion) error {
+	if len(questions) == 0 || len(questions) > maxSurveyQuestions {
+		return fmt.Errorf("a survey needs between 1 and %d questions", maxSurveyQuestions)
+	}
+
+	for i := range questions {
+		question := &questions[i]
+		question.Prompt = strings.TrimSpace(question.Prompt)
+		if question.Prompt == "" || len(question.Prompt) > maxPromptLength {
+			return fmt.Errorf("question %d: prompt has to be between 1 and %d characters", i+1, maxPromptLength)
+		}
+
+		if !questionTypes[question.Type] {
+			return fmt.Errorf("question %d: unknown type %q", i+1, question.Type)
+		}
+
+		switch question.Type {
+		case "ranked":
+			if question.Picks == 0 {
+				question.Picks = defaultRankedPicks
+			}
+			if question.Picks < 2 || question.Picks > maxRankedPicks {
+				return fmt.Errorf("question %d: ranked lists have between 2 and %d picks", i+1, maxRankedPicks)
+			}
+		case "numeric":
+			question.Picks = 1
+			if question.MinValue != nil && question.MaxValue != nil && *question.MinValue > *question.MaxValue {
+				return fmt.Errorf("question %d: min_value is higher than max_value", i+1)
+			}
+		default:
+			question.Picks = 1
+		}
+
+		if question.Type != "numeric" {
+			question.MinValue, question.MaxValue = nil, nil
+		}
+	}
+
+	return nil
+}
+
+func needsCandidates(questions []databasestructs.PollQuestion) bool {
+	for _, question := range questions {
+		if question.Type == "player" || question.Type == "ranked" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func aggregateQuestion(question databasestructs.PollQuestion, answers []databasestructs.SurveyAnswer, names map[string]string) QuestionResult {
+	result := QuestionResult{Question: question}
+
+	switch question.Type {
+	case "numeric":
+		var values []float64
+		for _, answer := range answers {
+			if answer.Number != nil {
+				values = append(values, *answer.Number)
+			}
+		}
+
+		result.Responses = int64(len(values))
+		result.Numeric = summarizeNumbers(values)
+	case "ranked":
+		// Borda count, the first place of a list of n picks is worth n points and the last one 1
+		ballots := make(map[int64]bool)
+		choices := make(map[string]*ChoiceResult)
+		for _, answer := range answers {
+			ballots[answer.BallotID] = true
+			choice := getChoice(choices, answer.Value, names)
+			choice.Votes++
+			choice.Points += question.Picks - answer.Position
+			if answer.Position == 0 {
+				choice.FirstPlaces++
+			}
+		}
+
+		result.Responses = int64(len(ballots))
+		result.Choices = sortChoices(choices, result.Responses, func(a, b ChoiceResult) bool { return a.Points > b.Points })
+	default:
+		choices := make(map[string]*ChoiceResult)
+		for _, answer := range answers {
+			getChoice(choices, answer.Value, names).Votes++
+		}
+
+		result.Responses = int64(len(answers))
+		result.Choices = sortChoices(choices, result.Responses, func(a, b ChoiceResult) bool { return a.Votes > b.Votes })
+	}
+
+	return result
+}
+
+func getChoice(choices map[string]*ChoiceResult, value string, names map[string]string) *ChoiceResult {
+	choice, ok := choices[value]
+	if !ok {
+		choice = &ChoiceResult{Value: value, Name: names[value]}
+		choices[value] = choice
+	}
+
+	return choice
+}
+
+func sortChoices(choices map[string]*ChoiceResult, responses int64, better func(a, b ChoiceResult) bool) []ChoiceResult {
+	sorted := make([]ChoiceResult, 0, len(choices))
+	for _, choice := range choices {
+		if responses > 0 {
+			choice.Percentage = round(float64(choice.Votes) / float64(responses) * 100)
+		}
+		sorted = append(sorted, *choice)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if better(sorted[i], sorted[j]) != better(sorted[j], sorted[i]) {
+			return better(sorted[i], sorted[j])
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+
+	return sorted
+}
+
+func summarizeNumbers(values []float64) *NumericResult {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(sorted))
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	return &NumericResult{
+		Mean:   round(mean),
+		Median: round(median),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		StdDev: round(math.Sqrt(variance)),
+	}
+}
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS seasons_entered (
+  id      SERIAL PRIMARY KEY,
+  season  VARCHAR(25)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS matchup_votes (
+  id             SERIAL PRIMARY KEY,
+  pollid         INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  userid         INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  winnerid       VARCHAR(128) NOT NULL,
+  loserid        VARCHAR(128) NOT NULL,
+  rating_change  REAL DEFAULT 0, /* elo points the winner gained */
+  created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS bracket_votes (
+  matchupid  INT NOT NULL REFERENCES bracket_matchups(id) ON DELETE CASCADE,
+  userid     INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  playerid   VARCHAR(128) NOT NULL,
+  PRIMARY KEY(matchupid, userid)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS teams (
+  teamabbr          VARCHAR(3) PRIMARY KEY,
+  name              VARCHAR(128) NOT NULL,
+  logo              VARCHAR(256) NOT NULL,
+  winlosspct        REAL,
+  playoffs          INT,
+  divisiontitles    INT,
+  conferencetitles  INT,
+  championships     INT
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS survey_answers (
+  ballotid    INT NOT NULL REFERENCES survey_ballots(id) ON DELETE CASCADE,
+  questionid  INT NOT NULL REFERENCES poll_questions(id) ON DELETE CASCADE,
+  position    INT NOT NULL DEFAULT 0, /* place in the list for ranked questions */
+  value       VARCHAR(128) DEFAULT '',
+  number      DOUBLE PRECISION DEFAULT NULL,
+  PRIMARY KEY(ballotid, questionid, position)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS team_standings (
+  season    VARCHAR(25) NOT NULL, /* year of the season */
+  teamabbr  VARCHAR(3) NOT NULL REFERENCES teams(teamabbr),
+  wins      INT NOT NULL,
+  losses    INT NOT NULL,
+  PRIMARY KEY(season, teamabbr)
+);
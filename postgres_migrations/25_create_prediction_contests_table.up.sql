@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS prediction_contests (
+  id           SERIAL PRIMARY KEY,
+  name         VARCHAR(128) NOT NULL,
+  description  VARCHAR(256) DEFAULT '',
+  season       VARCHAR(25) NOT NULL, /* year of the season */
+  deadline     INT NOT NULL, /* unix time predictions lock */
+  resolved     BOOLEAN DEFAULT false,
+  userid       INT NOT NULL REFERENCES users(id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS poll_candidates (
+  id            SERIAL PRIMARY KEY,
+  pollid        INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  playerid      VARCHAR(128) DEFAULT NULL, /* id from players or goat_players, empty for free text entries */
+  name          VARCHAR(128) NOT NULL,
+  name_key      VARCHAR(128) NOT NULL, /* normalized name so the same write-in isn't added twice */
+  status        VARCHAR(8) NOT NULL DEFAULT 'approved' CHECK (status IN ('approved', 'pending', 'rejected')),
+  is_write_in   BOOLEAN DEFAULT false,
+  submitted_by  INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  UNIQUE(pollid, name_key)
+);
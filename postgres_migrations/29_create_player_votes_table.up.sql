@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS player_votes (
+  id            SERIAL PRIMARY KEY,
+  playerid      VARCHAR(128) REFERENCES players(playerid),
+  goatplayerid  VARCHAR(128) REFERENCES goat_players(playerid),
+  candidateid   INT DEFAULT NULL REFERENCES poll_candidates(id) ON DELETE CASCADE,
+  pollid        INT NOT NULL REFERENCES polls(id),
+  userid        INT NOT NULL REFERENCES users(id),
+  votes_for     INT NOT NULL,
+  created_at    INT DEFAULT 0, /* unix time */
+  ip            VARCHAR(45) DEFAULT '',
+  switches      INT DEFAULT 0 /* how often the user changed their vote in the poll */
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS players (
+  playerid   VARCHAR(128) PRIMARY KEY NOT NULL,
+  name       VARCHAR(128) NOT NULL,
+  teamabbr   VARCHAR(3) DEFAULT '' REFERENCES teams(teamabbr),
+  college    VARCHAR(128) DEFAULT '',
+  height     VARCHAR(5) DEFAULT '',
+  weight     VARCHAR(3) DEFAULT '',
+  age        INT NOT NULL
+);
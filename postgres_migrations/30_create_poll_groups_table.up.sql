@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS poll_groups (
+  id           SERIAL PRIMARY KEY,
+  name         VARCHAR(128) NOT NULL,
+  description  VARCHAR(256) DEFAULT '',
+  invite_code  VARCHAR(16) NOT NULL UNIQUE,
+  ownerid      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at   INT NOT NULL /* unix time */
+);
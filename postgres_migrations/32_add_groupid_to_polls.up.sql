@@ -0,0 +1 @@
+ALTER TABLE polls ADD COLUMN groupid INT DEFAULT NULL CONSTRAINT fk_polls_group REFERENCES poll_groups(id) ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS guest_votes (
+  id           SERIAL PRIMARY KEY,
+  pollid       INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  deviceid     VARCHAR(64) NOT NULL, /* from the signed device token */
+  ip           VARCHAR(45) NOT NULL,
+  playerid     VARCHAR(128) DEFAULT NULL, /* id from players or goat_players */
+  candidateid  INT DEFAULT NULL REFERENCES poll_candidates(id) ON DELETE CASCADE,
+  created_at   INT NOT NULL, /* unix time */
+  UNIQUE(pollid, deviceid)
+);
+
+CREATE INDEX IF NOT EXISTS guest_votes_pollid_ip ON guest_votes(pollid, ip);
@@ -0,0 +1,16 @@
+/* the ratings of the polls with repeats are dropped and replayed from the remaining votes when the server starts */
+DELETE FROM matchup_ratings WHERE pollid IN (
+  SELECT v.pollid FROM matchup_votes v
+  INNER JOIN matchup_votes e ON e.pollid = v.pollid AND e.userid = v.userid
+    AND LEAST(e.winnerid, e.loserid) = LEAST(v.winnerid, v.loserid)
+    AND GREATEST(e.winnerid, e.loserid) = GREATEST(v.winnerid, v.loserid)
+    AND e.id < v.id
+);
+/* keeps the first vote of a user on every pair of players, the removed repeats aren't brought back by the down migration */
+DELETE FROM matchup_votes v USING matchup_votes e
+  WHERE e.pollid = v.pollid AND e.userid = v.userid
+    AND LEAST(e.winnerid, e.loserid) = LEAST(v.winnerid, v.loserid)
+    AND GREATEST(e.winnerid, e.loserid) = GREATEST(v.winnerid, v.loserid)
+    AND e.id < v.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS matchup_votes_pair ON matchup_votes(pollid, userid, LEAST(winnerid, loserid), GREATEST(winnerid, loserid));
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS stats (
+  id                 SERIAL PRIMARY KEY,
+  playerid           VARCHAR(128) NOT NULL REFERENCES players(playerid),
+  gamesplayed        INT,
+  gamesstarted       INT,
+  minutespergame     REAL,
+  pointspergame      REAL,
+  reboundspergame    REAL,
+  assistspergame     REAL,
+  stealspergame      REAL,
+  fgpercentage       REAL,
+  threeptpercentage  REAL,
+  ftpercentage       REAL,
+  blockspergame      REAL,
+  turnoverspergame   REAL,
+  season             VARCHAR(25), /* year of the season */
+  position           VARCHAR(5),
+  teamabbr           VARCHAR(3) REFERENCES teams(teamabbr),
+  rookieseason       BOOLEAN DEFAULT false
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS advancedstats (
+  id        SERIAL PRIMARY KEY,
+  playerid  VARCHAR(128) NOT NULL REFERENCES players(playerid),
+  per       REAL,
+  tspct     REAL,
+  usgpct    REAL,
+  ows       REAL,
+  dws       REAL,
+  ws        REAL,
+  obpm      REAL,
+  dbpm      REAL,
+  bpm       REAL,
+  vorp      REAL,
+  offrtg    REAL,
+  defrtg    REAL,
+  season    VARCHAR(25), /* year of the season */
+  teamabbr  VARCHAR(3) REFERENCES teams(teamabbr)
+);
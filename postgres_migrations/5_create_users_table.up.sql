@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS users (
+  id             SERIAL PRIMARY KEY,
+  username       VARCHAR(64) DEFAULT '',
+  email          VARCHAR(64) DEFAULT '',
+  password       TEXT,
+  refresh_token  TEXT,
+  profile_pic    VARCHAR(255) DEFAULT '',
+  created_at     INT DEFAULT 0, /* unix time */
+  signup_ip      VARCHAR(45) DEFAULT '',
+  favorite_team  VARCHAR(3) DEFAULT NULL, /* teamabbr, self-reported */
+  region         VARCHAR(64) DEFAULT NULL /* self-reported */
+);
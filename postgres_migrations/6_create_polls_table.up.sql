@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS polls (
+  id               SERIAL PRIMARY KEY,
+  name             VARCHAR(128) NOT NULL,
+  description      VARCHAR(256) DEFAULT '',
+  image            VARCHAR(500) DEFAULT '',
+  selected_stats   VARCHAR(500) NOT NULL,
+  season           CHAR(25) NOT NULL,
+  userid           INT NOT NULL REFERENCES users(id),
+  score_formula    VARCHAR(500) DEFAULT '',
+  allow_write_ins  BOOLEAN DEFAULT false,
+  panel_weight     DOUBLE PRECISION DEFAULT NULL, /* share of the blended result given to the panelists, NULL when the poll isn't blended */
+  allow_guests     BOOLEAN DEFAULT false
+);
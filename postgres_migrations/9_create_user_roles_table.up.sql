@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS user_roles (
+  id       SERIAL PRIMARY KEY,
+  user_id  INT REFERENCES users(id) ON DELETE CASCADE,
+  role     VARCHAR(50)
+);
@@ -0,0 +1,88 @@
+package ratings
+
+import "math"
+
+const (
+	maxIterations = 500
+	tolerance     = 1e-6
+)
+
+type Comparison struct {
+	Winner string
+	Loser  string
+}
+
+type BradleyTerryResult struct {
+	// strengths are on the elo scale, so they read the same as the running ratings
+	Ratings    map[string]float64
+	Iterations int
+	Converged  bool
+}
+
+// BradleyTerry fits strengths to all recorded comparisons with the MM algorithm.
+// Every candidate gets one virtual win and loss against an average opponent of strength 1, which keeps undefeated
+// or winless candidates finite and anchors the scale so an average candidate ends up at the initial rating.
+func BradleyTerry(candidates []string, comparisons []Comparison) BradleyTerryResult {
+	index := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		index[c] = i
+	}
+
+	n := len(candidates)
+	wins := make([]float64, n)
+	games := make([]map[int]float64, n)
+	for i := range games {
+		games[i] = make(map[int]float64)
+		wins[i] = 1
+	}
+
+	for _, c := range comparisons {
+		w, okW := index[c.Winner]
+		l, okL := index[c.Loser]
+		if !okW || !okL || w == l {
+			continue
+		}
+
+		wins[w]++
+		games[w][l]++
+		games[l][w]++
+	}
+
+	strength := make([]float64, n)
+	for i := range strength {
+		strength[i] = 1
+	}
+
+	result := BradleyTerryResult{Ratings: make(map[string]float64, n)}
+	for result.Iterations < maxIterations {
+		result.Iterations++
+		next := make([]float64, n)
+		maxChange := 0.0
+		for i := 0; i < n; i++ {
+			// the virtual average opponent has strength 1 and played twice
+			denominator := 2 / (strength[i] + 1)
+			for j, count := range games[i] {
+				denominator += count / (strength[i] + strength[j])
+			}
+			next[i] = wins[i] / denominator
+		}
+
+		for i := range next {
+			if change := math.Abs(next[i]-strength[i]) / strength[i]; change > maxChange {
+				maxChange = change
+			}
+		}
+		strength = next
+
+		if maxChange < tolerance {
+			result.Converged = true
+			break
+		}
+	}
+
+	for i, c := range candidates {
+		result.Ratings[c] = InitialRating + 400*math.Log10(strength[i])
+	}
+
+	return result
+}
@@ -0,0 +1,30 @@
+package ratings
+
+import "math"
+
+const (
+	InitialRating = 1500.0
+	// new candidates move quickly until they've been in enough matchups to settle
+	provisionalK     = 32.0
+	establishedK     = 16.0
+	provisionalGames = 30
+)
+
+// ExpectedScore is the probability the player rated a beats the player rated b
+func ExpectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// EloChange returns how many points the winner gains and the loser drops
+func EloChange(winner, loser float64, winnerMatches, loserMatches int64) (float64, float64) {
+	expected := ExpectedScore(winner, loser)
+	return kFactor(winnerMatches) * (1 - expected), kFactor(loserMatches) * (1 - expected)
+}
+
+func kFactor(matches int64) float64 {
+	if matches < provisionalGames {
+		return provisionalK
+	}
+
+	return establishedK
+}
@@ -3,7 +3,10 @@ package request
 import (
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -81,3 +84,19 @@ func GetPlayerIDFromLeadersDocument(row *goquery.Selection) string {
 
 	return ""
 }
+
+// ClientIP takes the address from the proxy header only when TRUST_PROXY_HEADERS is set, otherwise anyone could pick their own address
+func ClientIP(r *http.Request) string {
+	if trust, _ := strconv.ParseBool(os.Getenv("TRUST_PROXY_HEADERS")); trust {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
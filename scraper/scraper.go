@@ -30,3 +30,18 @@ func GetTeamLogo(doc *goquery.Document) string {
 
 	return ""
 }
+
+// GetSeasonEndYear turns a season like "1999-00" into the year it ended in, which is how seasons are stored
+func GetSeasonEndYear(row *goquery.Selection) string {
+	season := strings.TrimSpace(row.Find("th[data-stat='season']").Text())
+	if len(season) < 4 {
+		return ""
+	}
+
+	startYear, err := strconv.Atoi(season[:4])
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprint(startYear + 1)
+}
@@ -0,0 +1,115 @@
+package seasonresults
+
+import (
+	"fmt"
+	"log"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/request"
+	"sportsvoting/scraper"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// award tables on the basketball-reference awards page, named the way they are stored
+var awardTables = []string{"mvp", "roy", "dpoy", "smoy", "mip"}
+
+// basketball-reference ships some tables inside html comments, removing the markers makes them part of the document
+var commentReplacer = strings.NewReplacer("<!--", "", "-->", "")
+
+func GetStandings(season string) ([]databasestructs.TeamStanding, error) {
+	url := fmt.Sprintf("https://www.basketball-reference.com/leagues/NBA_%s.html", season)
+	doc, err := request.GetDocumentFromURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var standings []databasestructs.TeamStanding
+	doc.Find("table#confs_standings_E > tbody > tr.full_table, table#confs_standings_W > tbody > tr.full_table").Each(func(i int, row *goquery.Selection) {
+		href, exists := row.Find("th[data-stat='team_name'] > a").Attr("href")
+		if !exists {
+			return
+		}
+
+		// links look like /teams/BOS/2024.html
+		parts := strings.Split(href, "/")
+		if len(parts) < 3 {
+			return
+		}
+
+		standings = append(standings, databasestructs.TeamStanding{
+			Season:   season,
+			TeamAbbr: parts[2],
+			Wins:     scraper.GetTDDataStatInt(row, "wins"),
+			Losses:   scraper.GetTDDataStatInt(row, "losses"),
+		})
+	})
+
+	return standings, nil
+}
+
+func GetAwardWinners(season string) ([]databasestructs.SeasonAward, error) {
+	url := fmt.Sprintf("https://www.basketball-reference.com/awards/awards_%s.html", season)
+	doc, err := request.GetDocumentFromURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := doc.Html()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err = goquery.NewDocumentFromReader(strings.NewReader(commentReplacer.Replace(html)))
+	if err != nil {
+		return nil, err
+	}
+
+	var awards []databasestructs.SeasonAward
+	for _, award := range awardTables {
+		// the voting table lists the winner first
+		row := doc.Find(fmt.Sprintf("table#%s > tbody > tr", award)).First()
+		playerID := request.GetPlayerIDFromDocument(row)
+		if playerID == "" {
+			continue
+		}
+
+		awards = append(awards, databasestructs.SeasonAward{Season: season, Award: award, PlayerID: playerID})
+	}
+
+	return awards, nil
+}
+
+// InsertSeasonResults stores the standings and award winners of the season, awards are only published after the playoffs
+func InsertSeasonResults(db database.Database, season string) error {
+	log.Printf("Getting results of season %s\n", season)
+	standings, err := GetStandings(season)
+	if err != nil {
+		return err
+	}
+
+	for _, standing := range standings {
+		_, err := db.InsertTeamStanding(standing)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	time.Sleep(4 * time.Second)
+
+	awards, err := GetAwardWinners(season)
+	if err != nil {
+		return err
+	}
+
+	for _, award := range awards {
+		_, err := db.InsertSeasonAward(award)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS team_votes (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  teamabbr   VARCHAR(3) REFERENCES teams(teamabbr),
+  pollid     INT NOT NULL REFERENCES polls(id),
+  votes_for  INT NOT NULL
+);
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS seasons_entered (
+  id      INTEGER PRIMARY KEY AUTOINCREMENT,
+  season  VARCHAR(25)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS goat_stats;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS goat_stats (
+  id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+  playerid           VARCHAR(128) NOT NULL REFERENCES goat_players(playerid),
+  pointspergame      REAL,
+  reboundspergame    REAL,
+  assistspergame     REAL,
+  stealspergame      REAL,
+  blockspergame      REAL,
+  fgpercentage       REAL,
+  threeptpercentage  REAL,
+  ftpercentage       REAL,
+  turnoverspergame   REAL,
+  per                REAL,
+  ows                REAL,
+  dws                REAL,
+  ws                 REAL,
+  obpm               REAL,
+  dbpm               REAL,
+  bpm                REAL,
+  vorp               REAL,
+  offrtg             REAL,
+  defrtg             REAL,
+  totalpoints        INT,
+  totalrebounds      INT,
+  totalassists       INT,
+  totalsteals        INT,
+  totalblocks        INT,
+  isplayoffs         BOOLEAN DEFAULT false,
+  isactive           BOOLEAN DEFAULT false,
+  position           VARCHAR(5),
+  minutespergame     REAL DEFAULT 0,
+  tspct              REAL DEFAULT 0
+);
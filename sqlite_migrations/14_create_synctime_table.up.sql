@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS sync_time (
+  name            VARCHAR(7) NOT NULL CHECK (name IN ('Regular', 'GOAT')),
+  last_sync_time  INT DEFAULT NULL
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS goat_player_seasons (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  playerid     VARCHAR(128) NOT NULL REFERENCES goat_players(playerid),
+  season       VARCHAR(25) NOT NULL, /* year the season ended */
+  gamesplayed  INT,
+  UNIQUE(playerid, season)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS matchup_ratings (
+  pollid    INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  playerid  VARCHAR(128) NOT NULL,
+  rating    REAL NOT NULL,
+  matches   INT NOT NULL DEFAULT 0,
+  wins      INT NOT NULL DEFAULT 0,
+  PRIMARY KEY(pollid, playerid)
+);
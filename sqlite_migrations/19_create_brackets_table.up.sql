@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS brackets (
+  pollid         INT PRIMARY KEY NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  size           INT NOT NULL,
+  round_hours    INT NOT NULL,
+  current_round  INT NOT NULL DEFAULT 1,
+  round_ends_at  INT NOT NULL, /* unix time the current round closes */
+  championid     VARCHAR(128) DEFAULT NULL
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS poll_questions (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  pollid     INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  position   INT NOT NULL,
+  prompt     VARCHAR(256) NOT NULL,
+  type       VARCHAR(16) NOT NULL CHECK (type IN ('player', 'team', 'ranked', 'yesno', 'numeric')),
+  picks      INT NOT NULL DEFAULT 1, /* length of the list for ranked questions */
+  min_value  DOUBLE DEFAULT NULL,
+  max_value  DOUBLE DEFAULT NULL,
+  UNIQUE(pollid, position)
+);
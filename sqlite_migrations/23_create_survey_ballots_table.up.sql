@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS survey_ballots (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  pollid      INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  userid      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(pollid, userid)
+);
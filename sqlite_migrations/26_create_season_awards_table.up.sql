@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS season_awards (
+  season    VARCHAR(25) NOT NULL, /* year of the season */
+  award     VARCHAR(8) NOT NULL CHECK (award IN ('mvp', 'roy', 'dpoy', 'smoy', 'mip')),
+  playerid  VARCHAR(128) NOT NULL,
+  PRIMARY KEY(season, award)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS prediction_questions (
+  id             INTEGER PRIMARY KEY AUTOINCREMENT,
+  contestid      INT NOT NULL REFERENCES prediction_contests(id) ON DELETE CASCADE,
+  position       INT NOT NULL,
+  prompt         VARCHAR(256) NOT NULL,
+  type           VARCHAR(16) NOT NULL CHECK (type IN ('win_total', 'award', 'stat_leader')),
+  target         VARCHAR(128) NOT NULL, /* team abbreviation, award or stat the question is about */
+  points         INT NOT NULL DEFAULT 10,
+  answer         VARCHAR(128) DEFAULT NULL,
+  answer_number  DOUBLE DEFAULT NULL,
+  UNIQUE(contestid, position)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS predictions (
+  questionid  INT NOT NULL REFERENCES prediction_questions(id) ON DELETE CASCADE,
+  userid      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  value       VARCHAR(128) DEFAULT '',
+  number      DOUBLE DEFAULT NULL,
+  score       DOUBLE DEFAULT NULL,
+  PRIMARY KEY(questionid, userid)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS vote_flags (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  pollid       INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  userid       INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reason       VARCHAR(32) NOT NULL CHECK (reason IN ('new_account_burst', 'ip_cluster', 'vote_switching')),
+  details      VARCHAR(256) DEFAULT '',
+  status       VARCHAR(8) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'excluded', 'cleared')),
+  created_at   INT NOT NULL, /* unix time */
+  reviewed_by  INT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+  UNIQUE(pollid, userid, reason)
+);
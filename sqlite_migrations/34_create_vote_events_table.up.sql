@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS vote_events (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  pollid      INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+  userid      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event       VARCHAR(9) NOT NULL CHECK (event IN ('cast', 'changed', 'retracted', 'reset')),
+  choice      VARCHAR(128) DEFAULT NULL, /* player, GOAT player or candidate id voted for, NULL when the vote was taken away */
+  previous    VARCHAR(128) DEFAULT NULL, /* the pick the event replaced or removed */
+  created_at  INT NOT NULL /* unix time */
+);
+
+CREATE INDEX IF NOT EXISTS vote_events_pollid_created_at ON vote_events(pollid, created_at);
+CREATE INDEX IF NOT EXISTS vote_events_userid_created_at ON vote_events(userid, created_at);
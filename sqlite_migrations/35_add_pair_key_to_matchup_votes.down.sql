@@ -0,0 +1 @@
+DROP INDEX IF EXISTS matchup_votes_pair;
@@ -0,0 +1,18 @@
+/* the ratings of the polls with repeats are dropped and replayed from the remaining votes when the server starts */
+DELETE FROM matchup_ratings WHERE pollid IN (
+  SELECT v.pollid FROM matchup_votes v
+  INNER JOIN matchup_votes e ON e.pollid = v.pollid AND e.userid = v.userid
+    AND min(e.winnerid, e.loserid) = min(v.winnerid, v.loserid)
+    AND max(e.winnerid, e.loserid) = max(v.winnerid, v.loserid)
+    AND e.id < v.id
+);
+/* keeps the first vote of a user on every pair of players, the removed repeats aren't brought back by the down migration */
+DELETE FROM matchup_votes WHERE EXISTS (
+  SELECT 1 FROM matchup_votes e
+  WHERE e.pollid = matchup_votes.pollid AND e.userid = matchup_votes.userid
+    AND min(e.winnerid, e.loserid) = min(matchup_votes.winnerid, matchup_votes.loserid)
+    AND max(e.winnerid, e.loserid) = max(matchup_votes.winnerid, matchup_votes.loserid)
+    AND e.id < matchup_votes.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS matchup_votes_pair ON matchup_votes(pollid, userid, min(winnerid, loserid), max(winnerid, loserid));
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS group_members (
+  groupid    INT NOT NULL REFERENCES poll_groups(id) ON DELETE CASCADE,
+  userid     INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role       VARCHAR(6) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+  joined_at  INT NOT NULL, /* unix time */
+  PRIMARY KEY(groupid, userid)
+);
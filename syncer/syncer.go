@@ -2,13 +2,17 @@ package syncer
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"sportsvoting/contests"
 	"sportsvoting/database"
 	"sportsvoting/databasestructs"
+	"sportsvoting/fraud"
 	"sportsvoting/goatplayers"
+	"sportsvoting/leagueaverages"
 	"sportsvoting/players"
+	"sportsvoting/polls"
 	"sportsvoting/teams"
 	"time"
 )
@@ -62,7 +66,7 @@ func RunUpdate(db database.Database, ctx context.Context) {
 func isSyncNeeded(db database.Database, syncType string) (bool, error) {
 	syncTime, err := db.GetLastSyncTime(syncType)
 	if err != nil {
-		if err != sql.ErrNoRows {
+		if !errors.Is(err, database.ErrNotFound) {
 			log.Println(err)
 		}
 		return true, err
@@ -83,7 +87,7 @@ func SyncRegular(db database.Database) {
 			return
 		}
 
-		if errSync == sql.ErrNoRows {
+		if errors.Is(errSync, database.ErrNotFound) {
 			db.InsertLastSyncTime(time.Now(), "Regular")
 		} else {
 			db.UpdateLastSyncTime(time.Now(), "Regular")
@@ -95,10 +99,15 @@ func SyncGOAT(db database.Database) {
 	isSyncNeeded, errSync := isSyncNeeded(db, "GOAT")
 	if isSyncNeeded {
 		go func() {
+			err := leagueaverages.InsertLeagueAverages(db)
+			if err != nil {
+				log.Println(err)
+			}
+
 			playerIDs := goatplayers.GetGoatPlayersList()
 			goatplayers.InsertGoatPlayerStats(playerIDs, db)
 
-			_, err := db.InsertSeasonEntered("All")
+			_, err = db.InsertSeasonEntered("All")
 			if err != nil {
 				log.Println(err)
 				return
@@ -116,7 +125,7 @@ func SyncGOAT(db database.Database) {
 				return
 			}
 
-			if errSync == sql.ErrNoRows {
+			if errors.Is(errSync, database.ErrNotFound) {
 				db.InsertLastSyncTime(time.Now(), "GOAT")
 			} else {
 				db.UpdateLastSyncTime(time.Now(), "GOAT")
@@ -152,11 +161,61 @@ func ScheduleGOATStatsUpdate(db database.Database) {
 		defer ticker.Stop()
 
 		for range ticker.C {
+			err := leagueaverages.InsertLeagueAverages(db)
+			if err != nil {
+				log.Println(err)
+			}
+
 			goatplayers.UpdateActiveGOATStats(db)
 		}
 	}()
 }
 
+// ScheduleBracketAdvance checks every minute for bracket rounds whose voting window closed
+func ScheduleBracketAdvance(db database.Database) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			err := polls.AdvanceBrackets(db, now)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}()
+}
+
+// ScheduleContestGrading grades the locked prediction contests once a day, questions get graded as their results become final
+func ScheduleContestGrading(db database.Database) {
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			err := contests.GradeContests(db, now)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}()
+}
+
+// ScheduleFraudDetection flags suspicious ballots every hour for the admins to review
+func ScheduleFraudDetection(db database.Database) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			err := fraud.DetectFraud(db, now)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}()
+}
+
 func InsertDefaultPolls(db database.Database) {
 	pollsInsert := []databasestructs.Poll{
 		{ID: 1, Name: "MVP", Description: "Description for MVP", Image: "mvp-trophy.jpg", SelectedStats: "All stats", Season: "2024", UserID: 1},
@@ -175,4 +234,7 @@ func SetupSyncSchedules(db database.Database) {
 	InsertDefaultPolls(db)
 	ScheduleNewSeasonSync(db)
 	ScheduleGOATStatsUpdate(db)
+	ScheduleBracketAdvance(db)
+	ScheduleContestGrading(db)
+	ScheduleFraudDetection(db)
 }
@@ -1,7 +1,7 @@
 package users
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +11,7 @@ import (
 	"os"
 	"sportsvoting/database"
 	"sportsvoting/databasestructs"
+	"sportsvoting/request"
 	"strconv"
 	"strings"
 	"time"
@@ -21,8 +22,9 @@ import (
 )
 
 const (
-	UserRoleAdmin string = "admin"
-	UserRoleUser  string = "user"
+	UserRoleAdmin    string = "admin"
+	UserRoleUser     string = "user"
+	UserRolePanelist string = "panelist"
 )
 
 type UsersHandler struct {
@@ -109,7 +111,7 @@ func (u UsersHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := u.createNewUser(username, password, register.Email)
+	err := u.createNewUser(r.Context(), username, password, register.Email, request.ClientIP(r))
 	if err != nil {
 		log.Println(err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -120,24 +122,24 @@ func (u UsersHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Register successful"))
 }
 
-func (u UsersHandler) createNewUser(username, password, email string) error {
-	var user databasestructs.User
-	err := u.DB.GetUserByUsername(username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.RefreshToken, &user.ProfilePic)
-	if err == sql.ErrNoRows {
+// createNewUser inserts the user with their roles in one transaction, so a failed insert doesn't leave a user without roles
+func (u UsersHandler) createNewUser(ctx context.Context, username, password, email, signupIP string) error {
+	user, err := u.DB.GetUserByUsername(username)
+	if errors.Is(err, database.ErrNotFound) {
 		hash, _ := hashPassword(password)
-		userDb := databasestructs.User{Username: username, Email: email, Password: hash}
-		res, err := u.DB.InsertNewUser(userDb)
-		if err != nil {
-			return err
-		}
+		userDb := databasestructs.User{Username: username, Email: email, Password: hash, CreatedAt: time.Now().Unix(), SignupIP: signupIP}
+		return u.DB.WithTx(ctx, func(tx database.Database) error {
+			res, err := tx.InsertNewUser(userDb)
+			if err != nil {
+				return err
+			}
 
-		userid, _ := res.LastInsertId()
+			userid, _ := res.LastInsertId()
 
-		roles := databasestructs.Role{UserID: userid, Role: UserRoleUser}
-		_, err = u.DB.InsertUserRoles(roles)
-		if err != nil {
+			roles := databasestructs.Role{UserID: userid, Role: UserRoleUser}
+			_, err = tx.InsertUserRoles(roles)
 			return err
-		}
+		})
 	} else if err != nil {
 		return err
 	} else if user.Username != "" {
@@ -155,11 +157,9 @@ func (u UsersHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var userDB databasestructs.User
 	var match bool
-	var refresh_token sql.NullString
-	err := u.DB.GetUserByUsername(user).Scan(&userDB.ID, &userDB.Username, &userDB.Email, &userDB.Password, &refresh_token, &userDB.ProfilePic)
-	if err == sql.ErrNoRows {
+	userDB, err := u.DB.GetUserByUsername(user)
+	if errors.Is(err, database.ErrNotFound) {
 		log.Println(err)
 		w.WriteHeader(http.StatusUnauthorized)
 		w.Write([]byte("invalid credentials"))
@@ -171,8 +171,6 @@ func (u UsersHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userDB.RefreshToken = refresh_token.String
-
 	match = checkPasswordHash(pwd, userDB.Password)
 
 	if !match {
@@ -198,8 +196,7 @@ func (u UsersHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var role string
-	err = u.DB.GetUserRolesByID(userDB.ID).Scan(&role)
+	role, err := u.DB.GetUserRolesByID(userDB.ID)
 	if err != nil {
 		log.Println("error getting user roles", err)
 	}
@@ -248,8 +245,7 @@ func (u UsersHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var user databasestructs.User
-	err = u.DB.GetUserByRefreshToken(refreshToken).Scan(&user.ID, &user.Username, &user.Email)
+	user, err := u.DB.GetUserByRefreshToken(refreshToken)
 	if err != nil {
 		log.Println("can't find user by refresh token", err)
 	} else if user.Username != "" {
@@ -280,9 +276,8 @@ func (u UsersHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var user databasestructs.User
-	err = u.DB.GetUserByRefreshToken(refreshToken).Scan(&user.ID, &user.Username, &user.Email)
-	if err == sql.ErrNoRows {
+	user, err := u.DB.GetUserByRefreshToken(refreshToken)
+	if errors.Is(err, database.ErrNotFound) {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	} else if err != nil {
@@ -306,8 +301,7 @@ func (u UsersHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			var currentRoles string
-			err = u.DB.GetUserRolesByID(user.ID).Scan(&currentRoles)
+			currentRoles, err := u.DB.GetUserRolesByID(user.ID)
 			if err != nil {
 				log.Println(err)
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -354,9 +348,12 @@ func (u UsersHandler) HandleGetUserByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var user databasestructs.User
-	err = u.DB.GetUserByID(id).Scan(&user.Username, &user.Email, &user.ProfilePic)
+	user, err := u.DB.GetUserByID(id)
 	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -371,25 +368,12 @@ func (u UsersHandler) HandleGetUserByID(w http.ResponseWriter, r *http.Request)
 }
 
 func (u UsersHandler) HandleUserList(w http.ResponseWriter, r *http.Request) {
-	rows, err := u.DB.GetAllUsers()
+	users, err := u.DB.GetAllUsers()
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	var users []databasestructs.User
-
-	for rows.Next() {
-		var user databasestructs.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.RefreshToken, &user.ProfilePic); err != nil {
-			log.Println(err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		users = append(users, user)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(users); err != nil {
@@ -438,7 +422,7 @@ func (u UsersHandler) UpdateUserEmail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	updatedEmail := user.Email
-	err := u.DB.GetUserByUsername(user.Username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &sql.NullString{}, &user.ProfilePic)
+	_, err := u.DB.GetUserByUsername(user.Username)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -486,8 +470,7 @@ func (u UsersHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var user databasestructs.User
-	err := u.DB.GetUserByUsername(newPasswords.Username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &sql.NullString{}, &user.ProfilePic)
+	user, err := u.DB.GetUserByUsername(newPasswords.Username)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -536,22 +519,43 @@ func (u UsersHandler) UpdateAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var currentRoles string
-	err := u.DB.GetUserRolesByID(id).Scan(&currentRoles)
+	currentRoles, err := u.DB.GetUserRolesByID(id)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var newRoles string
 	// just reverse whether it is already an admin or not
-	if strings.Contains(currentRoles, "admin") {
-		newRoles = UserRoleUser
-	} else {
-		newRoles = fmt.Sprintf("%s,%s", UserRoleUser, UserRoleAdmin)
+	newRoles := toggleRole(currentRoles, UserRoleAdmin)
+	_, err = u.DB.UpdateUserRoles(newRoles, id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newRoles)
+}
+
+// UpdatePanelist makes the user part of the expert panel whose votes are reported apart from the fans, or removes them from it
+func (u UsersHandler) UpdatePanelist(w http.ResponseWriter, r *http.Request) {
+	var id int64
+	if err := json.NewDecoder(r.Body).Decode(&id); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	currentRoles, err := u.DB.GetUserRolesByID(id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
+	newRoles := toggleRole(currentRoles, UserRolePanelist)
 	_, err = u.DB.UpdateUserRoles(newRoles, id)
 	if err != nil {
 		log.Println(err)
@@ -563,6 +567,77 @@ func (u UsersHandler) UpdateAdmin(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(newRoles)
 }
 
+// UpdateProfile sets the favorite team and region the user reports about themselves, results can be broken down by them
+func (u UsersHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var user databasestructs.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err := u.DB.GetUserByID(user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	user.FavoriteTeam = strings.ToUpper(strings.TrimSpace(user.FavoriteTeam))
+	user.Region = strings.TrimSpace(user.Region)
+
+	if len(user.Region) > 64 {
+		http.Error(w, "region can't be longer than 64 characters", http.StatusBadRequest)
+		return
+	}
+
+	if user.FavoriteTeam != "" {
+		if _, err := u.DB.SelectTeamByAbbrevation(user.FavoriteTeam); err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				http.Error(w, "unknown team", http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	_, err = u.DB.UpdateUserProfile(user.ID, user.FavoriteTeam, user.Region)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// toggleRole adds the role to the comma separated roles or removes it when the user already has it, keeping the other roles
+func toggleRole(roles, role string) string {
+	newRoles := []string{}
+	found := false
+	for _, r := range strings.Split(roles, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+
+		if r == role {
+			found = true
+			continue
+		}
+		newRoles = append(newRoles, r)
+	}
+
+	if !found {
+		newRoles = append(newRoles, role)
+	}
+
+	return strings.Join(newRoles, ",")
+}
+
 func (u UsersHandler) UploadProfilePicHandler(w http.ResponseWriter, r *http.Request) {
 	r.ParseMultipartForm(10 << 20) // 10 MB limit for file size
 
@@ -577,16 +652,13 @@ func (u UsersHandler) UploadProfilePicHandler(w http.ResponseWriter, r *http.Req
 	fileName := r.MultipartForm.File["profileImage"][0].Filename
 	username := r.FormValue("username")
 
-	var user databasestructs.User
-	var profilepic sql.NullString
-	err = u.DB.GetUserByUsername(username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &sql.NullString{}, &profilepic)
+	user, err := u.DB.GetUserByUsername(username)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	user.ProfilePic = profilepic.String
 	if user.ProfilePic != "" {
 		err := os.Remove(uploadDir + user.ProfilePic)
 		if err != nil {
@@ -635,7 +707,7 @@ func (u UsersHandler) CreateUserAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := u.createNewUser(reqUser.Username, reqUser.Password, reqUser.Email)
+	err := u.createNewUser(r.Context(), reqUser.Username, reqUser.Password, reqUser.Email, "")
 	if err != nil {
 		log.Println(err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -0,0 +1,189 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sportsvoting/database"
+	"sportsvoting/database/memory_db"
+	"sportsvoting/databasestructs"
+	"sportsvoting/internal/testutil"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestRouter registers the user routes the way http.SetupHandlers does
+func newTestRouter(db database.Database) *mux.Router {
+	u := UsersHandler{DB: db}
+	post := []string{"POST"}
+	return testutil.NewRouter(
+		testutil.Route{Path: "/register", Handler: u.HandleRegister, Methods: post},
+		testutil.Route{Path: "/login", Handler: u.HandleLogin, Methods: post},
+		testutil.Route{Path: "/users/get/{id:[0-9]+}", Handler: u.HandleGetUserByID},
+		testutil.Route{Path: "/users/admin/update", Handler: u.UpdateAdmin, Methods: post},
+		testutil.Route{Path: "/users/profile/update", Handler: u.UpdateProfile, Methods: post},
+	)
+}
+
+func register(username, password, email string) *http.Request {
+	req := httptest.NewRequest("POST", "/register", bytes.NewBufferString(`{"email":"`+email+`"}`))
+	req.SetBasicAuth(username, password)
+	return req
+}
+
+func TestRegister(t *testing.T) {
+	db := memory_db.NewDB()
+	router := newTestRouter(db)
+
+	if w := testutil.Serve(t, router, register("fan", "secret", "fan@example.com")); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	user, err := db.GetUserByUsername("fan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "fan@example.com" || !checkPasswordHash("secret", user.Password) {
+		t.Errorf("user = %+v, want the email and a hash of the password", user)
+	}
+
+	roles, err := db.GetUserRolesByID(user.ID)
+	if err != nil || roles != UserRoleUser {
+		t.Errorf("roles = %q, %v, want %q", roles, err, UserRoleUser)
+	}
+
+	if w := testutil.Serve(t, router, register("fan", "other", "")); w.Code != http.StatusBadRequest {
+		t.Errorf("taken username: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	if w := testutil.Serve(t, router, httptest.NewRequest("POST", "/register", nil)); w.Code != http.StatusBadRequest {
+		t.Errorf("missing credentials: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLoginUnknownUser(t *testing.T) {
+	router := newTestRouter(memory_db.NewDB())
+
+	req := httptest.NewRequest("POST", "/login", nil)
+	req.SetBasicAuth("nobody", "secret")
+	if w := testutil.Serve(t, router, req); w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestGetUserByID(t *testing.T) {
+	db := memory_db.NewDB()
+	if _, err := db.InsertNewUser(databasestructs.User{Username: "fan", Email: "fan@example.com", Password: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(db)
+
+	w := testutil.Serve(t, router, httptest.NewRequest("GET", "/users/get/1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var user databasestructs.User
+	if err := json.NewDecoder(w.Body).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "fan" || user.Password != "" {
+		t.Errorf("user = %+v, want the public profile of fan", user)
+	}
+
+	if w := testutil.Serve(t, router, httptest.NewRequest("GET", "/users/get/99", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("missing user: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	db := memory_db.NewDB()
+	if _, err := db.InsertNewUser(databasestructs.User{Username: "fan"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(db)
+
+	for _, tc := range []struct {
+		name    string
+		profile databasestructs.User
+		want    int
+	}{
+		{"missing user", databasestructs.User{ID: 99, FavoriteTeam: "BOS"}, http.StatusNotFound},
+		{"unknown team", databasestructs.User{ID: 1, FavoriteTeam: "XYZ"}, http.StatusBadRequest},
+		{"valid", databasestructs.User{ID: 1, FavoriteTeam: " bos ", Region: "New England"}, http.StatusOK},
+	} {
+		if w := testutil.Serve(t, router, testutil.JSONRequest(t, "POST", "/users/profile/update", tc.profile)); w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d: %s", tc.name, w.Code, tc.want, w.Body)
+		}
+	}
+
+	user, err := db.GetUserByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.FavoriteTeam != "BOS" || user.Region != "New England" {
+		t.Errorf("profile = %q, %q, want BOS, New England", user.FavoriteTeam, user.Region)
+	}
+}
+
+func TestUpdateAdminTogglesRole(t *testing.T) {
+	db := memory_db.NewDB()
+	if _, err := db.InsertNewUser(databasestructs.User{Username: "fan"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.InsertUserRoles(databasestructs.Role{UserID: 1, Role: "user,panelist"}); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(db)
+
+	for _, want := range []string{"user,panelist,admin", "user,panelist"} {
+		if w := testutil.Serve(t, router, testutil.JSONRequest(t, "POST", "/users/admin/update", 1)); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+
+		roles, err := db.GetUserRolesByID(1)
+		if err != nil || roles != want {
+			t.Errorf("roles = %q, %v, want %q", roles, err, want)
+		}
+	}
+}
+
+// failingRoles fails to insert the roles of a user, inside the transaction as well
+type failingRoles struct {
+	database.Database
+}
+
+func (f failingRoles) WithTx(ctx context.Context, fn func(tx database.Database) error) error {
+	return f.Database.WithTx(ctx, func(tx database.Database) error {
+		return fn(failingRoles{Database: tx})
+	})
+}
+
+func (f failingRoles) InsertUserRoles(role databasestructs.Role) (sql.Result, error) {
+	return nil, errors.New("write failed")
+}
+
+func TestRegisterKeepsNoUserWithoutRoles(t *testing.T) {
+	db := memory_db.NewDB()
+
+	if w := testutil.Serve(t, newTestRouter(failingRoles{Database: db}), register("fan", "secret", "")); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	if _, err := db.GetUserByUsername("fan"); !errors.Is(err, database.ErrNotFound) {
+		t.Errorf("user without roles: err = %v, want %v", err, database.ErrNotFound)
+	}
+
+	// the username isn't taken by the failed attempt
+	if w := testutil.Serve(t, newTestRouter(db), register("fan", "secret", "")); w.Code != http.StatusOK {
+		t.Errorf("second attempt: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
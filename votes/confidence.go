@@ -0,0 +1,113 @@
+package votes
+
+import (
+	"math"
+	"sort"
+)
+
+// z score of a 95% confidence level
+const confidenceZ = 1.96
+
+// Confidence is the vote share of a candidate with its 95% confidence interval, in percent like the share
+type Confidence struct {
+	Share         float64 `json:"share"`
+	MarginOfError float64 `json:"margin_of_error"`
+	ShareLow      float64 `json:"share_low"`
+	ShareHigh     float64 `json:"share_high"`
+	TiedForLead   bool    `json:"statistically_tied"`
+}
+
+// addConfidence orders the candidates by their votes and sets the confidence of every one of them
+func addConfidence(results []Votes) {
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Value > results[j].Value })
+
+	counts := make([]int64, len(results))
+	for i, result := range results {
+		counts[i] = result.Value
+	}
+
+	for i, confidence := range confidenceOf(counts) {
+		results[i].Confidence = confidence
+	}
+}
+
+// addTallyConfidence sets the confidence of every tally out of the ballots of all of them
+func addTallyConfidence(tallies []PanelTally) {
+	counts := make([]int64, len(tallies))
+	for i, tally := range tallies {
+		counts[i] = tally.Votes
+	}
+
+	for i, confidence := range confidenceOf(counts) {
+		tallies[i].Confidence = confidence
+	}
+}
+
+// confidenceOf works out the share of every count with its interval and marks the counts whose difference
+// to the leader is within the noise of the poll, the leader too when anyone is tied with them
+func confidenceOf(counts []int64) []Confidence {
+	results := make([]Confidence, len(counts))
+
+	var ballots int64
+	lead := 0
+	for i, count := range counts {
+		ballots += count
+		if count > counts[lead] {
+			lead = i
+		}
+	}
+
+	if ballots == 0 {
+		return results
+	}
+
+	n := float64(ballots)
+	for i, count := range counts {
+		p := float64(count) / n
+		low, high := wilsonInterval(p, n)
+
+		results[i] = Confidence{
+			Share:         roundShare(p),
+			MarginOfError: roundShare(confidenceZ * math.Sqrt(p*(1-p)/n)),
+			ShareLow:      roundShare(low),
+			ShareHigh:     roundShare(high),
+		}
+	}
+
+	leader := float64(counts[lead]) / n
+	for i, count := range counts {
+		if i != lead && tiedWith(leader, float64(count)/n, n) {
+			results[lead].TiedForLead = true
+			results[i].TiedForLead = true
+		}
+	}
+
+	return results
+}
+
+// wilsonInterval is the score interval of a share, it stays within 0 and 1 and holds up better than the normal
+// approximation for the small polls and lopsided shares this is meant for
+func wilsonInterval(p, n float64) (float64, float64) {
+	z2 := confidenceZ * confidenceZ
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	spread := confidenceZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+
+	return math.Max(0, center-spread), math.Min(1, center+spread)
+}
+
+// tiedWith tests the difference of two shares of the same ballots, which are negatively correlated, so their
+// intervals overlapping isn't the right test
+func tiedWith(p1, p2, n float64) bool {
+	diff := p1 - p2
+	variance := (p1 + p2 - diff*diff) / n
+	if variance <= 0 {
+		return diff == 0
+	}
+
+	return diff < confidenceZ*math.Sqrt(variance)
+}
+
+// roundShare turns a share into a percentage with one decimal
+func roundShare(share float64) float64 {
+	return math.Round(share*1000) / 10
+}
@@ -0,0 +1,333 @@
+package votes
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/request"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// guest ballots one address can cast in a poll, high enough for a shared office or campus network
+	maxGuestVotesPerIP = 25
+	rateLimitWindow    = time.Hour
+	ipRateLimit        = 60
+	deviceRateLimit    = 10
+	tokenRateLimit     = 20
+)
+
+var (
+	guestSecret     []byte
+	guestSecretOnce sync.Once
+
+	ipLimiter     = newRateLimiter(ipRateLimit, rateLimitWindow)
+	deviceLimiter = newRateLimiter(deviceRateLimit, rateLimitWindow)
+	tokenLimiter  = newRateLimiter(tokenRateLimit, rateLimitWindow)
+)
+
+type GuestVotePayload struct {
+	Token       string `json:"token"`
+	PollID      int64  `json:"pollid"`
+	PlayerID    string `json:"playerid"`
+	CandidateID int64  `json:"candidateid"`
+}
+
+type GuestTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type GuestTally struct {
+	Name       string `json:"name"`
+	Registered int64  `json:"registered"`
+	Guests     int64  `json:"guests"`
+	Total      int64  `json:"total"`
+	Confidence
+}
+
+type GuestResultsResponse struct {
+	PollID          int64        `json:"pollid"`
+	RegisteredVotes int64        `json:"registered_votes"`
+	GuestVotes      int64        `json:"guest_votes"`
+	Results         []GuestTally `json:"results"`
+}
+
+// IssueGuestToken gives the device a signed id it sends with its guest ballots, so one device gets one ballot per poll
+func (v VotesHandler) IssueGuestToken(w http.ResponseWriter, r *http.Request) {
+	if !tokenLimiter.allow(request.ClientIP(r)) {
+		http.Error(w, "too many requests, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	deviceID := hex.EncodeToString(id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GuestTokenResponse{Token: deviceID + "." + signDevice(deviceID)})
+}
+
+// InsertGuestVote stores the ballot of a device without an account in a poll that accepts guests
+func (v VotesHandler) InsertGuestVote(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1024*1024)
+	var payload GuestVotePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deviceID, ok := verifyGuestToken(payload.Token)
+	if !ok {
+		http.Error(w, "invalid guest token", http.StatusUnauthorized)
+		return
+	}
+
+	ip := request.ClientIP(r)
+	if !ipLimiter.allow(ip) || !deviceLimiter.allow(deviceID) {
+		http.Error(w, "too many votes, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	poll, ok := v.getVisiblePoll(w, payload.PollID, 0)
+	if !ok {
+		return
+	}
+
+	if !poll.AllowGuests {
+		http.Error(w, "poll doesn't accept guest votes", http.StatusForbidden)
+		return
+	}
+
+	if (payload.PlayerID == "") == (payload.CandidateID == 0) {
+		http.Error(w, "vote for either a player or a candidate", http.StatusBadRequest)
+		return
+	}
+
+	if poll.SelectedStats == "Custom" && payload.CandidateID == 0 {
+		http.Error(w, "custom polls need a candidate id", http.StatusBadRequest)
+		return
+	}
+
+	if payload.CandidateID != 0 {
+		candidate, err := v.DB.GetPollCandidateByID(payload.CandidateID)
+		if err != nil && !errors.Is(err, database.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err != nil || candidate.PollID != payload.PollID || candidate.Status != "approved" {
+			http.Error(w, "candidate can't be voted for in this poll", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if payload.PlayerID != "" && !v.checkPoolPlayer(r.Context(), w, poll, payload.PlayerID) {
+		return
+	}
+
+	// changing the vote of a device doesn't count against the address
+	voted, err := v.DB.GetGuestDeviceVote(payload.PollID, deviceID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if voted == 0 {
+		fromIP, err := v.DB.CountGuestVotesByIP(payload.PollID, ip)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if fromIP >= maxGuestVotesPerIP {
+			http.Error(w, "too many guest votes from this network", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	vote := databasestructs.GuestVote{PollID: payload.PollID, DeviceID: deviceID, IP: ip, PlayerID: payload.PlayerID, CandidateID: payload.CandidateID, CreatedAt: time.Now().Unix()}
+	_, err = v.DB.InsertGuestVote(vote)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Guest vote counted in poll %d", payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// GuestVotes reports the votes of registered users and guests apart for every candidate
+func (v VotesHandler) GuestVotes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if _, ok := v.getVisiblePoll(w, id, userID); !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	tallies := make(map[string]*GuestTally)
+	response := GuestResultsResponse{PollID: id, Results: []GuestTally{}}
+
+	registered, err := v.DB.GetPlayerPollVotes(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, votes := range registered {
+		getTally(tallies, votes.Name).Registered += votes.Votes
+		response.RegisteredVotes += votes.Votes
+	}
+
+	// guest votes for players that aren't in the database anymore come without a name and are left out
+	guests, err := v.DB.GetGuestPollVotes(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, votes := range guests {
+		getTally(tallies, votes.Name).Guests += votes.Votes
+		response.GuestVotes += votes.Votes
+	}
+
+	for _, tally := range tallies {
+		tally.Total = tally.Registered + tally.Guests
+		response.Results = append(response.Results, *tally)
+	}
+
+	sort.Slice(response.Results, func(i, j int) bool {
+		if response.Results[i].Total != response.Results[j].Total {
+			return response.Results[i].Total > response.Results[j].Total
+		}
+		return response.Results[i].Name < response.Results[j].Name
+	})
+
+	// the interval is of the share of all the ballots, registered and guest
+	totals := make([]int64, len(response.Results))
+	for i, result := range response.Results {
+		totals[i] = result.Total
+	}
+	for i, confidence := range confidenceOf(totals) {
+		response.Results[i].Confidence = confidence
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getTally(tallies map[string]*GuestTally, name string) *GuestTally {
+	tally, ok := tallies[name]
+	if !ok {
+		tally = &GuestTally{Name: name}
+		tallies[name] = tally
+	}
+
+	return tally
+}
+
+// getGuestSecret reads the key guest tokens are signed with, a random key is used when it isn't set so tokens only last until a restart
+func getGuestSecret() []byte {
+	guestSecretOnce.Do(func() {
+		if secret := strings.TrimSpace(os.Getenv("GUEST_TOKEN_SECRET")); secret != "" {
+			guestSecret = []byte(secret)
+			return
+		}
+
+		log.Println("GUEST_TOKEN_SECRET isn't set, guest tokens won't survive a restart")
+		guestSecret = make([]byte, 32)
+		if _, err := rand.Read(guestSecret); err != nil {
+			log.Fatal(err)
+		}
+	})
+
+	return guestSecret
+}
+
+func signDevice(deviceID string) string {
+	mac := hmac.New(sha256.New, getGuestSecret())
+	mac.Write([]byte(deviceID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func verifyGuestToken(token string) (string, bool) {
+	deviceID, signature, found := strings.Cut(token, ".")
+	if !found || deviceID == "" {
+		return "", false
+	}
+
+	return deviceID, hmac.Equal([]byte(signature), []byte(signDevice(deviceID)))
+}
+
+// rateLimiter allows a number of events per key in a sliding window
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	events map[string][]time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, events: make(map[string][]time.Time)}
+}
+
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	recent := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= l.limit {
+		l.events[key] = recent
+		return false
+	}
+
+	l.events[key] = append(recent, now)
+
+	// forget quiet keys so the map doesn't grow forever
+	if len(l.events) > 10000 {
+		for k, times := range l.events {
+			if len(times) == 0 || now.Sub(times[len(times)-1]) >= l.window {
+				delete(l.events, k)
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,174 @@
+package votes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sportsvoting/database"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultMomentumDays = 7
+	maxMomentumDays     = 90
+)
+
+type RetractPayload struct {
+	PollID int64 `json:"pollid"`
+	UserID int64 `json:"userid"`
+}
+
+type MomentumChange struct {
+	Name   string `json:"name"`
+	Gained int64  `json:"gained"`
+	Lost   int64  `json:"lost"`
+	Net    int64  `json:"net"`
+}
+
+type MomentumDay struct {
+	Day     string           `json:"day"`
+	Changes []MomentumChange `json:"changes"`
+}
+
+type MomentumResponse struct {
+	PollID int64         `json:"pollid"`
+	Days   []MomentumDay `json:"days"`
+}
+
+// RetractVote takes back the user's vote in a poll, the vote history keeps a record of it
+func (v VotesHandler) RetractVote(w http.ResponseWriter, r *http.Request) {
+	var payload RetractPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := v.getVisiblePoll(w, payload.PollID, payload.UserID); !ok {
+		return
+	}
+
+	_, err := v.DB.RetractPlayerVote(payload.PollID, payload.UserID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "no vote to retract in this poll", http.StatusNotFound)
+			return
+		}
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Vote retracted in poll %d", payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// GetUserVoteHistory lists every vote the user cast, changed or lost, newest first,
+// only in the polls the viewer in the userid query parameter can see
+func (v VotesHandler) GetUserVoteHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["userid"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	pollID, _ := strconv.ParseInt(r.URL.Query().Get("pollid"), 10, 64)
+	viewerID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	events, err := v.DB.GetUserVoteEvents(ctx, userID, viewerID, pollID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(events)
+}
+
+// PollMomentum shows per day how many votes every candidate gained and lost, from new votes, changed votes, retractions and resets
+func (v VotesHandler) PollMomentum(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if _, ok := v.getVisiblePoll(w, id, userID); !ok {
+		return
+	}
+
+	days := defaultMomentumDays
+	if d := r.URL.Query().Get("days"); d != "" {
+		days, err = strconv.Atoi(d)
+		if err != nil || days < 1 || days > maxMomentumDays {
+			http.Error(w, fmt.Sprintf("days has to be between 1 and %d", maxMomentumDays), http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	events, err := v.DB.GetPollVoteEvents(ctx, id, since.Unix())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	changes := make(map[string]map[string]*MomentumChange)
+	change := func(day, name string) *MomentumChange {
+		if changes[day] == nil {
+			changes[day] = make(map[string]*MomentumChange)
+		}
+		if changes[day][name] == nil {
+			changes[day][name] = &MomentumChange{Name: name}
+		}
+		return changes[day][name]
+	}
+
+	for _, event := range events {
+		day := time.Unix(event.CreatedAt, 0).UTC().Format("2006-01-02")
+		if event.ChoiceName != "" {
+			change(day, event.ChoiceName).Gained++
+		}
+		if event.PreviousName != "" {
+			change(day, event.PreviousName).Lost++
+		}
+	}
+
+	response := MomentumResponse{PollID: id, Days: []MomentumDay{}}
+	for d := since; !d.After(now); d = d.AddDate(0, 0, 1) {
+		day := MomentumDay{Day: d.Format("2006-01-02"), Changes: []MomentumChange{}}
+		for _, c := range changes[day.Day] {
+			c.Net = c.Gained - c.Lost
+			day.Changes = append(day.Changes, *c)
+		}
+
+		sort.Slice(day.Changes, func(i, j int) bool {
+			if day.Changes[i].Net != day.Changes[j].Net {
+				return day.Changes[i].Net > day.Changes[j].Net
+			}
+			return day.Changes[i].Name < day.Changes[j].Name
+		})
+		response.Days = append(response.Days, day)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
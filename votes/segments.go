@@ -0,0 +1,159 @@
+package votes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// segments with fewer ballots than this are merged into "other", so a breakdown can't single out voters
+	minSegmentBallots = 10
+
+	otherSegment       = "other"
+	unspecifiedSegment = "unspecified"
+)
+
+var segments = map[string]bool{
+	"team":        true,
+	"region":      true,
+	"account_age": true,
+	"panel":       true,
+}
+
+type SegmentResult struct {
+	Segment string       `json:"segment"`
+	Ballots int64        `json:"ballots"`
+	Results []PanelTally `json:"results"`
+}
+
+type SegmentResultsResponse struct {
+	PollID     int64           `json:"pollid"`
+	By         string          `json:"by"`
+	MinBallots int64           `json:"min_ballots"`
+	Segments   []SegmentResult `json:"segments"`
+}
+
+// SegmentVotes breaks the results of the poll down by favorite team, region, account age or panelists and fans
+func (v VotesHandler) SegmentVotes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if _, ok := v.getVisiblePoll(w, id, userID); !ok {
+		return
+	}
+
+	by := r.URL.Query().Get("by")
+	if !segments[by] {
+		http.Error(w, "by has to be team, region, account_age or panel", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	tallies, err := v.DB.GetPollVotesBySegment(ctx, id, by)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bySegment := make(map[string]map[string]int64)
+	for _, tally := range tallies {
+		segment := tally.Segment
+		if segment == "" {
+			segment = unspecifiedSegment
+		}
+		if bySegment[segment] == nil {
+			bySegment[segment] = make(map[string]int64)
+		}
+		bySegment[segment][tally.Name] += tally.Votes
+	}
+
+	response := SegmentResultsResponse{PollID: id, By: by, MinBallots: minSegmentBallots, Segments: []SegmentResult{}}
+	response.Segments = append(response.Segments, protectSegments(bySegment)...)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
+
+// protectSegments merges the segments that are too small into "other". The overall results are public, so an "other" that's
+// still too small could be worked out from them and the published segments, the smallest segment is merged into it as well then
+func protectSegments(bySegment map[string]map[string]int64) []SegmentResult {
+	other := make(map[string]int64)
+	var results []SegmentResult
+	for segment, tallies := range bySegment {
+		var ballots int64
+		for _, votes := range tallies {
+			ballots += votes
+		}
+
+		if ballots < minSegmentBallots || segment == otherSegment {
+			for name, votes := range tallies {
+				other[name] += votes
+			}
+			continue
+		}
+		results = append(results, segmentResult(segment, tallies))
+	}
+
+	sortSegments(results)
+
+	if result := segmentResult(otherSegment, other); result.Ballots > 0 && result.Ballots < minSegmentBallots {
+		// every published segment is big enough on its own, so merging one makes "other" big enough too,
+		// with none published there's nothing to merge and nothing to work it out from
+		if len(results) == 0 {
+			return nil
+		}
+
+		smallest := results[len(results)-1]
+		results = results[:len(results)-1]
+		for _, tally := range smallest.Results {
+			other[tally.Name] += tally.Votes
+		}
+	}
+
+	if result := segmentResult(otherSegment, other); result.Ballots > 0 {
+		results = append(results, result)
+	}
+	sortSegments(results)
+
+	return results
+}
+
+func sortSegments(results []SegmentResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Ballots != results[j].Ballots {
+			return results[i].Ballots > results[j].Ballots
+		}
+		return results[i].Segment < results[j].Segment
+	})
+}
+
+func segmentResult(segment string, tallies map[string]int64) SegmentResult {
+	result := SegmentResult{Segment: segment, Results: []PanelTally{}}
+	for name, votes := range tallies {
+		result.Results = append(result.Results, PanelTally{Name: name, Votes: votes})
+		result.Ballots += votes
+	}
+
+	sort.Slice(result.Results, func(i, j int) bool {
+		if result.Results[i].Votes != result.Results[j].Votes {
+			return result.Results[i].Votes > result.Results[j].Votes
+		}
+		return result.Results[i].Name < result.Results[j].Name
+	})
+	addTallyConfidence(result.Results)
+
+	return result
+}
@@ -0,0 +1,181 @@
+package votes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// hourly series of polls that ran for months would get huge, those have to be asked for per day
+const maxTimelinePoints = 2000
+
+var timelineIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+}
+
+type TimelineShare struct {
+	Name  string  `json:"name"`
+	Votes int64   `json:"votes"`
+	Share float64 `json:"share"`
+}
+
+type TimelinePoint struct {
+	Time   int64           `json:"time"`
+	Total  int64           `json:"total"`
+	Shares []TimelineShare `json:"shares"`
+}
+
+type TimelineResponse struct {
+	PollID   int64           `json:"pollid"`
+	Interval string          `json:"interval"`
+	Points   []TimelinePoint `json:"points"`
+}
+
+// PollTimeline shows the vote share of every candidate at the end of each hour or day of the poll, replayed from the vote history
+// so changed and retracted votes count where they were at the time
+func (v VotesHandler) PollTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if _, ok := v.getVisiblePoll(w, id, userID); !ok {
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "day"
+	}
+
+	bucket, ok := timelineIntervals[interval]
+	if !ok {
+		http.Error(w, "interval has to be hour or day", http.StatusBadRequest)
+		return
+	}
+	bucketSeconds := int64(bucket.Seconds())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	timeline, err := v.DB.GetPollVoteTimeline(ctx, id, bucketSeconds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	current, err := v.DB.GetPlayerPollVotes(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	counts := map[int64]map[string]int64{0: {}}
+	var buckets []int64
+	for _, bucket := range timeline {
+		if counts[bucket.Start] == nil {
+			counts[bucket.Start] = make(map[string]int64)
+			buckets = append(buckets, bucket.Start)
+		}
+		counts[bucket.Start][bucket.Name] += bucket.Votes
+	}
+
+	// votes cast before the history was kept have no events, whatever the history doesn't explain of the results counts from the start
+	replayed := make(map[string]int64)
+	for _, bucket := range timeline {
+		replayed[bucket.Name] += bucket.Votes
+	}
+	for _, tally := range current {
+		replayed[tally.Name] -= tally.Votes
+	}
+	for name, votes := range replayed {
+		if votes != 0 {
+			counts[0][name] = -votes
+		}
+	}
+	if len(counts[0]) > 0 {
+		buckets = append([]int64{0}, buckets...)
+	}
+
+	response := TimelineResponse{PollID: id, Interval: interval, Points: []TimelinePoint{}}
+	if len(buckets) == 0 {
+		writeTimeline(w, response)
+		return
+	}
+
+	tally := make(map[string]int64)
+	var total int64
+	for name, votes := range counts[0] {
+		tally[name] += votes
+		total += votes
+	}
+
+	var first, last int64
+	for _, start := range buckets {
+		if start == 0 {
+			continue
+		}
+		if first == 0 {
+			first = start
+		}
+		last = start
+	}
+
+	if first == 0 {
+		// no timestamps at all, the whole poll is one point
+		response.Points = append(response.Points, timelinePoint(0, tally, total))
+		writeTimeline(w, response)
+		return
+	}
+
+	if (last-first)/bucketSeconds+1 > maxTimelinePoints {
+		http.Error(w, fmt.Sprintf("poll runs over more than %d %ss, use a longer interval", maxTimelinePoints, interval), http.StatusBadRequest)
+		return
+	}
+
+	for start := first; start <= last; start += bucketSeconds {
+		for name, votes := range counts[start] {
+			tally[name] += votes
+			total += votes
+		}
+		response.Points = append(response.Points, timelinePoint(start+bucketSeconds, tally, total))
+	}
+
+	writeTimeline(w, response)
+}
+
+// timelinePoint lists the candidates that have votes at the time, candidates that lost all of theirs drop out
+func timelinePoint(at int64, tally map[string]int64, total int64) TimelinePoint {
+	point := TimelinePoint{Time: at, Total: total, Shares: []TimelineShare{}}
+	for name, votes := range tally {
+		if votes <= 0 {
+			continue
+		}
+		point.Shares = append(point.Shares, TimelineShare{Name: name, Votes: votes, Share: math.Round(float64(votes)/float64(total)*1000) / 10})
+	}
+
+	sort.Slice(point.Shares, func(i, j int) bool {
+		if point.Shares[i].Votes != point.Shares[j].Votes {
+			return point.Shares[i].Votes > point.Shares[j].Votes
+		}
+		return point.Shares[i].Name < point.Shares[j].Name
+	})
+
+	return point
+}
+
+func writeTimeline(w http.ResponseWriter, response TimelineResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(response)
+}
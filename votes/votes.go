@@ -3,10 +3,17 @@ package votes
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"sort"
 	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/groups"
+	"sportsvoting/polls"
+	"sportsvoting/request"
 	"strconv"
 	"time"
 
@@ -17,6 +24,7 @@ type Votes struct {
 	Name     string `json:"name"`
 	Value    int64  `json:"value"`
 	Pollname string `json:"pollname"`
+	Confidence
 }
 
 type MyVotesResponse struct {
@@ -28,15 +36,40 @@ type MyVotesResponse struct {
 }
 
 type VotePayload struct {
-	PlayerID string `json:"playerid"`
-	PollID   int64  `json:"pollid"`
-	UserID   int64  `json:"userid"`
+	PlayerID    string `json:"playerid"`
+	CandidateID int64  `json:"candidateid"`
+	PollID      int64  `json:"pollid"`
+	UserID      int64  `json:"userid"`
+}
+
+type PanelTally struct {
+	Name  string `json:"name"`
+	Votes int64  `json:"votes"`
+	Confidence
+}
+
+type BlendedResult struct {
+	Name       string  `json:"name"`
+	PanelShare float64 `json:"panel_share"`
+	FanShare   float64 `json:"fan_share"`
+	Score      float64 `json:"score"`
+}
+
+type PanelResultsResponse struct {
+	PollID       int64           `json:"pollid"`
+	PanelWeight  *float64        `json:"panel_weight,omitempty"`
+	PanelBallots int64           `json:"panel_ballots"`
+	FanBallots   int64           `json:"fan_ballots"`
+	Panel        []PanelTally    `json:"panel"`
+	Fans         []PanelTally    `json:"fans"`
+	Blended      []BlendedResult `json:"blended,omitempty"`
 }
 
 type VotesHandler struct {
 	DB database.Database
 }
 
+// GetUserVotes lists the votes of the user, only in the polls the viewer in the userid query parameter can see
 func (v VotesHandler) GetUserVotes(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := strconv.Atoi(vars["userid"])
@@ -46,27 +79,27 @@ func (v VotesHandler) GetUserVotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	rows, err := v.DB.GetVotesOfUser(ctx, int64(userID))
+	userVotes, err := v.DB.GetVotesOfUser(ctx, int64(userID), viewerID)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var allVotes []MyVotesResponse
-	for rows.Next() {
-		var votes MyVotesResponse
-		err = rows.Scan(&votes.PollID, &votes.PlayerID, &votes.PlayerName, &votes.PollName, &votes.PollImage)
-		if err != nil {
-			log.Println(err.Error())
-			continue
-		}
-
-		allVotes = append(allVotes, votes)
+	allVotes := make([]MyVotesResponse, 0, len(userVotes))
+	for _, vote := range userVotes {
+		allVotes = append(allVotes, MyVotesResponse{
+			PollID:     strconv.FormatInt(vote.PollID, 10),
+			PlayerID:   vote.PlayerID,
+			PlayerName: vote.PlayerName,
+			PollName:   vote.PollName,
+			PollImage:  vote.PollImage,
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -83,38 +116,124 @@ func (v VotesHandler) PlayerVotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// results of group polls are only shown to the members
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	if _, ok := v.getVisiblePoll(w, id, userID); !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	rows, err := v.DB.GetPlayerPollVotes(ctx, id)
+	tallies, err := v.DB.GetPlayerPollVotes(ctx, id)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var playerList []Votes
-	for rows.Next() {
-		var votes Votes
-		err := rows.Scan(&votes.Name, &votes.Value, &votes.Pollname)
-		if err != nil {
-			log.Println(err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	playerList := make([]Votes, 0, len(tallies))
+	for _, tally := range tallies {
+		playerList = append(playerList, Votes{Name: tally.Name, Value: tally.Votes, Pollname: tally.PollName})
+	}
+
+	addConfidence(playerList)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	json.NewEncoder(w).Encode(playerList)
+}
+
+// PanelVotes reports the votes of the panelists and the fans apart, blending them when the poll or the request sets a panel weight
+func (v VotesHandler) PanelVotes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("userid"), 10, 64)
+	poll, ok := v.getVisiblePoll(w, id, userID)
+	if !ok {
+		return
+	}
+
+	weight := poll.PanelWeight
+	if value := r.URL.Query().Get("weight"); value != "" {
+		override, err := strconv.ParseFloat(value, 64)
+		if err != nil || override < 0 || override > 1 {
+			http.Error(w, "weight has to be between 0 and 1", http.StatusBadRequest)
 			return
 		}
-		playerList = append(playerList, votes)
+		weight = &override
 	}
 
-	if err := rows.Err(); err != nil {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	tallies, err := v.DB.GetPanelPollVotes(ctx, id)
+	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	response := PanelResultsResponse{PollID: id, PanelWeight: weight, Panel: []PanelTally{}, Fans: []PanelTally{}}
+	for _, t := range tallies {
+		tally := PanelTally{Name: t.Name, Votes: t.Votes}
+		if t.IsPanelist {
+			response.Panel = append(response.Panel, tally)
+			response.PanelBallots += tally.Votes
+		} else {
+			response.Fans = append(response.Fans, tally)
+			response.FanBallots += tally.Votes
+		}
+	}
+
+	addTallyConfidence(response.Panel)
+	addTallyConfidence(response.Fans)
+	if weight != nil {
+		response.Blended = blendResults(response.Panel, response.Fans, *weight)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Content-Security-Policy", "default-src 'self'")
-	json.NewEncoder(w).Encode(playerList)
+	json.NewEncoder(w).Encode(response)
+}
+
+// blendResults scores every candidate as the weighted average of their panel and fan vote shares
+func blendResults(panel, fans []PanelTally, weight float64) []BlendedResult {
+	byName := make(map[string]*BlendedResult)
+	var order []string
+	get := func(name string) *BlendedResult {
+		if result, ok := byName[name]; ok {
+			return result
+		}
+		byName[name] = &BlendedResult{Name: name}
+		order = append(order, name)
+		return byName[name]
+	}
+
+	for _, tally := range panel {
+		get(tally.Name).PanelShare = tally.Share
+	}
+
+	for _, tally := range fans {
+		get(tally.Name).FanShare = tally.Share
+	}
+
+	results := make([]BlendedResult, 0, len(order))
+	for _, name := range order {
+		result := byName[name]
+		result.Score = math.Round((weight*result.PanelShare+(1-weight)*result.FanShare)*10) / 10
+		results = append(results, *result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
 }
 
 func (v VotesHandler) TeamVotes(w http.ResponseWriter, r *http.Request) {}
@@ -129,7 +248,27 @@ func (v VotesHandler) InsertPlayerVotes(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	_, err = v.DB.InsertPlayerVotes(payload.PollID, payload.UserID, payload.PlayerID)
+	poll, ok := v.getVisiblePoll(w, payload.PollID, payload.UserID)
+	if !ok {
+		return
+	}
+
+	if payload.CandidateID != 0 {
+		v.insertCandidateVote(w, payload, request.ClientIP(r))
+		return
+	}
+
+	// custom polls only have the candidates picked by their creator
+	if poll.SelectedStats == "Custom" {
+		http.Error(w, "custom polls need a candidate id", http.StatusBadRequest)
+		return
+	}
+
+	if !v.checkPoolPlayer(r.Context(), w, poll, payload.PlayerID) {
+		return
+	}
+
+	_, err = v.DB.InsertPlayerVotes(payload.PollID, payload.UserID, payload.PlayerID, request.ClientIP(r))
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -140,3 +279,75 @@ func (v VotesHandler) InsertPlayerVotes(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "text/plain")
 	w.Write([]byte(response))
 }
+
+// insertCandidateVote votes for a curated candidate or write-in, which has to be approved and belong to the poll
+func (v VotesHandler) insertCandidateVote(w http.ResponseWriter, payload VotePayload, ip string) {
+	candidate, err := v.DB.GetPollCandidateByID(payload.CandidateID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "candidate not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if candidate.PollID != payload.PollID || candidate.Status != "approved" {
+		http.Error(w, "candidate can't be voted for in this poll", http.StatusBadRequest)
+		return
+	}
+
+	_, err = v.DB.InsertCandidateVote(payload.PollID, payload.UserID, candidate.ID, ip)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := fmt.Sprintf("Voted for %s in poll %d", candidate.Name, payload.PollID)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(response))
+}
+
+// checkPoolPlayer writes a bad request error for players outside the candidate pool of the poll
+func (v VotesHandler) checkPoolPlayer(ctx context.Context, w http.ResponseWriter, poll databasestructs.Poll, playerID string) bool {
+	ok, err := polls.IsPoolPlayer(ctx, v.DB, poll, playerID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if !ok {
+		http.Error(w, "player can't be voted for in this poll", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// getVisiblePoll loads the poll, writing a not found error for group polls the user isn't a member of
+func (v VotesHandler) getVisiblePoll(w http.ResponseWriter, pollID, userID int64) (databasestructs.Poll, bool) {
+	poll, err := v.DB.GetPollByID(pollID)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "poll not found", http.StatusNotFound)
+			return poll, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return poll, false
+	}
+
+	allowed, err := groups.CanView(v.DB, poll.GroupID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return poll, false
+	}
+
+	if !allowed {
+		http.Error(w, "poll not found", http.StatusNotFound)
+		return poll, false
+	}
+
+	return poll, true
+}
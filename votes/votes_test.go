@@ -0,0 +1,354 @@
+package votes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sportsvoting/database"
+	"sportsvoting/databasestructs"
+	"sportsvoting/internal/testutil"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	playerPollID int64 = 1
+	groupPollID  int64 = 2
+)
+
+// newTestDB returns the users and group of testutil.NewDB with a public poll on the 2024 season of two players,
+// a bench player who doesn't qualify for it, and a custom poll of the group with two candidates
+func newTestDB(t *testing.T) database.Database {
+	t.Helper()
+	db := testutil.NewDB(t)
+	exec := testutil.Must(t)
+
+	exec(db.InsertTeam(databasestructs.TeamInfo{TeamAbbr: "BOS", Name: "Boston"}))
+	exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "p1", Name: "Player One", TeamAbbr: "BOS"}))
+	exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "p2", Name: "Player Two", TeamAbbr: "BOS"}))
+	exec(db.InsertPlayer(databasestructs.PlayerInfo{ID: "p3", Name: "Bench Player", TeamAbbr: "BOS"}))
+	for id, minutes := range map[string]float64{"p1": 36, "p2": 30, "p3": 12} {
+		exec(db.InsertStats(databasestructs.PlayerStats{PlayerID: id, Season: "2024", TeamAbbr: "BOS", Games: 70, Minutes: minutes}))
+		exec(db.InsertAdvancedStats(databasestructs.AdvancedStats{PlayerID: id, Season: "2024", TeamAbbr: "BOS"}))
+	}
+
+	exec(db.InsertPolls(databasestructs.Poll{Name: "mvp", SelectedStats: "All stats", Season: "2024", UserID: testutil.OwnerID, AllowGuests: true}))
+	exec(db.InsertPolls(databasestructs.Poll{Name: "private", SelectedStats: "Custom", UserID: testutil.OwnerID, GroupID: testutil.GroupID}))
+	exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: groupPollID, Name: "Echo", Status: "approved"}, "echo"))
+	exec(db.InsertPollCandidate(databasestructs.PollCandidate{PollID: groupPollID, Name: "Foxtrot", Status: "approved"}, "foxtrot"))
+
+	return db
+}
+
+// newTestRouter registers the vote routes the way http.SetupHandlers does
+func newTestRouter(db database.Database) *mux.Router {
+	v := VotesHandler{DB: db}
+	get, post := []string{"GET"}, []string{"POST"}
+	return testutil.NewRouter(
+		testutil.Route{Path: "/votes/users/get/{userid}", Handler: v.GetUserVotes},
+		testutil.Route{Path: "/votes/users/{userid:[0-9]+}/history", Handler: v.GetUserVoteHistory, Methods: get},
+		testutil.Route{Path: "/votes/players/{id:[0-9]+}", Handler: v.PlayerVotes, Methods: get},
+		testutil.Route{Path: "/votes/players/{id:[0-9]+}/panel", Handler: v.PanelVotes, Methods: get},
+		testutil.Route{Path: "/votes/players/{id:[0-9]+}/timeline", Handler: v.PollTimeline, Methods: get},
+		testutil.Route{Path: "/votes/players/{id:[0-9]+}/segments", Handler: v.SegmentVotes, Methods: get},
+		testutil.Route{Path: "/votes/players/retract", Handler: v.RetractVote, Methods: post},
+		testutil.Route{Path: "/votes/players", Handler: v.InsertPlayerVotes, Methods: post},
+		testutil.Route{Path: "/votes/guest", Handler: v.InsertGuestVote, Methods: post},
+	)
+}
+
+func vote(t *testing.T, router http.Handler, payload VotePayload) {
+	t.Helper()
+	if w := testutil.ServeJSON(t, router, "POST", "/votes/players", payload); w.Code != http.StatusOK {
+		t.Fatalf("vote %+v: status = %d, want %d: %s", payload, w.Code, http.StatusOK, w.Body)
+	}
+}
+
+// tallies returns the results of the poll as name=votes, in the order they're listed
+func tallies(t *testing.T, router http.Handler, pollID, userID int64) string {
+	t.Helper()
+	w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/votes/players/%d?userid=%d", pollID, userID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var results []Votes
+	testutil.Decode(t, w, &results)
+
+	var list []string
+	for _, r := range results {
+		list = append(list, fmt.Sprintf("%s=%d", r.Name, r.Value))
+	}
+	return fmt.Sprint(list)
+}
+
+func TestPlayerVotes(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.OwnerID, PlayerID: "p2"})
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p2"})
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.OutsiderID, PlayerID: "p1"})
+	if got, want := tallies(t, router, playerPollID, 0), "[Player Two=2 Player One=1]"; got != want {
+		t.Errorf("tallies = %s, want %s", got, want)
+	}
+
+	// a second vote replaces the first one
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p1"})
+	if got, want := tallies(t, router, playerPollID, 0), "[Player One=2 Player Two=1]"; got != want {
+		t.Errorf("tallies after a changed vote = %s, want %s", got, want)
+	}
+
+	if w := testutil.ServeJSON(t, router, "GET", "/votes/players/99", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing poll: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestPlayerVotesOnlyForThePollCandidates(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+
+	// the bench player doesn't play enough minutes for the poll and p9 didn't play in its season
+	for _, id := range []string{"p3", "p9"} {
+		if w := testutil.ServeJSON(t, router, "POST", "/votes/players", VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: id}); w.Code != http.StatusBadRequest {
+			t.Errorf("vote for %s: status = %d, want %d", id, w.Code, http.StatusBadRequest)
+		}
+
+		if w := testutil.ServeJSON(t, router, "POST", "/votes/guest", guestBallot(id)); w.Code != http.StatusBadRequest {
+			t.Errorf("guest vote for %s: status = %d, want %d", id, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p1"})
+	if w := testutil.ServeJSON(t, router, "POST", "/votes/guest", guestBallot("p2")); w.Code != http.StatusOK {
+		t.Errorf("guest vote for p2: status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+// guestBallot is a guest vote for the player in the public poll, from a device of its own
+func guestBallot(playerID string) GuestVotePayload {
+	deviceID := "device-" + playerID
+	return GuestVotePayload{Token: deviceID + "." + signDevice(deviceID), PollID: playerPollID, PlayerID: playerID}
+}
+
+func TestGroupPollVotesOnlyForMembers(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+
+	if w := testutil.ServeJSON(t, router, "POST", "/votes/players", VotePayload{PollID: groupPollID, UserID: testutil.OutsiderID, CandidateID: 1}); w.Code != http.StatusNotFound {
+		t.Errorf("outsider vote: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	vote(t, router, VotePayload{PollID: groupPollID, UserID: testutil.MemberID, CandidateID: 2})
+	if got, want := tallies(t, router, groupPollID, testutil.MemberID), "[Foxtrot=1]"; got != want {
+		t.Errorf("tallies = %s, want %s", got, want)
+	}
+
+	if w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/votes/players/%d?userid=%d", groupPollID, testutil.OutsiderID), nil); w.Code != http.StatusNotFound {
+		t.Errorf("outsider results: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestRetractVote(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+	retract := RetractPayload{PollID: playerPollID, UserID: testutil.MemberID}
+
+	if w := testutil.ServeJSON(t, router, "POST", "/votes/players/retract", retract); w.Code != http.StatusNotFound {
+		t.Errorf("retract without a vote: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p1"})
+	if w := testutil.ServeJSON(t, router, "POST", "/votes/players/retract", retract); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if got := tallies(t, router, playerPollID, 0); got != "[]" {
+		t.Errorf("tallies after retracting = %s, want none", got)
+	}
+
+	w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/votes/users/%d/history", testutil.MemberID), nil)
+	var events []databasestructs.VoteEvent
+	testutil.Decode(t, w, &events)
+
+	var history []string
+	for _, e := range events {
+		history = append(history, e.Event)
+	}
+	if want := "[retracted cast]"; fmt.Sprint(history) != want {
+		t.Errorf("history = %v, want %s", history, want)
+	}
+}
+
+func TestVoteHistoryOnlyInVisiblePolls(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p1"})
+	vote(t, router, VotePayload{PollID: groupPollID, UserID: testutil.MemberID, CandidateID: 1})
+
+	for _, tc := range []struct {
+		viewerID int64
+		want     string
+	}{
+		{0, "[1]"},
+		{testutil.OutsiderID, "[1]"},
+		{testutil.OwnerID, "[2 1]"},
+		{testutil.MemberID, "[2 1]"},
+	} {
+		w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/votes/users/%d/history?userid=%d", testutil.MemberID, tc.viewerID), nil)
+		var events []databasestructs.VoteEvent
+		testutil.Decode(t, w, &events)
+
+		var polls []int64
+		for _, e := range events {
+			polls = append(polls, e.PollID)
+		}
+		if fmt.Sprint(polls) != tc.want {
+			t.Errorf("viewer %d: history in polls %v, want %s", tc.viewerID, polls, tc.want)
+		}
+	}
+}
+
+func TestUserVotesOnlyInVisiblePolls(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p1"})
+	vote(t, router, VotePayload{PollID: groupPollID, UserID: testutil.MemberID, CandidateID: 1})
+
+	for _, tc := range []struct {
+		viewerID int64
+		want     string
+	}{
+		{0, "[1]"},
+		{testutil.OutsiderID, "[1]"},
+		{testutil.MemberID, "[1 2]"},
+	} {
+		w := testutil.ServeJSON(t, router, "GET", fmt.Sprintf("/votes/users/get/%d?userid=%d", testutil.MemberID, tc.viewerID), nil)
+		var votes []MyVotesResponse
+		testutil.Decode(t, w, &votes)
+
+		var polls []string
+		for _, v := range votes {
+			polls = append(polls, v.PollID)
+		}
+		if fmt.Sprint(polls) != tc.want {
+			t.Errorf("viewer %d: votes in polls %v, want %s", tc.viewerID, polls, tc.want)
+		}
+	}
+}
+
+// fixedTimeline replays the given vote history instead of the one written with the votes, which all happen at the same time in a test
+type fixedTimeline struct {
+	database.Database
+	buckets []databasestructs.TimelineBucket
+}
+
+func (f fixedTimeline) GetPollVoteTimeline(ctx context.Context, pollid, bucketSeconds int64) ([]databasestructs.TimelineBucket, error) {
+	return f.buckets, nil
+}
+
+func TestPollTimelineReplaysHistory(t *testing.T) {
+	db := newTestDB(t)
+	router := newTestRouter(db)
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.OwnerID, PlayerID: "p1"})
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p2"})
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.OutsiderID, PlayerID: "p1"})
+
+	// the owner and the member voted for player one in the first hour and the member changed to player two in the second,
+	// the outsider's vote is from before the history was kept
+	router = newTestRouter(fixedTimeline{Database: db, buckets: []databasestructs.TimelineBucket{
+		{Start: 3600, Name: "Player One", Votes: 2},
+		{Start: 7200, Name: "Player One", Votes: -1},
+		{Start: 7200, Name: "Player Two", Votes: 1},
+	}})
+
+	w := testutil.ServeJSON(t, router, "GET", "/votes/players/1/timeline?interval=hour", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var timeline TimelineResponse
+	testutil.Decode(t, w, &timeline)
+
+	var points []string
+	for _, p := range timeline.Points {
+		var shares []string
+		for _, s := range p.Shares {
+			shares = append(shares, fmt.Sprintf("%s=%d", s.Name, s.Votes))
+		}
+		points = append(points, fmt.Sprintf("%d %v", p.Time, shares))
+	}
+	if want := "[7200 [Player One=3] 10800 [Player One=2 Player Two=1]]"; fmt.Sprint(points) != want {
+		t.Errorf("timeline = %v, want %s", points, want)
+	}
+}
+
+func TestProtectSegments(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ballots map[string]int64
+		want    string
+	}{
+		{"all big enough", map[string]int64{"BOS": 12, "LAL": 15}, "[LAL=15 BOS=12]"},
+		{"small ones merged", map[string]int64{"BOS": 12, "LAL": 4, "NYK": 7}, "[BOS=12 other=11]"},
+		// 3 ballots would be the total minus the published segments, the smallest one hides them
+		{"other still too small", map[string]int64{"BOS": 12, "LAL": 15, "NYK": 3}, "[LAL=15 other=15]"},
+		{"only one segment left", map[string]int64{"BOS": 12, "NYK": 3}, "[other=15]"},
+		{"nothing big enough", map[string]int64{"NYK": 5}, "[]"},
+	} {
+		bySegment := make(map[string]map[string]int64)
+		for segment, ballots := range tc.ballots {
+			bySegment[segment] = map[string]int64{"Player One": ballots}
+		}
+
+		list := []string{}
+		for _, s := range protectSegments(bySegment) {
+			list = append(list, fmt.Sprintf("%s=%d", s.Segment, s.Ballots))
+		}
+		if fmt.Sprint(list) != tc.want {
+			t.Errorf("%s: segments = %v, want %s", tc.name, list, tc.want)
+		}
+	}
+}
+
+func TestConfidenceOf(t *testing.T) {
+	for _, tc := range []struct {
+		counts []int64
+		tied   []bool
+	}{
+		{[]int64{0, 0}, []bool{false, false}},
+		{[]int64{1, 9}, []bool{false, false}},
+		{[]int64{5, 5}, []bool{true, true}},
+		// the leader doesn't have to come first, as in the guest and segment tallies
+		{[]int64{3, 4, 40}, []bool{false, false, false}},
+		{[]int64{2, 3, 0}, []bool{true, true, false}},
+	} {
+		results := confidenceOf(tc.counts)
+
+		for i, result := range results {
+			if result.TiedForLead != tc.tied[i] {
+				t.Errorf("%v: count %d tied = %v, want %v", tc.counts, tc.counts[i], result.TiedForLead, tc.tied[i])
+			}
+			if tc.counts[i] > 0 && !(result.ShareLow < result.Share && result.Share < result.ShareHigh) {
+				t.Errorf("%v: count %d interval = %v to %v, want it around the share %v", tc.counts, tc.counts[i], result.ShareLow, result.ShareHigh, result.Share)
+			}
+		}
+	}
+}
+
+func TestPanelVotesCarryConfidence(t *testing.T) {
+	router := newTestRouter(newTestDB(t))
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.OwnerID, PlayerID: "p1"})
+	vote(t, router, VotePayload{PollID: playerPollID, UserID: testutil.MemberID, PlayerID: "p2"})
+
+	w := testutil.ServeJSON(t, router, "GET", "/votes/players/1/panel", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var response PanelResultsResponse
+	testutil.Decode(t, w, &response)
+	if len(response.Fans) != 2 {
+		t.Fatalf("fans = %+v, want both players", response.Fans)
+	}
+	for _, tally := range response.Fans {
+		if tally.Share != 50 || !tally.TiedForLead || tally.MarginOfError == 0 {
+			t.Errorf("fan tally = %+v, want half the ballots, tied for the lead and a margin of error", tally)
+		}
+	}
+}